@@ -15,6 +15,7 @@ import (
 	"github.com/mrbentarikau/pagst/common"
 	"github.com/mrbentarikau/pagst/common/config"
 	"github.com/mrbentarikau/pagst/common/internalapi"
+	"github.com/mrbentarikau/pagst/common/mqueue"
 	"github.com/mrbentarikau/pagst/lib/dshardorchestrator/orchestrator/rest"
 	"github.com/mrbentarikau/pagst/web"
 	"goji.io"
@@ -27,10 +28,14 @@ var PageHTMLPanel string
 //go:embed assets/bot_admin_config.html
 var PageHTMLConfig string
 
+//go:embed assets/bot_admin_mqueue.html
+var PageHTMLMqueue string
+
 // InitWeb implements web.Plugin
 func (p *Plugin) InitWeb() {
 	web.AddHTMLTemplate("admin/assets/bot_admin_panel.html", PageHTMLPanel)
 	web.AddHTMLTemplate("admin/assets/bot_admin_config.html", PageHTMLConfig)
+	web.AddHTMLTemplate("admin/assets/bot_admin_mqueue.html", PageHTMLMqueue)
 
 	mux := goji.SubMux()
 	web.RootMux.Handle(pat.New("/admin/*"), mux)
@@ -69,6 +74,12 @@ func (p *Plugin) InitWeb() {
 	getConfigHandler := web.ControllerHandler(p.handleGetConfig, "bot_admin_config")
 	mux.Handle(pat.Get("/config"), getConfigHandler)
 	mux.Handle(pat.Post("/config/edit/:key"), web.ControllerPostHandler(p.handleEditConfig, getConfigHandler, nil))
+
+	// Message queue dead letters
+	getDeadLettersHandler := web.ControllerHandler(p.handleGetDeadLetters, "bot_admin_mqueue")
+	mux.Handle(pat.Get("/mqueue"), getDeadLettersHandler)
+	mux.Handle(pat.Post("/mqueue/replay"), web.ControllerPostHandler(p.handleReplayDeadLetters, getDeadLettersHandler, nil))
+	mux.Handle(pat.Post("/mqueue/purge"), web.ControllerPostHandler(p.handlePurgeDeadLetters, getDeadLettersHandler, nil))
 }
 
 type Host struct {
@@ -380,3 +391,69 @@ func (p *Plugin) handleReconnectAll(w http.ResponseWriter, r *http.Request) {
 		time.Sleep(time.Second * 5)
 	}
 }
+
+func (p *Plugin) handleGetDeadLetters(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	_, tmpl := web.GetBaseCPContextData(r.Context())
+
+	sources, err := mqueue.DeadLetterSources()
+	if err != nil {
+		return tmpl, err
+	}
+
+	source := r.FormValue("source")
+	deadLetters, err := mqueue.GetDeadLetters(source, 100)
+	if err != nil {
+		return tmpl, err
+	}
+
+	tmpl["DeadLetterSources"] = sources
+	tmpl["DeadLetters"] = deadLetters
+	tmpl["Source"] = source
+
+	return tmpl, nil
+}
+
+func deadLetterForm(r *http.Request) (source string, id int64, err error) {
+	source = r.FormValue("source")
+	if source == "" {
+		return "", 0, errors.New("No source specified")
+	}
+
+	if idStr := r.FormValue("id"); idStr != "" {
+		id, err = strconv.ParseInt(idStr, 10, 64)
+	}
+
+	return
+}
+
+func (p *Plugin) handleReplayDeadLetters(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	_, tmpl := web.GetBaseCPContextData(r.Context())
+
+	source, id, err := deadLetterForm(r)
+	if err != nil {
+		return tmpl.AddAlerts(web.ErrorAlert(err.Error())), nil
+	}
+
+	n, err := mqueue.ReplayDeadLetters(source, id)
+	if err != nil {
+		return tmpl.AddAlerts(web.ErrorAlert(fmt.Sprintf("Replayed %d before failing: %v", n, err))), nil
+	}
+
+	return tmpl.AddAlerts(web.SucessAlert(fmt.Sprintf("Queued %d dead letters from %s again", n, source))), nil
+}
+
+func (p *Plugin) handlePurgeDeadLetters(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	_, tmpl := web.GetBaseCPContextData(r.Context())
+
+	source, id, err := deadLetterForm(r)
+	if err != nil {
+		return tmpl.AddAlerts(web.ErrorAlert(err.Error())), nil
+	}
+
+	n, err := mqueue.PurgeDeadLetters(source, id)
+	if err != nil {
+		return tmpl, err
+	}
+
+	return tmpl.AddAlerts(web.SucessAlert(fmt.Sprintf("Deleted %d dead letters from %s", n, source))), nil
+}
@@ -0,0 +1,307 @@
+package announcements
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/common/scheduledevents2"
+	"github.com/mrbentarikau/pagst/common/templates"
+	"github.com/mrbentarikau/pagst/lib/discordgo"
+	"github.com/mrbentarikau/pagst/lib/dstate"
+	"github.com/mrbentarikau/pagst/premium"
+	"github.com/mrbentarikau/pagst/reminders"
+	"github.com/mrbentarikau/pagst/timezonecompanion"
+)
+
+const (
+	MaxAnnouncements        = 25
+	MaxAnnouncementsPremium = 50
+
+	MaxMessageLength = 2000
+	MaxEmbedLength   = 6000
+
+	// How far ahead an announcement can be scheduled
+	MaxScheduleAhead = time.Hour * 24 * 366
+)
+
+var logger = common.GetPluginLogger(&Plugin{})
+
+type Plugin struct{}
+
+func (p *Plugin) PluginInfo() *common.PluginInfo {
+	return &common.PluginInfo{
+		Name:     "Announcements",
+		SysName:  "announcements",
+		Category: common.PluginCategoryMisc,
+	}
+}
+
+func RegisterPlugin() {
+	err := common.GORM.AutoMigrate(&ScheduledAnnouncement{}, &GuildConfig{}).Error
+	if err != nil {
+		panic(err)
+	}
+
+	common.RegisterPlugin(&Plugin{})
+}
+
+// ScheduledAnnouncement is a message staff scheduled to be sent in a channel, once or on a recurrence
+type ScheduledAnnouncement struct {
+	common.SmallModel
+
+	GuildID   int64 `gorm:"index"`
+	ChannelID int64
+	AuthorID  int64
+
+	// Message is executed as a template when the announcement is sent
+	Message string
+	// Embed is an optional embed in the discord JSON format
+	Embed string
+
+	MentionEveryone bool
+	MentionRoles    pq.Int64Array `gorm:"type:bigint[]"`
+
+	// Crosspost publishes the message if it's sent in an announcement channel
+	Crosspost bool
+
+	NextRun    time.Time
+	Recurrence string
+
+	// LastError is the reason the last run failed, empty if it succeeded
+	LastError string
+}
+
+func (a *ScheduledAnnouncement) TableName() string {
+	return "scheduled_announcements"
+}
+
+// ParsedRecurrence returns the recurrence of the announcement, or nil if it's only sent once
+func (a *ScheduledAnnouncement) ParsedRecurrence() *reminders.Recurrence {
+	if a.Recurrence == "" {
+		return nil
+	}
+
+	r, err := reminders.ParseRecurrence(a.Recurrence)
+	if err != nil {
+		logger.WithError(err).WithField("announcement", a.ID).Error("failed parsing stored recurrence")
+		return nil
+	}
+
+	return r
+}
+
+// ParsedEmbed returns the embed of the announcement, or nil if it has none
+func (a *ScheduledAnnouncement) ParsedEmbed() (*discordgo.MessageEmbed, error) {
+	return ParseEmbed(a.Embed)
+}
+
+// GuildConfig holds the announcement settings of a guild
+type GuildConfig struct {
+	GuildID  int64 `gorm:"primary_key" sql:"AUTO_INCREMENT:false"`
+	Timezone string
+}
+
+func (c *GuildConfig) TableName() string {
+	return "announcement_guild_configs"
+}
+
+// ParseEmbed parses an embed in the discord JSON format, empty input returns a nil embed
+func ParseEmbed(s string) (*discordgo.MessageEmbed, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	if len(s) > MaxEmbedLength {
+		return nil, fmt.Errorf("embed is too long (max %d characters)", MaxEmbedLength)
+	}
+
+	var embed discordgo.MessageEmbed
+	err := json.Unmarshal([]byte(s), &embed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid embed JSON: %v", err)
+	}
+
+	return &embed, nil
+}
+
+func validateMessage(msg string) error {
+	if strings.TrimSpace(msg) == "" {
+		return errors.New("the message can't be empty")
+	}
+
+	if n := utf8.RuneCountInString(msg); n > MaxMessageLength {
+		return fmt.Errorf("the message is too long (%d/%d)", n, MaxMessageLength)
+	}
+
+	_, err := templates.NewContext(nil, nil, nil).Parse(msg)
+	return err
+}
+
+// LoadTimezone resolves a timezone name, falling back to the timezonecompanion search
+// if it's not an exact IANA name and only one zone matches
+func LoadTimezone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return time.UTC, nil
+	}
+
+	if loc, err := time.LoadLocation(name); err == nil {
+		return loc, nil
+	}
+
+	zones := timezonecompanion.FindZone(name)
+	if len(zones) != 1 {
+		return nil, fmt.Errorf("unknown timezone %q, use a name like Europe/Tallinn or America/New_York", name)
+	}
+
+	return time.LoadLocation(zones[0])
+}
+
+// GuildTimezone returns the timezone announcements are scheduled in for the guild, UTC by default
+func GuildTimezone(guildID int64) *time.Location {
+	var conf GuildConfig
+	err := common.GORM.Where("guild_id = ?", guildID).First(&conf).Error
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			logger.WithError(err).WithField("guild", guildID).Error("failed retrieving guild config")
+		}
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(conf.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+// SetGuildTimezone stores the timezone of the guild, returning the resolved location
+func SetGuildTimezone(guildID int64, name string) (*time.Location, error) {
+	loc, err := LoadTimezone(name)
+	if err != nil {
+		return nil, err
+	}
+
+	err = common.GORM.Save(&GuildConfig{GuildID: guildID, Timezone: loc.String()}).Error
+	return loc, err
+}
+
+func MaxAnnouncementsForGuild(guildID int64) int {
+	if isPremium, _ := premium.IsGuildPremium(guildID); isPremium {
+		return MaxAnnouncementsPremium
+	}
+
+	return MaxAnnouncements
+}
+
+func CountAnnouncements(guildID int64) (int, error) {
+	var count int
+	err := common.GORM.Model(&ScheduledAnnouncement{}).Where("guild_id = ?", guildID).Count(&count).Error
+	return count, err
+}
+
+func GuildAnnouncements(guildID int64) ([]*ScheduledAnnouncement, error) {
+	var result []*ScheduledAnnouncement
+	err := common.GORM.Where("guild_id = ?", guildID).Order("next_run asc").Find(&result).Error
+	return result, err
+}
+
+var ErrNotFound = errors.New("announcement not found")
+
+func FindAnnouncement(guildID int64, id int64) (*ScheduledAnnouncement, error) {
+	var a ScheduledAnnouncement
+	err := common.GORM.Where("guild_id = ? AND id = ?", guildID, id).First(&a).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &a, nil
+}
+
+// FirstRun returns when an announcement starting at start should first be sent,
+// recurring announcements are sent on the first occurrence at or after start
+func FirstRun(start time.Time, rec *reminders.Recurrence, loc *time.Location) time.Time {
+	if rec == nil {
+		return start
+	}
+
+	return rec.Next(start.In(loc).Add(-time.Second))
+}
+
+// UpcomingRuns returns up to n of the next times the announcement is sent
+func UpcomingRuns(a *ScheduledAnnouncement, loc *time.Location, n int) []time.Time {
+	result := []time.Time{a.NextRun.In(loc)}
+
+	rec := a.ParsedRecurrence()
+	if rec == nil {
+		return result
+	}
+
+	for len(result) < n {
+		result = append(result, rec.Next(result[len(result)-1]))
+	}
+
+	return result
+}
+
+// Schedule saves the announcement and schedules it to be sent at NextRun. Events of
+// previous schedules are ignored when they fire, as NextRun no longer matches them.
+func Schedule(a *ScheduledAnnouncement) error {
+	err := common.GORM.Save(a).Error
+	if err != nil {
+		return err
+	}
+
+	return scheduledevents2.ScheduleEvent("announcements_send", a.GuildID, a.NextRun, int64(a.ID))
+}
+
+func Cancel(a *ScheduledAnnouncement) error {
+	return common.GORM.Delete(a).Error
+}
+
+// Render executes the announcement message in the channel and returns the message to send
+// and whether it should be published
+func Render(a *ScheduledAnnouncement, gs *dstate.GuildSet, cs *dstate.ChannelState, ms *dstate.MemberState) (*discordgo.MessageSend, bool, error) {
+	ctx := templates.NewContext(gs, cs, ms)
+	ctx.Name = fmt.Sprintf("announcement #%d", a.ID)
+	ctx.CurrentFrame.MentionEveryone = a.MentionEveryone
+	ctx.CurrentFrame.MentionRoles = a.MentionRoles
+	ctx.CurrentFrame.PublishResponse = a.Crosspost
+	ctx.Data["AnnouncementID"] = a.ID
+	ctx.Data["ScheduledAt"] = a.NextRun
+	ctx.Data["Recurrence"] = a.Recurrence
+
+	content, err := ctx.Execute(a.Message)
+	if err != nil {
+		return nil, false, err
+	}
+
+	msg := ctx.MessageSend(content)
+	msg.Embeds = append(msg.Embeds, ctx.CurrentFrame.EmbedsToSend...)
+
+	embed, err := a.ParsedEmbed()
+	if err != nil {
+		return nil, false, err
+	}
+	if embed != nil {
+		msg.Embeds = append(msg.Embeds, embed)
+	}
+
+	if strings.TrimSpace(msg.Content) == "" && len(msg.Embeds) == 0 {
+		return nil, false, errors.New("the announcement rendered to an empty message")
+	}
+
+	return msg, ctx.CurrentFrame.PublishResponse, nil
+}
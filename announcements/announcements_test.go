@@ -0,0 +1,80 @@
+package announcements
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrbentarikau/pagst/reminders"
+)
+
+func TestFirstRun(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Tallinn")
+	if err != nil {
+		t.Skip("no tzdata: ", err)
+	}
+
+	// a wednesday
+	start := time.Date(2024, 3, 13, 10, 0, 0, 0, loc)
+
+	cases := []struct {
+		Recurrence string
+		Expected   time.Time
+	}{
+		{"", start},
+		{"every day at 10:00", start},
+		{"every day at 9:00", time.Date(2024, 3, 14, 9, 0, 0, 0, loc)},
+		{"friday at 17:00", time.Date(2024, 3, 15, 17, 0, 0, 0, loc)},
+		{"first monday of the month at 12:00", time.Date(2024, 4, 1, 12, 0, 0, 0, loc)},
+	}
+
+	for _, c := range cases {
+		var rec *reminders.Recurrence
+		if c.Recurrence != "" {
+			rec, err = reminders.ParseRecurrence(c.Recurrence)
+			if err != nil {
+				t.Fatalf("%q: %v", c.Recurrence, err)
+			}
+		}
+
+		if got := FirstRun(start.UTC(), rec, loc); !got.Equal(c.Expected) {
+			t.Errorf("%q: got %s, expected %s", c.Recurrence, got, c.Expected)
+		}
+	}
+}
+
+func TestUpcomingRuns(t *testing.T) {
+	next := time.Date(2024, 3, 15, 17, 0, 0, 0, time.UTC)
+
+	once := &ScheduledAnnouncement{NextRun: next}
+	if got := UpcomingRuns(once, time.UTC, 3); len(got) != 1 || !got[0].Equal(next) {
+		t.Errorf("one-off announcement: got %v", got)
+	}
+
+	weekly := &ScheduledAnnouncement{NextRun: next, Recurrence: "every friday at 17:00"}
+	got := UpcomingRuns(weekly, time.UTC, 3)
+	expected := []time.Time{next, next.AddDate(0, 0, 7), next.AddDate(0, 0, 14)}
+	if len(got) != len(expected) {
+		t.Fatalf("weekly announcement: got %v, expected %v", got, expected)
+	}
+	for i := range expected {
+		if !got[i].Equal(expected[i]) {
+			t.Errorf("weekly announcement run %d: got %s, expected %s", i, got[i], expected[i])
+		}
+	}
+}
+
+func TestParseEmbed(t *testing.T) {
+	embed, err := ParseEmbed(" ")
+	if embed != nil || err != nil {
+		t.Errorf("empty embed: got %v, %v", embed, err)
+	}
+
+	embed, err = ParseEmbed(`{"title": "Weekly update", "color": 4367861}`)
+	if err != nil || embed.Title != "Weekly update" || embed.Color != 4367861 {
+		t.Errorf("valid embed: got %+v, %v", embed, err)
+	}
+
+	if _, err = ParseEmbed(`{"title": `); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
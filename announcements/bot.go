@@ -0,0 +1,484 @@
+package announcements
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/mrbentarikau/pagst/bot"
+	"github.com/mrbentarikau/pagst/commands"
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/common/scheduledevents2"
+	seventsmodels "github.com/mrbentarikau/pagst/common/scheduledevents2/models"
+	"github.com/mrbentarikau/pagst/lib/dcmd"
+	"github.com/mrbentarikau/pagst/lib/discordgo"
+	"github.com/mrbentarikau/pagst/lib/dstate"
+	"github.com/mrbentarikau/pagst/reminders"
+)
+
+var _ bot.BotInitHandler = (*Plugin)(nil)
+var _ commands.CommandProvider = (*Plugin)(nil)
+
+func (p *Plugin) BotInit() {
+	scheduledevents2.RegisterHandler("announcements_send", int64(0), handleSendEvent)
+}
+
+func (p *Plugin) AddCommands() {
+	container, _ := commands.CommandSystem.Root.Sub("announce", "announcements", "announcement")
+	container.NotFound = commands.CommonContainerNotFoundHandler(container, "")
+	container.Description = "Schedule announcements, optionally on a recurrence"
+
+	cmdSchedule := &commands.YAGCommand{
+		CmdCategory: commands.CategoryTool,
+		Plugin:      p,
+		Name:        "Schedule",
+		Aliases:     []string{"new", "add"},
+		Description: "Schedules an announcement in the server's timezone (see `announce timezone`). The message is a template, so it can use template functions like `cembed` and `publishResponse`.\n" +
+			"The time is either a duration from now or a date like \"tomorrow 18:00\". With -every the time is when the recurrence starts, example: `announce schedule #news tomorrow \"Weekly update!\" -every \"friday at 17:00\"`",
+		RequireDiscordPerms: []int64{discordgo.PermissionManageServer},
+		RequiredArgs:        3,
+		Arguments: []*dcmd.ArgDef{
+			{Name: "Channel", Type: dcmd.Channel},
+			{Name: "Time", Type: dcmd.String},
+			{Name: "Message", Type: dcmd.String},
+		},
+		ArgSwitches: []*dcmd.ArgDef{
+			{Name: "every", Help: "Recurrence, e.g. \"weekday at 9:00\" or \"first monday of the month\"", Type: dcmd.String},
+			{Name: "embed", Help: "Embed in the discord JSON format", Type: dcmd.String},
+			{Name: "role", Help: "Role allowed to be mentioned", Type: &commands.RoleArg{}},
+			{Name: "everyone", Help: "Allow mentioning everyone and here"},
+			{Name: "crosspost", Help: "Publish the message in announcement channels"},
+		},
+		RunFunc: cmdFuncSchedule,
+	}
+
+	cmdList := &commands.YAGCommand{
+		CmdCategory:         commands.CategoryTool,
+		Plugin:              p,
+		Name:                "List",
+		Aliases:             []string{"ls"},
+		Description:         "Lists the scheduled announcements of this server",
+		RequireDiscordPerms: []int64{discordgo.PermissionManageServer},
+		RunFunc:             cmdFuncList,
+	}
+
+	cmdEdit := &commands.YAGCommand{
+		CmdCategory:         commands.CategoryTool,
+		Plugin:              p,
+		Name:                "Edit",
+		Description:         "Edits a scheduled announcement, use \"none\" with -every or -embed to remove them",
+		RequireDiscordPerms: []int64{discordgo.PermissionManageServer},
+		RequiredArgs:        1,
+		Arguments: []*dcmd.ArgDef{
+			{Name: "ID", Type: dcmd.Int},
+		},
+		ArgSwitches: []*dcmd.ArgDef{
+			{Name: "message", Help: "New message", Type: dcmd.String},
+			{Name: "time", Help: "New time", Type: dcmd.String},
+			{Name: "channel", Help: "New channel", Type: dcmd.Channel},
+			{Name: "every", Help: "New recurrence", Type: dcmd.String},
+			{Name: "embed", Help: "New embed", Type: dcmd.String},
+		},
+		RunFunc: cmdFuncEdit,
+	}
+
+	cmdCancel := &commands.YAGCommand{
+		CmdCategory:         commands.CategoryTool,
+		Plugin:              p,
+		Name:                "Cancel",
+		Aliases:             []string{"delete", "del", "rm"},
+		Description:         "Cancels a scheduled announcement",
+		RequireDiscordPerms: []int64{discordgo.PermissionManageServer},
+		RequiredArgs:        1,
+		Arguments: []*dcmd.ArgDef{
+			{Name: "ID", Type: dcmd.Int},
+		},
+		RunFunc: func(parsed *dcmd.Data) (interface{}, error) {
+			a, err := FindAnnouncement(parsed.GuildData.GS.ID, parsed.Args[0].Int64())
+			if err != nil {
+				if err == ErrNotFound {
+					return "Unknown announcement", nil
+				}
+				return nil, err
+			}
+
+			err = Cancel(a)
+			if err != nil {
+				return nil, err
+			}
+
+			return fmt.Sprintf("Cancelled announcement #%d", a.ID), nil
+		},
+	}
+
+	cmdPreview := &commands.YAGCommand{
+		CmdCategory:         commands.CategoryTool,
+		Plugin:              p,
+		Name:                "Preview",
+		Description:         "Renders a scheduled announcement in the current channel without mentioning anyone. Template functions that send or edit messages still run.",
+		RequireDiscordPerms: []int64{discordgo.PermissionManageServer},
+		RequiredArgs:        1,
+		Arguments: []*dcmd.ArgDef{
+			{Name: "ID", Type: dcmd.Int},
+		},
+		RunFunc: func(parsed *dcmd.Data) (interface{}, error) {
+			a, err := FindAnnouncement(parsed.GuildData.GS.ID, parsed.Args[0].Int64())
+			if err != nil {
+				if err == ErrNotFound {
+					return "Unknown announcement", nil
+				}
+				return nil, err
+			}
+
+			msg, _, err := Render(a, parsed.GuildData.GS, parsed.GuildData.CS, parsed.GuildData.MS)
+			if err != nil {
+				return "Failed rendering the announcement: " + err.Error(), nil
+			}
+
+			msg.Content = fmt.Sprintf("Preview of announcement #%d, sent in <#%d> <t:%d:R>:\n\n%s", a.ID, a.ChannelID, a.NextRun.Unix(), msg.Content)
+			msg.AllowedMentions = discordgo.AllowedMentions{Parse: []discordgo.AllowedMentionType{}}
+			return msg, nil
+		},
+	}
+
+	cmdTimezone := &commands.YAGCommand{
+		CmdCategory:         commands.CategoryTool,
+		Plugin:              p,
+		Name:                "Timezone",
+		Aliases:             []string{"tz"},
+		Description:         "Shows or sets the timezone announcements are scheduled in, e.g. `announce timezone Europe/Tallinn`",
+		RequireDiscordPerms: []int64{discordgo.PermissionManageServer},
+		Arguments: []*dcmd.ArgDef{
+			{Name: "Timezone", Type: dcmd.String},
+		},
+		RunFunc: func(parsed *dcmd.Data) (interface{}, error) {
+			if parsed.Args[0].Value == nil {
+				return fmt.Sprintf("Announcements are scheduled in `%s`", GuildTimezone(parsed.GuildData.GS.ID)), nil
+			}
+
+			loc, err := SetGuildTimezone(parsed.GuildData.GS.ID, parsed.Args[0].Str())
+			if err != nil {
+				return err.Error(), nil
+			}
+
+			return fmt.Sprintf("Announcements are now scheduled in `%s`, already scheduled ones keep their time", loc), nil
+		},
+	}
+
+	container.AddCommand(cmdSchedule, cmdSchedule.GetTrigger())
+	container.AddCommand(cmdList, cmdList.GetTrigger())
+	container.AddCommand(cmdEdit, cmdEdit.GetTrigger())
+	container.AddCommand(cmdCancel, cmdCancel.GetTrigger())
+	container.AddCommand(cmdPreview, cmdPreview.GetTrigger())
+	container.AddCommand(cmdTimezone, cmdTimezone.GetTrigger())
+	commands.RegisterSlashCommandsContainer(container, false, func(gs *dstate.GuildSet) ([]int64, error) {
+		return nil, nil
+	})
+}
+
+func cmdFuncSchedule(parsed *dcmd.Data) (interface{}, error) {
+	guildID := parsed.GuildData.GS.ID
+
+	count, err := CountAnnouncements(guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit := MaxAnnouncementsForGuild(guildID); count >= limit {
+		return fmt.Sprintf("Max %d scheduled announcements allowed (%d for premium servers)", MaxAnnouncements, MaxAnnouncementsPremium), nil
+	}
+
+	channel := parsed.Args[0].Value.(*dstate.ChannelState)
+	if resp := checkChannel(parsed, channel.ID); resp != "" {
+		return resp, nil
+	}
+
+	a := &ScheduledAnnouncement{
+		GuildID:   guildID,
+		ChannelID: channel.ID,
+		AuthorID:  parsed.Author.ID,
+		Message:   parsed.Args[2].Str(),
+		Crosspost: parsed.Switch("crosspost").Bool(),
+	}
+
+	if parsed.Switch("everyone").Bool() {
+		hasPerms, err := bot.AdminOrPermMS(guildID, channel.ID, parsed.GuildData.MS, discordgo.PermissionMentionEveryone)
+		if err != nil {
+			return nil, err
+		}
+		if !hasPerms {
+			return "You need the mention everyone permission in that channel to use -everyone", nil
+		}
+		a.MentionEveryone = true
+	}
+
+	if r := parsed.Switch("role"); r.Value != nil {
+		a.MentionRoles = append(a.MentionRoles, r.Value.(*discordgo.Role).ID)
+	}
+
+	if e := parsed.Switch("embed"); e.Value != nil {
+		a.Embed = e.Str()
+	}
+
+	if resp := validateContent(a); resp != "" {
+		return resp, nil
+	}
+
+	loc := GuildTimezone(guildID)
+	start, err := reminders.ParseTime(parsed.Args[1].Str(), parsed.Author.ID, loc)
+	if err != nil {
+		return "Couldn't understand that time", nil
+	}
+
+	var rec *reminders.Recurrence
+	if every := parsed.Switch("every"); every.Value != nil {
+		rec, err = reminders.ParseRecurrence(every.Str())
+		if err != nil {
+			return err.Error(), nil
+		}
+		a.Recurrence = rec.String()
+	}
+
+	a.NextRun = FirstRun(start, rec, loc)
+	if resp := checkRunTime(a.NextRun); resp != "" {
+		return resp, nil
+	}
+
+	err = Schedule(a)
+	if err != nil {
+		return nil, err
+	}
+
+	return fmt.Sprintf("Scheduled announcement #%d in <#%d> %s", a.ID, a.ChannelID, describeSchedule(a)), nil
+}
+
+func cmdFuncList(parsed *dcmd.Data) (interface{}, error) {
+	announcements, err := GuildAnnouncements(parsed.GuildData.GS.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(announcements) == 0 {
+		return "No scheduled announcements, schedule one with `announce schedule`", nil
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "Scheduled announcements (timezone `%s`):\n", GuildTimezone(parsed.GuildData.GS.ID))
+	for _, a := range announcements {
+		fmt.Fprintf(&out, "`#%3d` <#%d> %s: %s\n", a.ID, a.ChannelID, describeSchedule(a), common.CutStringShort(a.Message, 50))
+		if a.LastError != "" {
+			fmt.Fprintf(&out, "> Last run failed: %s\n", common.CutStringShort(a.LastError, 100))
+		}
+	}
+
+	return out.String(), nil
+}
+
+func cmdFuncEdit(parsed *dcmd.Data) (interface{}, error) {
+	guildID := parsed.GuildData.GS.ID
+
+	a, err := FindAnnouncement(guildID, parsed.Args[0].Int64())
+	if err != nil {
+		if err == ErrNotFound {
+			return "Unknown announcement", nil
+		}
+		return nil, err
+	}
+
+	if m := parsed.Switch("message"); m.Value != nil {
+		a.Message = m.Str()
+	}
+
+	if e := parsed.Switch("embed"); e.Value != nil {
+		a.Embed = e.Str()
+		if strings.EqualFold(a.Embed, "none") {
+			a.Embed = ""
+		}
+	}
+
+	if c := parsed.Switch("channel"); c.Value != nil {
+		channelID := c.Value.(*dstate.ChannelState).ID
+		if resp := checkChannel(parsed, channelID); resp != "" {
+			return resp, nil
+		}
+		a.ChannelID = channelID
+	}
+
+	if resp := validateContent(a); resp != "" {
+		return resp, nil
+	}
+
+	loc := GuildTimezone(guildID)
+	rec := a.ParsedRecurrence()
+	rescheduled := false
+
+	if every := parsed.Switch("every"); every.Value != nil {
+		if strings.EqualFold(every.Str(), "none") {
+			rec = nil
+		} else {
+			rec, err = reminders.ParseRecurrence(every.Str())
+			if err != nil {
+				return err.Error(), nil
+			}
+		}
+		rescheduled = true
+	}
+
+	start := time.Now()
+	if a.NextRun.After(start) {
+		start = a.NextRun
+	}
+
+	if t := parsed.Switch("time"); t.Value != nil {
+		start, err = reminders.ParseTime(t.Str(), parsed.Author.ID, loc)
+		if err != nil {
+			return "Couldn't understand that time", nil
+		}
+		rescheduled = true
+	}
+
+	if rescheduled {
+		a.Recurrence = ""
+		if rec != nil {
+			a.Recurrence = rec.String()
+		}
+
+		a.NextRun = FirstRun(start, rec, loc)
+		if resp := checkRunTime(a.NextRun); resp != "" {
+			return resp, nil
+		}
+	}
+
+	a.LastError = ""
+	if rescheduled {
+		err = Schedule(a)
+	} else {
+		err = common.GORM.Save(a).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return fmt.Sprintf("Updated announcement #%d in <#%d> %s", a.ID, a.ChannelID, describeSchedule(a)), nil
+}
+
+// checkChannel returns a response if the announcement can't be scheduled in the channel
+func checkChannel(parsed *dcmd.Data, channelID int64) string {
+	hasPerms, err := bot.AdminOrPermMS(parsed.GuildData.GS.ID, channelID, parsed.GuildData.MS, discordgo.PermissionSendMessages|discordgo.PermissionViewChannel)
+	if err != nil || !hasPerms {
+		return "You do not have permissions to send messages there"
+	}
+
+	if botHasPerms, _ := bot.BotHasPermissionGS(parsed.GuildData.GS, channelID, discordgo.PermissionSendMessages|discordgo.PermissionViewChannel); !botHasPerms {
+		return "I do not have permissions to send messages there"
+	}
+
+	return ""
+}
+
+// validateContent returns a response if the message or embed of the announcement is invalid
+func validateContent(a *ScheduledAnnouncement) string {
+	if err := validateMessage(a.Message); err != nil {
+		return err.Error()
+	}
+
+	if _, err := a.ParsedEmbed(); err != nil {
+		return err.Error()
+	}
+
+	return ""
+}
+
+func checkRunTime(t time.Time) string {
+	if t.Before(time.Now().Add(-time.Minute)) {
+		return "That time is in the past"
+	}
+
+	if t.After(time.Now().Add(MaxScheduleAhead)) {
+		return "Can be max 365 days from now..."
+	}
+
+	return ""
+}
+
+func describeSchedule(a *ScheduledAnnouncement) string {
+	if a.Recurrence != "" {
+		return fmt.Sprintf("%s, next <t:%d:f>", a.Recurrence, a.NextRun.Unix())
+	}
+
+	return fmt.Sprintf("at <t:%d:f>", a.NextRun.Unix())
+}
+
+func handleSendEvent(evt *seventsmodels.ScheduledEvent, data interface{}) (retry bool, err error) {
+	id := *(data.(*int64))
+
+	var a ScheduledAnnouncement
+	err = common.GORM.Where("id = ?", id).First(&a).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			// cancelled
+			return false, nil
+		}
+		return true, err
+	}
+
+	if a.NextRun.Unix() != evt.TriggersAt.Unix() {
+		// rescheduled after this event was created, a newer event handles it
+		return false, nil
+	}
+
+	gs := bot.State.GetGuild(a.GuildID)
+	if gs == nil {
+		return false, nil
+	}
+
+	a.LastError = ""
+	retry, err = sendAnnouncement(gs, &a)
+	if err != nil {
+		if retry {
+			return true, err
+		}
+
+		a.LastError = err.Error()
+		logger.WithError(err).WithField("guild", a.GuildID).WithField("announcement", a.ID).Info("failed sending announcement")
+	}
+
+	if rec := a.ParsedRecurrence(); rec != nil {
+		a.NextRun = rec.Next(time.Now().In(GuildTimezone(a.GuildID)))
+		return false, Schedule(&a)
+	}
+
+	if a.LastError != "" {
+		// keep it around so staff can see why it failed
+		return false, common.GORM.Save(&a).Error
+	}
+
+	return false, Cancel(&a)
+}
+
+func sendAnnouncement(gs *dstate.GuildSet, a *ScheduledAnnouncement) (retry bool, err error) {
+	cs := gs.GetChannelOrThread(a.ChannelID)
+	if cs == nil {
+		return false, fmt.Errorf("channel %d not found", a.ChannelID)
+	}
+
+	msg, publish, err := Render(a, gs, cs, nil)
+	if err != nil {
+		return false, err
+	}
+
+	m, err := common.BotSession.ChannelMessageSendComplex(cs.ID, msg)
+	if err != nil {
+		return scheduledevents2.CheckDiscordErrRetry(err), err
+	}
+
+	if publish && cs.Type == discordgo.ChannelTypeGuildNews {
+		_, err = common.BotSession.ChannelMessageCrosspost(m.ChannelID, m.ID)
+		if err != nil {
+			return false, fmt.Errorf("sent, but failed publishing: %v", err)
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,314 @@
+package announcements
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/common/cplogs"
+	"github.com/mrbentarikau/pagst/lib/discordgo"
+	"github.com/mrbentarikau/pagst/premium"
+	"github.com/mrbentarikau/pagst/reminders"
+	"github.com/mrbentarikau/pagst/web"
+	"goji.io"
+	"goji.io/pat"
+)
+
+//go:embed assets/announcements.html
+var PageHTML string
+
+var (
+	panelLogKeyScheduled = cplogs.RegisterActionFormat(&cplogs.ActionFormat{Key: "announcements_scheduled", FormatString: "Scheduled announcement #%d"})
+	panelLogKeyUpdated   = cplogs.RegisterActionFormat(&cplogs.ActionFormat{Key: "announcements_updated", FormatString: "Updated announcement #%d"})
+	panelLogKeyCancelled = cplogs.RegisterActionFormat(&cplogs.ActionFormat{Key: "announcements_cancelled", FormatString: "Cancelled announcement #%d"})
+	panelLogKeyTimezone  = cplogs.RegisterActionFormat(&cplogs.ActionFormat{Key: "announcements_timezone", FormatString: "Set announcement timezone to %s"})
+)
+
+// datetime-local input format
+const sendAtLayout = "2006-01-02T15:04"
+
+type Form struct {
+	ChannelID       int64  `valid:"channel,false"`
+	Message         string `valid:"template,2000"`
+	Embed           string `valid:",6000"`
+	MentionEveryone bool
+	MentionRoles    []int64 `valid:"role,true"`
+	Crosspost       bool
+	SendAt          string `valid:",32,trimspace"`
+	Recurrence      string `valid:",100,trimspace"`
+}
+
+func (f *Form) Validate(tmpl web.TemplateData, guildID int64) (ok bool) {
+	if strings.TrimSpace(f.Message) == "" {
+		tmpl.AddAlerts(web.ErrorAlert("The message can't be empty"))
+		return false
+	}
+
+	if _, err := ParseEmbed(f.Embed); err != nil {
+		tmpl.AddAlerts(web.ErrorAlert(err))
+		return false
+	}
+
+	if f.Recurrence != "" {
+		if _, err := reminders.ParseRecurrence(f.Recurrence); err != nil {
+			tmpl.AddAlerts(web.ErrorAlert(err))
+			return false
+		}
+	}
+
+	if _, err := time.Parse(sendAtLayout, f.SendAt); err != nil {
+		tmpl.AddAlerts(web.ErrorAlert("Invalid send time"))
+		return false
+	}
+
+	return true
+}
+
+type SettingsForm struct {
+	Timezone string `valid:",64,trimspace"`
+}
+
+// AnnouncementView is an announcement with its upcoming runs for the control panel
+type AnnouncementView struct {
+	*ScheduledAnnouncement
+	ChannelName  string
+	NextRunLocal string
+	Upcoming     []time.Time
+}
+
+func (p *Plugin) InitWeb() {
+	web.AddHTMLTemplate("announcements/assets/announcements.html", PageHTML)
+	web.AddSidebarItem(web.SidebarCategoryTools, &web.SidebarItem{
+		Name: "Announcements",
+		URL:  "announcements",
+		Icon: "fas fa-bullhorn",
+	})
+
+	muxer := goji.SubMux()
+	web.CPMux.Handle(pat.New("/announcements/*"), muxer)
+	web.CPMux.Handle(pat.New("/announcements"), muxer)
+
+	muxer.Use(web.RequireBotMemberMW)
+	muxer.Use(web.RequirePermMW(discordgo.PermissionMentionEveryone))
+
+	mainGetHandler := web.ControllerHandler(HandleAnnouncements, "cp_announcements")
+
+	muxer.Handle(pat.Get("/"), mainGetHandler)
+	muxer.Handle(pat.Get(""), mainGetHandler)
+
+	newHandler := web.ControllerPostHandler(HandleNew, mainGetHandler, Form{})
+	muxer.Handle(pat.Post(""), newHandler)
+	muxer.Handle(pat.Post("/"), newHandler)
+	muxer.Handle(pat.Post("/settings"), web.ControllerPostHandler(HandleSettings, mainGetHandler, SettingsForm{}))
+	muxer.Handle(pat.Post("/:item/update"), web.ControllerPostHandler(BaseEditHandler(HandleEdit), mainGetHandler, Form{}))
+	muxer.Handle(pat.Post("/:item/delete"), web.ControllerPostHandler(BaseEditHandler(HandleDelete), mainGetHandler, nil))
+}
+
+func HandleAnnouncements(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ag, templateData := web.GetBaseCPContextData(r.Context())
+
+	announcements, err := GuildAnnouncements(ag.ID)
+	if err != nil {
+		return templateData, err
+	}
+
+	loc := GuildTimezone(ag.ID)
+	views := make([]*AnnouncementView, 0, len(announcements))
+	for _, a := range announcements {
+		channelName := "deleted-channel"
+		if cs := ag.GetChannelOrThread(a.ChannelID); cs != nil {
+			channelName = cs.Name
+		}
+
+		views = append(views, &AnnouncementView{
+			ScheduledAnnouncement: a,
+			ChannelName:           channelName,
+			NextRunLocal:          a.NextRun.In(loc).Format(sendAtLayout),
+			Upcoming:              UpcomingRuns(a, loc, 3),
+		})
+	}
+
+	templateData["Announcements"] = views
+	templateData["Timezone"] = loc.String()
+	templateData["NewAnnouncement"] = &AnnouncementView{
+		ScheduledAnnouncement: &ScheduledAnnouncement{},
+		NextRunLocal:          time.Now().In(loc).Add(time.Hour).Truncate(time.Hour).Format(sendAtLayout),
+	}
+	templateData["MaxAnnouncements"] = maxAnnouncementsForContext(r.Context())
+
+	return templateData, nil
+}
+
+func HandleNew(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ctx := r.Context()
+	ag, templateData := web.GetBaseCPContextData(ctx)
+	form := ctx.Value(common.ContextKeyParsedForm).(*Form)
+
+	count, err := CountAnnouncements(ag.ID)
+	if err != nil {
+		return templateData, err
+	}
+
+	if count >= maxAnnouncementsForContext(ctx) {
+		return templateData.AddAlerts(web.ErrorAlert(fmt.Sprintf("Max %d scheduled announcements allowed (%d for premium servers)", MaxAnnouncements, MaxAnnouncementsPremium))), nil
+	}
+
+	a := &ScheduledAnnouncement{
+		GuildID:  ag.ID,
+		AuthorID: web.ContextUser(ctx).ID,
+	}
+
+	if ok := applyForm(templateData, a, form); !ok {
+		return templateData, nil
+	}
+
+	err = Schedule(a)
+	if err == nil {
+		go cplogs.RetryAddEntry(web.NewLogEntryFromContext(ctx, panelLogKeyScheduled, &cplogs.Param{Type: cplogs.ParamTypeInt, Value: int64(a.ID)}))
+	}
+
+	return templateData, err
+}
+
+type ContextKey int
+
+const (
+	ContextKeyAnnouncement ContextKey = iota
+)
+
+func BaseEditHandler(inner web.ControllerHandlerFunc) web.ControllerHandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+		ctx := r.Context()
+		ag, templateData := web.GetBaseCPContextData(ctx)
+
+		id, _ := strconv.ParseInt(pat.Param(r, "item"), 10, 64)
+		a, err := FindAnnouncement(ag.ID, id)
+		if err != nil {
+			return templateData.AddAlerts(web.ErrorAlert("Failed retrieving that announcement")), err
+		}
+
+		ctx = context.WithValue(ctx, ContextKeyAnnouncement, a)
+		return inner(w, r.WithContext(ctx))
+	}
+}
+
+func HandleEdit(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ctx := r.Context()
+	_, templateData := web.GetBaseCPContextData(ctx)
+
+	a := ctx.Value(ContextKeyAnnouncement).(*ScheduledAnnouncement)
+	form := ctx.Value(common.ContextKeyParsedForm).(*Form)
+
+	previousRun := a.NextRun
+	if ok := applyForm(templateData, a, form); !ok {
+		return templateData, nil
+	}
+
+	a.LastError = ""
+	var err error
+	if a.NextRun.Unix() != previousRun.Unix() {
+		err = Schedule(a)
+	} else {
+		err = common.GORM.Save(a).Error
+	}
+	if err == nil {
+		go cplogs.RetryAddEntry(web.NewLogEntryFromContext(ctx, panelLogKeyUpdated, &cplogs.Param{Type: cplogs.ParamTypeInt, Value: int64(a.ID)}))
+	}
+
+	return templateData, err
+}
+
+func HandleDelete(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ctx := r.Context()
+	_, templateData := web.GetBaseCPContextData(ctx)
+
+	a := ctx.Value(ContextKeyAnnouncement).(*ScheduledAnnouncement)
+	err := Cancel(a)
+	if err == nil {
+		go cplogs.RetryAddEntry(web.NewLogEntryFromContext(ctx, panelLogKeyCancelled, &cplogs.Param{Type: cplogs.ParamTypeInt, Value: int64(a.ID)}))
+	}
+
+	return templateData, err
+}
+
+func HandleSettings(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ctx := r.Context()
+	ag, templateData := web.GetBaseCPContextData(ctx)
+	form := ctx.Value(common.ContextKeyParsedForm).(*SettingsForm)
+
+	loc, err := SetGuildTimezone(ag.ID, form.Timezone)
+	if err != nil {
+		return templateData.AddAlerts(web.ErrorAlert(err)), nil
+	}
+
+	go cplogs.RetryAddEntry(web.NewLogEntryFromContext(ctx, panelLogKeyTimezone, &cplogs.Param{Type: cplogs.ParamTypeString, Value: loc.String()}))
+	return templateData, nil
+}
+
+// applyForm copies the form to the announcement and works out its next run in the guild's timezone,
+// adding an alert and returning false if the time is not valid
+func applyForm(templateData web.TemplateData, a *ScheduledAnnouncement, form *Form) bool {
+	loc := GuildTimezone(a.GuildID)
+
+	// both validated by Form.Validate
+	start, _ := time.ParseInLocation(sendAtLayout, form.SendAt, loc)
+	var rec *reminders.Recurrence
+	if form.Recurrence != "" {
+		rec, _ = reminders.ParseRecurrence(form.Recurrence)
+	}
+
+	nextRun := FirstRun(start, rec, loc)
+	if resp := checkRunTime(nextRun); resp != "" {
+		templateData.AddAlerts(web.ErrorAlert(resp))
+		return false
+	}
+
+	a.ChannelID = form.ChannelID
+	a.Message = form.Message
+	a.Embed = strings.TrimSpace(form.Embed)
+	a.MentionEveryone = form.MentionEveryone
+	a.MentionRoles = form.MentionRoles
+	a.Crosspost = form.Crosspost
+	a.NextRun = nextRun
+	a.Recurrence = ""
+	if rec != nil {
+		a.Recurrence = rec.String()
+	}
+
+	return true
+}
+
+func maxAnnouncementsForContext(ctx context.Context) int {
+	if premium.ContextPremium(ctx) {
+		return MaxAnnouncementsPremium
+	}
+
+	return MaxAnnouncements
+}
+
+var _ web.PluginWithServerHomeWidget = (*Plugin)(nil)
+
+func (p *Plugin) LoadServerHomeWidget(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ag, templateData := web.GetBaseCPContextData(r.Context())
+
+	templateData["WidgetTitle"] = "Announcements"
+	templateData["SettingsPath"] = "/announcements"
+
+	count, err := CountAnnouncements(ag.ID)
+	if count > 0 {
+		templateData["WidgetEnabled"] = true
+	} else {
+		templateData["WidgetDisabled"] = true
+	}
+
+	const format = `<p>Scheduled announcements: <code>%d</code></p>`
+	templateData["WidgetBody"] = template.HTML(fmt.Sprintf(format, count))
+
+	return templateData, err
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/mrbentarikau/pagst/common/scheduledevents2"
 
 	// Plugin imports
+	"github.com/mrbentarikau/pagst/announcements"
 	"github.com/mrbentarikau/pagst/automod"
 	"github.com/mrbentarikau/pagst/automod_basic"
 	"github.com/mrbentarikau/pagst/autorole"
@@ -23,7 +24,9 @@ import (
 	"github.com/mrbentarikau/pagst/commands"
 	"github.com/mrbentarikau/pagst/customcommands"
 	"github.com/mrbentarikau/pagst/discordlogger"
+	"github.com/mrbentarikau/pagst/integrations"
 	"github.com/mrbentarikau/pagst/logs"
+	"github.com/mrbentarikau/pagst/mastodon"
 	"github.com/mrbentarikau/pagst/moderation"
 	"github.com/mrbentarikau/pagst/notifications"
 	"github.com/mrbentarikau/pagst/premium"
@@ -59,6 +62,7 @@ func main() {
 	// Setup plugins
 	admin.RegisterPlugin()
 	analytics.RegisterPlugin()
+	announcements.RegisterPlugin()
 	antiphishing.RegisterPlugin()
 	automod.RegisterPlugin()
 	automod_basic.RegisterPlugin()
@@ -68,8 +72,10 @@ func main() {
 	customcommands.RegisterPlugin()
 	discordlogger.Register()
 	featureflags.RegisterPlugin()
+	integrations.RegisterPlugin()
 	internalapi.RegisterPlugin()
 	logs.RegisterPlugin()
+	mastodon.RegisterPlugin()
 	moderation.RegisterPlugin()
 	notifications.RegisterPlugin()
 	patreonpremiumsource.RegisterPlugin()
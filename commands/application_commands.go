@@ -15,6 +15,7 @@ import (
 	"github.com/mrbentarikau/pagst/bot/eventsystem"
 	"github.com/mrbentarikau/pagst/commands/models"
 	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/common/i18n"
 	"github.com/mrbentarikau/pagst/common/pubsub"
 	"github.com/mrbentarikau/pagst/lib/dcmd"
 	"github.com/mrbentarikau/pagst/lib/discordgo"
@@ -116,10 +117,13 @@ OUTER:
 
 func (p *Plugin) containerToSlashCommand(container *slashCommandsContainer) *discordgo.ApplicationCommand {
 	t := true
+	names, descriptions := i18n.CommandLocalizations(container.container.Names[0])
 	req := &discordgo.ApplicationCommand{
-		Name:              strings.ToLower(container.container.Names[0]),
-		Description:       common.CutStringShort(container.container.Description, 100),
-		DefaultPermission: &t,
+		Name:                     strings.ToLower(container.container.Names[0]),
+		NameLocalizations:        names,
+		Description:              common.CutStringShort(container.container.Description, 100),
+		DescriptionLocalizations: descriptions,
+		DefaultPermission:        &t,
 	}
 
 	for _, v := range container.container.Commands {
@@ -141,6 +145,15 @@ func (p *Plugin) containerToSlashCommand(container *slashCommandsContainer) *dis
 			Options:     innerOpts,
 		}
 
+		// sub commands are looked up as commands.<container>.<name>
+		subNames, subDescriptions := i18n.CommandLocalizations(container.container.Names[0] + "." + cast.Name)
+		if subNames != nil {
+			opt.NameLocalizations = *subNames
+		}
+		if subDescriptions != nil {
+			opt.DescriptionLocalizations = *subDescriptions
+		}
+
 		req.Options = append(req.Options, opt)
 	}
 
@@ -186,6 +199,15 @@ func (p *Plugin) yagCommandToSlashCommand(cmd *dcmd.RegisteredCommand) *discordg
 
 	}
 
+	names, descriptions := i18n.CommandLocalizations(cmd.Trigger.Names[0])
+	if applicationCommandPackage.NameLocalizations == nil && applicationCommandPackage.Type == 0 {
+		// localized names only apply to chat input commands, user and message commands set their own
+		applicationCommandPackage.NameLocalizations = names
+	}
+	if applicationCommandPackage.Description != "" {
+		applicationCommandPackage.DescriptionLocalizations = descriptions
+	}
+
 	return applicationCommandPackage
 }
 
@@ -1,11 +1,11 @@
 package commands
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/mrbentarikau/pagst/bot/paginatedmessages"
 	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/common/i18n"
 	"github.com/mrbentarikau/pagst/lib/dcmd"
 	"github.com/mrbentarikau/pagst/lib/discordgo"
 )
@@ -24,12 +24,13 @@ var cmdHelp = &YAGCommand{
 	Cooldown: 10,
 }
 
-func CmdNotFound(search string) string {
-	return fmt.Sprintf("Couldn't find command '%s'", search)
+func CmdNotFound(locale discordgo.Locale, search string) string {
+	return i18n.T(locale, "help.command_not_found", search)
 }
 
 func cmdFuncHelp(data *dcmd.Data) (interface{}, error) {
 	target := data.Args[0].Str()
+	locale := i18n.LocaleFromData(data)
 
 	// Send the targetted help in the channel it was requested in
 	resp := dcmd.GenerateTargettedHelp(target, data, data.ContainerChain[0], &dcmd.StdHelpFormatter{})
@@ -40,7 +41,7 @@ func cmdFuncHelp(data *dcmd.Data) (interface{}, error) {
 	if target != "" {
 		if len(resp) != 1 {
 			// Send command not found in same channel
-			return CmdNotFound(target), nil
+			return CmdNotFound(locale, target), nil
 		}
 
 		// Send short help in same channel
@@ -77,13 +78,13 @@ func cmdFuncHelp(data *dcmd.Data) (interface{}, error) {
 
 		embed := resp[0]
 		embed.Footer = &discordgo.MessageEmbedFooter{
-			Text: "Required permissions: " + requiredPerms,
+			Text: i18n.T(locale, "help.required_permissions", requiredPerms),
 		}
 		return embed, nil
 	}
 
 	// Send full help in DM
-	ir, err := createInteractiveHelp(data.Author.ID, resp)
+	ir, err := createInteractiveHelp(locale, data.Author.ID, resp)
 	if ir != nil || err != nil {
 		return ir, err
 	}
@@ -92,31 +93,27 @@ func cmdFuncHelp(data *dcmd.Data) (interface{}, error) {
 		return nil, nil
 	}
 
-	return "You've got mail!", nil
+	return i18n.T(locale, "help.sent_dm"), nil
 }
 
-func createInteractiveHelp(userID int64, helpEmbeds []*discordgo.MessageEmbed) (interface{}, error) {
+func createInteractiveHelp(locale discordgo.Locale, userID int64, helpEmbeds []*discordgo.MessageEmbed) (interface{}, error) {
 	channel, err := common.BotSession.UserChannelCreate(userID)
 	if err != nil {
-		return "Something went wrong, maybe you have DMs disabled? I don't want to spam this channel so here's a external link to available commands: <https://docs.yagpdb.xyz/commands>", err
+		return i18n.T(locale, "help.dm_failed"), err
 	}
 
 	// prepend a introductionairy first page
 	firstPage := &discordgo.MessageEmbed{
-		Title: common.ConfBotName.GetString() + " Help!",
-		Description: common.ConfBotName.GetString() + ` is a multi-purpose Discord bot that is configured through the web interface at https://` + common.ConfHost.GetString() + `.
-For more in depth help and information you should visit https://docs.yagpdb.xyz/ as this command only shows information about commands.
-		
-		
-**Use the emojis under to change pages**`,
+		Title:       i18n.T(locale, "help.title", common.ConfBotName.GetString()),
+		Description: i18n.T(locale, "help.intro", common.ConfBotName.GetString(), common.ConfHost.GetString()),
 	}
 
 	var pageLayout strings.Builder
 	for i, v := range helpEmbeds {
-		pageLayout.WriteString(fmt.Sprintf("**Page %d**: %s\n", i+2, v.Title))
+		pageLayout.WriteString(i18n.T(locale, "help.page", i+2, v.Title) + "\n")
 	}
 	firstPage.Fields = []*discordgo.MessageEmbedField{
-		{Name: "Help pages", Value: pageLayout.String()},
+		{Name: i18n.T(locale, "help.pages"), Value: pageLayout.String()},
 	}
 
 	helpEmbeds = append([]*discordgo.MessageEmbed{firstPage}, helpEmbeds...)
@@ -126,7 +123,7 @@ For more in depth help and information you should visit https://docs.yagpdb.xyz/
 		return embed, nil
 	})
 	if err != nil {
-		return "Something went wrong, make sure you don't have the bot blocked or your direct messages closed!", err
+		return i18n.T(locale, "help.pagination_failed"), err
 
 	}
 
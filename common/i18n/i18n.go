@@ -0,0 +1,247 @@
+// Package i18n is the message catalogue used for localized bot responses.
+//
+// Catalogues live in locales/<discord locale>.json and map a key to either a plain
+// string or an object of plural forms ("one", "few", "many", "other", ...).
+// Lookups fall back to the closest catalogue for the locale and then to english,
+// messages are fmt format strings.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/mrbentarikau/pagst/lib/dcmd"
+	"github.com/mrbentarikau/pagst/lib/discordgo"
+	"github.com/mrbentarikau/pagst/lib/dstate"
+)
+
+// DefaultLocale is the locale every other catalogue falls back to, it has to contain all keys
+const DefaultLocale = discordgo.EnglishUS
+
+//go:embed locales/*.json
+var localesFS embed.FS
+
+// Message is a single catalogue entry, entries that are plain strings only have Other set
+type Message struct {
+	Zero  string `json:"zero,omitempty"`
+	One   string `json:"one,omitempty"`
+	Two   string `json:"two,omitempty"`
+	Few   string `json:"few,omitempty"`
+	Many  string `json:"many,omitempty"`
+	Other string `json:"other"`
+}
+
+func (m *Message) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		m.Other = s
+		return nil
+	}
+
+	type message Message
+	var inner message
+	if err := json.Unmarshal(b, &inner); err != nil {
+		return err
+	}
+
+	if inner.Other == "" {
+		return fmt.Errorf("plural message is missing the \"other\" form")
+	}
+
+	*m = Message(inner)
+	return nil
+}
+
+// Form returns the plural form, falling back to Other if the catalogue doesn't have it
+func (m *Message) Form(f PluralForm) string {
+	var s string
+	switch f {
+	case PluralZero:
+		s = m.Zero
+	case PluralOne:
+		s = m.One
+	case PluralTwo:
+		s = m.Two
+	case PluralFew:
+		s = m.Few
+	case PluralMany:
+		s = m.Many
+	}
+
+	if s == "" {
+		return m.Other
+	}
+
+	return s
+}
+
+// Catalogue maps message keys to messages for a single locale
+type Catalogue map[string]*Message
+
+var catalogues map[discordgo.Locale]Catalogue
+
+// aliases maps locales without their own catalogue to the closest one
+var aliases = map[discordgo.Locale]discordgo.Locale{
+	discordgo.EnglishGB:    discordgo.EnglishUS,
+	discordgo.SpanishLATAM: discordgo.SpanishES,
+}
+
+func init() {
+	var err error
+	catalogues, err = loadCatalogues(localesFS, "locales")
+	if err != nil {
+		panic("i18n: failed loading catalogues: " + err.Error())
+	}
+}
+
+func loadCatalogues(fsys fs.FS, dir string) (map[discordgo.Locale]Catalogue, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[discordgo.Locale]Catalogue)
+	for _, v := range entries {
+		if v.IsDir() || path.Ext(v.Name()) != ".json" {
+			continue
+		}
+
+		locale := discordgo.Locale(strings.TrimSuffix(v.Name(), ".json"))
+		if _, ok := discordgo.Locales[locale]; !ok {
+			return nil, fmt.Errorf("%s: unknown discord locale", v.Name())
+		}
+
+		b, err := fs.ReadFile(fsys, path.Join(dir, v.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		var c Catalogue
+		if err := json.Unmarshal(b, &c); err != nil {
+			return nil, fmt.Errorf("%s: %w", v.Name(), err)
+		}
+
+		result[locale] = c
+	}
+
+	if _, ok := result[DefaultLocale]; !ok {
+		return nil, fmt.Errorf("missing catalogue for the default locale %s", DefaultLocale)
+	}
+
+	return result, nil
+}
+
+// catalogueFor returns the catalogue for the locale without falling back to the default one
+func catalogueFor(locale discordgo.Locale) Catalogue {
+	if c, ok := catalogues[locale]; ok {
+		return c
+	}
+
+	return catalogues[aliases[locale]]
+}
+
+func lookup(locale discordgo.Locale, key string) (*Message, discordgo.Locale) {
+	if c := catalogueFor(locale); c != nil {
+		if m, ok := c[key]; ok {
+			if _, own := catalogues[locale]; !own {
+				locale = aliases[locale]
+			}
+			return m, locale
+		}
+	}
+
+	if m, ok := catalogues[DefaultLocale][key]; ok {
+		return m, DefaultLocale
+	}
+
+	return nil, DefaultLocale
+}
+
+// T returns the message for key in the locale formatted with args, unknown keys are returned as is
+func T(locale discordgo.Locale, key string, args ...interface{}) string {
+	m, _ := lookup(locale, key)
+	if m == nil {
+		return key
+	}
+
+	if len(args) == 0 {
+		return m.Other
+	}
+
+	return fmt.Sprintf(m.Other, args...)
+}
+
+// N returns the plural form of the message matching n, n is passed as the first format argument
+func N(locale discordgo.Locale, key string, n int, args ...interface{}) string {
+	m, found := lookup(locale, key)
+	if m == nil {
+		return key
+	}
+
+	return fmt.Sprintf(m.Form(PluralFormFor(found, n)), append([]interface{}{n}, args...)...)
+}
+
+// GuildLocale returns the preferred locale of the guild, or the default one if it has none
+func GuildLocale(gs *dstate.GuildState) discordgo.Locale {
+	if gs == nil || gs.PreferredLocale == "" {
+		return DefaultLocale
+	}
+
+	return discordgo.Locale(gs.PreferredLocale)
+}
+
+// LocaleFromData returns the locale to respond to a command in: the locale of the user
+// for slash commands, otherwise the preferred locale of the guild
+func LocaleFromData(data *dcmd.Data) discordgo.Locale {
+	if data.TriggerType == dcmd.TriggerTypeSlashCommands && data.SlashCommandTriggerData != nil &&
+		data.SlashCommandTriggerData.Interaction != nil && data.SlashCommandTriggerData.Interaction.Locale != "" {
+		return data.SlashCommandTriggerData.Interaction.Locale
+	}
+
+	if data.GuildData != nil && data.GuildData.GS != nil {
+		return GuildLocale(&data.GuildData.GS.GuildState)
+	}
+
+	return DefaultLocale
+}
+
+// CommandLocalizations returns the slash command name and description localizations for the
+// command from the "commands.<name>.name" and "commands.<name>.description" keys, nil if there are none
+func CommandLocalizations(name string) (names, descriptions *map[discordgo.Locale]string) {
+	name = strings.ToLower(name)
+	nameKey := "commands." + name + ".name"
+	descKey := "commands." + name + ".description"
+
+	n := make(map[discordgo.Locale]string)
+	d := make(map[discordgo.Locale]string)
+	for locale := range discordgo.Locales {
+		if locale == discordgo.Unknown || locale == DefaultLocale {
+			continue
+		}
+
+		c := catalogueFor(locale)
+		if c == nil {
+			continue
+		}
+
+		if m, ok := c[nameKey]; ok {
+			n[locale] = m.Other
+		}
+		if m, ok := c[descKey]; ok {
+			d[locale] = m.Other
+		}
+	}
+
+	if len(n) > 0 {
+		names = &n
+	}
+	if len(d) > 0 {
+		descriptions = &d
+	}
+
+	return
+}
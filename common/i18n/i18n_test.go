@@ -0,0 +1,303 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/mrbentarikau/pagst/lib/discordgo"
+)
+
+var fmtVerbRegex = regexp.MustCompile(`%(?:\[(\d+)\])?[-+# 0]*\d*(?:\.\d+)?([a-zA-Z%])`)
+
+// verbs returns the format verb used for each argument index of the format string
+func verbs(format string) map[int]string {
+	result := make(map[int]string)
+	next := 1
+	for _, m := range fmtVerbRegex.FindAllStringSubmatch(format, -1) {
+		if m[2] == "%" {
+			continue
+		}
+
+		if m[1] != "" {
+			next, _ = strconv.Atoi(m[1])
+		}
+
+		result[next] = m[2]
+		next++
+	}
+
+	return result
+}
+
+func sameVerbs(a, b map[int]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+func forms(m *Message) map[string]string {
+	result := map[string]string{"other": m.Other}
+	for name, s := range map[string]string{"zero": m.Zero, "one": m.One, "two": m.Two, "few": m.Few, "many": m.Many} {
+		if s != "" {
+			result[name] = s
+		}
+	}
+
+	return result
+}
+
+func TestCataloguesComplete(t *testing.T) {
+	defaultCatalogue := catalogues[DefaultLocale]
+
+	for locale, c := range catalogues {
+		if locale == DefaultLocale {
+			continue
+		}
+
+		var missing []string
+		for key := range defaultCatalogue {
+			if _, ok := c[key]; !ok {
+				missing = append(missing, key)
+			}
+		}
+		sort.Strings(missing)
+		for _, key := range missing {
+			t.Errorf("%s: missing key %q", locale, key)
+		}
+
+		for key, m := range c {
+			if strings.HasPrefix(key, "commands.") {
+				// command localizations are optional and don't exist in the default catalogue
+				continue
+			}
+
+			def, ok := defaultCatalogue[key]
+			if !ok {
+				t.Errorf("%s: key %q does not exist in %s", locale, key, DefaultLocale)
+				continue
+			}
+
+			expected := verbs(def.Other)
+			for form, s := range forms(m) {
+				if !sameVerbs(expected, verbs(s)) {
+					t.Errorf("%s: %q (%s) has format verbs %v, expected %v", locale, key, form, verbs(s), expected)
+				}
+			}
+		}
+	}
+}
+
+// TestUsedKeysExist scans the repository for i18n.T and i18n.N calls with literal keys
+// and flags the ones missing from the default catalogue
+func TestUsedKeysExist(t *testing.T) {
+	root, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	checked := 0
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			name := info.Name()
+			if path != root && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		if !strings.Contains(string(src), "i18n.") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, src, 0)
+		if err != nil {
+			return err
+		}
+
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) < 2 {
+				return true
+			}
+
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || (sel.Sel.Name != "T" && sel.Sel.Name != "N") {
+				return true
+			}
+
+			if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "i18n" {
+				return true
+			}
+
+			lit, ok := call.Args[1].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+
+			key, _ := strconv.Unquote(lit.Value)
+			checked++
+			if _, ok := catalogues[DefaultLocale][key]; !ok {
+				t.Errorf("%s: key %q is missing from the %s catalogue", fset.Position(lit.Pos()), key, DefaultLocale)
+			}
+
+			return true
+		})
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if checked == 0 {
+		t.Error("found no i18n.T or i18n.N calls, the scan is probably broken")
+	}
+}
+
+var commandNameRegex = regexp.MustCompile(`^[-_\p{L}\p{N}\p{Devanagari}\p{Thai}]{1,32}$`)
+
+func TestCommandLocalizations(t *testing.T) {
+	for locale, c := range catalogues {
+		for key, m := range c {
+			if !strings.HasPrefix(key, "commands.") {
+				continue
+			}
+
+			switch {
+			case strings.HasSuffix(key, ".name"):
+				if !commandNameRegex.MatchString(m.Other) || strings.ToLower(m.Other) != m.Other {
+					t.Errorf("%s: %q is not a valid slash command name: %q", locale, key, m.Other)
+				}
+			case strings.HasSuffix(key, ".description"):
+				if n := utf8.RuneCountInString(m.Other); n < 1 || n > 100 {
+					t.Errorf("%s: %q has to be 1-100 characters, is %d", locale, key, n)
+				}
+			default:
+				t.Errorf("%s: unknown command localization key %q", locale, key)
+			}
+		}
+	}
+
+	names, descriptions := CommandLocalizations("Help")
+	if names == nil || (*names)[discordgo.German] != "hilfe" {
+		t.Errorf("expected a german name localization for help, got %v", names)
+	}
+	if descriptions == nil || (*descriptions)[discordgo.SpanishLATAM] != (*descriptions)[discordgo.SpanishES] {
+		t.Errorf("expected es-419 to use the es-ES description, got %v", descriptions)
+	}
+	if _, ok := (*descriptions)[DefaultLocale]; ok {
+		t.Error("the default locale should not be localized")
+	}
+
+	_, descriptions = CommandLocalizations("Tickets.AddUser")
+	if descriptions == nil || (*descriptions)[discordgo.French] == "" {
+		t.Errorf("expected a french description for the adduser sub command of tickets, got %v", descriptions)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	cases := []struct {
+		Locale   discordgo.Locale
+		Key      string
+		Args     []interface{}
+		Expected string
+	}{
+		{discordgo.German, "moderation.member_not_found", nil, "Mitglied nicht gefunden"},
+		{discordgo.SpanishLATAM, "moderation.member_not_found", nil, "Miembro no encontrado"},
+		{discordgo.Japanese, "moderation.member_not_found", nil, "Member not found"},
+		{discordgo.EnglishGB, "help.command_not_found", []interface{}{"foo"}, "Couldn't find command 'foo'"},
+		{discordgo.French, "does.not.exist", nil, "does.not.exist"},
+	}
+
+	for _, c := range cases {
+		if got := T(c.Locale, c.Key, c.Args...); got != c.Expected {
+			t.Errorf("T(%s, %s): got %q, expected %q", c.Locale, c.Key, got, c.Expected)
+		}
+	}
+}
+
+func TestPlurals(t *testing.T) {
+	cases := []struct {
+		Locale   discordgo.Locale
+		N        int
+		Expected string
+	}{
+		{discordgo.EnglishUS, 1, "Deleted 1 warning."},
+		{discordgo.EnglishUS, 0, "Deleted 0 warnings."},
+		{discordgo.French, 0, "0 avertissement supprimé."},
+		{discordgo.French, 2, "2 avertissements supprimés."},
+		{discordgo.Russian, 1, "Удалено 1 предупреждение."},
+		{discordgo.Russian, 3, "Удалено 3 предупреждения."},
+		{discordgo.Russian, 11, "Удалено 11 предупреждений."},
+		{discordgo.Russian, 21, "Удалено 21 предупреждение."},
+		{discordgo.Russian, 25, "Удалено 25 предупреждений."},
+		// falls back to english and its rules
+		{discordgo.Polish, 5, "Deleted 5 warnings."},
+	}
+
+	for _, c := range cases {
+		if got := N(c.Locale, "moderation.warnings_deleted", c.N); got != c.Expected {
+			t.Errorf("N(%s, %d): got %q, expected %q", c.Locale, c.N, got, c.Expected)
+		}
+	}
+}
+
+func TestPluralFormFor(t *testing.T) {
+	cases := []struct {
+		Locale   discordgo.Locale
+		N        int
+		Expected PluralForm
+	}{
+		{discordgo.German, 1, PluralOne},
+		{discordgo.German, 0, PluralOther},
+		{discordgo.PortugueseBR, 0, PluralOne},
+		{discordgo.Japanese, 1, PluralOther},
+		{discordgo.Polish, 1, PluralOne},
+		{discordgo.Polish, 22, PluralFew},
+		{discordgo.Polish, 21, PluralMany},
+		{discordgo.Czech, 3, PluralFew},
+		{discordgo.Czech, 5, PluralOther},
+		{discordgo.Croatian, 5, PluralOther},
+		{discordgo.Lithuanian, 11, PluralOther},
+		{discordgo.Lithuanian, 31, PluralOne},
+		{discordgo.Romanian, 0, PluralFew},
+		{discordgo.Romanian, 20, PluralOther},
+	}
+
+	for _, c := range cases {
+		if got := PluralFormFor(c.Locale, c.N); got != c.Expected {
+			t.Errorf("PluralFormFor(%s, %d): got %d, expected %d", c.Locale, c.N, got, c.Expected)
+		}
+	}
+}
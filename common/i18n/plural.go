@@ -0,0 +1,100 @@
+package i18n
+
+import (
+	"strings"
+
+	"github.com/mrbentarikau/pagst/lib/discordgo"
+)
+
+// PluralForm is a CLDR plural category
+type PluralForm int
+
+const (
+	PluralOther PluralForm = iota
+	PluralZero
+	PluralOne
+	PluralTwo
+	PluralFew
+	PluralMany
+)
+
+// PluralFormFor returns the plural category of the integer n in the locale, following
+// the CLDR cardinal rules for the languages discord supports
+func PluralFormFor(locale discordgo.Locale, n int) PluralForm {
+	if n < 0 {
+		n = -n
+	}
+
+	mod10 := n % 10
+	mod100 := n % 100
+
+	lang := string(locale)
+	if i := strings.IndexByte(lang, '-'); i != -1 {
+		lang = lang[:i]
+	}
+
+	switch lang {
+	case "ja", "ko", "zh", "th", "vi":
+		return PluralOther
+
+	case "fr", "pt", "hi":
+		if n == 0 || n == 1 {
+			return PluralOne
+		}
+		return PluralOther
+
+	case "ru", "uk", "hr":
+		switch {
+		case mod10 == 1 && mod100 != 11:
+			return PluralOne
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return PluralFew
+		case lang == "hr":
+			return PluralOther
+		}
+		return PluralMany
+
+	case "pl":
+		switch {
+		case n == 1:
+			return PluralOne
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return PluralFew
+		}
+		return PluralMany
+
+	case "cs":
+		switch {
+		case n == 1:
+			return PluralOne
+		case n >= 2 && n <= 4:
+			return PluralFew
+		}
+		return PluralOther
+
+	case "lt":
+		switch {
+		case mod10 == 1 && (mod100 < 11 || mod100 > 19):
+			return PluralOne
+		case mod10 >= 2 && (mod100 < 11 || mod100 > 19):
+			return PluralFew
+		}
+		return PluralOther
+
+	case "ro":
+		switch {
+		case n == 1:
+			return PluralOne
+		case n == 0 || (mod100 >= 2 && mod100 <= 19):
+			return PluralFew
+		}
+		return PluralOther
+	}
+
+	// english, german, spanish and the other "one or other" languages
+	if n == 1 {
+		return PluralOne
+	}
+
+	return PluralOther
+}
@@ -38,7 +38,7 @@ func (p *Plugin) LateBotInit() {
 		pool: common.RedisPool,
 	}
 
-	server := NewServer(redisBackend, &DiscordProcessor{})
+	server := NewServer(redisBackend, &DiscordProcessor{}, NewPostgresDeadLetterStore(common.PQ))
 	redisPubsub := RedisPushServer{
 		pushwork:    server.PushWork,
 		fullRefresh: server.refreshWork,
@@ -69,6 +69,11 @@ var (
 		Name: "yagpdb_mqueue_processed_total",
 		Help: "Total mqueue elements processed",
 	}, []string{"source"})
+
+	metricsDeadLetters = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "yagpdb_mqueue_dead_letters_total",
+		Help: "Total mqueue elements given up on and moved to the dead letters",
+	}, []string{"source"})
 )
 
 func handleWebhookSessionRatelimit(s *discordgo.Session, r *discordgo.RateLimit) {
@@ -0,0 +1,246 @@
+package mqueue
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/mrbentarikau/pagst/common"
+)
+
+const (
+	// MaxDeadLettersPerSource is how many dead letters are kept per source, the oldest ones are dropped first
+	MaxDeadLettersPerSource = 1000
+	// DeadLetterMaxAge is how long dead letters are kept around
+	DeadLetterMaxAge = time.Hour * 24 * 14
+
+	maxDeadLetterErrorLength = 2000
+)
+
+// DeadLetter is a queued message that could not be delivered
+type DeadLetter struct {
+	ID int64
+
+	Source       string
+	SourceItemID string
+	GuildID      int64
+	ChannelID    int64
+
+	Attempts  int
+	LastError string
+
+	Elem *QueuedElement
+
+	// CreatedAt is when the message was queued, FailedAt when it was given up on
+	CreatedAt time.Time
+	FailedAt  time.Time
+}
+
+// DeadLetterStore keeps the messages the queue gave up on
+type DeadLetterStore interface {
+	AddDeadLetter(elem *QueuedElement, attempts int, lastErr error) error
+}
+
+var _ DeadLetterStore = (*PostgresDeadLetterStore)(nil)
+
+// PostgresDeadLetterStore stores dead letters in the mqueue_dead_letters table
+type PostgresDeadLetterStore struct {
+	db *sql.DB
+}
+
+func NewPostgresDeadLetterStore(db *sql.DB) *PostgresDeadLetterStore {
+	return &PostgresDeadLetterStore{
+		db: db,
+	}
+}
+
+func (s *PostgresDeadLetterStore) AddDeadLetter(elem *QueuedElement, attempts int, lastErr error) error {
+	serialized, err := json.Marshal(elem)
+	if err != nil {
+		return err
+	}
+
+	errStr := ""
+	if lastErr != nil {
+		errStr = common.CutStringShort(lastErr.Error(), maxDeadLetterErrorLength)
+	}
+
+	const query = `
+INSERT INTO mqueue_dead_letters (source, source_item_id, guild_id, channel_id, attempts, last_error, element, created_at, failed_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, now());
+`
+	_, err = s.db.Exec(query, elem.Source, elem.SourceItemID, elem.GuildID, elem.ChannelID, attempts, errStr, serialized, elem.CreatedAt)
+	if err != nil {
+		return errors.WrapIf(err, "sql.insert")
+	}
+
+	const trimQuery = `
+DELETE FROM mqueue_dead_letters
+WHERE source = $1 AND (failed_at < $2 OR id <= (
+	SELECT id FROM mqueue_dead_letters WHERE source = $1 ORDER BY id DESC OFFSET $3 LIMIT 1
+));
+`
+	_, err = s.db.Exec(trimQuery, elem.Source, time.Now().Add(-DeadLetterMaxAge), MaxDeadLettersPerSource)
+	return errors.WrapIf(err, "sql.trim")
+}
+
+// DeadLetterSourceCount is the number of dead letters of a source
+type DeadLetterSourceCount struct {
+	Source string
+	Count  int64
+}
+
+// DeadLetterSources returns how many dead letters every source has
+func DeadLetterSources() ([]*DeadLetterSourceCount, error) {
+	const query = `SELECT source, count(*) FROM mqueue_dead_letters GROUP BY source ORDER BY source;`
+
+	rows, err := common.PQ.Query(query)
+	if err != nil {
+		return nil, errors.WrapIf(err, "sql.query")
+	}
+	defer rows.Close()
+
+	var result []*DeadLetterSourceCount
+	for rows.Next() {
+		var c DeadLetterSourceCount
+		if err := rows.Scan(&c.Source, &c.Count); err != nil {
+			return nil, err
+		}
+
+		result = append(result, &c)
+	}
+
+	return result, rows.Err()
+}
+
+// GetDeadLetters returns the newest dead letters of the source, or of all sources if it's empty
+func GetDeadLetters(source string, limit int) ([]*DeadLetter, error) {
+	const query = `
+SELECT id, source, source_item_id, guild_id, channel_id, attempts, last_error, element, created_at, failed_at
+FROM mqueue_dead_letters
+WHERE $1 = '' OR source = $1
+ORDER BY id DESC
+LIMIT $2;
+`
+
+	rows, err := common.PQ.Query(query, source, limit)
+	if err != nil {
+		return nil, errors.WrapIf(err, "sql.query")
+	}
+	defer rows.Close()
+
+	var result []*DeadLetter
+	for rows.Next() {
+		var dl DeadLetter
+		var serialized []byte
+		err := rows.Scan(&dl.ID, &dl.Source, &dl.SourceItemID, &dl.GuildID, &dl.ChannelID, &dl.Attempts, &dl.LastError, &serialized, &dl.CreatedAt, &dl.FailedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(serialized, &dl.Elem); err != nil {
+			logger.WithError(err).WithField("dead_letter", dl.ID).Error("Failed decoding mqueue dead letter")
+		}
+
+		result = append(result, &dl)
+	}
+
+	return result, rows.Err()
+}
+
+// ReplayDeadLetters queues the dead letters of the source again and removes them from the store,
+// only the one with the given id if it's not 0. Returns the number of messages queued.
+func ReplayDeadLetters(source string, id int64) (int, error) {
+	const query = `
+SELECT id, element FROM mqueue_dead_letters
+WHERE source = $1 AND ($2 = 0 OR id = $2)
+ORDER BY id ASC;
+`
+
+	rows, err := common.PQ.Query(query, source, id)
+	if err != nil {
+		return 0, errors.WrapIf(err, "sql.query")
+	}
+
+	type replay struct {
+		id         int64
+		serialized []byte
+	}
+
+	var toReplay []replay
+	for rows.Next() {
+		var r replay
+		if err := rows.Scan(&r.id, &r.serialized); err != nil {
+			rows.Close()
+			return 0, err
+		}
+
+		toReplay = append(toReplay, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, r := range toReplay {
+		var elem QueuedElement
+		if err := json.Unmarshal(r.serialized, &elem); err != nil {
+			return n, errors.WithMessagef(err, "decoding dead letter %d", r.id)
+		}
+
+		replayed, err := replayDeadLetter(r.id, &elem)
+		if err != nil {
+			return n, err
+		}
+
+		if !replayed {
+			// replayed or purged by someone else in the meantime
+			continue
+		}
+
+		n++
+	}
+
+	return n, nil
+}
+
+// replayDeadLetter deletes the dead letter and queues the message in a transaction, so it's queued only once
+// even with concurrent replays, and it stays in the store if queueing it fails
+func replayDeadLetter(id int64, elem *QueuedElement) (bool, error) {
+	tx, err := common.PQ.Begin()
+	if err != nil {
+		return false, errors.WrapIf(err, "sql.begin")
+	}
+
+	result, err := tx.Exec(`DELETE FROM mqueue_dead_letters WHERE id = $1`, id)
+	if err != nil {
+		tx.Rollback()
+		return false, errors.WrapIf(err, "sql.delete")
+	}
+
+	if deleted, _ := result.RowsAffected(); deleted < 1 {
+		tx.Rollback()
+		return false, nil
+	}
+
+	if err := QueueMessage(elem); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	return true, errors.WrapIf(tx.Commit(), "sql.commit")
+}
+
+// PurgeDeadLetters deletes the dead letters of the source, only the one with the given id if it's not 0
+func PurgeDeadLetters(source string, id int64) (int64, error) {
+	const query = `DELETE FROM mqueue_dead_letters WHERE source = $1 AND ($2 = 0 OR id = $2);`
+
+	result, err := common.PQ.Exec(query, source, id)
+	if err != nil {
+		return 0, errors.WrapIf(err, "sql.delete")
+	}
+
+	return result.RowsAffected()
+}
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"regexp"
 	"strings"
-	"time"
 
 	"emperror.dev/errors"
 	"github.com/mrbentarikau/pagst/bot"
@@ -20,12 +19,11 @@ type DiscordProcessor struct {
 func (d *DiscordProcessor) ProcessItem(resp chan *workResult, wi *workItem) {
 	metricsProcessed.With(prometheus.Labels{"source": wi.Elem.Source}).Inc()
 
-	retry := false
+	result := &workResult{
+		item: wi,
+	}
 	defer func() {
-		resp <- &workResult{
-			item:  wi,
-			retry: retry,
-		}
+		resp <- result
 	}()
 
 	queueLogger := logger.WithField("mq_id", wi.Elem.ID)
@@ -47,6 +45,7 @@ func (d *DiscordProcessor) ProcessItem(resp chan *workResult, wi *workItem) {
 				maybeDisableFeed(source, wi.Elem, e)
 			}
 
+			result.err = err
 			return
 		}
 	} else {
@@ -56,20 +55,20 @@ func (d *DiscordProcessor) ProcessItem(resp chan *workResult, wi *workItem) {
 				source.DisableFeed(wi.Elem, err)
 			}
 
+			// nowhere to deliver it anymore, not worth keeping
 			return
 		} else if err != nil {
 			logger.WithError(err).Error("failed checking if bot is on guild")
 		}
 	}
 
+	result.err = err
 	if c, _ := common.DiscordError(err); c != 0 {
 		return
 	}
 
-	retry = true
-	queueLogger.Warn("Non-discord related error when sending message, retrying. ", err)
-	time.Sleep(time.Second)
-
+	result.retry = true
+	queueLogger.Warnf("Non-discord related error when sending message, retrying (attempt %d). %v", wi.Attempts+1, err)
 }
 
 var disableOnError = []int{
@@ -77,7 +76,7 @@ var disableOnError = []int{
 	discordgo.ErrCodeMissingAccess,
 	discordgo.ErrCodeMissingPermissions,
 	30007,  // max number of webhooks
-	220001, // webhook points to a forum channel
+	220001, // webhook points to a forum channel, but the element has no forum post to create in it
 }
 
 func maybeDisableFeed(source PluginWithSourceDisabler, elem *QueuedElement, err *discordgo.RESTError) {
@@ -116,14 +115,32 @@ func trySendNormal(l *logrus.Entry, elem *QueuedElement) (err error) {
 	if elem.MessageEmbeds != nil {
 		msg.Embeds = elem.MessageEmbeds
 	}
-	m, err := common.BotSession.ChannelMessageSendComplex(elem.ChannelID, msg)
+
+	for _, v := range elem.Components {
+		msg.Components = append(msg.Components, v)
+	}
+
+	var m *discordgo.Message
+	if forum := forumChannel(elem); forum != nil {
+		m, err = sendForumPost(elem, forum, msg)
+	} else if elem.ThreadID != 0 {
+		m, err = common.BotSession.ChannelMessageSendComplex(elem.ThreadID, msg)
+	} else {
+		m, err = common.BotSession.ChannelMessageSendComplex(elem.ChannelID, msg)
+	}
 	if err != nil {
 		logrus.WithError(err).Error("Failed sending mqueue message")
 		return
 	}
 
-	// Publish the announcement
-	if elem.PublishAnnouncement {
+	if source, ok := sources[elem.Source]; ok {
+		if sentHandler, ok := source.(PluginWithMessageSent); ok {
+			sentHandler.OnMessageSent(elem, m)
+		}
+	}
+
+	// Publish the announcement, only messages in the announcement channel itself can be
+	if elem.PublishAnnouncement && m.ChannelID == elem.ChannelID {
 		_, err = common.BotSession.ChannelMessageCrosspost(elem.ChannelID, m.ID)
 	}
 	return
@@ -155,13 +172,10 @@ func trySendWebhook(l *logrus.Entry, elem *QueuedElement) (err error) {
 		}
 	}
 
-	whI, err := webhookCache.GetCustomFetch(elem.ChannelID, func(key interface{}) (interface{}, error) {
-		return findCreateWebhook(elem.GuildID, elem.ChannelID, elem.Source, avatar)
-	})
+	wh, err := getWebhook(elem.GuildID, elem.ChannelID, elem.Source, avatar)
 	if err != nil {
 		return err
 	}
-	wh := whI.(*webhook)
 
 	webhookParams := &discordgo.WebhookParams{
 		Username:  elem.WebhookUsername,
@@ -183,19 +197,70 @@ func trySendWebhook(l *logrus.Entry, elem *QueuedElement) (err error) {
 		webhookParams.Embeds = elem.MessageEmbeds
 	}
 
-	err = webhookSession.WebhookExecute(wh.ID, wh.Token, true, webhookParams)
+	if forum := forumChannel(elem); forum != nil {
+		webhookParams.ThreadName = forumPostTitle(elem.ForumPost.Title)
+		webhookParams.AppliedTags = ForumTagIDs(forum.AvailableTags, elem.ForumPost.Tags)
+	}
+
+	if elem.ThreadID != 0 {
+		err = webhookSession.WebhookThreadExecute(wh.ID, wh.Token, true, elem.ThreadID, webhookParams)
+	} else {
+		err = webhookSession.WebhookExecute(wh.ID, wh.Token, true, webhookParams)
+	}
 	if code, _ := common.DiscordError(err); code == discordgo.ErrCodeUnknownWebhook {
 		// if the webhook was deleted, then delete the bad boi from the databse and retry
-		const query = `DELETE FROM mqueue_webhooks WHERE id=$1`
-		_, err := common.PQ.Exec(query, wh.ID)
-		if err != nil {
-			return errors.WrapIf(err, "sql.delete")
+		if err := deleteWebhook(wh); err != nil {
+			return err
 		}
 
-		webhookCache.Delete(elem.ChannelID)
-
 		return errors.New("deleted webhook")
 	}
 
 	return
 }
+
+func getWebhook(guildID, channelID int64, source string, avatar string) (*webhook, error) {
+	whI, err := webhookCache.GetCustomFetch(channelID, func(key interface{}) (interface{}, error) {
+		return findCreateWebhook(guildID, channelID, source, avatar)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return whI.(*webhook), nil
+}
+
+func deleteWebhook(wh *webhook) error {
+	const query = `DELETE FROM mqueue_webhooks WHERE id=$1`
+	_, err := common.PQ.Exec(query, wh.ID)
+	if err != nil {
+		return errors.WrapIf(err, "sql.delete")
+	}
+
+	webhookCache.Delete(wh.ChannelID)
+	return nil
+}
+
+// SendWebhookMessage sends the message right away through the webhook of the channel, bypassing the queue.
+// The webhook is shared with the feeds and created if the channel doesn't have one yet.
+func SendWebhookMessage(guildID, channelID int64, source string, params *discordgo.WebhookParams) (*discordgo.Message, error) {
+	for i := 0; i < 2; i++ {
+		wh, err := getWebhook(guildID, channelID, source, "")
+		if err != nil {
+			return nil, err
+		}
+
+		m, err := webhookSession.WebhookExecuteComplex(wh.ID, wh.Token, true, params)
+		if code, _ := common.DiscordError(err); code == discordgo.ErrCodeUnknownWebhook {
+			// deleted from discord's side, create a new one
+			if err := deleteWebhook(wh); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		return m, err
+	}
+
+	return nil, errors.New("deleted webhook")
+}
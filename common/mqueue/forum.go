@@ -0,0 +1,102 @@
+package mqueue
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mrbentarikau/pagst/bot"
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/lib/discordgo"
+	"github.com/mrbentarikau/pagst/lib/dstate"
+)
+
+const (
+	// discord limits
+	maxForumPostTitleLength = 100
+	maxForumPostTags        = 5
+)
+
+// forumChannel returns the channel of the element if it's a forum and the element should become a post in it
+func forumChannel(elem *QueuedElement) *dstate.ChannelState {
+	if elem.ForumPost == nil || elem.ThreadID != 0 {
+		return nil
+	}
+
+	gs := bot.State.GetGuild(elem.GuildID)
+	if gs == nil {
+		return nil
+	}
+
+	cs := gs.GetChannel(elem.ChannelID)
+	if cs == nil || !cs.Type.IsForum() {
+		return nil
+	}
+
+	return cs
+}
+
+// forumPostTitle returns the title trimmed to what discord allows, posts need one so it falls back to a generic one
+func forumPostTitle(title string) string {
+	title = strings.Join(strings.Fields(title), " ")
+	if title == "" {
+		return "New post"
+	}
+
+	return common.CutStringShort(title, maxForumPostTitleLength)
+}
+
+// ForumTagIDs resolves tag names and ids to the ids of the tags the forum has, in order and without duplicates
+func ForumTagIDs(available []discordgo.ForumTag, tags []string) discordgo.IDSlice {
+	var result discordgo.IDSlice
+
+OUTER:
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+
+		id, _ := strconv.ParseInt(tag, 10, 64)
+		for _, v := range available {
+			if v.ID != id && !strings.EqualFold(v.Name, tag) {
+				continue
+			}
+
+			for _, added := range result {
+				if added == v.ID {
+					continue OUTER
+				}
+			}
+
+			result = append(result, v.ID)
+			if len(result) >= maxForumPostTags {
+				return result
+			}
+
+			continue OUTER
+		}
+	}
+
+	return result
+}
+
+// sendForumPost creates a post with the message in the forum
+func sendForumPost(elem *QueuedElement, forum *dstate.ChannelState, msg *discordgo.MessageSend) (*discordgo.Message, error) {
+	thread, err := common.BotSession.ForumThreadStartComplex(forum.ID, &discordgo.ThreadStart{
+		Name:             forumPostTitle(elem.ForumPost.Title),
+		RateLimitPerUser: forum.DefaultThreadRateLimitPerUser,
+		AppliedTags:      ForumTagIDs(forum.AvailableTags, elem.ForumPost.Tags),
+	}, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	// the starter message of a post has the same id as the post itself
+	return &discordgo.Message{
+		ID:        thread.ID,
+		ChannelID: thread.ID,
+		GuildID:   elem.GuildID,
+		Content:   msg.Content,
+		Embeds:    msg.Embeds,
+	}, nil
+}
@@ -14,6 +14,9 @@ type QueuedElement struct {
 	ChannelID int64 `json:"Channel"`
 	GuildID   int64 `json:"Guild"`
 
+	// The thread of the channel to send the message in, if any
+	ThreadID int64 `json:",omitempty"`
+
 	ID int64
 
 	// Where this feed originated from, responsible for handling discord specific errors
@@ -30,6 +33,9 @@ type QueuedElement struct {
 	// The actual message as an embeds KRAAKA might look at it later for optimization
 	MessageEmbeds []*discordgo.MessageEmbed `json:",omitempty"`
 
+	// Buttons and such, only sent with normal bot messages as webhooks can't have them
+	Components []discordgo.ActionsRow `json:",omitempty"`
+
 	UseWebhook      bool
 	WebhookUsername string
 
@@ -44,6 +50,18 @@ type QueuedElement struct {
 	CreatedAt time.Time
 
 	WebhookAvatarURL string
+
+	// ForumPost is used when the channel turns out to be a forum, the message then becomes a new post in it.
+	// It's ignored for other channels, so feeds can set it without knowing what kind of channel they post in.
+	ForumPost *ForumPost `json:",omitempty"`
+}
+
+// ForumPost is the title and tags of the post created for a message queued to a forum channel
+type ForumPost struct {
+	Title string
+
+	// Names or ids of the tags to apply, the ones the forum doesn't have are left out
+	Tags []string `json:",omitempty"`
 }
 
 type webhook struct {
@@ -27,6 +27,12 @@ type PluginWithWebhookAvatar interface {
 	WebhookAvatar() string
 }
 
+// PluginWithMessageSent can be implemented by sources that need to know the messages they sent, for example to edit them later.
+// It's only called for messages sent by the bot, not the ones sent through webhooks
+type PluginWithMessageSent interface {
+	OnMessageSent(elem *QueuedElement, msg *discordgo.Message)
+}
+
 var (
 	_ bot.LateBotInitHandler = (*Plugin)(nil)
 	_ bot.BotStopperHandler  = (*Plugin)(nil)
@@ -1,13 +1,17 @@
 package mqueue
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/lib/discordgo"
 )
 
 func TestMain(m *testing.M) {
@@ -33,7 +37,7 @@ func TestMqueuePubsub(t *testing.T) {
 	backend := &RedisBackend{
 		pool: common.RedisPool,
 	}
-	server := NewServer(backend, fakeProcessor)
+	server := NewServer(backend, fakeProcessor, nil)
 	server.forceAllShards = true
 	go server.Run()
 
@@ -84,7 +88,7 @@ func TestMqueueRefresh(t *testing.T) {
 	backend := &RedisBackend{
 		pool: common.RedisPool,
 	}
-	server := NewServer(backend, fakeProcessor)
+	server := NewServer(backend, fakeProcessor, nil)
 	server.forceAllShards = true
 	go server.Run()
 
@@ -143,3 +147,111 @@ func (f *FakeProcessor) ProcessItem(resp chan *workResult, wi *workItem) {
 		retry: f.retry,
 	}
 }
+
+func TestRetryBackoff(t *testing.T) {
+	for attempts := 1; attempts < 30; attempts++ {
+		full := retryBackoffMax
+		if attempts < 10 && retryBackoffBase<<(attempts-1) < retryBackoffMax {
+			full = retryBackoffBase << (attempts - 1)
+		}
+
+		for i := 0; i < 10; i++ {
+			backoff := retryBackoff(attempts)
+			if backoff < full/2 || backoff > full {
+				t.Fatalf("attempt %d: backoff %s outside of [%s, %s]", attempts, backoff, full/2, full)
+			}
+		}
+	}
+}
+
+func TestFinishWorkDeadLetters(t *testing.T) {
+	deadLetters := &FakeDeadLetterStore{added: make(chan *QueuedElement, 1)}
+	server := NewServer(&FakeStorage{}, &FakeProcessor{onHit: func(wi *workItem) {}}, deadLetters)
+
+	wi := &workItem{Elem: &QueuedElement{ID: 1, ChannelID: 100, Source: "test"}}
+	server.localWork = []*workItem{wi}
+	server.activeWork = []*workItem{wi}
+
+	// retried with a backoff until the attempts run out
+	maxAttempts := 5
+	confMaxAttempts.LoadedValue = maxAttempts
+	for i := 1; i < maxAttempts; i++ {
+		server.finishWork(&workResult{item: wi, retry: true, err: errors.New("connection reset")})
+		if wi.Attempts != i || len(server.localWork) != 1 || !wi.RetryAt.After(time.Now()) {
+			t.Fatalf("attempt %d: expected item to be waiting for a retry, attempts %d, local work %d", i, wi.Attempts, len(server.localWork))
+		}
+
+		if server.findWork() != nil {
+			t.Fatalf("attempt %d: item picked up before its backoff ran out", i)
+		}
+		server.activeWork = []*workItem{wi}
+	}
+
+	server.finishWork(&workResult{item: wi, retry: true, err: errors.New("connection reset")})
+	if len(server.localWork) != 0 {
+		t.Fatalf("expected the item to be removed after %d attempts", maxAttempts)
+	}
+
+	select {
+	case elem := <-deadLetters.added:
+		if elem.ID != 1 {
+			t.Errorf("unexpected dead letter %d", elem.ID)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected a dead letter")
+	}
+}
+
+type FakeStorage struct{}
+
+func (f *FakeStorage) GetFullQueue() ([]*workItem, error)   { return nil, nil }
+func (f *FakeStorage) AppendItem(elem *QueuedElement) error { return nil }
+func (f *FakeStorage) DelItem(elem *workItem) error         { return nil }
+func (f *FakeStorage) NextID() (int64, error)               { return 0, nil }
+
+type FakeDeadLetterStore struct {
+	added chan *QueuedElement
+}
+
+func (f *FakeDeadLetterStore) AddDeadLetter(elem *QueuedElement, attempts int, lastErr error) error {
+	f.added <- elem
+	return nil
+}
+
+func TestForumTagIDs(t *testing.T) {
+	available := []discordgo.ForumTag{
+		{ID: 1, Name: "News"},
+		{ID: 2, Name: "Updates"},
+		{ID: 3, Name: "a"}, {ID: 4, Name: "b"}, {ID: 5, Name: "c"}, {ID: 6, Name: "d"},
+	}
+
+	cases := []struct {
+		Tags     []string
+		Expected discordgo.IDSlice
+	}{
+		{nil, nil},
+		{[]string{"news", " UPDATES "}, discordgo.IDSlice{1, 2}},
+		{[]string{"2", "updates", "missing", "99"}, discordgo.IDSlice{2}},
+		{[]string{"a", "b", "c", "d", "news", "updates"}, discordgo.IDSlice{3, 4, 5, 6, 1}},
+	}
+
+	for _, c := range cases {
+		if got := ForumTagIDs(available, c.Tags); !reflect.DeepEqual(got, c.Expected) {
+			t.Errorf("%v: got %v, expected %v", c.Tags, got, c.Expected)
+		}
+	}
+}
+
+func TestForumPostTitle(t *testing.T) {
+	if got := forumPostTitle("  a\n  title "); got != "a title" {
+		t.Errorf("unexpected title %q", got)
+	}
+
+	if got := forumPostTitle(""); got != "New post" {
+		t.Errorf("unexpected title %q", got)
+	}
+
+	if got := forumPostTitle(strings.Repeat("x", 150)); len([]rune(got)) > maxForumPostTitleLength {
+		t.Errorf("title not cut, length %d", len([]rune(got)))
+	}
+}
@@ -12,4 +12,22 @@ CREATE TABLE IF NOT EXISTS mqueue_webhooks (
 );
 
 CREATE INDEX IF NOT EXISTS mqueue_webhooks_channel_id_idx ON mqueue_webhooks(channel_id);
+
+CREATE TABLE IF NOT EXISTS mqueue_dead_letters (
+	id BIGSERIAL PRIMARY KEY,
+
+	source TEXT NOT NULL,
+	source_item_id TEXT NOT NULL,
+	guild_id BIGINT NOT NULL,
+	channel_id BIGINT NOT NULL,
+
+	attempts INT NOT NULL,
+	last_error TEXT NOT NULL,
+	element JSONB NOT NULL,
+
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	failed_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS mqueue_dead_letters_source_idx ON mqueue_dead_letters(source, id);
 `
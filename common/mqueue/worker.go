@@ -1,23 +1,54 @@
 package mqueue
 
 import (
+	"math/rand"
 	"sync"
 	"time"
 
 	"github.com/mrbentarikau/pagst/bot"
 	"github.com/mrbentarikau/pagst/common/config"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
-var confMaxConcurrentSends = config.RegisterOption("yagpdb.mqueue.max_concurrent_sends", "Max number of concurrent sends that mqueue will do", 3)
+var (
+	confMaxConcurrentSends = config.RegisterOption("yagpdb.mqueue.max_concurrent_sends", "Max number of concurrent sends that mqueue will do", 3)
+	confMaxAttempts        = config.RegisterOption("yagpdb.mqueue.max_attempts", "Max number of times mqueue tries to send a message before moving it to the dead letters", 10)
+)
+
+const (
+	retryBackoffBase = time.Second * 5
+	retryBackoffMax  = time.Minute * 10
+)
 
 type workItem struct {
 	Elem *QueuedElement
 	Raw  []byte
+
+	// Attempts is the number of failed sends so far, these are only tracked in memory so they start over on restarts
+	Attempts int
+	// RetryAt is when the next attempt can be made after a failed one
+	RetryAt time.Time
 }
 
 type workResult struct {
 	item  *workItem
 	retry bool
+	// err is the reason the send failed, if it's set and the item isn't retried it's moved to the dead letters
+	err error
+}
+
+// retryBackoff returns how long to wait before the next attempt, doubling for every failed one with up to half of it as jitter
+// so messages that failed at the same time don't all come back at once
+func retryBackoff(attempts int) time.Duration {
+	backoff := retryBackoffMax
+	if attempts < 20 {
+		backoff = retryBackoffBase << (attempts - 1)
+		if backoff > retryBackoffMax {
+			backoff = retryBackoffMax
+		}
+	}
+
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
 }
 
 // MqueueServer is a worker that processes mqueue items for the current shards on the process
@@ -47,15 +78,16 @@ type MqueueServer struct {
 	activeWork      []*workItem
 	ratelimitedWork int
 
-	backend   Storage
-	processor ItemProcessor
+	backend     Storage
+	processor   ItemProcessor
+	deadLetters DeadLetterStore
 
 	forceAllShards bool
 
 	recentSentTimes map[int64]time.Time
 }
 
-func NewServer(backend Storage, processor ItemProcessor) *MqueueServer {
+func NewServer(backend Storage, processor ItemProcessor, deadLetters DeadLetterStore) *MqueueServer {
 	return &MqueueServer{
 		PushWork:        make(chan *workItem),
 		clearTotalWork:  make(chan bool),
@@ -64,12 +96,14 @@ func NewServer(backend Storage, processor ItemProcessor) *MqueueServer {
 		doneWork:        make(chan *workResult),
 		backend:         backend,
 		processor:       processor,
+		deadLetters:     deadLetters,
 		recentSentTimes: make(map[int64]time.Time),
 	}
 }
 
 func (m *MqueueServer) Run() {
 	gcTicker := time.NewTicker(time.Second * 10)
+	retryTicker := time.NewTicker(time.Second)
 	for {
 		select {
 		case wg := <-m.Stop:
@@ -88,6 +122,9 @@ func (m *MqueueServer) Run() {
 			m.finishWork(wi)
 		case <-gcTicker.C:
 			m.cleanRecentSentTimes()
+		case <-retryTicker.C:
+			// pick up items whose backoff ran out
+			m.checkRunNextWork()
 		}
 	}
 }
@@ -190,18 +227,48 @@ func (m *MqueueServer) checkRunNextWork() {
 }
 
 func (m *MqueueServer) finishWork(wr *workResult) {
-	if !wr.retry {
-		m.backend.DelItem(wr.item)
-		m.localWork = removeFromWorkSlice(m.localWork, wr.item)
-		if m.totalWorkPresent {
-			m.totalWork = removeFromWorkSlice(m.totalWork, wr.item)
+	m.activeWork = removeFromWorkSlice(m.activeWork, wr.item)
+
+	if wr.retry {
+		wr.item.Attempts++
+		if wr.item.Attempts < confMaxAttempts.GetInt() {
+			wr.item.RetryAt = time.Now().Add(retryBackoff(wr.item.Attempts))
+			m.checkRunNextWork()
+			return
 		}
+
+		logger.WithError(wr.err).WithField("mq_id", wr.item.Elem.ID).Warnf("Giving up on mqueue message after %d attempts", wr.item.Attempts)
+	} else if wr.err != nil {
+		// failed for good on the first attempt
+		wr.item.Attempts++
+	}
+
+	if wr.err != nil {
+		m.addDeadLetter(wr.item, wr.err)
+	}
+
+	m.backend.DelItem(wr.item)
+	m.localWork = removeFromWorkSlice(m.localWork, wr.item)
+	if m.totalWorkPresent {
+		m.totalWork = removeFromWorkSlice(m.totalWork, wr.item)
 	}
 
-	m.activeWork = removeFromWorkSlice(m.activeWork, wr.item)
 	m.checkRunNextWork()
 }
 
+func (m *MqueueServer) addDeadLetter(wi *workItem, err error) {
+	metricsDeadLetters.With(prometheus.Labels{"source": wi.Elem.Source}).Inc()
+	if m.deadLetters == nil {
+		return
+	}
+
+	go func() {
+		if dlErr := m.deadLetters.AddDeadLetter(wi.Elem, wi.Attempts, err); dlErr != nil {
+			logger.WithError(dlErr).WithField("mq_id", wi.Elem.ID).Error("Failed saving mqueue dead letter")
+		}
+	}()
+}
+
 func removeFromWorkSlice(s []*workItem, wi *workItem) []*workItem {
 	for i, v := range s {
 		if v.Elem.ID == wi.Elem.ID {
@@ -225,6 +292,11 @@ func (m *MqueueServer) findWork() *workItem {
 	// find a work item that does not share a channel with any other item being processed (so ratelimits only take up max 1 worker)
 OUTER:
 	for _, v := range m.localWork {
+		// Still backing off after a failed attempt
+		if now.Before(v.RetryAt) {
+			continue
+		}
+
 		// Don't send 2 messages in a channel at the same time
 		for _, active := range m.activeWork {
 			if active.Elem.ChannelID == v.Elem.ChannelID {
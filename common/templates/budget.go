@@ -0,0 +1,141 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+const (
+	// MaxExecTime is how long a template can run for, not counting time spent in sleep
+	MaxExecTimeNormal  = 30 * time.Second
+	MaxExecTimePremium = 60 * time.Second
+
+	// MaxAlloc is the approximate amount of memory a template can allocate over its execution
+	MaxAllocNormal  = 50 << 20
+	MaxAllocPremium = 125 << 20
+
+	allocInterfaceSize = 16 // an interface value, which is what a Slice holds
+	allocMapEntrySize  = 48 // rough size of a map entry including overhead
+)
+
+// execTimer cancels the execution context with context.DeadlineExceeded as the cause once the template
+// has run for longer than its limit, it can be paused so time spent in sleep doesn't count towards it
+type execTimer struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	timer  *time.Timer
+
+	deadline  time.Time
+	remaining time.Duration
+}
+
+func newExecTimer(limit time.Duration) *execTimer {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	return &execTimer{
+		ctx:      ctx,
+		cancel:   cancel,
+		timer:    time.AfterFunc(limit, func() { cancel(context.DeadlineExceeded) }),
+		deadline: time.Now().Add(limit),
+	}
+}
+
+func (t *execTimer) pause() {
+	if t.timer.Stop() {
+		t.remaining = time.Until(t.deadline)
+	}
+}
+
+func (t *execTimer) resume() {
+	if t.remaining <= 0 {
+		return
+	}
+
+	t.deadline = time.Now().Add(t.remaining)
+	t.timer.Reset(t.remaining)
+	t.remaining = 0
+}
+
+func (t *execTimer) stop() {
+	t.timer.Stop()
+	t.cancel(context.Canceled)
+}
+
+func (c *Context) maxExecTime() time.Duration {
+	if c.IsPremium {
+		return MaxExecTimePremium
+	}
+	return MaxExecTimeNormal
+}
+
+func (c *Context) maxAlloc() int {
+	if c.IsPremium {
+		return MaxAllocPremium
+	}
+	return MaxAllocNormal
+}
+
+// chargeAlloc is the call hook of executed templates, it adds the approximate memory
+// allocated by each call to the budget shared by all frames of the context
+func (c *Context) chargeAlloc(name string, args []reflect.Value, result reflect.Value) error {
+	c.allocated += approxAlloc(name, args, result)
+	if limit := c.maxAlloc(); c.allocated > limit {
+		return fmt.Errorf("exceeded max memory allocation (%d MiB), try building smaller strings, slices and dicts", limit>>20)
+	}
+
+	return nil
+}
+
+// approxAlloc estimates how many bytes a call allocated. Only the newly allocated
+// part is counted, values stored in slices and dicts were charged when they were created.
+func approxAlloc(name string, args []reflect.Value, result reflect.Value) int {
+	switch name {
+	case "Append":
+		// Slice.Append copies the slice header and adds one element, the backing array
+		// grows amortized so a single element is close enough
+		return allocInterfaceSize
+	case "AppendSlice":
+		if len(args) > 0 {
+			if v := indirectValue(args[0]); v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+				return v.Len() * allocInterfaceSize
+			}
+		}
+		return allocInterfaceSize
+	case "Set":
+		// Dict.Set, SDict.Set and Slice.Set
+		n := allocMapEntrySize
+		if len(args) > 0 {
+			if k := indirectValue(args[0]); k.Kind() == reflect.String {
+				n += k.Len()
+			}
+		}
+		return n
+	}
+
+	v := indirectValue(result)
+	switch v.Kind() {
+	case reflect.String:
+		// joinStr, print, printf and friends
+		return v.Len()
+	case reflect.Slice:
+		// seq, cslice, shuffle and friends
+		return v.Len() * allocInterfaceSize
+	case reflect.Map:
+		// sdict, dict
+		return v.Len() * allocMapEntrySize
+	}
+
+	return 0
+}
+
+func indirectValue(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+
+	return v
+}
@@ -0,0 +1,121 @@
+package templates
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApproxAlloc(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Args     []interface{}
+		Result   interface{}
+		Expected int
+	}{
+		{"joinStr", []interface{}{"", "abc", "def"}, "abcdef", 6},
+		{"seq", []interface{}{0, 10}, make([]int, 10), 10 * allocInterfaceSize},
+		{"sdict", nil, SDict{"a": 1, "b": 2}, 2 * allocMapEntrySize},
+		{"Append", []interface{}{"x"}, Slice(make([]interface{}, 1000)), allocInterfaceSize},
+		{"AppendSlice", []interface{}{Slice{1, 2, 3}}, Slice(make([]interface{}, 1000)), 3 * allocInterfaceSize},
+		{"Set", []interface{}{"key", Slice(make([]interface{}, 1000))}, "", allocMapEntrySize + 3},
+		{"add", []interface{}{1, 2}, 3, 0},
+		{"getMember", nil, (*Slice)(nil), 0},
+	}
+
+	for _, c := range cases {
+		args := make([]reflect.Value, 0, len(c.Args))
+		for _, v := range c.Args {
+			args = append(args, reflect.ValueOf(v))
+		}
+
+		if got := approxAlloc(c.Name, args, reflect.ValueOf(c.Result)); got != c.Expected {
+			t.Errorf("%s: got %d, expected %d", c.Name, got, c.Expected)
+		}
+	}
+}
+
+func TestChargeAlloc(t *testing.T) {
+	ctx := NewContext(nil, nil, nil)
+	ctx.allocated = MaxAllocNormal - 5
+
+	err := ctx.chargeAlloc("joinStr", nil, reflect.ValueOf("123456"))
+	if err == nil || !strings.Contains(err.Error(), "exceeded max memory allocation") {
+		t.Fatalf("expected allocation error, got %v", err)
+	}
+
+	ctx.IsPremium = true
+	ctx.allocated = MaxAllocNormal - 5
+	if err := ctx.chargeAlloc("joinStr", nil, reflect.ValueOf("123456")); err != nil {
+		t.Errorf("premium limit should be higher, got %v", err)
+	}
+}
+
+func TestExecuteBudget(t *testing.T) {
+	ctx := NewContext(nil, nil, nil)
+
+	parsed, err := ctx.Parse(`{{$s := cslice}}{{range seq 0 10}}{{$s = $s.Append (joinStr "" "ab" "cd")}}{{end}}{{len $s}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx.CurrentFrame.parsedTemplate = parsed
+
+	out, err := ctx.executeParsed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "10" {
+		t.Errorf("got %q, expected 10", out)
+	}
+
+	if ctx.allocated < 10*(allocInterfaceSize+4) {
+		t.Errorf("expected the appends and strings to be charged, got %d bytes", ctx.allocated)
+	}
+	if ctx.execTimer != nil {
+		t.Error("the execution timer should be stopped after the outermost frame")
+	}
+}
+
+func TestExecTimerPause(t *testing.T) {
+	timer := newExecTimer(50 * time.Millisecond)
+	defer timer.stop()
+
+	timer.pause()
+	time.Sleep(100 * time.Millisecond)
+	if timer.ctx.Err() != nil {
+		t.Fatal("paused timer cancelled the context")
+	}
+
+	timer.resume()
+	select {
+	case <-timer.ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("resumed timer did not cancel the context")
+	}
+
+	if cause := context.Cause(timer.ctx); cause != context.DeadlineExceeded {
+		t.Errorf("expected the deadline to be the cause, got %v", cause)
+	}
+}
+
+func TestExecuteTimeout(t *testing.T) {
+	ctx := NewContext(nil, nil, nil)
+
+	parsed, err := ctx.Parse(`{{while true}}{{end}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx.CurrentFrame.parsedTemplate = parsed
+
+	// an already expired timer, executeParsed uses the timer of the outer frame if there is one
+	ctx.execTimer = newExecTimer(time.Nanosecond)
+	defer ctx.execTimer.stop()
+	<-ctx.execTimer.ctx.Done()
+
+	_, err = ctx.executeParsed()
+	if err == nil || !strings.Contains(err.Error(), "exceeded max execution time") {
+		t.Fatalf("expected max execution time error, got %v", err)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/mrbentarikau/pagst/lib/discordgo"
 	"github.com/mrbentarikau/pagst/lib/dstate"
 	"github.com/mrbentarikau/pagst/lib/template"
+	"github.com/mrbentarikau/pagst/lib/template/parse"
 	"github.com/mrbentarikau/pagst/web/discorddata"
 
 	"github.com/sirupsen/logrus"
@@ -204,12 +205,24 @@ type Context struct {
 	FixedOutput  string
 	secondsSlept int
 
+	// shared by the frames of an execution, see budget.go
+	execTimer *execTimer
+	allocated int
+
 	IsPremium bool
 
 	RegexCache map[string]*regexp.Regexp
 
+	// Libraries are shared templates added to the template set on parse, keyed by the name
+	// they're invoked with. Templates defined in the source itself take precedence.
+	Libraries map[string]*parse.Tree
+
 	CurrentFrame *ContextFrame
 
+	// Operations and MessagesSent are totals over every execution of this context, used for metrics
+	Operations   int
+	MessagesSent int
+
 	ExecutedFrom ExecutedFromType
 
 	// See DelayedRunCCData.CallChain.
@@ -269,6 +282,11 @@ func (c *Context) setupContextFuncs() {
 		f(c)
 	}
 
+	// setup funcs of other plugins set ContextFuncs directly instead of going through addContextFunc
+	for _, name := range c.DisabledContextFuncs {
+		delete(c.ContextFuncs, name)
+	}
+
 	c.contextFuncsAdded = true
 }
 
@@ -361,6 +379,16 @@ func (c *Context) Parse(source string) (*template.Template, error) {
 		return nil, err
 	}
 
+	for name, tree := range c.Libraries {
+		if parsed.Lookup(name) != nil {
+			continue
+		}
+
+		if _, err = parsed.AddParseTree(name, tree); err != nil {
+			return nil, err
+		}
+	}
+
 	return parsed, nil
 }
 
@@ -429,13 +457,23 @@ func (c *Context) executeParsed() (string, error) {
 		}
 	}
 
+	if c.execTimer == nil {
+		// outermost frame, nested frames share its time and memory budget
+		c.execTimer = newExecTimer(c.maxExecTime())
+		c.allocated = 0
+		defer func() {
+			c.execTimer.stop()
+			c.execTimer = nil
+		}()
+	}
+	parsed = parsed.Hook(c.chargeAlloc)
+
 	var buf bytes.Buffer
 	w := LimitWriter(&buf, 25000)
 
-	// started := time.Now()
-	err := parsed.Execute(w, c.Data)
+	ops, err := parsed.ExecuteContext(c.execTimer.ctx, w, c.Data)
+	c.Operations += ops
 
-	// dur := time.Since(started)
 	if c.FixedOutput != "" {
 		return c.FixedOutput, nil
 	}
@@ -563,6 +601,7 @@ func (c *Context) SendResponse(content string) (*discordgo.Message, error) {
 		errOut += "`" + err.Error() + "`"
 		common.BotSession.ChannelMessageSendComplex(channelID, c.MessageSend(fmt.Sprint(errOut)))
 	} else {
+		c.MessagesSent++
 		if c.CurrentFrame.DelResponse {
 			MaybeScheduledDeleteMessage(c.GS.ID, channelID, m.ID, c.CurrentFrame.DelResponseDelay)
 		}
@@ -760,6 +799,14 @@ func baseContextFuncs(c *Context) {
 	c.addContextFunc("hasPermissions", c.tmplHasPermissions)
 	c.addContextFunc("targetHasPermissions", c.tmplTargetHasPermissions)
 
+	// Image functions
+	c.addContextFunc("drawAvatar", c.tmplDrawAvatar)
+	c.addContextFunc("drawCircle", c.tmplDrawCircle)
+	c.addContextFunc("drawRect", c.tmplDrawRect)
+	c.addContextFunc("drawText", c.tmplDrawText)
+	c.addContextFunc("newCanvas", c.tmplNewCanvas)
+	c.addContextFunc("roundCrop", c.tmplRoundCrop)
+
 	// Regexp functions
 	c.addContextFunc("reFind", c.reFind)
 	c.addContextFunc("reFindAll", c.reFindAll)
@@ -774,6 +821,7 @@ func baseContextFuncs(c *Context) {
 	c.addContextFunc("ccCounters", c.tmplCounters)
 	c.addContextFunc("getApplicationCommands", c.tmplGetApplicationCommands)
 	c.addContextFunc("getGuildPreview", c.tmplGetGuildPreview)
+	c.addContextFunc("roll", c.tmplRoll)
 	c.addContextFunc("sleep", c.tmplSleep)
 	c.addContextFunc("sort", c.tmplSort)
 
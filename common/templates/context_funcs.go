@@ -15,6 +15,7 @@ import (
 	"github.com/mrbentarikau/pagst/bot"
 	"github.com/mrbentarikau/pagst/common"
 	"github.com/mrbentarikau/pagst/common/scheduledevents2"
+	"github.com/mrbentarikau/pagst/lib/dice"
 	"github.com/mrbentarikau/pagst/lib/discordgo"
 	"github.com/mrbentarikau/pagst/lib/dstate"
 	tzModels "github.com/mrbentarikau/pagst/timezonecompanion/models"
@@ -38,7 +39,7 @@ func (c *Context) buildDM(gName string, s ...interface{}) *discordgo.MessageSend
 		msgSend.Embeds = []*discordgo.MessageEmbed{t}
 	case *discordgo.MessageSend:
 		msgSend = t
-		if (strings.TrimSpace(msgSend.Content) == "") && (msgSend.File == nil) {
+		if (strings.TrimSpace(msgSend.Content) == "") && (msgSend.File == nil && len(msgSend.Files) == 0) {
 			return nil
 		}
 	default:
@@ -80,7 +81,7 @@ func (c *Context) tmplSendDM(s ...interface{}) (string, error) {
 		msgSend.Embeds = t
 	case *discordgo.MessageSend:
 		msgSend = t
-		if (len(msgSend.Embeds) == 0 && strings.TrimSpace(msgSend.Content) == "") && (msgSend.File == nil) {
+		if (len(msgSend.Embeds) == 0 && strings.TrimSpace(msgSend.Content) == "") && (msgSend.File == nil && len(msgSend.Files) == 0) {
 			return "", errors.New("message to send is empty")
 		}
 	default:
@@ -107,6 +108,7 @@ func (c *Context) tmplSendDM(s ...interface{}) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	c.MessagesSent++
 	return "", nil
 }
 
@@ -135,7 +137,10 @@ func (c *Context) tmplSendTargetDM(target interface{}, s ...interface{}) string
 		if err != nil {
 			return ""
 		}
-		_, _ = common.BotSession.ChannelMessageSendComplex(channel.ID, msgSend)
+		_, err = common.BotSession.ChannelMessageSendComplex(channel.ID, msgSend)
+		if err == nil {
+			c.MessagesSent++
+		}
 	}
 
 	return ""
@@ -480,6 +485,7 @@ func (c *Context) tmplSendMessage(filterSpecialMentions bool, returnID bool) fun
 		if err != nil {
 			return err
 		}
+		c.MessagesSent++
 
 		if err == nil && returnID {
 			return m.ID
@@ -1735,10 +1741,34 @@ func (c *Context) tmplSleep(duration interface{}) (string, error) {
 	}
 
 	c.secondsSlept += seconds
+	if c.execTimer != nil {
+		// sleeping doesn't count towards the execution time limit
+		c.execTimer.pause()
+		defer c.execTimer.resume()
+	}
 	time.Sleep(time.Duration(seconds) * time.Second)
 	return "", nil
 }
 
+// tmplRoll rolls dice using the same syntax as the roll command, the result has .Int, .String and
+// for expressions the per die breakdown in .Groups
+func (c *Context) tmplRoll(expr string) (dice.RollResult, error) {
+	if c.IncreaseCheckCallCounter("roll", 50) {
+		return nil, ErrTooManyCalls
+	}
+
+	r, reason, err := dice.Roll(strings.ToLower(expr))
+	if err != nil {
+		return nil, err
+	}
+
+	if reason != "" {
+		return nil, errors.New("invalid dice expression, unexpected: " + reason)
+	}
+
+	return r, nil
+}
+
 func (c *Context) compileRegex(r string) (*regexp.Regexp, error) {
 	if c.RegexCache == nil {
 		c.RegexCache = make(map[string]*regexp.Regexp)
@@ -228,6 +228,7 @@ func CreateMessageSend(values ...interface{}) (*discordgo.MessageSend, error) {
 	embedSlice := []*discordgo.MessageEmbed{}
 	// limitation from discord
 	numEmbeds := 10
+	var image *discordgo.File
 
 	for key, val := range messageSdict {
 		switch strings.ToLower(key) {
@@ -267,6 +268,33 @@ func CreateMessageSend(values ...interface{}) (*discordgo.MessageSend, error) {
 				ContentType: "text/plain",
 				Reader:      &buf,
 			}
+		case "image":
+			if val == nil {
+				continue
+			}
+			canvas, ok := val.(*Canvas)
+			if !ok {
+				return nil, errors.New("image for send message builder has to be a canvas created with newCanvas")
+			}
+
+			encoded, err := canvas.EncodePNG()
+			if err != nil {
+				return nil, err
+			}
+
+			image = &discordgo.File{
+				ContentType: "image/png",
+				Reader:      bytes.NewReader(encoded),
+			}
+		case "buttons":
+			if val == nil {
+				continue
+			}
+			buttons, err := CreateButtons(val)
+			if err != nil {
+				return nil, err
+			}
+			msg.Components = []discordgo.MessageComponent{discordgo.ActionsRow{Components: buttons}}
 		case "filename":
 			// Cut the filename to a reasonable length if it's too long
 			filename = common.CutStringShort(ToString(val), 64)
@@ -307,10 +335,103 @@ func CreateMessageSend(values ...interface{}) (*discordgo.MessageSend, error) {
 		// We hardcode the extension to .txt to prevent possible abuse via .bat or other possible harmful/easily corruptible file formats
 		msg.File.Name = filename + ".txt"
 	}
+	if image != nil {
+		image.Name = filename + ".png"
+		// discord doesn't accept both the legacy File and Files
+		if msg.File != nil {
+			msg.Files = append(msg.Files, msg.File)
+			msg.File = nil
+		}
+		msg.Files = append(msg.Files, image)
+	}
 
 	return msg, nil
 }
 
+// ButtonCustomIDPrefix is prepended to the ids of the buttons created with complexMessage,
+// so other plugins listening to button presses can tell them apart
+const ButtonCustomIDPrefix = "cc:"
+
+var buttonStyles = map[string]discordgo.ButtonStyle{
+	"primary":   discordgo.PrimaryButton,
+	"secondary": discordgo.SecondaryButton,
+	"success":   discordgo.SuccessButton,
+	"danger":    discordgo.DangerButton,
+}
+
+// CreateButtons creates a row of buttons from a slice of labels, or sdicts with the keys label, id, style and emoji.
+// The id defaults to the label.
+func CreateButtons(values interface{}) ([]discordgo.MessageComponent, error) {
+	rv, _ := indirect(reflect.ValueOf(values))
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, errors.New("buttons has to be a slice")
+	}
+
+	// limitation from discord
+	if rv.Len() > 5 {
+		return nil, errors.New("max 5 buttons per message")
+	}
+
+	buttons := make([]discordgo.MessageComponent, 0, rv.Len())
+	seen := make(map[string]bool)
+	for i := 0; i < rv.Len(); i++ {
+		button := discordgo.Button{Style: discordgo.PrimaryButton}
+		id := ""
+
+		switch t := rv.Index(i).Interface().(type) {
+		case string:
+			button.Label = t
+		default:
+			opts, err := StringKeyDictionary(t)
+			if err != nil {
+				return nil, err
+			}
+
+			for k, v := range opts {
+				switch strings.ToLower(k) {
+				case "label":
+					button.Label = ToString(v)
+				case "id":
+					id = ToString(v)
+				case "style":
+					style, ok := buttonStyles[strings.ToLower(ToString(v))]
+					if !ok {
+						return nil, errors.New("invalid button style, available styles are primary, secondary, success and danger")
+					}
+					button.Style = style
+				case "emoji":
+					button.Emoji = &discordgo.ComponentEmoji{Name: ToString(v)}
+				default:
+					return nil, errors.New(`invalid key "` + k + `" passed to button builder`)
+				}
+			}
+		}
+
+		button.Label = common.CutStringShort(button.Label, 80)
+		if id == "" {
+			id = button.Label
+		}
+
+		if id == "" {
+			return nil, errors.New("buttons need a label or an id")
+		}
+
+		if len(id) > 90 {
+			return nil, errors.New("button id can be max 90 characters long")
+		}
+
+		if seen[id] {
+			return nil, errors.New("button ids have to be unique: " + id)
+		}
+		seen[id] = true
+
+		button.CustomID = ButtonCustomIDPrefix + id
+		buttons = append(buttons, button)
+	}
+
+	return buttons, nil
+}
+
 func CreateMessageEdit(values ...interface{}) (*discordgo.MessageEdit, error) {
 	if len(values) < 1 {
 		return &discordgo.MessageEdit{}, nil
@@ -5,6 +5,8 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+
+	"github.com/mrbentarikau/pagst/lib/discordgo"
 )
 
 func buildLongStr(length int) string {
@@ -86,3 +88,38 @@ func TestSlice(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateButtons(t *testing.T) {
+	buttons, err := CreateButtons(Slice{"Yes", SDict{"label": "No", "id": "no", "style": "danger"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(buttons) != 2 {
+		t.Fatalf("expected 2 buttons, got %d", len(buttons))
+	}
+
+	yes := buttons[0].(discordgo.Button)
+	if yes.CustomID != ButtonCustomIDPrefix+"Yes" || yes.Style != discordgo.PrimaryButton {
+		t.Errorf("unexpected button: %#v", yes)
+	}
+
+	no := buttons[1].(discordgo.Button)
+	if no.CustomID != ButtonCustomIDPrefix+"no" || no.Label != "No" || no.Style != discordgo.DangerButton {
+		t.Errorf("unexpected button: %#v", no)
+	}
+
+	invalid := []interface{}{
+		"Yes",
+		Slice{"a", "b", "c", "d", "e", "f"},
+		Slice{"a", "a"},
+		Slice{SDict{"style": "primary"}},
+		Slice{SDict{"label": "a", "style": "blurple"}},
+	}
+
+	for _, v := range invalid {
+		if _, err := CreateButtons(v); err == nil {
+			t.Errorf("expected error for %v", v)
+		}
+	}
+}
@@ -0,0 +1,464 @@
+package templates
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mrbentarikau/pagst/bot"
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/lib/discordgo"
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	MaxCanvasSide        = 1024
+	MaxCanvasSidePremium = 2048
+
+	// MaxImageBytes is the max size of an encoded canvas
+	MaxImageBytes = 4000000
+
+	maxImageFontSize   = 256
+	maxImageTextLength = 1000
+	maxAvatarBytes     = 8000000
+)
+
+var (
+	ErrImageTooBig = errors.New("image grew too big (>4MB)")
+
+	imageFontData = map[string][]byte{
+		"regular": goregular.TTF,
+		"bold":    gobold.TTF,
+		"italic":  goitalic.TTF,
+		"mono":    gomono.TTF,
+	}
+
+	imageFonts     map[string]*opentype.Font
+	imageFontsOnce sync.Once
+	imageFontsErr  error
+)
+
+// AvatarFetcher returns the avatar of the user, scaled to at least size pixels.
+// It can be replaced to add caching or for tests.
+var AvatarFetcher = fetchAvatarHTTP
+
+var avatarHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+func fetchAvatarHTTP(user *discordgo.User, size int) (image.Image, error) {
+	// discord only serves power of two sizes
+	reqSize := 16
+	for reqSize < size && reqSize < 1024 {
+		reqSize *= 2
+	}
+
+	resp, err := avatarHTTPClient.Get(user.AvatarURL(strconv.Itoa(reqSize)))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed fetching avatar, status %d", resp.StatusCode)
+	}
+
+	img, _, err := image.Decode(io.LimitReader(resp.Body, maxAvatarBytes))
+	return img, err
+}
+
+// Canvas is an image that can be drawn on by templates and sent as an attachment with complexMessage
+type Canvas struct {
+	img *image.RGBA
+	ctx *Context
+}
+
+func (c *Canvas) Width() int  { return c.img.Bounds().Dx() }
+func (c *Canvas) Height() int { return c.img.Bounds().Dy() }
+
+func (c *Canvas) String() string {
+	return fmt.Sprintf("Canvas %dx%d", c.Width(), c.Height())
+}
+
+// EncodePNG encodes the canvas, this is counted towards the export limit of the context
+func (c *Canvas) EncodePNG() ([]byte, error) {
+	if c.ctx != nil && c.ctx.IncreaseCheckCallCounterPremium("image_export", 3, 5) {
+		return nil, ErrTooManyCalls
+	}
+
+	var buf bytes.Buffer
+	err := png.Encode(LimitWriter(&buf, MaxImageBytes), c.img)
+	if err != nil {
+		if err == io.ErrShortWrite {
+			return nil, ErrImageTooBig
+		}
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (c *Context) maxCanvasSide() int {
+	if c.IsPremium {
+		return MaxCanvasSidePremium
+	}
+
+	return MaxCanvasSide
+}
+
+func (c *Context) checkImageDraw() error {
+	if c.IncreaseCheckCallCounterPremium("image_draw", 100, 250) {
+		return ErrTooManyCalls
+	}
+
+	return nil
+}
+
+func (c *Context) tmplNewCanvas(width, height interface{}, background ...interface{}) (*Canvas, error) {
+	if c.IncreaseCheckCallCounterPremium("image_canvas", 2, 5) {
+		return nil, ErrTooManyCalls
+	}
+
+	w, h := tmplToInt(width), tmplToInt(height)
+	max := c.maxCanvasSide()
+	if w < 1 || h < 1 || w > max || h > max {
+		return nil, fmt.Errorf("canvas width and height must be between 1 and %d", max)
+	}
+
+	canvas := &Canvas{
+		img: image.NewRGBA(image.Rect(0, 0, w, h)),
+		ctx: c,
+	}
+
+	if len(background) > 0 {
+		col, err := parseImageColor(background[0])
+		if err != nil {
+			return nil, err
+		}
+
+		draw.Draw(canvas.img, canvas.img.Bounds(), image.NewUniform(col), image.Point{}, draw.Src)
+	}
+
+	return canvas, nil
+}
+
+func (c *Context) tmplDrawRect(canvas *Canvas, x, y, width, height, fill interface{}) (string, error) {
+	if err := c.checkImageDraw(); err != nil {
+		return "", err
+	}
+
+	col, err := parseImageColor(fill)
+	if err != nil {
+		return "", err
+	}
+
+	x0, y0 := tmplToInt(x), tmplToInt(y)
+	rect := image.Rect(x0, y0, x0+tmplToInt(width), y0+tmplToInt(height)).Intersect(canvas.img.Bounds())
+	draw.Draw(canvas.img, rect, image.NewUniform(col), image.Point{}, draw.Over)
+	return "", nil
+}
+
+func (c *Context) tmplDrawCircle(canvas *Canvas, cx, cy, radius, fill interface{}) (string, error) {
+	if err := c.checkImageDraw(); err != nil {
+		return "", err
+	}
+
+	col, err := parseImageColor(fill)
+	if err != nil {
+		return "", err
+	}
+
+	mask := &circleMask{cx: ToFloat64(cx), cy: ToFloat64(cy), r: ToFloat64(radius)}
+	draw.DrawMask(canvas.img, mask.Bounds().Intersect(canvas.img.Bounds()), image.NewUniform(col), image.Point{}, mask, mask.Bounds().Intersect(canvas.img.Bounds()).Min, draw.Over)
+	return "", nil
+}
+
+// tmplDrawText draws text with its baseline at y, options is an optional sdict with
+// size, color, font (regular, bold, italic or mono), align (left, center or right) and maxWidth
+func (c *Context) tmplDrawText(canvas *Canvas, text interface{}, x, y interface{}, options ...interface{}) (string, error) {
+	if err := c.checkImageDraw(); err != nil {
+		return "", err
+	}
+
+	str := ToString(text)
+	if utf8.RuneCountInString(str) > maxImageTextLength {
+		return "", fmt.Errorf("text can be max %d characters long", maxImageTextLength)
+	}
+
+	size := 16.0
+	fontName := "regular"
+	align := "left"
+	maxWidth := 0
+	var col color.Color = color.Black
+
+	if len(options) > 0 {
+		opts, err := StringKeyDictionary(options...)
+		if err != nil {
+			return "", err
+		}
+
+		for k, v := range opts {
+			switch k {
+			case "size":
+				size = ToFloat64(v)
+				if size < 1 || size > maxImageFontSize {
+					return "", fmt.Errorf("font size must be between 1 and %d", maxImageFontSize)
+				}
+			case "color":
+				col, err = parseImageColor(v)
+				if err != nil {
+					return "", err
+				}
+			case "font":
+				fontName = strings.ToLower(ToString(v))
+			case "align":
+				align = strings.ToLower(ToString(v))
+			case "maxWidth":
+				maxWidth = tmplToInt(v)
+			default:
+				return "", errors.New(`invalid key "` + k + `" passed to drawText`)
+			}
+		}
+	}
+
+	face, err := imageFontFace(fontName, size)
+	if err != nil {
+		return "", err
+	}
+	defer face.Close()
+
+	drawer := &font.Drawer{
+		Dst:  canvas.img,
+		Src:  image.NewUniform(col),
+		Face: face,
+	}
+
+	if maxWidth > 0 {
+		str = truncateToWidth(drawer, str, fixed.I(maxWidth))
+	}
+
+	startX := fixed.I(tmplToInt(x))
+	switch align {
+	case "left":
+	case "center":
+		startX -= drawer.MeasureString(str) / 2
+	case "right":
+		startX -= drawer.MeasureString(str)
+	default:
+		return "", errors.New("align must be left, center or right")
+	}
+
+	drawer.Dot = fixed.Point26_6{X: startX, Y: fixed.I(tmplToInt(y))}
+	drawer.DrawString(str)
+	return "", nil
+}
+
+func truncateToWidth(drawer *font.Drawer, str string, width fixed.Int26_6) string {
+	if drawer.MeasureString(str) <= width {
+		return str
+	}
+
+	runes := []rune(str)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := string(runes) + "…"
+		if drawer.MeasureString(candidate) <= width {
+			return candidate
+		}
+	}
+
+	return ""
+}
+
+// tmplDrawAvatar pastes the avatar of the target scaled to size x size, rounded makes it a circle
+func (c *Context) tmplDrawAvatar(canvas *Canvas, target interface{}, x, y, size interface{}, rounded ...bool) (string, error) {
+	if err := c.checkImageDraw(); err != nil {
+		return "", err
+	}
+
+	if c.IncreaseCheckCallCounterPremium("image_avatar", 3, 10) || c.IncreaseCheckGenericAPICall() {
+		return "", ErrTooManyCalls
+	}
+
+	s := tmplToInt(size)
+	if s < 1 || s > c.maxCanvasSide() {
+		return "", fmt.Errorf("avatar size must be between 1 and %d", c.maxCanvasSide())
+	}
+
+	user, ok := target.(*discordgo.User)
+	if !ok {
+		targetID := TargetUserID(target)
+		if targetID == 0 || c.GS == nil {
+			return "", errors.New("unknown user")
+		}
+
+		ms, err := bot.GetMember(c.GS.ID, targetID)
+		if err != nil {
+			return "", err
+		}
+
+		user = &ms.User
+	}
+
+	avatar, err := AvatarFetcher(user, s)
+	if err != nil {
+		return "", err
+	}
+
+	scaled := image.NewRGBA(image.Rect(0, 0, s, s))
+	xdraw.ApproxBiLinear.Scale(scaled, scaled.Bounds(), avatar, avatar.Bounds(), draw.Src, nil)
+
+	x0, y0 := tmplToInt(x), tmplToInt(y)
+	dst := image.Rect(x0, y0, x0+s, y0+s)
+	if len(rounded) > 0 && rounded[0] {
+		half := float64(s) / 2
+		mask := &circleMask{cx: half, cy: half, r: half}
+		draw.DrawMask(canvas.img, dst, scaled, image.Point{}, mask, image.Point{}, draw.Over)
+	} else {
+		draw.Draw(canvas.img, dst, scaled, image.Point{}, draw.Over)
+	}
+
+	return "", nil
+}
+
+// tmplRoundCrop makes the corners of the canvas transparent, a radius of half the shortest side gives a circle or pill
+func (c *Context) tmplRoundCrop(canvas *Canvas, radius interface{}) (*Canvas, error) {
+	if err := c.checkImageDraw(); err != nil {
+		return nil, err
+	}
+
+	bounds := canvas.img.Bounds()
+	mask := &roundedRectMask{w: bounds.Dx(), h: bounds.Dy(), r: ToFloat64(radius)}
+
+	cropped := image.NewRGBA(bounds)
+	draw.DrawMask(cropped, bounds, canvas.img, bounds.Min, mask, image.Point{}, draw.Src)
+	canvas.img = cropped
+	return canvas, nil
+}
+
+func imageFontFace(name string, size float64) (font.Face, error) {
+	imageFontsOnce.Do(func() {
+		imageFonts = make(map[string]*opentype.Font, len(imageFontData))
+		for k, v := range imageFontData {
+			f, err := opentype.Parse(v)
+			if err != nil {
+				imageFontsErr = err
+				return
+			}
+			imageFonts[k] = f
+		}
+	})
+
+	if imageFontsErr != nil {
+		return nil, imageFontsErr
+	}
+
+	f, ok := imageFonts[name]
+	if !ok {
+		return nil, errors.New("unknown font, available fonts are regular, bold, italic and mono")
+	}
+
+	// faces keep internal buffers, so they're not shared between executions
+	return opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+}
+
+// parseImageColor accepts a color as an int (0xRRGGBB), a hex string (#RRGGBB or #RRGGBBAA) or a color name
+func parseImageColor(v interface{}) (color.Color, error) {
+	switch t := v.(type) {
+	case string:
+		raw := strings.TrimPrefix(t, "#")
+		if len(raw) == 8 {
+			parsed, err := strconv.ParseUint(raw, 16, 32)
+			if err == nil {
+				return color.NRGBA{R: uint8(parsed >> 24), G: uint8(parsed >> 16), B: uint8(parsed >> 8), A: uint8(parsed)}, nil
+			}
+		}
+
+		parsed, ok := common.ParseColor(t)
+		if !ok {
+			return nil, errors.New("invalid color: " + t)
+		}
+		return rgbColor(parsed), nil
+	default:
+		if !common.IsNumber(v) {
+			return nil, fmt.Errorf("invalid color: %v", v)
+		}
+		return rgbColor(int(ToInt64(v))), nil
+	}
+}
+
+func rgbColor(c int) color.Color {
+	return color.NRGBA{R: uint8(c >> 16), G: uint8(c >> 8), B: uint8(c), A: 255}
+}
+
+// circleMask is an anti-aliased filled circle
+type circleMask struct {
+	cx, cy, r float64
+}
+
+func (m *circleMask) ColorModel() color.Model { return color.AlphaModel }
+
+func (m *circleMask) Bounds() image.Rectangle {
+	return image.Rect(int(math.Floor(m.cx-m.r)), int(math.Floor(m.cy-m.r)), int(math.Ceil(m.cx+m.r)), int(math.Ceil(m.cy+m.r)))
+}
+
+func (m *circleMask) At(x, y int) color.Color {
+	dx, dy := float64(x)+0.5-m.cx, float64(y)+0.5-m.cy
+	return color.Alpha{A: edgeAlpha(m.r - math.Sqrt(dx*dx+dy*dy))}
+}
+
+// roundedRectMask is an anti-aliased rectangle with rounded corners, starting at 0,0
+type roundedRectMask struct {
+	w, h int
+	r    float64
+}
+
+func (m *roundedRectMask) ColorModel() color.Model { return color.AlphaModel }
+
+func (m *roundedRectMask) Bounds() image.Rectangle { return image.Rect(0, 0, m.w, m.h) }
+
+func (m *roundedRectMask) At(x, y int) color.Color {
+	r := math.Min(m.r, math.Min(float64(m.w), float64(m.h))/2)
+	px, py := float64(x)+0.5, float64(y)+0.5
+
+	// distance from the closest corner circle center, if the point is in a corner area
+	cx := math.Max(r, math.Min(px, float64(m.w)-r))
+	cy := math.Max(r, math.Min(py, float64(m.h)-r))
+	if cx == px || cy == py {
+		return color.Alpha{A: 255}
+	}
+
+	dx, dy := px-cx, py-cy
+	return color.Alpha{A: edgeAlpha(r - math.Sqrt(dx*dx+dy*dy))}
+}
+
+// edgeAlpha returns the coverage of a pixel that's dist away from the edge of a shape, negative is outside
+func edgeAlpha(dist float64) uint8 {
+	switch {
+	case dist >= 0.5:
+		return 255
+	case dist <= -0.5:
+		return 0
+	default:
+		return uint8((dist + 0.5) * 255)
+	}
+}
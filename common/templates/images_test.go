@@ -0,0 +1,191 @@
+package templates
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"testing"
+
+	"github.com/mrbentarikau/pagst/lib/discordgo"
+)
+
+func TestImageCanvasLimits(t *testing.T) {
+	if _, err := NewContext(nil, nil, nil).tmplNewCanvas(MaxCanvasSide+1, 10); err == nil {
+		t.Error("expected error for a canvas above the size limit")
+	}
+
+	if _, err := NewContext(nil, nil, nil).tmplNewCanvas(0, 10); err == nil {
+		t.Error("expected error for an empty canvas")
+	}
+
+	ctx := NewContext(nil, nil, nil)
+	canvas, err := ctx.tmplNewCanvas(64, 32, "#ff000080")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if canvas.Width() != 64 || canvas.Height() != 32 {
+		t.Errorf("unexpected canvas size %s", canvas)
+	}
+
+	if _, err := ctx.tmplNewCanvas(10, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ctx.tmplNewCanvas(10, 10); err != ErrTooManyCalls {
+		t.Errorf("expected the canvas limit to be hit, got %v", err)
+	}
+
+	for i := 0; i < 100; i++ {
+		if _, err := ctx.tmplDrawRect(canvas, 0, 0, 1, 1, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := ctx.tmplDrawRect(canvas, 0, 0, 1, 1, 0); err != ErrTooManyCalls {
+		t.Errorf("expected the draw limit to be hit, got %v", err)
+	}
+}
+
+func TestImageDrawing(t *testing.T) {
+	ctx := NewContext(nil, nil, nil)
+
+	canvas, err := ctx.tmplNewCanvas(100, 100, "white")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = ctx.tmplDrawRect(canvas, 0, 0, 10, 10, 0x00ff00); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = ctx.tmplDrawCircle(canvas, 50, 50, 20, "#0000ff"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = ctx.tmplDrawText(canvas, "Hello", 50, 90, SDict{"size": 20, "font": "bold", "align": "center", "color": "red"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = ctx.tmplDrawText(canvas, "Hello", 0, 0, SDict{"font": "comic sans"}); err == nil {
+		t.Error("expected error for an unknown font")
+	}
+
+	expectColor(t, canvas, 5, 5, color.RGBA{G: 255, A: 255})
+	expectColor(t, canvas, 50, 50, color.RGBA{B: 255, A: 255})
+	expectColor(t, canvas, 30, 10, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+
+	if _, err = ctx.tmplRoundCrop(canvas, 50); err != nil {
+		t.Fatal(err)
+	}
+
+	expectColor(t, canvas, 0, 0, color.RGBA{})
+	expectColor(t, canvas, 50, 50, color.RGBA{B: 255, A: 255})
+}
+
+func TestImageDrawAvatar(t *testing.T) {
+	oldFetcher := AvatarFetcher
+	defer func() { AvatarFetcher = oldFetcher }()
+
+	AvatarFetcher = func(user *discordgo.User, size int) (image.Image, error) {
+		img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+		draw.Draw(img, img.Bounds(), image.NewUniform(color.RGBA{R: 255, A: 255}), image.Point{}, draw.Src)
+		return img, nil
+	}
+
+	ctx := NewContext(nil, nil, nil)
+	canvas, err := ctx.tmplNewCanvas(64, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = ctx.tmplDrawAvatar(canvas, &discordgo.User{ID: 1}, 0, 0, 32, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// the corners outside of the circle are left untouched
+	expectColor(t, canvas, 16, 16, color.RGBA{R: 255, A: 255})
+	expectColor(t, canvas, 1, 1, color.RGBA{})
+
+	if _, err = ctx.tmplDrawAvatar(canvas, int64(1), 0, 0, 32); err == nil {
+		t.Error("expected error looking up a member without a guild")
+	}
+}
+
+func TestCreateMessageSendImage(t *testing.T) {
+	ctx := NewContext(nil, nil, nil)
+	canvas, err := ctx.tmplNewCanvas(8, 8, 0xffffff)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := CreateMessageSend("image", canvas, "file", "hello", "filename", "card")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if msg.File != nil || len(msg.Files) != 2 {
+		t.Fatalf("expected both files in Files, got %#v", msg)
+	}
+
+	var imageFile *discordgo.File
+	for _, f := range msg.Files {
+		if f.ContentType == "image/png" {
+			imageFile = f
+		}
+	}
+
+	if imageFile == nil || imageFile.Name != "card.png" {
+		t.Fatalf("unexpected image file %#v", imageFile)
+	}
+
+	decoded, err := png.Decode(imageFile.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.Bounds().Dx() != 8 {
+		t.Errorf("unexpected decoded width %d", decoded.Bounds().Dx())
+	}
+
+	if _, err = CreateMessageSend("image", "not a canvas"); err == nil {
+		t.Error("expected error for a non canvas image")
+	}
+}
+
+func TestParseImageColor(t *testing.T) {
+	cases := []struct {
+		in       interface{}
+		expected color.NRGBA
+	}{
+		{0xff8000, color.NRGBA{R: 255, G: 128, A: 255}},
+		{"#ff8000", color.NRGBA{R: 255, G: 128, A: 255}},
+		{"#ff800080", color.NRGBA{R: 255, G: 128, A: 128}},
+		{"black", color.NRGBA{A: 255}},
+	}
+
+	for _, c := range cases {
+		got, err := parseImageColor(c.in)
+		if err != nil {
+			t.Errorf("%v: %v", c.in, err)
+			continue
+		}
+
+		if got != c.expected {
+			t.Errorf("%v: expected %v, got %v", c.in, c.expected, got)
+		}
+	}
+
+	if _, err := parseImageColor("notacolor"); err == nil {
+		t.Error("expected error for an invalid color")
+	}
+}
+
+func expectColor(t *testing.T, canvas *Canvas, x, y int, expected color.RGBA) {
+	t.Helper()
+
+	if got := canvas.img.RGBAAt(x, y); got != expected {
+		t.Errorf("pixel %d,%d: expected %v, got %v", x, y, expected, got)
+	}
+}
@@ -0,0 +1,460 @@
+package templates
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mrbentarikau/pagst/lib/template"
+	"github.com/mrbentarikau/pagst/lib/template/parse"
+)
+
+// LintIssue is a single problem found by Lint, Line is 1-based
+type LintIssue struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+func (l *LintIssue) String() string {
+	return fmt.Sprintf("line %d: %s", l.Line, l.Message)
+}
+
+// functions that are safe to call in a while condition, as in they never change the state of anything
+var lintPureFuncs = map[string]bool{
+	"and": true, "or": true, "not": true, "len": true, "index": true,
+	"eq": true, "ne": true, "lt": true, "le": true, "gt": true, "ge": true,
+}
+
+var lintParseErrLineRe = regexp.MustCompile(`^template: [^:]*:(\d+):`)
+
+// Lint parses the source with the full function map and looks for common mistakes,
+// such as wrong argument counts, unused or shadowed variables, unreachable code and unbounded loops.
+// A parse error is returned as a single issue.
+func Lint(source string) []*LintIssue {
+	ctx := NewContext(nil, nil, nil)
+	ctx.Name = "lint"
+
+	parsed, err := ctx.Parse(source)
+	if err != nil {
+		line := 0
+		if m := lintParseErrLineRe.FindStringSubmatch(err.Error()); m != nil {
+			line, _ = strconv.Atoi(m[1])
+		}
+
+		return []*LintIssue{{Line: line, Message: err.Error()}}
+	}
+
+	l := &linter{
+		source: source,
+		funcs:  make(map[string]reflect.Type),
+	}
+
+	for _, m := range []map[string]interface{}{StandardFuncMap, ctx.ContextFuncs} {
+		for k, v := range m {
+			l.funcs[k] = reflect.TypeOf(v)
+		}
+	}
+
+	for _, t := range parsed.Templates() {
+		if t.Tree == nil || t.Tree.Root == nil {
+			continue
+		}
+
+		l.lintTree(t)
+	}
+
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		return l.issues[i].Line < l.issues[j].Line
+	})
+
+	return l.issues
+}
+
+type lintVar struct {
+	name     string
+	line     int
+	used     bool
+	rangeKey bool
+}
+
+type linter struct {
+	source string
+	funcs  map[string]reflect.Type
+	scopes [][]*lintVar
+	issues []*LintIssue
+}
+
+func (l *linter) lintTree(t *template.Template) {
+	l.scopes = nil
+	l.pushScope()
+	l.walkList(t.Tree.Root)
+	l.popScope()
+}
+
+func (l *linter) line(n parse.Node) int {
+	pos := int(n.Position())
+	if pos > len(l.source) {
+		pos = len(l.source)
+	}
+
+	return strings.Count(l.source[:pos], "\n") + 1
+}
+
+func (l *linter) report(n parse.Node, format string, args ...interface{}) {
+	l.issues = append(l.issues, &LintIssue{
+		Line:    l.line(n),
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (l *linter) pushScope() {
+	l.scopes = append(l.scopes, nil)
+}
+
+func (l *linter) popScope() {
+	last := l.scopes[len(l.scopes)-1]
+	l.scopes = l.scopes[:len(l.scopes)-1]
+
+	for _, v := range last {
+		if !v.used && !v.rangeKey {
+			l.issues = append(l.issues, &LintIssue{Line: v.line, Message: fmt.Sprintf("variable %s declared but never used", v.name)})
+		}
+	}
+}
+
+func (l *linter) lookup(name string) *lintVar {
+	for i := len(l.scopes) - 1; i >= 0; i-- {
+		for j := len(l.scopes[i]) - 1; j >= 0; j-- {
+			if l.scopes[i][j].name == name {
+				return l.scopes[i][j]
+			}
+		}
+	}
+
+	return nil
+}
+
+func (l *linter) declare(v *parse.VariableNode, rangeKey bool) {
+	name := v.Ident[0]
+	if name == "$" {
+		return
+	}
+
+	current := l.scopes[len(l.scopes)-1]
+	inCurrent := false
+	for _, cv := range current {
+		if cv.name == name {
+			inCurrent = true
+			break
+		}
+	}
+
+	if !inCurrent {
+		if outer := l.lookup(name); outer != nil {
+			l.report(v, "variable %s shadows the declaration on line %d", name, outer.line)
+		}
+	}
+
+	l.scopes[len(l.scopes)-1] = append(current, &lintVar{name: name, line: l.line(v), rangeKey: rangeKey})
+}
+
+func (l *linter) use(v *parse.VariableNode) {
+	if v.Ident[0] == "$" {
+		return
+	}
+
+	if lv := l.lookup(v.Ident[0]); lv != nil {
+		lv.used = true
+	}
+}
+
+func (l *linter) walkList(list *parse.ListNode) {
+	if list == nil {
+		return
+	}
+
+	exited := false
+	reportedUnreachable := false
+	for _, n := range list.Nodes {
+		if exited && !reportedUnreachable && !isWhitespaceText(n) {
+			l.report(n, "unreachable code")
+			reportedUnreachable = true
+		}
+
+		l.walkNode(n)
+
+		switch n.(type) {
+		case *parse.ReturnNode, *parse.BreakNode, *parse.ContinueNode:
+			exited = true
+		}
+	}
+}
+
+func isWhitespaceText(n parse.Node) bool {
+	t, ok := n.(*parse.TextNode)
+	return ok && strings.TrimSpace(string(t.Text)) == ""
+}
+
+func (l *linter) walkNode(n parse.Node) {
+	switch n := n.(type) {
+	case *parse.ActionNode:
+		l.walkPipe(n.Pipe, false)
+	case *parse.IfNode:
+		l.walkBranch(&n.BranchNode, false)
+	case *parse.WithNode:
+		l.walkBranch(&n.BranchNode, false)
+	case *parse.RangeNode:
+		l.walkBranch(&n.BranchNode, true)
+	case *parse.WhileNode:
+		l.checkWhile(n)
+		l.walkBranch(&n.BranchNode, false)
+	case *parse.TryNode:
+		l.pushScope()
+		l.walkList(n.List)
+		l.popScope()
+
+		l.pushScope()
+		l.walkList(n.CatchList)
+		l.popScope()
+	case *parse.ReturnNode:
+		l.walkPipe(n.Pipe, false)
+	case *parse.TemplateNode:
+		l.walkPipe(n.Pipe, false)
+	case *parse.ListNode:
+		l.walkList(n)
+	}
+}
+
+func (l *linter) walkBranch(b *parse.BranchNode, isRange bool) {
+	l.pushScope()
+	l.walkPipe(b.Pipe, isRange)
+	l.walkList(b.List)
+	l.walkList(b.ElseList)
+	l.popScope()
+}
+
+func (l *linter) walkPipe(pipe *parse.PipeNode, isRange bool) {
+	if pipe == nil {
+		return
+	}
+
+	for i, cmd := range pipe.Cmds {
+		l.walkCommand(cmd, i > 0)
+	}
+
+	for i, v := range pipe.Decl {
+		if pipe.IsAssign {
+			// assigning to a variable isn't a use of it, but make sure it's known
+			continue
+		}
+
+		l.declare(v, isRange && len(pipe.Decl) > 1 && i == 0)
+	}
+}
+
+func (l *linter) walkCommand(cmd *parse.CommandNode, piped bool) {
+	if len(cmd.Args) == 0 {
+		return
+	}
+
+	if ident, ok := cmd.Args[0].(*parse.IdentifierNode); ok {
+		given := len(cmd.Args) - 1
+		if piped {
+			given++
+		}
+
+		l.checkArgCount(ident, given)
+	}
+
+	for _, arg := range cmd.Args {
+		l.walkArg(arg)
+	}
+}
+
+func (l *linter) walkArg(arg parse.Node) {
+	switch arg := arg.(type) {
+	case *parse.VariableNode:
+		l.use(arg)
+	case *parse.PipeNode:
+		l.walkPipe(arg, false)
+	case *parse.ChainNode:
+		l.walkArg(arg.Node)
+	}
+}
+
+func (l *linter) checkArgCount(ident *parse.IdentifierNode, given int) {
+	t, ok := l.funcs[ident.Ident]
+	if !ok || t == nil || t.Kind() != reflect.Func {
+		return
+	}
+
+	numIn := t.NumIn()
+	if t.IsVariadic() {
+		if given < numIn-1 {
+			l.report(ident, "%s expects at least %d arguments, got %d", ident.Ident, numIn-1, given)
+		}
+		return
+	}
+
+	if given != numIn {
+		l.report(ident, "%s expects %d arguments, got %d", ident.Ident, numIn, given)
+	}
+}
+
+// checkWhile reports while loops that can obviously never end: the condition only depends on
+// constants or variables that are never touched in the loop body, and there's no break or return
+func (l *linter) checkWhile(n *parse.WhileNode) {
+	condVars := make(map[string]bool)
+	if !collectStaticVars(n.Pipe, condVars) {
+		return
+	}
+
+	if hasLoopExit(n.List, false) {
+		return
+	}
+
+	for name := range condVars {
+		if referencesVar(n.List, name) {
+			return
+		}
+	}
+
+	l.report(n, "while loop condition never changes and the loop has no break or return")
+}
+
+// collectStaticVars returns false if the pipeline calls anything that could change state between iterations
+func collectStaticVars(pipe *parse.PipeNode, vars map[string]bool) bool {
+	if pipe == nil {
+		return true
+	}
+
+	for _, cmd := range pipe.Cmds {
+		for _, arg := range cmd.Args {
+			switch arg := arg.(type) {
+			case *parse.IdentifierNode:
+				if !lintPureFuncs[arg.Ident] {
+					return false
+				}
+			case *parse.VariableNode:
+				if len(arg.Ident) > 1 {
+					// method or field access, can't know what it does
+					return false
+				}
+				vars[arg.Ident[0]] = true
+			case *parse.PipeNode:
+				if !collectStaticVars(arg, vars) {
+					return false
+				}
+			case *parse.ChainNode, *parse.FieldNode:
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func hasLoopExit(list *parse.ListNode, nestedLoop bool) bool {
+	if list == nil {
+		return false
+	}
+
+	for _, n := range list.Nodes {
+		switch n := n.(type) {
+		case *parse.ReturnNode:
+			return true
+		case *parse.BreakNode:
+			if !nestedLoop {
+				return true
+			}
+		case *parse.IfNode:
+			if hasLoopExit(n.List, nestedLoop) || hasLoopExit(n.ElseList, nestedLoop) {
+				return true
+			}
+		case *parse.WithNode:
+			if hasLoopExit(n.List, nestedLoop) || hasLoopExit(n.ElseList, nestedLoop) {
+				return true
+			}
+		case *parse.RangeNode:
+			if hasLoopExit(n.List, true) || hasLoopExit(n.ElseList, nestedLoop) {
+				return true
+			}
+		case *parse.WhileNode:
+			if hasLoopExit(n.List, true) || hasLoopExit(n.ElseList, nestedLoop) {
+				return true
+			}
+		case *parse.TryNode:
+			// an error inside try jumps to catch, which may exit the loop
+			if hasLoopExit(n.List, nestedLoop) || hasLoopExit(n.CatchList, nestedLoop) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func referencesVar(n parse.Node, name string) bool {
+	found := false
+	var walk func(n parse.Node)
+	walk = func(n parse.Node) {
+		if found || n == nil || reflect.ValueOf(n).IsNil() {
+			return
+		}
+
+		switch n := n.(type) {
+		case *parse.ListNode:
+			for _, c := range n.Nodes {
+				walk(c)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			for _, d := range n.Decl {
+				walk(d)
+			}
+			for _, c := range n.Cmds {
+				walk(c)
+			}
+		case *parse.CommandNode:
+			for _, a := range n.Args {
+				walk(a)
+			}
+		case *parse.ChainNode:
+			walk(n.Node)
+		case *parse.VariableNode:
+			if n.Ident[0] == name {
+				found = true
+			}
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.WhileNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.TryNode:
+			walk(n.List)
+			walk(n.CatchList)
+		case *parse.ReturnNode:
+			walk(n.Pipe)
+		case *parse.TemplateNode:
+			walk(n.Pipe)
+		}
+	}
+
+	walk(n)
+	return found
+}
@@ -0,0 +1,53 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	cases := []struct {
+		name     string
+		source   string
+		expected []string // substrings of the expected issues, in order
+		lines    []int
+	}{
+		{"clean", "{{$x := 1}}{{$x}}", nil, nil},
+		{"parse error", "{{undefinedFunc 1}}", []string{`function "undefinedFunc" not defined`}, []int{1}},
+		{"too few args", "{{joinStr}}", []string{"joinStr expects at least 1 arguments, got 0"}, []int{1}},
+		{"too many args", "\n{{lower \"a\" \"b\"}}", []string{"lower expects 1 arguments, got 2"}, []int{2}},
+		{"piped arg counts", `{{"A" | lower}}`, nil, nil},
+		{"unused var", "{{$x := 1}}\n{{$y := 2}}{{$y}}", []string{"variable $x declared but never used"}, []int{1}},
+		{"assign is not use", "{{$x := 1}}{{$x = 2}}", []string{"variable $x declared but never used"}, []int{1}},
+		{"shadowed var", "{{$x := 1}}{{$x}}\n{{if true}}{{$x := 2}}{{$x}}{{end}}", []string{"variable $x shadows the declaration on line 1"}, []int{2}},
+		{"range key unused", "{{range $k, $v := cslice 1 2}}{{$v}}{{end}}", nil, nil},
+		{"unreachable", "{{return}}\n{{print 1}}", []string{"unreachable code"}, []int{2}},
+		{"return in branch", "{{if true}}{{return}}{{end}}{{print 1}}", nil, nil},
+		{"unbounded while", "{{while true}}{{print 1}}{{end}}", []string{"while loop condition never changes"}, []int{1}},
+		{"while with break", "{{while true}}{{break}}{{end}}", nil, nil},
+		{"while with nested break", "{{while true}}{{range seq 0 2}}{{break}}{{end}}{{end}}", []string{"while loop condition never changes"}, []int{1}},
+		{"while var changes", "{{$i := 0}}{{while lt $i 5}}{{$i = add $i 1}}{{end}}", nil, nil},
+		{"while var untouched", "{{$i := 0}}{{while lt $i 5}}{{print 1}}{{end}}", []string{"while loop condition never changes"}, []int{1}},
+		{"while with func cond", "{{while randInt 2}}{{print 1}}{{end}}", nil, nil},
+		{"define block", "{{define \"a\"}}{{$z := 1}}{{end}}{{template \"a\"}}", []string{"variable $z declared but never used"}, []int{1}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			issues := Lint(c.source)
+			if len(issues) != len(c.expected) {
+				t.Fatalf("expected %d issues, got %d: %v", len(c.expected), len(issues), issues)
+			}
+
+			for i, issue := range issues {
+				if !strings.Contains(issue.Message, c.expected[i]) {
+					t.Errorf("issue %d: expected message containing %q, got %q", i, c.expected[i], issue.Message)
+				}
+
+				if issue.Line != c.lines[i] {
+					t.Errorf("issue %d: expected line %d, got %d", i, c.lines[i], issue.Line)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package customcommands
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mediocregopher/radix/v3"
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/common/backgroundworkers"
+	"github.com/mrbentarikau/pagst/customcommands/models"
+	"github.com/mrbentarikau/pagst/lib/discordgo"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+var _ backgroundworkers.BackgroundWorkerPlugin = (*Plugin)(nil)
+
+const (
+	// redis key holding the id of the last database entry value_json was filled in for, -1 once all of them are done
+	keyValueJSONBackfill = "custom_commands_value_json_backfill"
+
+	valueJSONBackfillBatch = 500
+)
+
+// RunBackgroundWorker implements backgroundworkers.BackgroundWorkerPlugin
+func (p *Plugin) RunBackgroundWorker() {
+	t := time.NewTicker(time.Second * 5)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+		case wg := <-p.stopBGWorker:
+			wg.Done()
+			return
+		}
+
+		done, err := backfillValueJSON()
+		if err != nil {
+			logger.WithError(err).Error("Failed backfilling value_json of custom command database entries")
+			continue
+		}
+
+		if done {
+			break
+		}
+	}
+
+	wg := <-p.stopBGWorker
+	wg.Done()
+}
+
+// StopBackgroundWorker implements backgroundworkers.BackgroundWorkerPlugin
+func (p *Plugin) StopBackgroundWorker(wg *sync.WaitGroup) {
+	p.stopBGWorker <- wg
+}
+
+// backfillValueJSON fills in value_json for a batch of the database entries written before it existed,
+// so that dbQuery's valueMatch also finds those. Returns true once there's nothing left to do.
+func backfillValueJSON() (done bool, err error) {
+	var lastID int64
+	err = common.RedisPool.Do(radix.Cmd(&lastID, "GET", keyValueJSONBackfill))
+	if err != nil {
+		return false, err
+	}
+
+	if lastID < 0 {
+		return true, nil
+	}
+
+	entries, err := models.TemplatesUserDatabases(
+		qm.Select(models.TemplatesUserDatabaseColumns.ID, models.TemplatesUserDatabaseColumns.ValueRaw),
+		qm.Where("id > ? AND value_json IS NULL", lastID),
+		qm.OrderBy("id ASC"),
+		qm.Limit(valueJSONBackfillBatch)).AllG(context.Background())
+	if err != nil {
+		return false, err
+	}
+
+	if len(entries) == 0 {
+		logger.Info("Done backfilling value_json of custom command database entries")
+		return true, common.RedisPool.Do(radix.Cmd(nil, "SET", keyValueJSONBackfill, "-1"))
+	}
+
+	for _, entry := range entries {
+		var value interface{}
+		if err := newDecoder(bytes.NewBuffer(entry.ValueRaw)).Decode(&value); err != nil {
+			// leave it as is, dbGet and friends fail on it as well
+			continue
+		}
+
+		entry.ValueJSON = jsonValue(value)
+		if !entry.ValueJSON.Valid {
+			continue
+		}
+
+		_, err = entry.UpdateG(context.Background(), boil.Whitelist(models.TemplatesUserDatabaseColumns.ValueJSON))
+		if err != nil {
+			return false, err
+		}
+	}
+
+	lastID = entries[len(entries)-1].ID
+	return false, common.RedisPool.Do(radix.Cmd(nil, "SET", keyValueJSONBackfill, discordgo.StrID(lastID)))
+}
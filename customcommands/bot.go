@@ -66,7 +66,7 @@ var _ bot.BotInitHandler = (*Plugin)(nil)
 var _ commands.CommandProvider = (*Plugin)(nil)
 
 func (p *Plugin) AddCommands() {
-	commands.AddRootCommands(p, cmdListCommands, cmdFixCommands, cmdEvalCommand)
+	commands.AddRootCommands(p, cmdListCommands, cmdFixCommands, cmdEvalCommand, cmdLintCommand)
 }
 
 func (p *Plugin) BotInit() {
@@ -77,6 +77,9 @@ func (p *Plugin) BotInit() {
 	pubsub.AddHandler("custom_commands_run_now", handleCustomCommandsRunNow, models.CustomCommand{})
 	scheduledevents2.RegisterHandler("cc_next_run", NextRunScheduledEvent{}, handleNextRunScheduledEVent)
 	scheduledevents2.RegisterHandler("cc_delayed_run", DelayedRunCCData{}, handleDelayedRunCC)
+	scheduledevents2.RegisterHandler("cc_reply_timeout", ReplyTimeoutData{}, handleReplyTimeout)
+	loadReplyWaitingGuilds()
+	eventsystem.AddHandlerAsyncLastLegacy(p, handleReplyInteraction, eventsystem.EventInteractionCreate)
 }
 
 func handleCustomCommandsRunNow(event *pubsub.Event) {
@@ -206,6 +209,11 @@ var cmdEvalCommand = &commands.YAGCommand{
 			return "Something weird happened... Contact the support server.", nil
 		}
 
+		tmplCtx.Libraries, err = BotCachedGetLibraries(guildData.GS.ID, data.Context())
+		if err != nil {
+			logger.WithError(err).WithField("guild", guildData.GS.ID).Error("failed fetching custom command libraries")
+		}
+
 		out, err := tmplCtx.Execute(code)
 		if err != nil {
 			return formatCustomCommandRunErr(code, err), err
@@ -255,6 +263,71 @@ var cmdEvalCommand = &commands.YAGCommand{
 	},
 }
 
+var cmdLintCommand = &commands.YAGCommand{
+	CmdCategory:    commands.CategoryTool,
+	Name:           "CCLint",
+	Description:    "Checks a custom command specified by id or trigger, or a code snippet, for common mistakes",
+	ArgumentCombos: [][]int{{0}, {1}},
+	Arguments: []*dcmd.ArgDef{
+		{Name: "ID", Type: dcmd.Int},
+		{Name: "Trigger-or-Code", Type: dcmd.String},
+	},
+	RequiredArgs:              1,
+	ApplicationCommandEnabled: false,
+	DefaultEnabled:            false,
+	RunFunc: func(data *dcmd.Data) (interface{}, error) {
+		var cc *models.CustomCommand
+		if data.Args[0].Value != nil {
+			var err error
+			cc, err = models.CustomCommands(qm.Where("guild_id = ? AND local_id = ?", data.GuildData.GS.ID, data.Args[0].Int64())).OneG(data.Context())
+			if err != nil {
+				return "Couldn't find a custom command with that ID", nil
+			}
+		} else {
+			// a trigger of exactly one command lints that command, anything else is linted as code
+			matches, err := models.CustomCommands(qm.Where("guild_id = ? AND lower(text_trigger) = ?", data.GuildData.GS.ID, strings.ToLower(strings.TrimSpace(data.Args[1].Str())))).AllG(data.Context())
+			if err != nil {
+				return "Failed retrieving custom commands", err
+			}
+
+			if len(matches) == 1 {
+				cc = matches[0]
+			}
+		}
+
+		var source, name string
+		if cc != nil {
+			if len(cc.Responses) > 0 {
+				source = cc.Responses[0]
+			}
+			name = fmt.Sprintf("CC #%d", cc.LocalID)
+		} else {
+			source = common.ParseCodeblock(data.Args[1].Str())
+			name = "The code"
+		}
+
+		issues := templates.Lint(source)
+		if len(issues) == 0 {
+			return name + " looks good, no issues found", nil
+		}
+
+		var out strings.Builder
+		out.WriteString(fmt.Sprintf("%s has %d possible issue(s):\n```\n", name, len(issues)))
+		for _, issue := range issues {
+			line := issue.String() + "\n"
+			if out.Len()+len(line) > 1900 {
+				out.WriteString("...\n")
+				break
+			}
+
+			out.WriteString(line)
+		}
+		out.WriteString("```")
+
+		return out.String(), nil
+	},
+}
+
 var cmdListCommands = &commands.YAGCommand{
 	CmdCategory:    commands.CategoryTool,
 	Name:           "CustomCommands",
@@ -854,6 +927,8 @@ func HandleMessageCreate(evt *eventsystem.EventData) {
 	member := dstate.MemberStateFromMember(mc.Member)
 	member.GuildID = evt.GS.ID
 
+	handleReplyMessage(evt.GS, cs, member, mc.Message)
+
 	var matchedCustomCommands []*TriggeredCC
 	var err error
 	common.LogLongCallTime(time.Second, true, "Took longer than a second to fetch custom commands", logrus.Fields{"guild": evt.GS.ID}, func() {
@@ -1090,6 +1165,10 @@ func ExecuteCustomCommand(cmd *models.CustomCommand, tmplCtx *templates.Context)
 		}
 	}()
 
+	if cmd.TriggerType == int(CommandTriggerLibrary) {
+		return errors.New("library custom commands can't be executed")
+	}
+
 	tmplCtx.Name = "CC #" + strconv.Itoa(int(cmd.LocalID))
 	tmplCtx.Data["CCID"] = cmd.LocalID
 	tmplCtx.Data["CCNote"] = cmd.Note.String
@@ -1144,13 +1223,37 @@ func ExecuteCustomCommand(cmd *models.CustomCommand, tmplCtx *templates.Context)
 
 	defer CCExecLock.Unlock(lockKey, lockHandle)
 
+	tmplCtx.Data["CooldownRemaining"] = time.Duration(0)
+	cooldownRemaining, err := checkSetCooldowns(cmd, tmplCtx)
+	if err != nil {
+		f.WithError(err).Error("failed checking custom command cooldowns")
+	} else if cooldownRemaining > 0 {
+		err = sendCooldownResponse(cmd, tmplCtx, cooldownRemaining)
+		if err != nil {
+			f.WithError(err).Warn("failed sending custom command cooldown response")
+		}
+		return nil
+	}
+
 	go analytics.RecordActiveUnit(cmd.GuildID, &Plugin{}, "executed_cc")
 
 	// pick a response and execute it
 	f.Info("Custom command #", tmplCtx.Data["CCID"], " triggered")
 
+	tmplCtx.Libraries, err = BotCachedGetLibraries(cmd.GuildID, context.Background())
+	if err != nil {
+		f.WithError(err).Error("failed fetching custom command libraries")
+	}
+
 	chanMsg := cmd.Responses[0]
+	started := time.Now()
 	out, err := tmplCtx.Execute(chanMsg)
+	execDuration := time.Since(started)
+
+	// recorded once the response is sent, so it's included in the messages sent
+	defer func(execErr error) {
+		go recordExecMetric(cmd.GuildID, newExecMetric(cmd.LocalID, execTriggerName(cmd, tmplCtx), execDuration, tmplCtx, execErr))
+	}(err)
 
 	// trim whitespace for accurate character count
 	out = strings.TrimSpace(out)
@@ -1204,6 +1307,9 @@ func ExecuteCustomCommand(cmd *models.CustomCommand, tmplCtx *templates.Context)
 					Content: content,
 				}, nil
 			})
+		if err == nil {
+			tmplCtx.MessagesSent++
+		}
 
 		if tmplCtx.CurrentFrame.DelResponse {
 			templates.MaybeScheduledDeleteMessage(tmplCtx.GS.ID, tmplCtx.CurrentFrame.CS.ID, pm.MessageID, tmplCtx.CurrentFrame.DelResponseDelay)
@@ -1230,6 +1336,20 @@ func ExecuteCustomCommand(cmd *models.CustomCommand, tmplCtx *templates.Context)
 	return nil
 }
 
+// execTriggerName returns what caused this execution, commands ran with execCC or scheduleUniqueCC are "Exec"
+// and continuations of waitForReply are "Reply"
+func execTriggerName(cmd *models.CustomCommand, tmplCtx *templates.Context) string {
+	if _, ok := tmplCtx.Data["ReplyContinuation"]; ok {
+		return "Reply"
+	}
+
+	if _, ok := tmplCtx.Data["ExecData"]; ok {
+		return "Exec"
+	}
+
+	return CommandTriggerType(cmd.TriggerType).String()
+}
+
 func formatCustomCommandRunErr(src string, err error) string {
 	// check if we can retrieve the original ExecError
 	cause := errors.Cause(err)
@@ -1554,7 +1674,7 @@ func CCTriggerLimitFinder(ctx context.Context, ccmdID, guildID, userID int64) (*
 		return nil, errors.WrapIf(err, "retrieving full custom command model for limiter")
 	}
 
-	if cc.TriggerType == 10 || cc.TriggerType == 5 {
+	if cc.TriggerType == 10 || cc.TriggerType == 5 || cc.TriggerType == int(CommandTriggerLibrary) {
 		return nil, nil
 	}
 
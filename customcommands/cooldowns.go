@@ -0,0 +1,153 @@
+package customcommands
+
+import (
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/mediocregopher/radix/v3"
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/common/templates"
+	"github.com/mrbentarikau/pagst/customcommands/models"
+	"github.com/mrbentarikau/pagst/lib/discordgo"
+)
+
+const (
+	// MaxCooldownSeconds is the longest cooldown that can be set on a custom command, 1 week
+	MaxCooldownSeconds = 604800
+
+	// MaxDailyLimit is the highest daily usage cap that can be set on a custom command
+	MaxDailyLimit = 100000
+)
+
+func keyCCCooldown(scope string, guildID, ccID, targetID int64) string {
+	return "custom_command_cooldown:" + scope + ":" + discordgo.StrID(guildID) + ":" + discordgo.StrID(ccID) + ":" + discordgo.StrID(targetID)
+}
+
+func keyCCDailyUsage(guildID, ccID, userID int64, day string) string {
+	return "custom_command_daily_usage:" + discordgo.StrID(guildID) + ":" + discordgo.StrID(ccID) + ":" + discordgo.StrID(userID) + ":" + day
+}
+
+type ccCooldown struct {
+	scope   string
+	seconds int
+	target  int64
+}
+
+// cooldownsFor returns the cooldowns that apply to this execution,
+// user cooldowns are skipped when there's no member (e.g interval triggers)
+func cooldownsFor(cmd *models.CustomCommand, tmplCtx *templates.Context) []ccCooldown {
+	var cooldowns []ccCooldown
+	if cmd.CooldownUser > 0 && tmplCtx.MS != nil {
+		cooldowns = append(cooldowns, ccCooldown{"user", cmd.CooldownUser, tmplCtx.MS.User.ID})
+	}
+
+	if cmd.CooldownChannel > 0 && tmplCtx.CurrentFrame.CS != nil {
+		cooldowns = append(cooldowns, ccCooldown{"channel", cmd.CooldownChannel, tmplCtx.CurrentFrame.CS.ID})
+	}
+
+	if cmd.CooldownGuild > 0 {
+		cooldowns = append(cooldowns, ccCooldown{"guild", cmd.CooldownGuild, cmd.GuildID})
+	}
+
+	return cooldowns
+}
+
+func hasCooldownsOrLimits(cmd *models.CustomCommand) bool {
+	return cmd.CooldownUser > 0 || cmd.CooldownChannel > 0 || cmd.CooldownGuild > 0 || cmd.DailyLimitUser > 0 || cmd.DailyLimitGuild > 0
+}
+
+// checkSetCooldowns checks the cooldowns and daily usage limits of the command, returning the time
+// remaining if it's on cooldown or over the limit. Otherwise the usage is recorded and the cooldowns started.
+//
+// This is called while holding the execution lock of the command, so the check and set don't race.
+func checkSetCooldowns(cmd *models.CustomCommand, tmplCtx *templates.Context) (time.Duration, error) {
+	if !hasCooldownsOrLimits(cmd) {
+		return 0, nil
+	}
+
+	if tmplCtx.MS != nil && common.ContainsInt64SliceOneOf(cmd.CooldownBypassRoles, tmplCtx.MS.Member.Roles) {
+		return 0, nil
+	}
+
+	cooldowns := cooldownsFor(cmd, tmplCtx)
+
+	var remaining time.Duration
+	for _, v := range cooldowns {
+		var ttl int64
+		err := common.RedisPool.Do(radix.Cmd(&ttl, "PTTL", keyCCCooldown(v.scope, cmd.GuildID, cmd.LocalID, v.target)))
+		if err != nil {
+			return 0, errors.WithStackIf(err)
+		}
+
+		if d := time.Duration(ttl) * time.Millisecond; d > remaining {
+			remaining = d
+		}
+	}
+
+	if remaining > 0 {
+		return remaining, nil
+	}
+
+	now := time.Now().UTC()
+	day := now.Format("2006-01-02")
+	untilReset := now.Truncate(24 * time.Hour).Add(24 * time.Hour).Sub(now)
+
+	var usageKeys []string
+	if cmd.DailyLimitGuild > 0 {
+		key := keyCCDailyUsage(cmd.GuildID, cmd.LocalID, 0, day)
+		over, err := dailyUsageAtLimit(key, cmd.DailyLimitGuild)
+		if err != nil || over {
+			return untilReset, err
+		}
+		usageKeys = append(usageKeys, key)
+	}
+
+	if cmd.DailyLimitUser > 0 && tmplCtx.MS != nil {
+		key := keyCCDailyUsage(cmd.GuildID, cmd.LocalID, tmplCtx.MS.User.ID, day)
+		over, err := dailyUsageAtLimit(key, cmd.DailyLimitUser)
+		if err != nil || over {
+			return untilReset, err
+		}
+		usageKeys = append(usageKeys, key)
+	}
+
+	actions := make([]radix.CmdAction, 0, len(usageKeys)*2+len(cooldowns))
+	for _, key := range usageKeys {
+		actions = append(actions, radix.Cmd(nil, "INCR", key))
+		actions = append(actions, radix.FlatCmd(nil, "EXPIRE", key, int((untilReset+time.Hour).Seconds())))
+	}
+
+	for _, v := range cooldowns {
+		actions = append(actions, radix.FlatCmd(nil, "SET", keyCCCooldown(v.scope, cmd.GuildID, cmd.LocalID, v.target), 1, "EX", v.seconds))
+	}
+
+	err := common.RedisPool.Do(radix.Pipeline(actions...))
+	return 0, errors.WithStackIf(err)
+}
+
+func dailyUsageAtLimit(key string, limit int) (bool, error) {
+	var used int
+	err := common.RedisPool.Do(radix.Cmd(&used, "GET", key))
+	if err != nil {
+		return false, errors.WithStackIf(err)
+	}
+
+	return used >= limit, nil
+}
+
+// sendCooldownResponse executes the cooldown response of the command, if it has one
+func sendCooldownResponse(cmd *models.CustomCommand, tmplCtx *templates.Context, remaining time.Duration) error {
+	if strings.TrimSpace(cmd.CooldownResponse) == "" {
+		return nil
+	}
+
+	tmplCtx.Data["CooldownRemaining"] = remaining
+	out, err := tmplCtx.Execute(cmd.CooldownResponse)
+	if err != nil {
+		return errors.WithMessage(err, "cooldown response")
+	}
+
+	_, err = tmplCtx.SendResponse(strings.TrimSpace(out))
+	return err
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"math"
 	"strings"
+	"sync"
 	"time"
 	"unicode/utf8"
 
@@ -42,12 +43,16 @@ const (
 
 func KeyCommands(guildID int64) string { return "custom_commands:" + discordgo.StrID(guildID) }
 
-type Plugin struct{}
+type Plugin struct {
+	stopBGWorker chan *sync.WaitGroup
+}
 
 func RegisterPlugin() {
 	common.InitSchemas("customcommands", DBSchemas...)
 
-	plugin := &Plugin{}
+	plugin := &Plugin{
+		stopBGWorker: make(chan *sync.WaitGroup),
+	}
 	common.RegisterPlugin(plugin)
 }
 
@@ -74,6 +79,7 @@ const (
 	CommandTriggerExact      CommandTriggerType = 4
 	CommandTriggerInterval   CommandTriggerType = 5
 	CommandTriggerReaction   CommandTriggerType = 6
+	CommandTriggerLibrary    CommandTriggerType = 11
 )
 
 var (
@@ -86,6 +92,7 @@ var (
 		CommandTriggerExact,
 		CommandTriggerInterval,
 		CommandTriggerReaction,
+		CommandTriggerLibrary,
 	}
 
 	triggerStrings = map[CommandTriggerType]string{
@@ -97,6 +104,7 @@ var (
 		CommandTriggerExact:      "Exact",
 		CommandTriggerInterval:   "Interval",
 		CommandTriggerReaction:   "Reaction",
+		CommandTriggerLibrary:    "Library",
 	}
 
 	embedTriggerStrings = map[CommandTriggerType]string{
@@ -108,6 +116,7 @@ var (
 		CommandTriggerExact:      "exac",
 		CommandTriggerInterval:   "intv",
 		CommandTriggerReaction:   "reac",
+		CommandTriggerLibrary:    "libr",
 	}
 )
 
@@ -167,6 +176,17 @@ type CustomCommand struct {
 	ShowErrors       bool `schema:"show_errors"`
 	ThreadsEnabled   bool `schema:"threads_enabled"`
 	NormalizeUnicode bool `json:"normalize_unicode" schema:"normalize_unicode"`
+
+	// Cooldowns are in seconds, 0 disables them
+	CooldownUser        int     `json:"cooldown_user" schema:"cooldown_user"`
+	CooldownChannel     int     `json:"cooldown_channel" schema:"cooldown_channel"`
+	CooldownGuild       int     `json:"cooldown_guild" schema:"cooldown_guild"`
+	CooldownBypassRoles []int64 `json:"cooldown_bypass_roles" schema:"cooldown_bypass_roles" valid:"role,true"`
+	CooldownResponse    string  `json:"cooldown_response" schema:"cooldown_response" valid:"template,2000"`
+
+	// Max number of runs per day (UTC), 0 disables them
+	DailyLimitUser  int `json:"daily_limit_user" schema:"daily_limit_user"`
+	DailyLimitGuild int `json:"daily_limit_guild" schema:"daily_limit_guild"`
 }
 
 var _ web.CustomValidator = (*CustomCommand)(nil)
@@ -212,6 +232,18 @@ func (cc *CustomCommand) Validate(tmpl web.TemplateData, guild_id int64) (ok boo
 		return false
 	}
 
+	for _, v := range []int{cc.CooldownUser, cc.CooldownChannel, cc.CooldownGuild} {
+		if v < 0 || v > MaxCooldownSeconds {
+			tmpl.AddAlerts(web.ErrorAlert(fmt.Sprintf("Cooldowns have to be between 0 and %d seconds", MaxCooldownSeconds)))
+			return false
+		}
+	}
+
+	if cc.DailyLimitUser < 0 || cc.DailyLimitUser > MaxDailyLimit || cc.DailyLimitGuild < 0 || cc.DailyLimitGuild > MaxDailyLimit {
+		tmpl.AddAlerts(web.ErrorAlert(fmt.Sprintf("Daily usage limits have to be between 0 and %d", MaxDailyLimit)))
+		return false
+	}
+
 	if cc.TriggerTypeForm == "interval_minutes" && cc.TimeTriggerInterval < 1 {
 		tmpl.AddAlerts(web.ErrorAlert("Minimum interval is 1 minute..."))
 		return false
@@ -272,6 +304,14 @@ func (cc *CustomCommand) ToDBModel() *models.CustomCommand {
 		ThreadsEnabled:   cc.ThreadsEnabled,
 		NormalizeUnicode: cc.NormalizeUnicode,
 
+		CooldownUser:        cc.CooldownUser,
+		CooldownChannel:     cc.CooldownChannel,
+		CooldownGuild:       cc.CooldownGuild,
+		CooldownBypassRoles: cc.CooldownBypassRoles,
+		CooldownResponse:    cc.CooldownResponse,
+		DailyLimitUser:      cc.DailyLimitUser,
+		DailyLimitGuild:     cc.DailyLimitGuild,
+
 		DateUpdated: null.TimeFrom(time.Now()),
 	}
 
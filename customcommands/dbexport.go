@@ -0,0 +1,330 @@
+package customcommands
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/common/templates"
+	"github.com/mrbentarikau/pagst/customcommands/models"
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+const dbExportBatchSize = 1000
+
+var dbExportCSVHeader = []string{"user_id", "key", "value", "value_num", "created_at", "updated_at", "expires_at"}
+
+// DBExportEntry is a single database entry in an export, values are stored as json
+// so numbers come back as floats and dicts as sdicts on import
+type DBExportEntry struct {
+	UserID    int64       `json:"user_id"`
+	Key       string      `json:"key"`
+	Value     interface{} `json:"value"`
+	ValueNum  float64     `json:"value_num"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	ExpiresAt *time.Time  `json:"expires_at,omitempty"`
+}
+
+func dbExportEntryFromModel(m *models.TemplatesUserDatabase) (*DBExportEntry, error) {
+	light, err := ToLightDBEntry(m)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &DBExportEntry{
+		UserID:    m.UserID,
+		Key:       m.Key,
+		Value:     jsonCompatible(light.Value),
+		ValueNum:  m.ValueNum,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+
+	if m.ExpiresAt.Valid {
+		t := m.ExpiresAt.Time
+		entry.ExpiresAt = &t
+	}
+
+	return entry, nil
+}
+
+// jsonCompatible converts maps with non string keys (dicts) so they can be encoded as json
+func jsonCompatible(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, v := range t {
+			m[fmt.Sprint(k)] = jsonCompatible(v)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, v := range t {
+			m[k] = jsonCompatible(v)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(t))
+		for i, v := range t {
+			s[i] = jsonCompatible(v)
+		}
+		return s
+	}
+
+	return v
+}
+
+// exportGuildDB writes all the non expired database entries of the guild to w, format is either json or csv
+func exportGuildDB(ctx context.Context, w io.Writer, guildID int64, format string) error {
+	var csvWriter *csv.Writer
+	if format == "csv" {
+		csvWriter = csv.NewWriter(w)
+		if err := csvWriter.Write(dbExportCSVHeader); err != nil {
+			return err
+		}
+	} else if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+
+	written := 0
+	lastID := int64(0)
+	for {
+		rows, err := models.TemplatesUserDatabases(
+			qm.Where("guild_id = ? AND id > ? AND (expires_at IS NULL OR expires_at > now())", guildID, lastID),
+			qm.OrderBy("id asc"), qm.Limit(dbExportBatchSize)).AllG(ctx)
+		if err != nil {
+			return errors.WithStackIf(err)
+		}
+
+		for _, row := range rows {
+			lastID = row.ID
+
+			entry, err := dbExportEntryFromModel(row)
+			if err != nil {
+				logger.WithError(err).WithField("guild", guildID).Warn("[cc/web] failed decoding db entry for export")
+				continue
+			}
+
+			if csvWriter != nil {
+				err = csvWriter.Write(entry.csvRecord())
+			} else {
+				err = writeJSONExportEntry(w, entry, written == 0)
+			}
+			if err != nil {
+				return err
+			}
+
+			written++
+		}
+
+		if len(rows) < dbExportBatchSize {
+			break
+		}
+	}
+
+	if csvWriter != nil {
+		csvWriter.Flush()
+		return csvWriter.Error()
+	}
+
+	_, err := io.WriteString(w, "]")
+	return err
+}
+
+func writeJSONExportEntry(w io.Writer, entry *DBExportEntry, first bool) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if !first {
+		if _, err = io.WriteString(w, ","); err != nil {
+			return err
+		}
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+func (e *DBExportEntry) csvRecord() []string {
+	value, _ := json.Marshal(e.Value)
+
+	expires := ""
+	if e.ExpiresAt != nil {
+		expires = e.ExpiresAt.Format(time.RFC3339)
+	}
+
+	return []string{
+		strconv.FormatInt(e.UserID, 10),
+		e.Key,
+		string(value),
+		strconv.FormatFloat(e.ValueNum, 'f', -1, 64),
+		e.CreatedAt.Format(time.RFC3339),
+		e.UpdatedAt.Format(time.RFC3339),
+		expires,
+	}
+}
+
+// parseDBImport reads the entries of a json or csv export
+func parseDBImport(r io.Reader, format string) ([]*DBExportEntry, error) {
+	if format != "csv" {
+		var entries []*DBExportEntry
+		err := json.NewDecoder(r).Decode(&entries)
+		return entries, errors.WithMessage(err, "invalid json")
+	}
+
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid csv")
+	}
+
+	if len(records) < 1 {
+		return nil, nil
+	}
+
+	// allow the header row to be left out
+	if records[0][0] == dbExportCSVHeader[0] {
+		records = records[1:]
+	}
+
+	entries := make([]*DBExportEntry, 0, len(records))
+	for i, record := range records {
+		entry, err := dbImportEntryFromCSV(record)
+		if err != nil {
+			return nil, errors.WithMessage(err, "row "+strconv.Itoa(i+1))
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func dbImportEntryFromCSV(record []string) (*DBExportEntry, error) {
+	if len(record) != len(dbExportCSVHeader) {
+		return nil, errors.Errorf("expected %d columns, got %d", len(dbExportCSVHeader), len(record))
+	}
+
+	var err error
+	entry := &DBExportEntry{Key: record[1]}
+
+	if entry.UserID, err = strconv.ParseInt(record[0], 10, 64); err != nil {
+		return nil, errors.New("invalid user_id")
+	}
+
+	if err = json.Unmarshal([]byte(record[2]), &entry.Value); err != nil {
+		return nil, errors.New("value is not valid json")
+	}
+
+	if entry.ValueNum, err = strconv.ParseFloat(record[3], 64); err != nil {
+		return nil, errors.New("invalid value_num")
+	}
+
+	if entry.CreatedAt, err = time.Parse(time.RFC3339, record[4]); err != nil {
+		return nil, errors.New("invalid created_at")
+	}
+
+	if entry.UpdatedAt, err = time.Parse(time.RFC3339, record[5]); err != nil {
+		return nil, errors.New("invalid updated_at")
+	}
+
+	if record[6] != "" {
+		t, err := time.Parse(time.RFC3339, record[6])
+		if err != nil {
+			return nil, errors.New("invalid expires_at")
+		}
+		entry.ExpiresAt = &t
+	}
+
+	return entry, nil
+}
+
+var ErrDBImportAboveLimit = errors.New("importing these entries would put the database above its limit")
+
+// importGuildDB upserts the entries into the guild database, already expired entries are skipped.
+// Nothing is imported if the guild would end up with more than limit entries.
+// Returns the number of entries imported.
+func importGuildDB(ctx context.Context, guildID int64, entries []*DBExportEntry, limit int64) (int, error) {
+	tx, err := common.PQ.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, errors.WithStackIf(err)
+	}
+
+	imported := 0
+	for _, entry := range entries {
+		if entry.ExpiresAt != nil && entry.ExpiresAt.Before(time.Now()) {
+			continue
+		}
+
+		valueSerialized, err := serializeValue(entry.Value)
+		if err != nil {
+			tx.Rollback()
+			return 0, errors.WithMessage(err, "key "+entry.Key)
+		}
+
+		if entry.CreatedAt.IsZero() {
+			entry.CreatedAt = time.Now()
+		}
+		if entry.UpdatedAt.IsZero() {
+			entry.UpdatedAt = entry.CreatedAt
+		}
+
+		valueNum := entry.ValueNum
+		if valueNum == 0 {
+			valueNum = templates.ToFloat64(entry.Value)
+		}
+
+		m := &models.TemplatesUserDatabase{
+			GuildID:   guildID,
+			UserID:    entry.UserID,
+			CreatedAt: entry.CreatedAt,
+			UpdatedAt: entry.UpdatedAt,
+
+			Key:       limitString(entry.Key, 256),
+			ValueRaw:  valueSerialized,
+			ValueNum:  valueNum,
+			ValueJSON: jsonValue(entry.Value),
+		}
+
+		if entry.ExpiresAt != nil {
+			m.ExpiresAt = null.TimeFrom(*entry.ExpiresAt)
+		}
+
+		err = m.Upsert(ctx, tx, true, []string{"guild_id", "user_id", "key"}, boil.Whitelist("value_raw", "value_num", "value_json", "updated_at", "expires_at"), boil.Infer())
+		if err != nil {
+			tx.Rollback()
+			return 0, errors.WithStackIf(err)
+		}
+
+		imported++
+	}
+
+	count, err := models.TemplatesUserDatabases(qm.Where("guild_id = ? AND (expires_at > now() OR expires_at IS NULL)", guildID)).Count(ctx, tx)
+	if err != nil {
+		tx.Rollback()
+		return 0, errors.WithStackIf(err)
+	}
+
+	if count > limit {
+		tx.Rollback()
+		return 0, ErrDBImportAboveLimit
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, errors.WithStackIf(err)
+	}
+
+	cachedDBLimits.Delete(guildID)
+	return imported, nil
+}
@@ -0,0 +1,282 @@
+package customcommands
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/mrbentarikau/pagst/common/templates"
+	"github.com/mrbentarikau/pagst/customcommands/models"
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+const dbQueryMaxLimit = 100
+
+// DBQuery is the parsed form of the conditions passed to dbQuery
+type DBQuery struct {
+	UserID  null.Int64
+	Pattern null.String
+	Prefix  null.String
+
+	ValueMin null.Float64
+	ValueMax null.Float64
+
+	// ValueMatch is matched against the stored sdicts, nested keys are separated with dots: "stats.level"
+	ValueMatch map[string]interface{}
+
+	CreatedAfter  null.Time
+	CreatedBefore null.Time
+	UpdatedAfter  null.Time
+	UpdatedBefore null.Time
+
+	OrderBy string
+	Reverse bool
+	Limit   int
+	Skip    int
+}
+
+var dbQueryOrderColumns = map[string]string{
+	"id":      "id",
+	"key":     "key",
+	"value":   "value_num",
+	"created": "created_at",
+	"updated": "updated_at",
+}
+
+// dbQueryFromArg parses the sdict of conditions passed to dbQuery
+func dbQueryFromArg(arg interface{}) (*DBQuery, error) {
+	conditions, err := templates.StringKeyDictionary(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &DBQuery{
+		OrderBy: "id",
+		Limit:   dbQueryMaxLimit,
+	}
+
+	for key, val := range conditions {
+		switch key {
+		case "userID":
+			switch val.(type) {
+			case int, int64:
+				q.UserID = null.Int64From(templates.ToInt64(val))
+			default:
+				return nil, errors.New("Invalid userID datatype in query. Must be a number")
+			}
+		case "pattern":
+			q.Pattern = null.StringFrom(limitString(templates.ToString(val), 256))
+		case "prefix":
+			q.Prefix = null.StringFrom(limitString(templates.ToString(val), 256))
+		case "valueMin":
+			q.ValueMin = null.Float64From(templates.ToFloat64(val))
+		case "valueMax":
+			q.ValueMax = null.Float64From(templates.ToFloat64(val))
+		case "valueMatch":
+			match, err := templates.StringKeyDictionary(val)
+			if err != nil {
+				return nil, errors.WithMessage(err, "valueMatch")
+			}
+			q.ValueMatch = match
+		case "createdAfter", "createdBefore", "updatedAfter", "updatedBefore":
+			t, err := dbQueryTime(val)
+			if err != nil {
+				return nil, errors.WithMessage(err, key)
+			}
+
+			switch key {
+			case "createdAfter":
+				q.CreatedAfter = null.TimeFrom(t)
+			case "createdBefore":
+				q.CreatedBefore = null.TimeFrom(t)
+			case "updatedAfter":
+				q.UpdatedAfter = null.TimeFrom(t)
+			case "updatedBefore":
+				q.UpdatedBefore = null.TimeFrom(t)
+			}
+		case "orderBy":
+			str := templates.ToString(val)
+			if _, ok := dbQueryOrderColumns[str]; !ok {
+				return nil, errors.New("Invalid orderBy in query, must be one of id, key, value, created or updated")
+			}
+			q.OrderBy = str
+		case "reverse":
+			revFlag, ok := val.(bool)
+			if !ok {
+				return nil, errors.New("Invalid reverse flag datatype in query. Must be a boolean value.")
+			}
+			q.Reverse = revFlag
+		case "limit":
+			q.Limit = int(templates.ToInt64(val))
+			if q.Limit > dbQueryMaxLimit || q.Limit < 1 {
+				q.Limit = dbQueryMaxLimit
+			}
+		case "skip":
+			q.Skip = int(templates.ToInt64(val))
+			if q.Skip < 0 {
+				q.Skip = 0
+			}
+		default:
+			return nil, errors.New("Invalid Key: " + key + " passed to query constructor")
+		}
+	}
+
+	return q, nil
+}
+
+// dbQueryTime accepts either a time or a duration, which is treated as that long ago
+func dbQueryTime(val interface{}) (time.Time, error) {
+	if t, ok := val.(time.Time); ok {
+		return t, nil
+	}
+
+	d := templates.ToDuration(val)
+	if d <= 0 {
+		return time.Time{}, errors.New("must be a time or a duration")
+	}
+
+	return time.Now().Add(-d), nil
+}
+
+// valueMatchJSON turns the dotted paths into a nested json object, so it can be matched with the @> operator
+// which is covered by the GIN index on value_json
+func valueMatchJSON(match map[string]interface{}) ([]byte, error) {
+	root := make(map[string]interface{})
+	for path, v := range match {
+		parts := strings.Split(path, ".")
+		current := root
+		for i, part := range parts {
+			if i == len(parts)-1 {
+				if _, exists := current[part]; exists {
+					return nil, errors.New("valueMatch: conflicting paths for " + path)
+				}
+
+				current[part] = v
+				break
+			}
+
+			next, ok := current[part].(map[string]interface{})
+			if !ok {
+				if _, exists := current[part]; exists {
+					return nil, errors.New("valueMatch: conflicting paths for " + path)
+				}
+
+				next = make(map[string]interface{})
+				current[part] = next
+			}
+			current = next
+		}
+	}
+
+	return json.Marshal(root)
+}
+
+// QueryMods returns the query mods for the query in the provided guild, expired entries are excluded
+func (q *DBQuery) QueryMods(guildID int64) ([]qm.QueryMod, error) {
+	qms := []qm.QueryMod{qm.Where("guild_id = ? AND (expires_at IS NULL OR expires_at > now())", guildID)}
+
+	if q.UserID.Valid {
+		qms = append(qms, qm.Where("user_id = ?", q.UserID.Int64))
+	}
+	if q.Pattern.Valid {
+		qms = append(qms, qm.Where("key LIKE ?", q.Pattern.String))
+	}
+	if q.Prefix.Valid {
+		qms = append(qms, qm.Where("key LIKE ?", escapeLike(q.Prefix.String)+"%"))
+	}
+	if q.ValueMin.Valid {
+		qms = append(qms, qm.Where("value_num >= ?", q.ValueMin.Float64))
+	}
+	if q.ValueMax.Valid {
+		qms = append(qms, qm.Where("value_num <= ?", q.ValueMax.Float64))
+	}
+	if len(q.ValueMatch) > 0 {
+		b, err := valueMatchJSON(q.ValueMatch)
+		if err != nil {
+			return nil, err
+		}
+		qms = append(qms, qm.Where("value_json @> ?::jsonb", string(b)))
+	}
+	if q.CreatedAfter.Valid {
+		qms = append(qms, qm.Where("created_at > ?", q.CreatedAfter.Time))
+	}
+	if q.CreatedBefore.Valid {
+		qms = append(qms, qm.Where("created_at < ?", q.CreatedBefore.Time))
+	}
+	if q.UpdatedAfter.Valid {
+		qms = append(qms, qm.Where("updated_at > ?", q.UpdatedAfter.Time))
+	}
+	if q.UpdatedBefore.Valid {
+		qms = append(qms, qm.Where("updated_at < ?", q.UpdatedBefore.Time))
+	}
+
+	order := " ASC"
+	if q.Reverse {
+		order = " DESC"
+	}
+
+	column := dbQueryOrderColumns[q.OrderBy]
+	orderBy := column + order
+	if column != "id" {
+		orderBy += ", id" + order
+	}
+
+	qms = append(qms, qm.OrderBy(orderBy), qm.Limit(q.Limit), qm.Offset(q.Skip))
+	return qms, nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// jsonValue returns the json representation of values that can be matched by dbQuery, only sdicts are stored
+func jsonValue(v interface{}) null.JSON {
+	var m map[string]interface{}
+	switch t := v.(type) {
+	case templates.SDict:
+		m = t
+	case map[string]interface{}:
+		m = t
+	default:
+		return null.JSON{}
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return null.JSON{}
+	}
+
+	return null.JSONFrom(b)
+}
+
+func tmplDBQuery(ctx *templates.Context) interface{} {
+	return func(conditions interface{}) (interface{}, error) {
+		if ctx.IncreaseCheckCallCounterPremium("db_interactions", 10, 50) {
+			return "", templates.ErrTooManyCalls
+		}
+
+		if ctx.IncreaseCheckCallCounterPremium("db_multiple", 2, 10) {
+			return "", templates.ErrTooManyCalls
+		}
+
+		q, err := dbQueryFromArg(conditions)
+		if err != nil {
+			return "", err
+		}
+
+		qms, err := q.QueryMods(ctx.GS.ID)
+		if err != nil {
+			return "", err
+		}
+
+		results, err := models.TemplatesUserDatabases(qms...).AllG(context.Background())
+		if err != nil {
+			return nil, err
+		}
+
+		return tmplResultSetToLightDBEntries(ctx, ctx.GS, results), nil
+	}
+}
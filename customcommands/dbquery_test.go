@@ -0,0 +1,112 @@
+package customcommands
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrbentarikau/pagst/common/templates"
+)
+
+func TestDBQueryFromArg(t *testing.T) {
+	q, err := dbQueryFromArg(templates.SDict{
+		"userID":       int64(5),
+		"prefix":       "xp_",
+		"valueMin":     10,
+		"orderBy":      "value",
+		"reverse":      true,
+		"limit":        500,
+		"createdAfter": time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if q.UserID.Int64 != 5 || q.Prefix.String != "xp_" || q.ValueMin.Float64 != 10 || q.ValueMax.Valid {
+		t.Errorf("unexpected conditions: %#v", q)
+	}
+
+	if q.Limit != dbQueryMaxLimit {
+		t.Errorf("expected limit to be capped at %d, got %d", dbQueryMaxLimit, q.Limit)
+	}
+
+	if since := time.Since(q.CreatedAfter.Time); since < time.Hour || since > time.Hour+time.Minute {
+		t.Errorf("expected createdAfter about an hour ago, got %s", since)
+	}
+
+	invalid := []templates.SDict{
+		{"userID": "abc"},
+		{"orderBy": "value_raw"},
+		{"reverse": "yes"},
+		{"createdBefore": "soon"},
+		{"unknown": 1},
+	}
+
+	for _, v := range invalid {
+		if _, err := dbQueryFromArg(v); err == nil {
+			t.Errorf("expected error for %v", v)
+		}
+	}
+}
+
+func TestValueMatchJSON(t *testing.T) {
+	b, err := valueMatchJSON(map[string]interface{}{"stats.level": 5, "stats.class": "mage", "name": "bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"name":"bob","stats":{"class":"mage","level":5}}`
+	if string(b) != expected {
+		t.Errorf("expected %s, got %s", expected, string(b))
+	}
+
+	if _, err = valueMatchJSON(map[string]interface{}{"a": 1, "a.b": 2}); err == nil {
+		t.Error("expected error for conflicting paths")
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	if got := escapeLike(`50%_off\`); got != `50\%\_off\\` {
+		t.Errorf("unexpected escaped string: %s", got)
+	}
+}
+
+func TestParseDBImportCSV(t *testing.T) {
+	entry := &DBExportEntry{
+		UserID:    1,
+		Key:       "inventory",
+		Value:     map[string]interface{}{"apples": 3.0},
+		CreatedAt: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+
+	input := strings.Join(dbExportCSVHeader, ",") + "\n" + strings.Join(quoteCSV(entry.csvRecord()), ",") + "\n"
+	entries, err := parseDBImport(strings.NewReader(input), "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+
+	got := entries[0]
+	if got.UserID != 1 || got.Key != "inventory" || !got.UpdatedAt.Equal(entry.UpdatedAt) || got.ExpiresAt != nil {
+		t.Errorf("unexpected entry: %#v", got)
+	}
+
+	if m, ok := got.Value.(map[string]interface{}); !ok || m["apples"] != 3.0 {
+		t.Errorf("unexpected value: %#v", got.Value)
+	}
+
+	if _, err = parseDBImport(strings.NewReader("1,key\n"), "csv"); err == nil {
+		t.Error("expected error for missing columns")
+	}
+}
+
+func quoteCSV(record []string) []string {
+	for i, v := range record {
+		record[i] = `"` + strings.ReplaceAll(v, `"`, `""`) + `"`
+	}
+	return record
+}
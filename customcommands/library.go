@@ -0,0 +1,233 @@
+package customcommands
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/common/templates"
+	"github.com/mrbentarikau/pagst/customcommands/models"
+	"github.com/mrbentarikau/pagst/lib/template/parse"
+	"github.com/mrbentarikau/pagst/premium"
+	"github.com/sirupsen/logrus"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+// Library custom commands never trigger, instead the define blocks in them can be used by every custom command
+// in the guild with {{template "lib/name" .}} or {{execTemplate "lib/name" .}}
+const (
+	LibraryTemplatePrefix = "lib/"
+
+	MaxLibraries        = 5
+	MaxLibrariesPremium = 20
+)
+
+func MaxLibrariesForContext(ctx context.Context) int {
+	if premium.ContextPremium(ctx) {
+		return MaxLibrariesPremium
+	}
+
+	return MaxLibraries
+}
+
+// ParseLibrary returns the define blocks of a library, keyed by the name they're invoked with
+func ParseLibrary(source string) (map[string]*parse.Tree, error) {
+	parsed, err := templates.NewContext(nil, nil, nil).Parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*parse.Tree)
+	for _, t := range parsed.Templates() {
+		if t.Name() == parsed.Name() || t.Tree == nil {
+			continue
+		}
+
+		result[LibraryTemplatePrefix+t.Name()] = t.Tree
+	}
+
+	// the defines call each other by their own names, which no longer exist once they're prefixed
+	for _, tree := range result {
+		renameLibraryRefs(tree.Root, result)
+	}
+
+	return result, nil
+}
+
+// renameLibraryRefs points the template invocations in the node that refer to a define of the same library to its prefixed name,
+// both {{template "name"}} and {{execTemplate "name"}} with a constant name
+func renameLibraryRefs(node parse.Node, library map[string]*parse.Tree) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, v := range n.Nodes {
+			renameLibraryRefs(v, library)
+		}
+	case *parse.ActionNode:
+		renameLibraryRefs(n.Pipe, library)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, v := range n.Cmds {
+			renameLibraryRefs(v, library)
+		}
+	case *parse.CommandNode:
+		if len(n.Args) > 1 {
+			if ident, ok := n.Args[0].(*parse.IdentifierNode); ok && ident.Ident == "execTemplate" {
+				if str, ok := n.Args[1].(*parse.StringNode); ok {
+					if _, ok := library[LibraryTemplatePrefix+str.Text]; ok {
+						str.Text = LibraryTemplatePrefix + str.Text
+						str.Quoted = strconv.Quote(str.Text)
+					}
+				}
+			}
+		}
+		for _, v := range n.Args {
+			renameLibraryRefs(v, library)
+		}
+	case *parse.ChainNode:
+		renameLibraryRefs(n.Node, library)
+	case *parse.TemplateNode:
+		if _, ok := library[LibraryTemplatePrefix+n.Name]; ok {
+			n.Name = LibraryTemplatePrefix + n.Name
+		}
+		renameLibraryRefs(n.Pipe, library)
+	case *parse.IfNode:
+		renameLibraryBranchRefs(&n.BranchNode, library)
+	case *parse.RangeNode:
+		renameLibraryBranchRefs(&n.BranchNode, library)
+	case *parse.WithNode:
+		renameLibraryBranchRefs(&n.BranchNode, library)
+	case *parse.WhileNode:
+		renameLibraryBranchRefs(&n.BranchNode, library)
+	case *parse.TryNode:
+		renameLibraryRefs(n.List, library)
+		renameLibraryRefs(n.CatchList, library)
+	case *parse.ReturnNode:
+		renameLibraryRefs(n.Pipe, library)
+	}
+}
+
+func renameLibraryBranchRefs(n *parse.BranchNode, library map[string]*parse.Tree) {
+	renameLibraryRefs(n.Pipe, library)
+	renameLibraryRefs(n.List, library)
+	renameLibraryRefs(n.ElseList, library)
+}
+
+// LibraryTemplateNames returns the sorted names the define blocks of a library are invoked with
+func LibraryTemplateNames(source string) []string {
+	trees, err := ParseLibrary(source)
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(trees))
+	for k := range trees {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+var libraryRefRe = regexp.MustCompile("[\"`](" + regexp.QuoteMeta(LibraryTemplatePrefix) + "[^\"`]+)[\"`]")
+
+// libraryReferences returns the library templates referenced in the source
+func libraryReferences(source string) []string {
+	var refs []string
+	for _, m := range libraryRefRe.FindAllStringSubmatch(source, -1) {
+		if !common.ContainsStringSlice(refs, m[1]) {
+			refs = append(refs, m[1])
+		}
+	}
+
+	return refs
+}
+
+// LibraryDependents returns the custom commands that use any of the provided library templates, keyed by template name
+func LibraryDependents(ccs []*models.CustomCommand, names []string) map[string][]int64 {
+	result := make(map[string][]int64)
+	for _, cc := range ccs {
+		if cc.TriggerType == int(CommandTriggerLibrary) || len(cc.Responses) < 1 {
+			continue
+		}
+
+		for _, ref := range libraryReferences(cc.Responses[0]) {
+			if common.ContainsStringSlice(names, ref) {
+				result[ref] = append(result[ref], cc.LocalID)
+			}
+		}
+	}
+
+	return result
+}
+
+// libraryConflicts returns the template names provided by the library that are also provided by another library of the guild
+func libraryConflicts(ccs []*models.CustomCommand, library *models.CustomCommand) []string {
+	names := LibraryTemplateNames(library.Responses[0])
+
+	var conflicts []string
+	for _, cc := range ccs {
+		if cc.LocalID == library.LocalID || cc.TriggerType != int(CommandTriggerLibrary) || len(cc.Responses) < 1 {
+			continue
+		}
+
+		for _, name := range LibraryTemplateNames(cc.Responses[0]) {
+			if common.ContainsStringSlice(names, name) && !common.ContainsStringSlice(conflicts, name) {
+				conflicts = append(conflicts, name)
+			}
+		}
+	}
+
+	return conflicts
+}
+
+var cachedLibraries = common.CacheSet.RegisterSlot("custom_commands_libraries", nil, int64(0))
+
+// BotCachedGetLibraries returns the parsed define blocks of all the enabled libraries in the guild,
+// if several libraries provide the same template the one with the lowest id wins
+func BotCachedGetLibraries(guildID int64, ctx context.Context) (map[string]*parse.Tree, error) {
+	v, err := cachedLibraries.GetCustomFetch(guildID, func(key interface{}) (interface{}, error) {
+		var libraries []*models.CustomCommand
+		var err error
+
+		common.LogLongCallTime(time.Second, true, "Took longer than a second to fetch custom command libraries from db", logrus.Fields{"guild": guildID}, func() {
+			libraries, err = models.CustomCommands(qm.Where("guild_id = ? AND trigger_type = ? AND disabled = false", guildID, int(CommandTriggerLibrary)), qm.OrderBy("local_id desc")).AllG(ctx)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		result := make(map[string]*parse.Tree)
+		for _, lib := range libraries {
+			if len(lib.Responses) < 1 {
+				continue
+			}
+
+			trees, err := ParseLibrary(lib.Responses[0])
+			if err != nil {
+				logger.WithError(err).WithField("guild", guildID).WithField("cc_id", lib.LocalID).Warn("failed parsing custom command library")
+				continue
+			}
+
+			for k, v := range trees {
+				result[k] = v
+			}
+		}
+
+		return result, nil
+	})
+
+	if err != nil {
+		return nil, errors.WithStackIf(err)
+	}
+
+	return v.(map[string]*parse.Tree), nil
+}
@@ -0,0 +1,39 @@
+package customcommands
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseLibrary(t *testing.T) {
+	const source = `{{define "inner"}}inner {{.}}{{end}}` +
+		`{{define "outer"}}{{if .}}{{template "inner" .}}{{end}} {{execTemplate "inner" 1}} {{template "lib/other" .}}{{end}}`
+
+	trees, err := ParseLibrary(source)
+	if err != nil {
+		t.Fatalf("failed parsing library: %v", err)
+	}
+
+	if len(trees) != 2 || trees["lib/inner"] == nil || trees["lib/outer"] == nil {
+		t.Fatalf("unexpected templates: %v", trees)
+	}
+
+	outer := trees["lib/outer"].Root.String()
+	if !strings.Contains(outer, `{{template "lib/inner" .}}`) {
+		t.Errorf("template call to a define of the library was not renamed: %s", outer)
+	}
+
+	if !strings.Contains(outer, `{{execTemplate "lib/inner" 1}}`) {
+		t.Errorf("execTemplate call to a define of the library was not renamed: %s", outer)
+	}
+
+	if !strings.Contains(outer, `{{template "lib/other" .}}`) {
+		t.Errorf("template call to another library was changed: %s", outer)
+	}
+}
+
+func TestParseLibraryInvalid(t *testing.T) {
+	if _, err := ParseLibrary(`{{define "x"}}{{if}}{{end}}`); err == nil {
+		t.Errorf("expected an error for an invalid library")
+	}
+}
@@ -0,0 +1,237 @@
+package customcommands
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sort"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/mediocregopher/radix/v3"
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/common/templates"
+	"github.com/mrbentarikau/pagst/lib/discordgo"
+	"github.com/mrbentarikau/pagst/premium"
+)
+
+const (
+	// metricsMaxEntries is how many of the latest executions are kept per guild
+	metricsMaxEntries = 2000
+	metricsExpiry     = 7 * 24 * time.Hour
+
+	// how many commands are shown in the top consumers list
+	metricsTopConsumers = 10
+)
+
+func keyCCMetrics(guildID int64) string {
+	return "custom_command_metrics:" + discordgo.StrID(guildID)
+}
+
+// ExecMetric is a single custom command execution
+type ExecMetric struct {
+	CCID     int64         `json:"cc"`
+	Trigger  string        `json:"t"`
+	Duration time.Duration `json:"d"`
+	Ops      int           `json:"o"`
+	DBCalls  int           `json:"db"`
+	Messages int           `json:"m"`
+	Error    bool          `json:"e,omitempty"`
+	Time     int64         `json:"ts"`
+}
+
+func newExecMetric(ccID int64, trigger string, duration time.Duration, tmplCtx *templates.Context, err error) *ExecMetric {
+	return &ExecMetric{
+		CCID:     ccID,
+		Trigger:  trigger,
+		Duration: duration,
+		Ops:      tmplCtx.Operations,
+		DBCalls:  tmplCtx.Counters["db_interactions"],
+		Messages: tmplCtx.MessagesSent,
+		Error:    err != nil,
+		Time:     time.Now().Unix(),
+	}
+}
+
+// recordExecMetric adds the execution to the rolling store of the guild, dropping the oldest ones
+func recordExecMetric(guildID int64, m *ExecMetric) {
+	serialized, err := json.Marshal(m)
+	if err != nil {
+		logger.WithError(err).Error("failed serializing cc exec metric")
+		return
+	}
+
+	key := keyCCMetrics(guildID)
+	err = common.RedisPool.Do(radix.Pipeline(
+		radix.Cmd(nil, "LPUSH", key, string(serialized)),
+		radix.FlatCmd(nil, "LTRIM", key, 0, metricsMaxEntries-1),
+		radix.FlatCmd(nil, "EXPIRE", key, int(metricsExpiry.Seconds())),
+	))
+	if err != nil {
+		logger.WithError(err).WithField("guild", guildID).Error("failed recording cc exec metric")
+	}
+}
+
+// GetExecMetrics returns the latest executions of custom commands in the guild, newest first
+func GetExecMetrics(guildID int64) ([]*ExecMetric, error) {
+	var raw []string
+	err := common.RedisPool.Do(radix.FlatCmd(&raw, "LRANGE", keyCCMetrics(guildID), 0, -1))
+	if err != nil {
+		return nil, errors.WithStackIf(err)
+	}
+
+	result := make([]*ExecMetric, 0, len(raw))
+	for _, v := range raw {
+		var m *ExecMetric
+		if err := json.Unmarshal([]byte(v), &m); err != nil {
+			continue
+		}
+
+		result = append(result, m)
+	}
+
+	return result, nil
+}
+
+// MetricsSummary is the aggregate of a set of executions
+type MetricsSummary struct {
+	CCID int64
+
+	Runs      int
+	Errors    int
+	ErrorRate float64
+
+	P50   time.Duration
+	P95   time.Duration
+	Total time.Duration
+
+	AvgOps int
+	MaxOps int
+	// MaxOpsPercent is how close the heaviest execution got to the operations limit
+	MaxOpsPercent float64
+
+	AvgDBCalls  float64
+	AvgMessages float64
+
+	Triggers map[string]int
+}
+
+// GuildMetrics is what's shown on the custom commands page
+type GuildMetrics struct {
+	Since   time.Time
+	Overall *MetricsSummary
+
+	// Commands is sorted by total runtime, so the top consumers come first
+	Commands []*MetricsSummary
+}
+
+// SummarizeExecMetrics aggregates the executions, overall and per custom command.
+// opsLimit is the max template operations of the guild.
+func SummarizeExecMetrics(metrics []*ExecMetric, opsLimit int) *GuildMetrics {
+	result := &GuildMetrics{
+		Overall: summarize(0, metrics, opsLimit),
+	}
+
+	byCC := make(map[int64][]*ExecMetric)
+	for _, m := range metrics {
+		byCC[m.CCID] = append(byCC[m.CCID], m)
+
+		t := time.Unix(m.Time, 0)
+		if result.Since.IsZero() || t.Before(result.Since) {
+			result.Since = t
+		}
+	}
+
+	for ccID, ccMetrics := range byCC {
+		result.Commands = append(result.Commands, summarize(ccID, ccMetrics, opsLimit))
+	}
+
+	sort.Slice(result.Commands, func(i, j int) bool {
+		if result.Commands[i].Total == result.Commands[j].Total {
+			return result.Commands[i].CCID < result.Commands[j].CCID
+		}
+		return result.Commands[i].Total > result.Commands[j].Total
+	})
+
+	return result
+}
+
+// TopConsumers returns the commands with the most total runtime
+func (g *GuildMetrics) TopConsumers() []*MetricsSummary {
+	if len(g.Commands) > metricsTopConsumers {
+		return g.Commands[:metricsTopConsumers]
+	}
+
+	return g.Commands
+}
+
+func summarize(ccID int64, metrics []*ExecMetric, opsLimit int) *MetricsSummary {
+	s := &MetricsSummary{
+		CCID:     ccID,
+		Runs:     len(metrics),
+		Triggers: make(map[string]int),
+	}
+
+	if len(metrics) == 0 {
+		return s
+	}
+
+	durations := make([]time.Duration, 0, len(metrics))
+	totalOps, totalDB, totalMessages := 0, 0, 0
+	for _, m := range metrics {
+		durations = append(durations, m.Duration)
+		s.Total += m.Duration
+
+		if m.Error {
+			s.Errors++
+		}
+
+		totalOps += m.Ops
+		if m.Ops > s.MaxOps {
+			s.MaxOps = m.Ops
+		}
+
+		totalDB += m.DBCalls
+		totalMessages += m.Messages
+		s.Triggers[m.Trigger]++
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	s.P50 = percentile(durations, 50).Round(time.Microsecond)
+	s.P95 = percentile(durations, 95).Round(time.Microsecond)
+	s.Total = s.Total.Round(time.Microsecond)
+
+	n := float64(len(metrics))
+	s.ErrorRate = float64(s.Errors) / n * 100
+	s.AvgOps = int(math.Round(float64(totalOps) / n))
+	s.AvgDBCalls = float64(totalDB) / n
+	s.AvgMessages = float64(totalMessages) / n
+	if opsLimit > 0 {
+		s.MaxOpsPercent = float64(s.MaxOps) / float64(opsLimit) * 100
+	}
+
+	return s
+}
+
+// percentile uses the nearest-rank method, sorted has to be sorted ascending
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(float64(p) / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
+
+// maxOpsForContext returns the template operations limit that applies to custom commands in the guild
+func maxOpsForContext(ctx context.Context) int {
+	if premium.ContextPremium(ctx) {
+		return templates.MaxOpsPremium
+	}
+
+	return templates.MaxOpsNormal
+}
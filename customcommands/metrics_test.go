@@ -0,0 +1,59 @@
+package customcommands
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	durations := make([]time.Duration, 0, 20)
+	for i := 1; i <= 20; i++ {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+
+	if got := percentile(durations, 50); got != 10*time.Millisecond {
+		t.Errorf("p50: expected 10ms, got %s", got)
+	}
+
+	if got := percentile(durations, 95); got != 19*time.Millisecond {
+		t.Errorf("p95: expected 19ms, got %s", got)
+	}
+
+	if got := percentile(durations[:1], 95); got != time.Millisecond {
+		t.Errorf("single value: expected 1ms, got %s", got)
+	}
+
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("empty: expected 0, got %s", got)
+	}
+}
+
+func TestSummarizeExecMetrics(t *testing.T) {
+	metrics := []*ExecMetric{
+		{CCID: 1, Trigger: "Command", Duration: time.Millisecond, Ops: 100, DBCalls: 2, Messages: 1, Time: 200},
+		{CCID: 1, Trigger: "Exec", Duration: 3 * time.Millisecond, Ops: 300, Error: true, Time: 150},
+		{CCID: 2, Trigger: "Interval", Duration: 10 * time.Millisecond, Ops: 500, Messages: 1, Time: 100},
+	}
+
+	result := SummarizeExecMetrics(metrics, 1000)
+	if result.Overall.Runs != 3 || result.Overall.Errors != 1 {
+		t.Errorf("unexpected overall summary: %#v", result.Overall)
+	}
+
+	if !result.Since.Equal(time.Unix(100, 0)) {
+		t.Errorf("expected since to be the oldest execution, got %s", result.Since)
+	}
+
+	if len(result.Commands) != 2 || result.Commands[0].CCID != 2 {
+		t.Fatalf("expected cc 2 to be the top consumer: %#v", result.Commands)
+	}
+
+	cc1 := result.Commands[1]
+	if cc1.ErrorRate != 50 || cc1.AvgOps != 200 || cc1.MaxOps != 300 || cc1.MaxOpsPercent != 30 || cc1.AvgDBCalls != 1 {
+		t.Errorf("unexpected summary for cc 1: %#v", cc1)
+	}
+
+	if cc1.Triggers["Command"] != 1 || cc1.Triggers["Exec"] != 1 {
+		t.Errorf("unexpected triggers: %v", cc1.Triggers)
+	}
+}
@@ -60,6 +60,13 @@ type CustomCommand struct {
 	PublicID                  string            `boil:"public_id" json:"public_id" toml:"public_id" yaml:"public_id"`
 	ImportCount               int               `boil:"import_count" json:"import_count" toml:"import_count" yaml:"import_count"`
 	Public                    bool              `boil:"public" json:"public" toml:"public" yaml:"public"`
+	CooldownUser              int               `boil:"cooldown_user" json:"cooldown_user" toml:"cooldown_user" yaml:"cooldown_user"`
+	CooldownChannel           int               `boil:"cooldown_channel" json:"cooldown_channel" toml:"cooldown_channel" yaml:"cooldown_channel"`
+	CooldownGuild             int               `boil:"cooldown_guild" json:"cooldown_guild" toml:"cooldown_guild" yaml:"cooldown_guild"`
+	CooldownBypassRoles       types.Int64Array  `boil:"cooldown_bypass_roles" json:"cooldown_bypass_roles,omitempty" toml:"cooldown_bypass_roles" yaml:"cooldown_bypass_roles,omitempty"`
+	DailyLimitUser            int               `boil:"daily_limit_user" json:"daily_limit_user" toml:"daily_limit_user" yaml:"daily_limit_user"`
+	DailyLimitGuild           int               `boil:"daily_limit_guild" json:"daily_limit_guild" toml:"daily_limit_guild" yaml:"daily_limit_guild"`
+	CooldownResponse          string            `boil:"cooldown_response" json:"cooldown_response" toml:"cooldown_response" yaml:"cooldown_response"`
 
 	R *customCommandR `boil:"-" json:"-" toml:"-" yaml:"-"`
 	L customCommandL  `boil:"-" json:"-" toml:"-" yaml:"-"`
@@ -101,6 +108,13 @@ var CustomCommandColumns = struct {
 	PublicID                  string
 	ImportCount               string
 	Public                    string
+	CooldownUser              string
+	CooldownChannel           string
+	CooldownGuild             string
+	CooldownBypassRoles       string
+	DailyLimitUser            string
+	DailyLimitGuild           string
+	CooldownResponse          string
 }{
 	LocalID:                   "local_id",
 	GuildID:                   "guild_id",
@@ -137,6 +151,13 @@ var CustomCommandColumns = struct {
 	PublicID:                  "public_id",
 	ImportCount:               "import_count",
 	Public:                    "public",
+	CooldownUser:              "cooldown_user",
+	CooldownChannel:           "cooldown_channel",
+	CooldownGuild:             "cooldown_guild",
+	CooldownBypassRoles:       "cooldown_bypass_roles",
+	DailyLimitUser:            "daily_limit_user",
+	DailyLimitGuild:           "daily_limit_guild",
+	CooldownResponse:          "cooldown_response",
 }
 
 var CustomCommandTableColumns = struct {
@@ -175,6 +196,13 @@ var CustomCommandTableColumns = struct {
 	PublicID                  string
 	ImportCount               string
 	Public                    string
+	CooldownUser              string
+	CooldownChannel           string
+	CooldownGuild             string
+	CooldownBypassRoles       string
+	DailyLimitUser            string
+	DailyLimitGuild           string
+	CooldownResponse          string
 }{
 	LocalID:                   "custom_commands.local_id",
 	GuildID:                   "custom_commands.guild_id",
@@ -211,6 +239,13 @@ var CustomCommandTableColumns = struct {
 	PublicID:                  "custom_commands.public_id",
 	ImportCount:               "custom_commands.import_count",
 	Public:                    "custom_commands.public",
+	CooldownUser:              "custom_commands.cooldown_user",
+	CooldownChannel:           "custom_commands.cooldown_channel",
+	CooldownGuild:             "custom_commands.cooldown_guild",
+	CooldownBypassRoles:       "custom_commands.cooldown_bypass_roles",
+	DailyLimitUser:            "custom_commands.daily_limit_user",
+	DailyLimitGuild:           "custom_commands.daily_limit_guild",
+	CooldownResponse:          "custom_commands.cooldown_response",
 }
 
 // Generated where
@@ -390,6 +425,13 @@ var CustomCommandWhere = struct {
 	PublicID                  whereHelperstring
 	ImportCount               whereHelperint
 	Public                    whereHelperbool
+	CooldownUser              whereHelperint
+	CooldownChannel           whereHelperint
+	CooldownGuild             whereHelperint
+	CooldownBypassRoles       whereHelpertypes_Int64Array
+	DailyLimitUser            whereHelperint
+	DailyLimitGuild           whereHelperint
+	CooldownResponse          whereHelperstring
 }{
 	LocalID:                   whereHelperint64{field: "\"custom_commands\".\"local_id\""},
 	GuildID:                   whereHelperint64{field: "\"custom_commands\".\"guild_id\""},
@@ -426,6 +468,13 @@ var CustomCommandWhere = struct {
 	PublicID:                  whereHelperstring{field: "\"custom_commands\".\"public_id\""},
 	ImportCount:               whereHelperint{field: "\"custom_commands\".\"import_count\""},
 	Public:                    whereHelperbool{field: "\"custom_commands\".\"public\""},
+	CooldownUser:              whereHelperint{field: "\"custom_commands\".\"cooldown_user\""},
+	CooldownChannel:           whereHelperint{field: "\"custom_commands\".\"cooldown_channel\""},
+	CooldownGuild:             whereHelperint{field: "\"custom_commands\".\"cooldown_guild\""},
+	CooldownBypassRoles:       whereHelpertypes_Int64Array{field: "\"custom_commands\".\"cooldown_bypass_roles\""},
+	DailyLimitUser:            whereHelperint{field: "\"custom_commands\".\"daily_limit_user\""},
+	DailyLimitGuild:           whereHelperint{field: "\"custom_commands\".\"daily_limit_guild\""},
+	CooldownResponse:          whereHelperstring{field: "\"custom_commands\".\"cooldown_response\""},
 }
 
 // CustomCommandRels is where relationship names are stored.
@@ -456,9 +505,9 @@ func (r *customCommandR) GetGroup() *CustomCommandGroup {
 type customCommandL struct{}
 
 var (
-	customCommandAllColumns            = []string{"local_id", "guild_id", "group_id", "trigger_type", "text_trigger", "text_trigger_case_sensitive", "time_trigger_interval", "time_trigger_excluding_days", "time_trigger_excluding_hours", "last_run", "next_run", "responses", "channels", "channels_whitelist_mode", "roles", "roles_whitelist_mode", "context_channel", "reaction_trigger_mode", "last_error", "last_error_time", "run_count", "show_errors", "disabled", "date_updated", "categories", "categories_whitelist_mode", "regex_trigger", "regex_trigger_case_sensitive", "note", "threads_enabled", "normalize_unicode", "trigger_on_edit", "public_id", "import_count", "public"}
+	customCommandAllColumns            = []string{"local_id", "guild_id", "group_id", "trigger_type", "text_trigger", "text_trigger_case_sensitive", "time_trigger_interval", "time_trigger_excluding_days", "time_trigger_excluding_hours", "last_run", "next_run", "responses", "channels", "channels_whitelist_mode", "roles", "roles_whitelist_mode", "context_channel", "reaction_trigger_mode", "last_error", "last_error_time", "run_count", "show_errors", "disabled", "date_updated", "categories", "categories_whitelist_mode", "regex_trigger", "regex_trigger_case_sensitive", "note", "threads_enabled", "normalize_unicode", "trigger_on_edit", "public_id", "import_count", "public", "cooldown_user", "cooldown_channel", "cooldown_guild", "cooldown_bypass_roles", "daily_limit_user", "daily_limit_guild", "cooldown_response"}
 	customCommandColumnsWithoutDefault = []string{"local_id", "guild_id", "trigger_type", "text_trigger", "text_trigger_case_sensitive", "time_trigger_interval", "time_trigger_excluding_days", "time_trigger_excluding_hours", "responses", "channels_whitelist_mode", "roles_whitelist_mode"}
-	customCommandColumnsWithDefault    = []string{"group_id", "last_run", "next_run", "channels", "roles", "context_channel", "reaction_trigger_mode", "last_error", "last_error_time", "run_count", "show_errors", "disabled", "date_updated", "categories", "categories_whitelist_mode", "regex_trigger", "regex_trigger_case_sensitive", "note", "threads_enabled", "normalize_unicode", "trigger_on_edit", "public_id", "import_count", "public"}
+	customCommandColumnsWithDefault    = []string{"group_id", "last_run", "next_run", "channels", "roles", "context_channel", "reaction_trigger_mode", "last_error", "last_error_time", "run_count", "show_errors", "disabled", "date_updated", "categories", "categories_whitelist_mode", "regex_trigger", "regex_trigger_case_sensitive", "note", "threads_enabled", "normalize_unicode", "trigger_on_edit", "public_id", "import_count", "public", "cooldown_user", "cooldown_channel", "cooldown_guild", "cooldown_bypass_roles", "daily_limit_user", "daily_limit_guild", "cooldown_response"}
 	customCommandPrimaryKeyColumns     = []string{"guild_id", "local_id"}
 	customCommandGeneratedColumns      = []string{}
 )
@@ -33,6 +33,7 @@ type TemplatesUserDatabase struct {
 	Key       string    `boil:"key" json:"key" toml:"key" yaml:"key"`
 	ValueNum  float64   `boil:"value_num" json:"value_num" toml:"value_num" yaml:"value_num"`
 	ValueRaw  []byte    `boil:"value_raw" json:"value_raw" toml:"value_raw" yaml:"value_raw"`
+	ValueJSON null.JSON `boil:"value_json" json:"value_json,omitempty" toml:"value_json" yaml:"value_json,omitempty"`
 
 	R *templatesUserDatabaseR `boil:"-" json:"-" toml:"-" yaml:"-"`
 	L templatesUserDatabaseL  `boil:"-" json:"-" toml:"-" yaml:"-"`
@@ -48,6 +49,7 @@ var TemplatesUserDatabaseColumns = struct {
 	Key       string
 	ValueNum  string
 	ValueRaw  string
+	ValueJSON string
 }{
 	ID:        "id",
 	CreatedAt: "created_at",
@@ -58,6 +60,7 @@ var TemplatesUserDatabaseColumns = struct {
 	Key:       "key",
 	ValueNum:  "value_num",
 	ValueRaw:  "value_raw",
+	ValueJSON: "value_json",
 }
 
 var TemplatesUserDatabaseTableColumns = struct {
@@ -70,6 +73,7 @@ var TemplatesUserDatabaseTableColumns = struct {
 	Key       string
 	ValueNum  string
 	ValueRaw  string
+	ValueJSON string
 }{
 	ID:        "templates_user_database.id",
 	CreatedAt: "templates_user_database.created_at",
@@ -80,6 +84,7 @@ var TemplatesUserDatabaseTableColumns = struct {
 	Key:       "templates_user_database.key",
 	ValueNum:  "templates_user_database.value_num",
 	ValueRaw:  "templates_user_database.value_raw",
+	ValueJSON: "templates_user_database.value_json",
 }
 
 // Generated where
@@ -143,6 +148,30 @@ func (w whereHelper__byte) LTE(x []byte) qm.QueryMod { return qmhelper.Where(w.f
 func (w whereHelper__byte) GT(x []byte) qm.QueryMod  { return qmhelper.Where(w.field, qmhelper.GT, x) }
 func (w whereHelper__byte) GTE(x []byte) qm.QueryMod { return qmhelper.Where(w.field, qmhelper.GTE, x) }
 
+type whereHelpernull_JSON struct{ field string }
+
+func (w whereHelpernull_JSON) EQ(x null.JSON) qm.QueryMod {
+	return qmhelper.WhereNullEQ(w.field, false, x)
+}
+func (w whereHelpernull_JSON) NEQ(x null.JSON) qm.QueryMod {
+	return qmhelper.WhereNullEQ(w.field, true, x)
+}
+func (w whereHelpernull_JSON) LT(x null.JSON) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.LT, x)
+}
+func (w whereHelpernull_JSON) LTE(x null.JSON) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.LTE, x)
+}
+func (w whereHelpernull_JSON) GT(x null.JSON) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.GT, x)
+}
+func (w whereHelpernull_JSON) GTE(x null.JSON) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.GTE, x)
+}
+
+func (w whereHelpernull_JSON) IsNull() qm.QueryMod    { return qmhelper.WhereIsNull(w.field) }
+func (w whereHelpernull_JSON) IsNotNull() qm.QueryMod { return qmhelper.WhereIsNotNull(w.field) }
+
 var TemplatesUserDatabaseWhere = struct {
 	ID        whereHelperint64
 	CreatedAt whereHelpertime_Time
@@ -153,6 +182,7 @@ var TemplatesUserDatabaseWhere = struct {
 	Key       whereHelperstring
 	ValueNum  whereHelperfloat64
 	ValueRaw  whereHelper__byte
+	ValueJSON whereHelpernull_JSON
 }{
 	ID:        whereHelperint64{field: "\"templates_user_database\".\"id\""},
 	CreatedAt: whereHelpertime_Time{field: "\"templates_user_database\".\"created_at\""},
@@ -163,6 +193,7 @@ var TemplatesUserDatabaseWhere = struct {
 	Key:       whereHelperstring{field: "\"templates_user_database\".\"key\""},
 	ValueNum:  whereHelperfloat64{field: "\"templates_user_database\".\"value_num\""},
 	ValueRaw:  whereHelper__byte{field: "\"templates_user_database\".\"value_raw\""},
+	ValueJSON: whereHelpernull_JSON{field: "\"templates_user_database\".\"value_json\""},
 }
 
 // TemplatesUserDatabaseRels is where relationship names are stored.
@@ -182,9 +213,9 @@ func (*templatesUserDatabaseR) NewStruct() *templatesUserDatabaseR {
 type templatesUserDatabaseL struct{}
 
 var (
-	templatesUserDatabaseAllColumns            = []string{"id", "created_at", "updated_at", "expires_at", "guild_id", "user_id", "key", "value_num", "value_raw"}
+	templatesUserDatabaseAllColumns            = []string{"id", "created_at", "updated_at", "expires_at", "guild_id", "user_id", "key", "value_num", "value_raw", "value_json"}
 	templatesUserDatabaseColumnsWithoutDefault = []string{"created_at", "updated_at", "guild_id", "user_id", "key", "value_num", "value_raw"}
-	templatesUserDatabaseColumnsWithDefault    = []string{"id", "expires_at"}
+	templatesUserDatabaseColumnsWithDefault    = []string{"id", "expires_at", "value_json"}
 	templatesUserDatabasePrimaryKeyColumns     = []string{"id"}
 	templatesUserDatabaseGeneratedColumns      = []string{}
 )
@@ -0,0 +1,422 @@
+package customcommands
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/mediocregopher/radix/v3"
+	"github.com/mrbentarikau/pagst/bot"
+	"github.com/mrbentarikau/pagst/bot/eventsystem"
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/common/scheduledevents2"
+	schEventsModels "github.com/mrbentarikau/pagst/common/scheduledevents2/models"
+	"github.com/mrbentarikau/pagst/common/templates"
+	"github.com/mrbentarikau/pagst/customcommands/models"
+	"github.com/mrbentarikau/pagst/lib/discordgo"
+	"github.com/mrbentarikau/pagst/lib/dstate"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vmihailenco/msgpack"
+)
+
+const (
+	ReplyTimeoutMin = 5 * time.Second
+	ReplyTimeoutMax = 15 * time.Minute
+
+	// how long the continuation is kept around after the timeout, so the timeout event can still claim it
+	replyTimeoutGrace = time.Minute
+)
+
+// ReplyContinuation is a pending waitForReply, the next message or button press from the user
+// in the channel runs the custom command with the reply and the carried over state
+type ReplyContinuation struct {
+	// ID is unique per waitForReply call, so a timeout event doesn't claim a newer continuation
+	ID        string `json:"id"`
+	CmdID     int64  `json:"cmd_id"`
+	ChannelID int64  `json:"channel_id"`
+	UserID    int64  `json:"user_id"`
+
+	// State is msgpack encoded to include type information, like execCC data
+	State []byte `json:"state,omitempty"`
+
+	CallChain    []time.Time                `json:"call_chain"`
+	ExecutedFrom templates.ExecutedFromType `json:"executed_from"`
+}
+
+// ReplyTimeoutData is the scheduled event data that runs the continuation if there was no reply in time
+type ReplyTimeoutData struct {
+	ID        string `json:"id"`
+	ChannelID int64  `json:"channel_id"`
+	UserID    int64  `json:"user_id"`
+}
+
+func keyReplyContinuation(guildID, channelID, userID int64) string {
+	return "cc_reply_wait:" + discordgo.StrID(guildID) + ":" + discordgo.StrID(channelID) + ":" + discordgo.StrID(userID)
+}
+
+// sorted set of guild:channel:user with the time the continuation expires as score,
+// used to know which guilds have any continuations pending after a restart
+const keyReplyWaitingGuilds = "cc_reply_wait_guilds"
+
+var (
+	// guilds with pending continuations and when the last of them expires,
+	// so messages in all the other guilds don't need to check redis
+	replyWaitingGuilds   = make(map[int64]time.Time)
+	replyWaitingGuildsMU sync.Mutex
+)
+
+func markReplyWaiting(guildID int64, until time.Time) {
+	replyWaitingGuildsMU.Lock()
+	if until.After(replyWaitingGuilds[guildID]) {
+		replyWaitingGuilds[guildID] = until
+	}
+	replyWaitingGuildsMU.Unlock()
+}
+
+// guildHasReplyWaiting returns whether there may be a pending continuation in the guild
+func guildHasReplyWaiting(guildID int64) bool {
+	replyWaitingGuildsMU.Lock()
+	defer replyWaitingGuildsMU.Unlock()
+
+	until, ok := replyWaitingGuilds[guildID]
+	if !ok {
+		return false
+	}
+
+	if time.Now().After(until) {
+		delete(replyWaitingGuilds, guildID)
+		return false
+	}
+
+	return true
+}
+
+// loadReplyWaitingGuilds restores the guilds with pending continuations from before the bot was started
+func loadReplyWaitingGuilds() {
+	now := time.Now().Unix()
+
+	err := common.RedisPool.Do(radix.FlatCmd(nil, "ZREMRANGEBYSCORE", keyReplyWaitingGuilds, "-inf", now))
+	if err != nil {
+		logger.WithError(err).Error("failed removing expired cc reply continuations")
+	}
+
+	var pairs []string
+	err = common.RedisPool.Do(radix.FlatCmd(&pairs, "ZRANGEBYSCORE", keyReplyWaitingGuilds, now, "+inf", "WITHSCORES"))
+	if err != nil {
+		logger.WithError(err).Error("failed retrieving pending cc reply continuations")
+		return
+	}
+
+	for i := 0; i+1 < len(pairs); i += 2 {
+		guildStr, _, _ := strings.Cut(pairs[i], ":")
+		guildID, _ := strconv.ParseInt(guildStr, 10, 64)
+		expires, _ := strconv.ParseInt(pairs[i+1], 10, 64)
+		if guildID != 0 {
+			markReplyWaiting(guildID, time.Unix(expires, 0))
+		}
+	}
+}
+
+func setReplyContinuation(guildID int64, c *ReplyContinuation, timeout time.Duration) error {
+	serialized, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	ttl := timeout + replyTimeoutGrace
+	err = common.RedisPool.Do(radix.FlatCmd(nil, "SET", keyReplyContinuation(guildID, c.ChannelID, c.UserID), serialized, "EX", int(ttl.Seconds())))
+	if err != nil {
+		return err
+	}
+
+	expires := time.Now().Add(ttl)
+	markReplyWaiting(guildID, expires)
+
+	member := discordgo.StrID(guildID) + ":" + discordgo.StrID(c.ChannelID) + ":" + discordgo.StrID(c.UserID)
+	return common.RedisPool.Do(radix.FlatCmd(nil, "ZADD", keyReplyWaitingGuilds, expires.Unix(), member))
+}
+
+// claimReplyScript gets and deletes the continuation in one go, if ARGV[1] isn't empty only if it has that id
+var claimReplyScript = radix.NewEvalScript(1, `
+local raw = redis.call("GET", KEYS[1])
+if not raw then
+	return false
+end
+
+if ARGV[1] ~= "" and cjson.decode(raw)["id"] ~= ARGV[1] then
+	return false
+end
+
+redis.call("DEL", KEYS[1])
+return raw
+`)
+
+// claimReplyContinuation removes and returns the pending continuation for the user in the channel, if any.
+// If id is set it's only claimed if it's that continuation. Only one caller gets it when there's a reply and a timeout at the same time.
+func claimReplyContinuation(guildID, channelID, userID int64, id string) (*ReplyContinuation, error) {
+	var raw []byte
+	err := common.RedisPool.Do(claimReplyScript.Cmd(&raw, keyReplyContinuation(guildID, channelID, userID), id))
+	if err != nil || len(raw) == 0 {
+		return nil, err
+	}
+
+	var c *ReplyContinuation
+	err = json.Unmarshal(raw, &c)
+	return c, err
+}
+
+// tmplWaitForReply makes the next message or button press from the user in the channel run the custom command,
+// the reply and the state are then available in .ExecData
+// if the user doesn't reply within the timeout, the custom command is ran with .ExecData.TimedOut set
+//
+// Usage: waitForReply ccID user channel timeoutSeconds state
+// user and channel default to the ones of the current execution when nil
+func tmplWaitForReply(ctx *templates.Context) interface{} {
+	return func(ccID int, user interface{}, channel interface{}, timeoutSeconds interface{}, state ...interface{}) (string, error) {
+		if ctx.IncreaseCheckCallCounterPremium("wait_reply", 1, 3) {
+			return "", templates.ErrTooManyCalls
+		}
+
+		if channel == nil && ctx.CurrentFrame.CS != nil {
+			channel = ctx.CurrentFrame.CS.ID
+		}
+
+		var data interface{}
+		if len(state) > 0 {
+			data = state[0]
+		}
+
+		opts, err := parseRunCCOptions(ctx, ccID, channel, timeoutSeconds, data)
+		if err != nil {
+			return "", err
+		}
+
+		if opts.Delay < ReplyTimeoutMin || opts.Delay > ReplyTimeoutMax {
+			return "", errors.Errorf("reply timeout has to be between %d and %d seconds", int(ReplyTimeoutMin.Seconds()), int(ReplyTimeoutMax.Seconds()))
+		}
+
+		var userID int64
+		if user == nil {
+			if ctx.MS != nil {
+				userID = ctx.MS.User.ID
+			}
+		} else {
+			userID = templates.TargetUserID(user)
+		}
+
+		if userID == 0 {
+			return "", errors.New("unknown user")
+		}
+
+		timesOutAt := time.Now().Add(opts.Delay)
+		newCallChain, err := updateCallChain(ctx.ExecCallChain, timesOutAt)
+		if err != nil {
+			return "", err
+		}
+
+		c := &ReplyContinuation{
+			ID:           strconv.FormatInt(time.Now().UnixNano(), 36),
+			CmdID:        opts.Cmd.LocalID,
+			ChannelID:    opts.CS.ID,
+			UserID:       userID,
+			CallChain:    newCallChain,
+			ExecutedFrom: ctx.ExecutedFrom,
+		}
+
+		if data != nil {
+			c.State, err = encodeRunCCUserData(data)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		// a newer continuation replaces the current one, the old timeout event will notice the id changed
+		err = setReplyContinuation(ctx.GS.ID, c, opts.Delay)
+		if err != nil {
+			return "", err
+		}
+
+		err = scheduledevents2.ScheduleEvent("cc_reply_timeout", ctx.GS.ID, timesOutAt, &ReplyTimeoutData{
+			ID:        c.ID,
+			ChannelID: c.ChannelID,
+			UserID:    c.UserID,
+		})
+		if err != nil {
+			return "", errors.WrapIf(err, "failed scheduling reply timeout")
+		}
+
+		return "", nil
+	}
+}
+
+// handleReplyMessage runs the pending continuation of the author in the channel, if any
+func handleReplyMessage(gs *dstate.GuildSet, cs *dstate.ChannelState, member *dstate.MemberState, msg *discordgo.Message) {
+	if !guildHasReplyWaiting(gs.ID) {
+		return
+	}
+
+	c, err := claimReplyContinuation(gs.ID, cs.ID, msg.Author.ID, "")
+	if err != nil {
+		logger.WithError(err).WithField("guild", gs.ID).Error("failed claiming cc reply continuation")
+		return
+	}
+
+	if c == nil {
+		return
+	}
+
+	execData := templates.SDict{
+		"Type":    "message",
+		"Reply":   msg.Content,
+		"Message": msg,
+	}
+
+	err = runReplyContinuation(gs, cs, member, msg, c, execData)
+	if err != nil {
+		logger.WithField("guild", gs.ID).WithField("cc_id", c.CmdID).WithError(err).Error("Error executing custom command reply continuation")
+	}
+}
+
+func handleReplyInteraction(evt *eventsystem.EventData) {
+	ic := evt.InteractionCreate()
+	if ic.Type != discordgo.InteractionMessageComponent || ic.GuildID == 0 || ic.Member == nil || evt.GS == nil {
+		return
+	}
+
+	customID := ic.MessageComponentData().CustomID
+	if !strings.HasPrefix(customID, templates.ButtonCustomIDPrefix) {
+		return
+	}
+
+	c, err := claimReplyContinuation(ic.GuildID, ic.ChannelID, ic.Member.User.ID, "")
+	if err != nil {
+		logger.WithError(err).WithField("guild", ic.GuildID).Error("failed claiming cc reply continuation")
+		return
+	}
+
+	if c == nil {
+		// nobody is waiting for this user to press something here, the button is stale or meant for someone else
+		err = common.BotSession.CreateInteractionResponse(ic.ID, ic.Token, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "This button isn't waiting for you.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		if err != nil {
+			logger.WithError(err).WithField("guild", ic.GuildID).Error("failed responding to cc button interaction")
+		}
+		return
+	}
+
+	err = common.BotSession.CreateInteractionResponse(ic.ID, ic.Token, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	})
+	if err != nil {
+		logger.WithError(err).WithField("guild", ic.GuildID).Error("failed acknowledging cc button interaction")
+	}
+
+	cs := evt.GS.GetChannelOrThread(ic.ChannelID)
+	if cs == nil {
+		return
+	}
+
+	member := dstate.MemberStateFromMember(ic.Member)
+	member.GuildID = ic.GuildID
+
+	id := strings.TrimPrefix(customID, templates.ButtonCustomIDPrefix)
+	execData := templates.SDict{
+		"Type":    "button",
+		"Reply":   id,
+		"Button":  id,
+		"Message": ic.Message,
+	}
+
+	err = runReplyContinuation(evt.GS, cs, member, ic.Message, c, execData)
+	if err != nil {
+		logger.WithField("guild", ic.GuildID).WithField("cc_id", c.CmdID).WithError(err).Error("Error executing custom command reply continuation")
+	}
+}
+
+func handleReplyTimeout(evt *schEventsModels.ScheduledEvent, data interface{}) (retry bool, err error) {
+	dataCast := data.(*ReplyTimeoutData)
+
+	gs := bot.State.GetGuild(evt.GuildID)
+	if gs == nil {
+		if onGuild, err := common.BotIsOnGuild(evt.GuildID); !onGuild && err == nil {
+			return false, nil
+		} else if err != nil {
+			logger.WithError(err).Error("failed checking if bot is on guild")
+		}
+
+		return true, nil
+	}
+
+	c, err := claimReplyContinuation(evt.GuildID, dataCast.ChannelID, dataCast.UserID, dataCast.ID)
+	if err != nil {
+		return true, err
+	}
+
+	if c == nil {
+		// already replied to, or replaced by a newer waitForReply
+		return false, nil
+	}
+
+	cs := gs.GetChannelOrThread(c.ChannelID)
+	if cs == nil {
+		return false, nil
+	}
+
+	ms, _ := bot.GetMember(gs.ID, c.UserID)
+	execData := templates.SDict{
+		"Type":     "timeout",
+		"TimedOut": true,
+	}
+
+	return false, runReplyContinuation(gs, cs, ms, nil, c, execData)
+}
+
+func runReplyContinuation(gs *dstate.GuildSet, cs *dstate.ChannelState, ms *dstate.MemberState, msg *discordgo.Message, c *ReplyContinuation, execData templates.SDict) error {
+	cmd, err := models.CustomCommands(models.CustomCommandWhere.GuildID.EQ(gs.ID), models.CustomCommandWhere.LocalID.EQ(c.CmdID)).OneG(context.Background())
+	if err != nil {
+		return errors.WrapIf(err, "find_command")
+	}
+
+	if cmd.Disabled {
+		return errors.New("custom command is disabled")
+	}
+
+	if !DelayedCCRunLimit.AllowN(DelayedRunLimitKey{GuildID: gs.ID, ChannelID: cs.ID}, time.Now(), 1) {
+		logger.WithField("guild", gs.ID).Warn("went above delayed cc run ratelimit")
+		return nil
+	}
+
+	execData["TimedOut"] = execData["TimedOut"] == true
+	execData["State"] = nil
+	if len(c.State) > 0 {
+		var state interface{}
+		err := msgpack.Unmarshal(c.State, &state)
+		if err != nil {
+			return err
+		}
+		execData["State"] = state
+	}
+
+	tmplCtx := templates.NewContext(gs, cs, ms)
+	if msg != nil {
+		tmplCtx.Msg = msg
+		tmplCtx.Data["Message"] = msg
+	}
+
+	tmplCtx.Data["ExecData"] = execData
+	tmplCtx.Data["ReplyContinuation"] = true
+	tmplCtx.ExecCallChain = c.CallChain
+	tmplCtx.ExecutedFrom = c.ExecutedFrom
+
+	metricsExecutedCommands.With(prometheus.Labels{"trigger": "reply"}).Inc()
+	return ExecuteCustomCommand(cmd, tmplCtx)
+}
@@ -0,0 +1,24 @@
+package customcommands
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGuildHasReplyWaiting(t *testing.T) {
+	markReplyWaiting(1, time.Now().Add(time.Minute))
+	markReplyWaiting(1, time.Now().Add(-time.Minute))
+	markReplyWaiting(2, time.Now().Add(-time.Second))
+
+	if !guildHasReplyWaiting(1) {
+		t.Errorf("expected guild 1 to have a continuation waiting, a shorter one shouldn't replace it")
+	}
+
+	if guildHasReplyWaiting(2) {
+		t.Errorf("expected the continuation of guild 2 to be expired")
+	}
+
+	if guildHasReplyWaiting(3) {
+		t.Errorf("expected guild 3 to have nothing waiting")
+	}
+}
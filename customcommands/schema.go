@@ -114,6 +114,32 @@ ALTER TABLE custom_commands ADD COLUMN IF NOT EXISTS note TEXT;
 ALTER TABLE custom_commands ADD COLUMN IF NOT EXISTS public BOOLEAN NOT NULL DEFAULT false;
 `, `
 ALTER TABLE custom_commands ADD COLUMN IF NOT EXISTS response TEXT NOT NULL DEFAULT '';
+`, `
+ALTER TABLE custom_commands ADD COLUMN IF NOT EXISTS cooldown_user INT NOT NULL DEFAULT 0;
+`, `
+ALTER TABLE custom_commands ADD COLUMN IF NOT EXISTS cooldown_channel INT NOT NULL DEFAULT 0;
+`, `
+ALTER TABLE custom_commands ADD COLUMN IF NOT EXISTS cooldown_guild INT NOT NULL DEFAULT 0;
+`, `
+ALTER TABLE custom_commands ADD COLUMN IF NOT EXISTS cooldown_bypass_roles BIGINT[];
+`, `
+ALTER TABLE custom_commands ADD COLUMN IF NOT EXISTS daily_limit_user INT NOT NULL DEFAULT 0;
+`, `
+ALTER TABLE custom_commands ADD COLUMN IF NOT EXISTS daily_limit_guild INT NOT NULL DEFAULT 0;
+`, `
+ALTER TABLE custom_commands ADD COLUMN IF NOT EXISTS cooldown_response TEXT NOT NULL DEFAULT '';
+`, `
+ALTER TABLE templates_user_database ADD COLUMN IF NOT EXISTS value_json JSONB;
+`, `
+CREATE INDEX IF NOT EXISTS templates_user_database_key_prefix_idx ON templates_user_database (guild_id, key text_pattern_ops);
+`, `
+CREATE INDEX IF NOT EXISTS templates_user_database_value_num_idx ON templates_user_database (guild_id, value_num);
+`, `
+CREATE INDEX IF NOT EXISTS templates_user_database_created_at_idx ON templates_user_database (guild_id, created_at);
+`, `
+CREATE INDEX IF NOT EXISTS templates_user_database_updated_at_idx ON templates_user_database (guild_id, updated_at);
+`, `
+CREATE INDEX IF NOT EXISTS templates_user_database_value_json_idx ON templates_user_database USING GIN (value_json jsonb_path_ops);
 `}
 
 //`, `
@@ -34,6 +34,8 @@ func init() {
 		ctx.ContextFuncs["execCC"] = tmplRunCC(ctx)
 		ctx.ContextFuncs["parseArgs"] = tmplExpectArgs(ctx)
 		ctx.ContextFuncs["scheduleUniqueCC"] = tmplScheduleUniqueCC(ctx)
+		ctx.ContextFuncs["sendWebhookMessage"] = tmplSendWebhookMessage(ctx)
+		ctx.ContextFuncs["waitForReply"] = tmplWaitForReply(ctx)
 
 		//template user database
 		ctx.ContextFuncs["dbBottomEntries"] = tmplDBTopEntries(ctx, true)
@@ -46,6 +48,7 @@ func init() {
 		ctx.ContextFuncs["dbGetByID"] = tmplDBGetByID(ctx)
 		ctx.ContextFuncs["dbGetPattern"] = tmplDBGetPattern(ctx, false)
 		ctx.ContextFuncs["dbIncr"] = tmplDBIncr(ctx, false)
+		ctx.ContextFuncs["dbQuery"] = tmplDBQuery(ctx)
 		ctx.ContextFuncs["dbRank"] = tmplDBRank(ctx)
 		ctx.ContextFuncs["dbSet"] = tmplDBSet(ctx)
 		ctx.ContextFuncs["dbSetExpire"] = tmplDBSetExpire(ctx)
@@ -330,6 +333,10 @@ func parseRunCCOptions(ctx *templates.Context, ccID int, channel interface{}, de
 		return nil, errors.New("couldn't find custom command")
 	}
 
+	if cmd.TriggerType == int(CommandTriggerLibrary) {
+		return nil, errors.New("can't run a library custom command")
+	}
+
 	channelID := ctx.ChannelArg(channel)
 	if channelID == 0 {
 		return nil, errors.New("unknown channel")
@@ -500,12 +507,13 @@ func tmplDBSetExpire(ctx *templates.Context) func(userID int64, key interface{},
 			UpdatedAt: time.Now(),
 			ExpiresAt: expires,
 
-			Key:      keyStr,
-			ValueRaw: valueSerialized,
-			ValueNum: vNum,
+			Key:       keyStr,
+			ValueRaw:  valueSerialized,
+			ValueNum:  vNum,
+			ValueJSON: jsonValue(value),
 		}
 
-		err = m.Upsert(context.Background(), common.PQ, true, []string{"guild_id", "user_id", "key"}, boil.Whitelist("value_raw", "value_num", "updated_at", "expires_at"), boil.Infer())
+		err = m.Upsert(context.Background(), common.PQ, true, []string{"guild_id", "user_id", "key"}, boil.Whitelist("value_raw", "value_num", "value_json", "updated_at", "expires_at"), boil.Infer())
 		return "", err
 	}
 }
@@ -899,14 +907,19 @@ func serializeValue(v interface{}) ([]byte, error) {
 	return b.Bytes(), err
 }
 
-// returns true if were above db limit for the specified guild
-func CheckGuildDBLimit(gs *dstate.GuildSet) (bool, error) {
+// GuildDBLimit returns the max number of database entries the guild can have
+func GuildDBLimit(gs *dstate.GuildSet) int64 {
 	limitMuliplier := 1
 	if isPremium, _ := premium.IsGuildPremium(gs.ID); isPremium {
 		limitMuliplier = 10
 	}
 
-	limit := gs.MemberCount * 50 * int64(limitMuliplier)
+	return gs.MemberCount * 50 * int64(limitMuliplier)
+}
+
+// returns true if were above db limit for the specified guild
+func CheckGuildDBLimit(gs *dstate.GuildSet) (bool, error) {
+	limit := GuildDBLimit(gs)
 
 	curValues, err := cacheCheckDBLimit(gs)
 	if err != nil {
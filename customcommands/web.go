@@ -87,6 +87,9 @@ var (
 	panelLogKeyRemovedGroup = cplogs.RegisterActionFormat(&cplogs.ActionFormat{Key: "customcommands_removed_group", FormatString: "Removed custom command group: %d"})
 
 	panelLogKeyDeleteCCDBEntry = cplogs.RegisterActionFormat(&cplogs.ActionFormat{Key: "customcommands_db_entry_delete", FormatString: "Deleted CC DBEntry: %d"})
+	panelLogKeyImportedCCDB    = cplogs.RegisterActionFormat(&cplogs.ActionFormat{Key: "customcommands_db_imported", FormatString: "Imported %d CC DB entries"})
+
+	panelLogKeyUpdatedWebhookConfig = cplogs.RegisterActionFormat(&cplogs.ActionFormat{Key: "customcommands_webhook_config_updated", FormatString: "Updated custom command webhook settings"})
 )
 
 // InitWeb implements web.Plugin
@@ -143,6 +146,8 @@ func (p *Plugin) InitWeb() {
 	subMux.Handle(pat.Get("/database"), getDBHandler)
 	subMux.Handle(pat.Get("/database/"), getDBHandler)
 	subMux.Handle(pat.Post("/database/delete/:id"), web.ControllerPostHandler(handleDeleteDatabaseEntry, getDBHandler, nil))
+	subMux.Handle(pat.Get("/database/export"), http.HandlerFunc(handleExportDatabase))
+	subMux.Handle(pat.Post("/database/import"), web.ControllerPostHandler(handleImportDatabase, getDBHandler, nil))
 
 	subMux.Handle(pat.Get("/commands/:cmd/"), getCmdHandler)
 
@@ -161,6 +166,8 @@ func (p *Plugin) InitWeb() {
 	subMux.Handle(pat.Post("/creategroup"), web.ControllerPostHandler(handleNewGroup, getHandler, GroupForm{}))
 	subMux.Handle(pat.Post("/groups/:group/update"), web.ControllerPostHandler(handleUpdateGroup, getGroupHandler, GroupForm{}))
 	subMux.Handle(pat.Post("/groups/:group/delete"), web.ControllerPostHandler(handleDeleteGroup, getHandler, nil))
+
+	subMux.Handle(pat.Post("/webhooks"), web.ControllerPostHandler(handleUpdateWebhookConfig, getHandler, WebhookConfig{}))
 	subMux.Use(web.NotFound())
 
 	// shortlink-specific mux
@@ -252,6 +259,62 @@ func handleDeleteDatabaseEntry(w http.ResponseWriter, r *http.Request) (web.Temp
 	return templateData.AddAlerts(), nil
 }
 
+// maxDBImportSize is the max size of an uploaded database import file, 10MB
+const maxDBImportSize = 10000000
+
+func handleExportDatabase(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	activeGuild, _ := web.GetBaseCPContextData(ctx)
+
+	format := "json"
+	if r.URL.Query().Get("format") == "csv" {
+		format = "csv"
+	}
+
+	w.Header().Set("Content-Type", "application/"+format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="cc_database_%d.%s"`, activeGuild.ID, format))
+
+	err := exportGuildDB(ctx, w, activeGuild.ID, format)
+	if err != nil {
+		web.CtxLogger(ctx).WithError(err).Error("failed exporting cc database")
+	}
+}
+
+func handleImportDatabase(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ctx := r.Context()
+	activeGuild, templateData := web.GetBaseCPContextData(ctx)
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxDBImportSize)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return templateData.AddAlerts(web.ErrorAlert("No file uploaded, or it's larger than 10MB")), nil
+	}
+	defer file.Close()
+
+	format := "json"
+	if strings.HasSuffix(strings.ToLower(header.Filename), ".csv") {
+		format = "csv"
+	}
+
+	entries, err := parseDBImport(file, format)
+	if err != nil {
+		return templateData.AddAlerts(web.ErrorAlert("Failed reading the import: ", err.Error())), nil
+	}
+
+	imported, err := importGuildDB(ctx, activeGuild.ID, entries, GuildDBLimit(activeGuild))
+	if err != nil {
+		if err == ErrDBImportAboveLimit {
+			return templateData.AddAlerts(web.ErrorAlert("Nothing was imported, ", err.Error())), nil
+		}
+
+		return templateData, err
+	}
+
+	go cplogs.RetryAddEntry(web.NewLogEntryFromContext(r.Context(), panelLogKeyImportedCCDB, &cplogs.Param{Type: cplogs.ParamTypeInt, Value: int64(imported)}))
+
+	return templateData.AddAlerts(web.SucessAlert(fmt.Sprintf("Imported %d of %d entries, expired entries were skipped", imported, len(entries)))), nil
+}
+
 func getLangBuiltInFuncs() string {
 	var langBuiltins strings.Builder
 	for k := range yagtemplate.StandardFuncMap {
@@ -278,6 +341,19 @@ func handleCommands(w http.ResponseWriter, r *http.Request) (web.TemplateData, e
 	templateData["HLJSBuiltins"] = getLangBuiltInFuncs()
 	updateTemplateWithCountData(int(count), templateData, ctx)
 
+	metrics, err := GetExecMetrics(activeGuild.ID)
+	if err != nil {
+		web.CtxLogger(ctx).WithError(err).Error("failed retrieving cc exec metrics")
+	} else if len(metrics) > 0 {
+		templateData["CCMetrics"] = SummarizeExecMetrics(metrics, maxOpsForContext(ctx))
+	}
+
+	webhookConfig, err := GetWebhookConfig(activeGuild.ID)
+	if err != nil {
+		return templateData, err
+	}
+	templateData["CCWebhookConfig"] = webhookConfig
+
 	return serveGroupSelected(r, templateData, groupID, activeGuild.ID)
 }
 
@@ -315,6 +391,20 @@ func handleGetCommand(w http.ResponseWriter, r *http.Request) (web.TemplateData,
 	templateData["IsGuildPremium"] = premium.ContextPremium(r.Context())
 	templateData["MaxCCLength"] = allowedCCLength
 	templateData["PublicLink"] = getPublicLink(cc)
+	if len(cc.Responses) > 0 {
+		templateData["LintIssues"] = yagtemplate.Lint(cc.Responses[0])
+	}
+
+	if cc.TriggerType == int(CommandTriggerLibrary) && len(cc.Responses) > 0 {
+		ccs, err := models.CustomCommands(models.CustomCommandWhere.GuildID.EQ(activeGuild.ID)).AllG(r.Context())
+		if err != nil {
+			return templateData, errors.WithStackIf(err)
+		}
+
+		names := LibraryTemplateNames(cc.Responses[0])
+		templateData["LibraryTemplates"] = names
+		templateData["LibraryDependents"] = LibraryDependents(ccs, names)
+	}
 
 	return serveGroupSelected(r, templateData, cc.GroupID.Int64, cc.GuildID)
 }
@@ -478,6 +568,17 @@ func handleNewCommand(w http.ResponseWriter, r *http.Request) (web.TemplateData,
 			return templateData, nil
 		}
 
+		if importCC.TriggerType == int(CommandTriggerLibrary) {
+			c, err := models.CustomCommands(qm.Where("guild_id = ? AND trigger_type = ?", activeGuild.ID, int(CommandTriggerLibrary))).CountG(ctx)
+			if err != nil {
+				return templateData, err
+			}
+
+			if int(c) >= MaxLibrariesForContext(ctx) {
+				return templateData.AddAlerts(web.ErrorAlert(fmt.Sprintf("Max %d library custom commands allowed (or %d for premium servers)", MaxLibraries, MaxLibrariesPremium))), nil
+			}
+		}
+
 		dbModel.ReactionTriggerMode = importCC.ReactionTriggerMode
 		dbModel.Responses = importCC.Responses
 		dbModel.TextTrigger = importCC.TextTrigger
@@ -487,6 +588,12 @@ func handleNewCommand(w http.ResponseWriter, r *http.Request) (web.TemplateData,
 		dbModel.TimeTriggerInterval = importCC.TimeTriggerInterval
 		dbModel.TriggerOnEdit = importCC.TriggerOnEdit && premium.ContextPremium(ctx)
 		dbModel.TriggerType = importCC.TriggerType
+		dbModel.CooldownUser = importCC.CooldownUser
+		dbModel.CooldownChannel = importCC.CooldownChannel
+		dbModel.CooldownGuild = importCC.CooldownGuild
+		dbModel.CooldownResponse = importCC.CooldownResponse
+		dbModel.DailyLimitUser = importCC.DailyLimitUser
+		dbModel.DailyLimitGuild = importCC.DailyLimitGuild
 		templateData.AddAlerts(web.WarningAlert("It is recommended you scan your CC for hardcoded IDs or other server-specific arguments you may want to update"))
 	}
 
@@ -510,6 +617,7 @@ func handleNewCommand(w http.ResponseWriter, r *http.Request) (web.TemplateData,
 	}
 
 	pubsub.EvictCacheSet(cachedCommandsMessage, activeGuild.ID)
+	pubsub.EvictCacheSet(cachedLibraries, activeGuild.ID)
 	return templateData, nil
 }
 
@@ -558,6 +666,17 @@ func handleUpdateCommand(w http.ResponseWriter, r *http.Request) (web.TemplateDa
 		return templateData.AddAlerts(web.ErrorAlert("`Trigger on edits` is a premium feature, your command wasn't saved, please save again after disabling `Trigger on edits`")), nil
 	}
 
+	if triggerTypeFromForm(cmd.TriggerTypeForm) == CommandTriggerLibrary && cmdSaved.TriggerType != int(CommandTriggerLibrary) {
+		c, err := models.CustomCommands(qm.Where("guild_id = ? AND trigger_type = ?", activeGuild.ID, int(CommandTriggerLibrary))).CountG(ctx)
+		if err != nil {
+			return templateData, err
+		}
+
+		if int(c) >= MaxLibrariesForContext(ctx) {
+			return templateData.AddAlerts(web.ErrorAlert(fmt.Sprintf("Max %d library custom commands allowed (or %d for premium servers)", MaxLibraries, MaxLibrariesPremium))), nil
+		}
+	}
+
 	dbModel := cmd.ToDBModel()
 
 	templateData["CurrentGroupID"] = dbModel.GroupID.Int64
@@ -643,6 +762,16 @@ func handleUpdateCommand(w http.ResponseWriter, r *http.Request) (web.TemplateDa
 	}
 
 	pubsub.EvictCacheSet(cachedCommandsMessage, activeGuild.ID)
+	pubsub.EvictCacheSet(cachedLibraries, activeGuild.ID)
+
+	addLintAlerts(templateData, dbModel.Responses)
+
+	if dbModel.TriggerType == int(CommandTriggerLibrary) {
+		err = addLibraryAlerts(ctx, templateData, dbModel)
+		if err != nil {
+			return templateData, err
+		}
+	}
 
 	var limiter *CCLimits
 	limiter, _ = CCTriggerLimitFinder(ctx, cmd.ID, activeGuild.ID, templateData["User"].(*discordgo.User).ID)
@@ -695,6 +824,7 @@ func handleDeleteCommand(w http.ResponseWriter, r *http.Request) (web.TemplateDa
 	err = DelNextRunEvent(cmd.GuildID, cmd.LocalID)
 	featureflags.MarkGuildDirty(activeGuild.ID)
 	pubsub.EvictCacheSet(cachedCommandsMessage, activeGuild.ID)
+	pubsub.EvictCacheSet(cachedLibraries, activeGuild.ID)
 	return templateData, err
 }
 
@@ -735,6 +865,17 @@ func handleDuplicateCommand(w http.ResponseWriter, r *http.Request) (web.Templat
 		return templateData, web.NewPublicError(fmt.Sprintf("Max %d custom commands allowed (or %d for premium servers)", MaxCommands, MaxCommandsPremium))
 	}
 
+	if cmd.TriggerType == int(CommandTriggerLibrary) {
+		c, err := models.CustomCommands(qm.Where("guild_id = ? AND trigger_type = ?", activeGuild.ID, int(CommandTriggerLibrary))).CountG(ctx)
+		if err != nil {
+			return templateData, err
+		}
+
+		if int(c) >= MaxLibrariesForContext(ctx) {
+			return templateData, web.NewPublicError(fmt.Sprintf("Max %d library custom commands allowed (or %d for premium servers)", MaxLibraries, MaxLibrariesPremium))
+		}
+	}
+
 	localID, err := common.GenLocalIncrID(activeGuild.ID, "custom_command")
 	if err != nil {
 		return templateData, errors.WrapIf(err, "error generating local id")
@@ -767,6 +908,14 @@ func handleDuplicateCommand(w http.ResponseWriter, r *http.Request) (web.Templat
 		TextTrigger:               "duplicate_" + cmd.TextTrigger,
 		TextTriggerCaseSensitive:  cmd.TextTriggerCaseSensitive,
 		TriggerType:               cmd.TriggerType,
+
+		CooldownUser:        cmd.CooldownUser,
+		CooldownChannel:     cmd.CooldownChannel,
+		CooldownGuild:       cmd.CooldownGuild,
+		CooldownBypassRoles: cmd.CooldownBypassRoles,
+		CooldownResponse:    cmd.CooldownResponse,
+		DailyLimitUser:      cmd.DailyLimitUser,
+		DailyLimitGuild:     cmd.DailyLimitGuild,
 	}
 
 	err = dbModel.InsertG(ctx, boil.Blacklist("last_run", "next_run", "last_error", "last_error_time", "run_count"))
@@ -942,6 +1091,19 @@ func handleUpdateGroup(w http.ResponseWriter, r *http.Request) (web.TemplateData
 	return templateData, err
 }
 
+func handleUpdateWebhookConfig(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ctx := r.Context()
+	activeGuild, templateData := web.GetBaseCPContextData(ctx)
+
+	conf := ctx.Value(common.ContextKeyParsedForm).(*WebhookConfig)
+	err := conf.Save(activeGuild.ID)
+	if err == nil {
+		go cplogs.RetryAddEntry(web.NewLogEntryFromContext(r.Context(), panelLogKeyUpdatedWebhookConfig))
+	}
+
+	return templateData, err
+}
+
 func handleDeleteGroup(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
 	ctx := r.Context()
 	activeGuild, templateData := web.GetBaseCPContextData(ctx)
@@ -961,9 +1123,56 @@ func handleDeleteGroup(w http.ResponseWriter, r *http.Request) (web.TemplateData
 	}
 
 	pubsub.EvictCacheSet(cachedCommandsMessage, activeGuild.ID)
+	pubsub.EvictCacheSet(cachedLibraries, activeGuild.ID)
 	return templateData, err
 }
 
+// addLibraryAlerts tells the user which custom commands depend on the library that was just saved,
+// and which of its templates are also provided by other libraries
+func addLibraryAlerts(ctx context.Context, templateData web.TemplateData, library *models.CustomCommand) error {
+	ccs, err := models.CustomCommands(models.CustomCommandWhere.GuildID.EQ(library.GuildID)).AllG(ctx)
+	if err != nil {
+		return err
+	}
+
+	if conflicts := libraryConflicts(ccs, library); len(conflicts) > 0 {
+		templateData.AddAlerts(web.WarningAlert("These templates are also provided by another library, the library with the lowest ID is used: " + strings.Join(conflicts, ", ")))
+	}
+
+	var dependents []string
+	for _, ids := range LibraryDependents(ccs, LibraryTemplateNames(library.Responses[0])) {
+		for _, id := range ids {
+			str := "#" + strconv.FormatInt(id, 10)
+			if !common.ContainsStringSlice(dependents, str) {
+				dependents = append(dependents, str)
+			}
+		}
+	}
+
+	if len(dependents) > 0 {
+		templateData.AddAlerts(web.WarningAlert(fmt.Sprintf("%d custom command(s) use this library and are affected by this change: %s", len(dependents), strings.Join(dependents, ", "))))
+	}
+
+	return nil
+}
+
+const maxLintAlerts = 10
+
+// addLintAlerts runs the template linter on the saved responses and shows the findings as warnings
+func addLintAlerts(templateData web.TemplateData, responses []string) {
+	for _, response := range responses {
+		issues := yagtemplate.Lint(response)
+		for i, issue := range issues {
+			if i >= maxLintAlerts {
+				templateData.AddAlerts(web.WarningAlert(fmt.Sprintf("Lint: %d more issues not shown, use the CCLint command to see all of them", len(issues)-maxLintAlerts)))
+				break
+			}
+
+			templateData.AddAlerts(web.WarningAlert("Lint: " + issue.String()))
+		}
+	}
+}
+
 func triggerTypeFromForm(str string) CommandTriggerType {
 	switch str {
 	case "none":
@@ -980,6 +1189,8 @@ func triggerTypeFromForm(str string) CommandTriggerType {
 		return CommandTriggerCommand
 	case "reaction":
 		return CommandTriggerReaction
+	case "library":
+		return CommandTriggerLibrary
 	case "interval_minutes", "interval_hours":
 		return CommandTriggerInterval
 	default:
@@ -0,0 +1,166 @@
+package customcommands
+
+import (
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/common/mqueue"
+	"github.com/mrbentarikau/pagst/common/multiratelimit"
+	"github.com/mrbentarikau/pagst/common/templates"
+	"github.com/mrbentarikau/pagst/lib/discordgo"
+)
+
+const (
+	webhookUsernameMaxLength = 80
+	webhookMQueueSource      = "customcommands"
+)
+
+var (
+	// webhook messages share the webhook of the channel with the feeds, so keep them from flooding it
+	WebhookMessageLimit        = multiratelimit.NewMultiRatelimiter(0.2, 10)
+	WebhookMessageLimitPremium = multiratelimit.NewMultiRatelimiter(0.5, 25)
+
+	ErrWebhookChannelNotAllowed = errors.New("webhook messages are not enabled in this channel, allow it in the custom commands settings")
+)
+
+func KeyWebhookConfig(guildID int64) string {
+	return "custom_command_webhook_config:" + discordgo.StrID(guildID)
+}
+
+// WebhookConfig controls where sendWebhookMessage can be used
+type WebhookConfig struct {
+	// Empty means webhook messages are disabled
+	WebhookChannels []int64 `json:"webhook_channels" valid:"channel,true"`
+}
+
+func (c *WebhookConfig) Save(guildID int64) error {
+	return common.SetRedisJson(KeyWebhookConfig(guildID), c)
+}
+
+func (c *WebhookConfig) ChannelAllowed(channelID int64) bool {
+	return common.ContainsInt64Slice(c.WebhookChannels, channelID)
+}
+
+// GetWebhookConfig returns the guild's webhook config, or an empty one if not set
+func GetWebhookConfig(guildID int64) (*WebhookConfig, error) {
+	var config *WebhookConfig
+	err := common.GetRedisJson(KeyWebhookConfig(guildID), &config)
+	if err == nil && config == nil {
+		return &WebhookConfig{}, nil
+	}
+
+	return config, err
+}
+
+// tmplSendWebhookMessage sends a message under a custom name and avatar using the channel's webhook.
+// Usage: sendWebhookMessage channel (sdict "username" "name" "avatar_url" "url" "content" "text" "embed" (cembed ...))
+func tmplSendWebhookMessage(ctx *templates.Context) interface{} {
+	return func(channel interface{}, msg interface{}) (interface{}, error) {
+		if ctx.IncreaseCheckCallCounterPremium("send_webhook", 2, 5) || ctx.IncreaseCheckGenericAPICall() {
+			return "", templates.ErrTooManyCalls
+		}
+
+		if ctx.GS == nil {
+			return "", errors.New("no guild to send webhook messages in")
+		}
+
+		cID := ctx.ChannelArg(channel)
+		if cID == 0 {
+			return "", errors.New("unknown channel")
+		}
+
+		cs := ctx.GS.GetChannel(cID)
+		if cs == nil {
+			// threads have no webhooks of their own
+			return "", errors.New("webhook messages can only be sent in text channels")
+		}
+
+		conf, err := GetWebhookConfig(ctx.GS.ID)
+		if err != nil {
+			return "", err
+		}
+
+		if !conf.ChannelAllowed(cID) {
+			return "", ErrWebhookChannelNotAllowed
+		}
+
+		params, err := webhookParamsFromArg(msg)
+		if err != nil {
+			return "", err
+		}
+
+		limiter := WebhookMessageLimit
+		if ctx.IsPremium {
+			limiter = WebhookMessageLimitPremium
+		}
+
+		if !limiter.AllowN(ctx.GS.ID, time.Now(), 1) {
+			return "", errors.New("too many webhook messages sent in this server recently, try again later")
+		}
+
+		m, err := mqueue.SendWebhookMessage(ctx.GS.ID, cID, webhookMQueueSource, params)
+		if err != nil {
+			return "", err
+		}
+
+		ctx.MessagesSent++
+		return m.ID, nil
+	}
+}
+
+// webhookParamsFromArg builds the webhook message from an sdict, mentions are only allowed if explicitly set
+func webhookParamsFromArg(arg interface{}) (*discordgo.WebhookParams, error) {
+	if str, ok := arg.(string); ok {
+		arg = templates.SDict{"content": str}
+	}
+
+	dict, err := templates.StringKeyDictionary(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	params := &discordgo.WebhookParams{
+		AllowedMentions: &discordgo.AllowedMentions{},
+	}
+
+	for k, v := range dict {
+		switch strings.ToLower(k) {
+		case "username":
+			params.Username = common.CutStringShort(templates.ToString(v), webhookUsernameMaxLength)
+		case "avatar_url", "avatar":
+			params.AvatarURL = templates.ToString(v)
+			if params.AvatarURL != "" && !strings.HasPrefix(params.AvatarURL, "https://") {
+				return nil, errors.New("avatar_url has to be a https url")
+			}
+		case "content":
+			params.Content = templates.ToString(v)
+		case "embed", "embeds":
+			send, err := templates.CreateMessageSend("embed", v)
+			if err != nil {
+				return nil, err
+			}
+			params.Embeds = send.Embeds
+		case "allowed_mentions":
+			send, err := templates.CreateMessageSend("allowed_mentions", v)
+			if err != nil {
+				return nil, err
+			}
+			params.AllowedMentions = &send.AllowedMentions
+		default:
+			return nil, errors.New(`invalid key "` + k + `" passed to sendWebhookMessage`)
+		}
+	}
+
+	if strings.TrimSpace(params.Content) == "" && len(params.Embeds) == 0 {
+		return nil, errors.New("webhook message is empty")
+	}
+
+	if lower := strings.ToLower(params.Username); strings.Contains(lower, "discord") || strings.Contains(lower, "clyde") {
+		// discord rejects these
+		return nil, errors.New("webhook username can't contain discord or clyde")
+	}
+
+	return params, nil
+}
@@ -0,0 +1,55 @@
+package customcommands
+
+import (
+	"testing"
+
+	"github.com/mrbentarikau/pagst/common/templates"
+)
+
+func TestWebhookParamsFromArg(t *testing.T) {
+	params, err := webhookParamsFromArg(templates.SDict{
+		"username":   "Gandalf",
+		"avatar_url": "https://example.com/gandalf.png",
+		"content":    "You shall not pass",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if params.Username != "Gandalf" || params.Content != "You shall not pass" || params.AvatarURL != "https://example.com/gandalf.png" {
+		t.Errorf("unexpected params: %#v", params)
+	}
+
+	if params.AllowedMentions == nil || len(params.AllowedMentions.Parse) > 0 {
+		t.Errorf("expected mentions to be disabled by default, got %#v", params.AllowedMentions)
+	}
+
+	params, err = webhookParamsFromArg("hello")
+	if err != nil || params.Content != "hello" {
+		t.Errorf("expected plain string to be used as content, got %#v, %v", params, err)
+	}
+
+	invalid := []templates.SDict{
+		{"username": "Gandalf"},
+		{"content": "hi", "avatar_url": "http://example.com/a.png"},
+		{"content": "hi", "username": "Discord Staff"},
+		{"content": "hi", "tts": true},
+	}
+
+	for _, v := range invalid {
+		if _, err := webhookParamsFromArg(v); err == nil {
+			t.Errorf("expected error for %v", v)
+		}
+	}
+}
+
+func TestWebhookConfigChannelAllowed(t *testing.T) {
+	conf := &WebhookConfig{WebhookChannels: []int64{1, 2}}
+	if !conf.ChannelAllowed(2) || conf.ChannelAllowed(3) {
+		t.Error("unexpected channel restriction result")
+	}
+
+	if (&WebhookConfig{}).ChannelAllowed(1) {
+		t.Error("expected webhook messages to be disabled without channels")
+	}
+}
@@ -0,0 +1,16 @@
+package feeds
+
+import (
+	"regexp"
+	"strings"
+)
+
+// CompileFilterPattern compiles a feed filter, "/regex/" is used as a regex and anything else is
+// matched as a whole word, both ignoring case
+func CompileFilterPattern(pattern string) (*regexp.Regexp, error) {
+	if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		return regexp.Compile("(?i)" + pattern[1:len(pattern)-1])
+	}
+
+	return regexp.MustCompile(`(?i)(^|\W)` + regexp.QuoteMeta(pattern) + `($|\W)`), nil
+}
@@ -0,0 +1,111 @@
+package feeds
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/common/mqueue"
+	"github.com/mrbentarikau/pagst/lib/dstate"
+)
+
+const (
+	MaxForumTagRules      = 50
+	MaxForumTagRuleLength = 200
+)
+
+// ForumTagRule applies a forum tag to the posts of feed items that have the label,
+// labels are things like the categories of an rss item or the flair of a reddit post
+type ForumTagRule struct {
+	Label string
+	Tag   string
+}
+
+// Matches returns whether the rule applies to an item with the labels
+func (r *ForumTagRule) Matches(labels []string) bool {
+	if r.Label == "*" {
+		return true
+	}
+
+	return common.ContainsStringSliceFold(labels, r.Label)
+}
+
+// ParseForumTagMapping parses the forum tag mapping of a feed, one rule per line in the form `label = tag`.
+// A line with only a tag applies it to items with a label of the same name, and a label of * applies the tag to every item.
+func ParseForumTagMapping(mapping string) ([]*ForumTagRule, error) {
+	var rules []*ForumTagRule
+	for _, line := range strings.Split(mapping, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if len(line) > MaxForumTagRuleLength {
+			return nil, fmt.Errorf("rule `%s` is too long (max %d characters)", common.CutStringShort(line, 50), MaxForumTagRuleLength)
+		}
+
+		label, tag, found := strings.Cut(line, "=")
+		if !found {
+			tag = label
+		}
+
+		label = strings.TrimSpace(label)
+		tag = strings.TrimSpace(tag)
+		if label == "" || tag == "" {
+			return nil, fmt.Errorf("rule `%s` needs both a label and a tag", line)
+		}
+
+		rules = append(rules, &ForumTagRule{Label: label, Tag: tag})
+	}
+
+	if len(rules) > MaxForumTagRules {
+		return nil, fmt.Errorf("too many rules (%d/%d)", len(rules), MaxForumTagRules)
+	}
+
+	return rules, nil
+}
+
+// ForumTags returns the tags the mapping applies to an item with the labels, in the order of the rules
+func ForumTags(mapping string, labels []string) []string {
+	// the mapping is validated when it's saved
+	rules, _ := ParseForumTagMapping(mapping)
+
+	var tags []string
+	for _, rule := range rules {
+		if rule.Matches(labels) && !common.ContainsStringSliceFold(tags, rule.Tag) {
+			tags = append(tags, rule.Tag)
+		}
+	}
+
+	return tags
+}
+
+// ForumPost returns the forum post for a feed item, which is only used if the feed posts in a forum channel
+func ForumPost(title, tagMapping string, labels []string) *mqueue.ForumPost {
+	return &mqueue.ForumPost{
+		Title: title,
+		Tags:  ForumTags(tagMapping, labels),
+	}
+}
+
+// ValidateThread checks that the thread a feed posts in exists and belongs to the channel of the feed, 0 posts in the channel itself
+func ValidateThread(gs *dstate.GuildSet, channelID, threadID int64) error {
+	if threadID == 0 {
+		return nil
+	}
+
+	for _, v := range gs.Threads {
+		if v.ID != threadID {
+			continue
+		}
+
+		if v.ParentID != channelID {
+			return errors.New("the thread is not in the channel of the feed")
+		}
+
+		return nil
+	}
+
+	return errors.New("thread not found, it may be archived")
+}
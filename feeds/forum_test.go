@@ -0,0 +1,64 @@
+package feeds
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mrbentarikau/pagst/lib/dstate"
+)
+
+func TestForumTags(t *testing.T) {
+	mapping := `
+News = Announcements
+patch notes = Updates
+Discussion
+* = Feed
+news = Feed
+`
+
+	cases := []struct {
+		Labels   []string
+		Expected []string
+	}{
+		{nil, []string{"Feed"}},
+		{[]string{"NEWS"}, []string{"Announcements", "Feed"}},
+		{[]string{"Patch Notes", "discussion"}, []string{"Updates", "Discussion", "Feed"}},
+		{[]string{"memes"}, []string{"Feed"}},
+	}
+
+	for _, c := range cases {
+		if got := ForumTags(mapping, c.Labels); !reflect.DeepEqual(got, c.Expected) {
+			t.Errorf("%v: got %v, expected %v", c.Labels, got, c.Expected)
+		}
+	}
+}
+
+func TestParseForumTagMappingInvalid(t *testing.T) {
+	for _, mapping := range []string{"= Tag", "label =", " = "} {
+		if _, err := ParseForumTagMapping(mapping); err == nil {
+			t.Errorf("%q: expected an error", mapping)
+		}
+	}
+}
+
+func TestValidateThread(t *testing.T) {
+	gs := &dstate.GuildSet{
+		Threads: []dstate.ChannelState{{ID: 10, ParentID: 1}},
+	}
+
+	if err := ValidateThread(gs, 1, 0); err != nil {
+		t.Errorf("posting in the channel itself should be valid, got %v", err)
+	}
+
+	if err := ValidateThread(gs, 1, 10); err != nil {
+		t.Errorf("thread of the channel should be valid, got %v", err)
+	}
+
+	if err := ValidateThread(gs, 2, 10); err == nil {
+		t.Errorf("expected an error for a thread of another channel")
+	}
+
+	if err := ValidateThread(gs, 1, 11); err == nil {
+		t.Errorf("expected an error for an unknown thread")
+	}
+}
@@ -0,0 +1,40 @@
+package feeds
+
+import (
+	"github.com/mrbentarikau/pagst/common/templates"
+	"github.com/mrbentarikau/pagst/lib/dstate"
+)
+
+// PreviewDisabledFuncs change something on discord or in the database, previews run in the
+// control panel before anything is saved so these are disabled
+var PreviewDisabledFuncs = []string{
+	"addMessageReactions", "addReactions", "addResponseReactions",
+	"addRole", "addRoleID", "addRoleName", "giveRole", "giveRoleID", "giveRoleName",
+	"removeRole", "removeRoleID", "removeRoleName", "takeRole", "takeRoleID", "takeRoleName", "setRoles",
+	"addThreadMember", "removeThreadMember", "createThread", "editThread", "deleteThread",
+	"createForumPost", "deleteForumPost",
+	"cancelScheduledUniqueCC", "scheduleUniqueCC", "execCC", "editCCTriggerType",
+	"exec", "execAdmin", "execBot", "createTicket",
+	"dbSet", "dbSetExpire", "dbIncr", "dbDecr", "dbDel", "dbDelByID", "dbDelMultiple",
+	"deleteAllMessageReactions", "deleteMessage", "deleteMessageReaction", "deleteResponse", "deleteTrigger",
+	"editChannelName", "editChannelTopic", "editMessage", "editMessageNoEscape", "editNickname",
+	"guildMemberMove", "pinMessage", "unpinMessage", "publishMessage", "setMemberTimeout",
+	"sendDM", "sendMessage", "sendMessageNoEscape", "sendMessageNoEscapeRetID", "sendMessageRetID",
+	"sendTargetDM", "sendTemplate", "sendTemplateDM", "sendWebhookMessage",
+	"sleep", "waitForReply",
+}
+
+// PreviewTemplate renders an announcement template with example data for the control panel
+func PreviewTemplate(gs *dstate.GuildSet, cs *dstate.ChannelState, tmpl string, data map[string]interface{}) (string, error) {
+	return previewContext(gs, cs, data).Execute(tmpl)
+}
+
+func previewContext(gs *dstate.GuildSet, cs *dstate.ChannelState, data map[string]interface{}) *templates.Context {
+	ctx := templates.NewContext(gs, cs, nil)
+	ctx.DisabledContextFuncs = PreviewDisabledFuncs
+	for k, v := range data {
+		ctx.Data[k] = v
+	}
+
+	return ctx
+}
@@ -0,0 +1,30 @@
+package feeds
+
+import (
+	"testing"
+
+	"github.com/mrbentarikau/pagst/common/templates"
+)
+
+func init() {
+	// like the custom commands plugin, which sets its funcs directly instead of through addContextFunc
+	templates.RegisterSetupFunc(func(ctx *templates.Context) {
+		ctx.ContextFuncs["dbSet"] = func(userID, key, value interface{}) (string, error) {
+			return "stored", nil
+		}
+	})
+}
+
+func TestPreviewDisabledFuncs(t *testing.T) {
+	if _, err := previewContext(nil, nil, nil).Parse(`{{dbSet 1 "a" "b"}}`); err == nil {
+		t.Errorf("expected dbSet to be unavailable in previews")
+	}
+
+	if _, err := previewContext(nil, nil, nil).Parse(`{{sendMessage nil "hi"}}`); err == nil {
+		t.Errorf("expected sendMessage to be unavailable in previews")
+	}
+
+	if _, err := templates.NewContext(nil, nil, nil).Parse(`{{dbSet 1 "a" "b"}}`); err != nil {
+		t.Errorf("expected dbSet to be available outside of previews, got %v", err)
+	}
+}
@@ -191,6 +191,7 @@
     "reQuoteMeta":true,
     "reReplace":true,
     "reSplit":true,
+    "roll":true,
     "sleep":true,
     "sort":true,
     
@@ -201,6 +202,8 @@
     "execCC":true,
     "parseArgs":true,
     "scheduleUniqueCC":true,
+    "sendWebhookMessage":true,
+    "waitForReply":true,
     
     // templexec
     "exec":true,
@@ -0,0 +1,61 @@
+package integrations
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/mrbentarikau/pagst/bot"
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/common/scheduledevents2"
+	seventsmodels "github.com/mrbentarikau/pagst/common/scheduledevents2/models"
+)
+
+var _ bot.BotInitHandler = (*Plugin)(nil)
+
+func (p *Plugin) BotInit() {
+	scheduledevents2.RegisterHandler("integrations_deliver", int64(0), handleDeliverEvent)
+}
+
+// handleDeliverEvent renders and sends a delivery queued by the web server
+func handleDeliverEvent(evt *seventsmodels.ScheduledEvent, data interface{}) (retry bool, err error) {
+	deliveryID := *data.(*int64)
+
+	var d Delivery
+	err = common.GORM.Where("guild_id = ? AND id = ?", evt.GuildID, deliveryID).First(&d).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			// pushed out of the log by newer deliveries, or the integration was removed
+			return false, nil
+		}
+		return true, err
+	}
+
+	if d.Status != DeliveryStatusQueued {
+		return false, nil
+	}
+
+	i, err := FindIntegration(evt.GuildID, int64(d.IntegrationID))
+	if err != nil {
+		if err == ErrNotFound {
+			return false, nil
+		}
+		return true, err
+	}
+
+	gs := bot.State.GetGuild(evt.GuildID)
+	if gs == nil {
+		// in case the bot left in the meantime
+		if onGuild, err := common.BotIsOnGuild(evt.GuildID); !onGuild && err == nil {
+			d.Status = DeliveryStatusFailed
+			d.Error = "the bot is not on the server"
+			return false, common.GORM.Save(&d).Error
+		} else if err != nil {
+			logger.WithError(err).Error("failed checking if bot is on guild")
+		}
+
+		return true, nil
+	}
+
+	Deliver(i, gs, &d)
+
+	// not retried as the message may have been queued already
+	return false, common.GORM.Save(&d).Error
+}
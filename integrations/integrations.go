@@ -0,0 +1,412 @@
+package integrations
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/common/mqueue"
+	"github.com/mrbentarikau/pagst/common/scheduledevents2"
+	"github.com/mrbentarikau/pagst/common/templates"
+	"github.com/mrbentarikau/pagst/lib/discordgo"
+	"github.com/mrbentarikau/pagst/lib/dstate"
+)
+
+const (
+	MaxIntegrations        = 5
+	MaxIntegrationsPremium = 20
+
+	// How many deliveries are kept per integration for the log and replays
+	MaxDeliveriesPerIntegration = 25
+
+	// Larger payloads are rejected
+	MaxPayloadSize = 256 * 1024
+
+	mqueueSource = "integrations"
+)
+
+var logger = common.GetPluginLogger(&Plugin{})
+
+type Plugin struct{}
+
+func (p *Plugin) PluginInfo() *common.PluginInfo {
+	return &common.PluginInfo{
+		Name:     "Integrations",
+		SysName:  "integrations",
+		Category: common.PluginCategoryFeeds,
+	}
+}
+
+func RegisterPlugin() {
+	err := common.GORM.AutoMigrate(&Integration{}, &Delivery{}).Error
+	if err != nil {
+		panic(err)
+	}
+
+	p := &Plugin{}
+	mqueue.RegisterSource(mqueueSource, p)
+	common.RegisterPlugin(p)
+}
+
+// Integration receives events from another service on a secret URL and posts them in a channel
+type Integration struct {
+	common.SmallModel
+
+	GuildID int64 `gorm:"index"`
+	Name    string
+	Enabled bool
+
+	Provider string
+	// Token is the secret part of the URL events are sent to
+	Token string `gorm:"unique_index"`
+	// SigningSecret verifies the signature of deliveries if set, the URL token is enough otherwise
+	SigningSecret string
+
+	ChannelID int64
+	// Template renders the payload into the message
+	Template string
+
+	// LastError is set when the integration was disabled because of an error sending to discord
+	LastError string
+}
+
+func (i *Integration) TableName() string {
+	return "integrations"
+}
+
+// HookURL returns the URL the service should send events to
+func (i *Integration) HookURL(baseURL string) string {
+	return baseURL + "/integrations/hook/" + i.Token
+}
+
+// Delivery is a received event, kept so the payload can be inspected and replayed
+type Delivery struct {
+	common.SmallModel
+
+	IntegrationID uint  `gorm:"index"`
+	GuildID       int64 `gorm:"index"`
+
+	Event   string
+	Payload string
+
+	Status string
+	Error  string
+	// ReplayOf is the delivery this one replayed, 0 if it was received
+	ReplayOf uint
+}
+
+func (d *Delivery) TableName() string {
+	return "integration_deliveries"
+}
+
+// IndentedPayload returns the payload formatted for reading
+func (d *Delivery) IndentedPayload() string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(d.Payload), "", "  "); err != nil {
+		return d.Payload
+	}
+
+	return buf.String()
+}
+
+const (
+	DeliveryStatusQueued   = "queued"
+	DeliveryStatusSent     = "sent"
+	DeliveryStatusSkipped  = "skipped"
+	DeliveryStatusFailed   = "failed"
+	DeliveryStatusRejected = "rejected"
+)
+
+// Provider is a service that sends events, with its own way of naming and signing them
+type Provider struct {
+	Name        string
+	DisplayName string
+	// EventHeader holds the type of event
+	EventHeader string
+	// SignatureHelp tells the user where to put the signing secret
+	SignatureHelp string
+	// Verify checks that the body was sent by someone knowing the secret
+	Verify func(header http.Header, body []byte, secret string) bool
+}
+
+var Providers = []*Provider{
+	{
+		Name:          "generic",
+		DisplayName:   "Generic JSON",
+		EventHeader:   "X-Event",
+		SignatureHelp: "Signed with HMAC-SHA256 of the body in the X-Signature-256 header, as hex optionally prefixed with sha256=",
+		Verify: func(header http.Header, body []byte, secret string) bool {
+			return verifyHMACSHA256(header.Get("X-Signature-256"), body, secret)
+		},
+	},
+	{
+		Name:          "github",
+		DisplayName:   "GitHub",
+		EventHeader:   "X-GitHub-Event",
+		SignatureHelp: "Use the same secret as the webhook secret on GitHub",
+		Verify: func(header http.Header, body []byte, secret string) bool {
+			return verifyHMACSHA256(header.Get("X-Hub-Signature-256"), body, secret)
+		},
+	},
+	{
+		Name:          "gitlab",
+		DisplayName:   "GitLab",
+		EventHeader:   "X-Gitlab-Event",
+		SignatureHelp: "Use the same secret as the secret token on GitLab, GitLab sends it as is instead of signing",
+		Verify: func(header http.Header, body []byte, secret string) bool {
+			return subtle.ConstantTimeCompare([]byte(header.Get("X-Gitlab-Token")), []byte(secret)) == 1
+		},
+	},
+	{
+		Name:          "gitea",
+		DisplayName:   "Gitea / Forgejo",
+		EventHeader:   "X-Gitea-Event",
+		SignatureHelp: "Use the same secret as the webhook secret on Gitea",
+		Verify: func(header http.Header, body []byte, secret string) bool {
+			return verifyHMACSHA256(header.Get("X-Gitea-Signature"), body, secret)
+		},
+	},
+}
+
+// FindProvider returns the provider with the name, or the generic one if it's unknown
+func FindProvider(name string) *Provider {
+	for _, p := range Providers {
+		if p.Name == name {
+			return p
+		}
+	}
+
+	return Providers[0]
+}
+
+// verifyHMACSHA256 checks a hex HMAC-SHA256 signature of the body, with or without a sha256= prefix
+func verifyHMACSHA256(signature string, body []byte, secret string) bool {
+	signature = strings.TrimPrefix(strings.TrimSpace(signature), "sha256=")
+	decoded, err := hex.DecodeString(signature)
+	if err != nil || len(decoded) == 0 {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(decoded, mac.Sum(nil))
+}
+
+// ErrInvalidSignature is returned for deliveries that fail the signature check
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// VerifyDelivery checks the delivery if the integration has a signing secret
+func VerifyDelivery(i *Integration, header http.Header, body []byte) error {
+	if i.SigningSecret == "" {
+		return nil
+	}
+
+	if !FindProvider(i.Provider).Verify(header, body, i.SigningSecret) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// PayloadJSON returns the JSON of the body, taken from the payload field of form encoded bodies
+// as some services send it that way
+func PayloadJSON(contentType string, body []byte) ([]byte, error) {
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, err
+		}
+		body = []byte(values.Get("payload"))
+	}
+
+	if !json.Valid(body) {
+		return nil, errors.New("the payload is not JSON")
+	}
+
+	return body, nil
+}
+
+// DecodePayload decodes a JSON payload, objects are decoded to sdicts so they work like dicts made in templates
+func DecodePayload(payload []byte) (interface{}, error) {
+	var obj templates.SDict
+	if err := json.Unmarshal(payload, &obj); err == nil {
+		return obj, nil
+	}
+
+	var v interface{}
+	err := json.Unmarshal(payload, &v)
+	return v, err
+}
+
+// Render executes the template of the integration with the payload, returning a nil message if
+// the template chose not to send anything
+func Render(i *Integration, gs *dstate.GuildSet, cs *dstate.ChannelState, d *Delivery) (*discordgo.MessageSend, error) {
+	payload, err := DecodePayload([]byte(d.Payload))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := templates.NewContext(gs, cs, nil)
+	ctx.Name = "integration " + i.Name
+	ctx.Data["Payload"] = payload
+	ctx.Data["Event"] = d.Event
+	ctx.Data["IntegrationName"] = i.Name
+	ctx.Data["DeliveryID"] = d.ID
+
+	content, err := ctx.Execute(i.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := ctx.MessageSend(content)
+	msg.Embeds = append(msg.Embeds, ctx.CurrentFrame.EmbedsToSend...)
+	if strings.TrimSpace(msg.Content) == "" && len(msg.Embeds) == 0 {
+		return nil, nil
+	}
+
+	return msg, nil
+}
+
+// Deliver renders the delivery and queues the message, recording the outcome on the delivery
+func Deliver(i *Integration, gs *dstate.GuildSet, d *Delivery) {
+	cs := gs.GetChannel(i.ChannelID)
+	if cs == nil {
+		d.Status = DeliveryStatusFailed
+		d.Error = "the channel of the integration no longer exists"
+		return
+	}
+
+	msg, err := Render(i, gs, cs, d)
+	if err != nil {
+		d.Status = DeliveryStatusFailed
+		d.Error = common.CutStringShort(err.Error(), 500)
+		return
+	}
+
+	if msg == nil {
+		d.Status = DeliveryStatusSkipped
+		return
+	}
+
+	err = mqueue.QueueMessage(&mqueue.QueuedElement{
+		GuildID:         i.GuildID,
+		ChannelID:       i.ChannelID,
+		Source:          mqueueSource,
+		SourceItemID:    strconv.FormatUint(uint64(i.ID), 10),
+		MessageStr:      msg.Content,
+		MessageEmbeds:   msg.Embeds,
+		AllowedMentions: msg.AllowedMentions,
+		Priority:        2,
+	})
+	if err != nil {
+		d.Status = DeliveryStatusFailed
+		d.Error = "failed queueing the message"
+		logger.WithError(err).WithField("integration", i.ID).Error("failed queueing message")
+		return
+	}
+
+	d.Status = DeliveryStatusSent
+}
+
+// SaveDelivery stores the delivery and removes the oldest ones above MaxDeliveriesPerIntegration
+func SaveDelivery(d *Delivery) error {
+	err := common.GORM.Save(d).Error
+	if err != nil {
+		return err
+	}
+
+	return common.GORM.Exec(`DELETE FROM integration_deliveries WHERE integration_id = ? AND id NOT IN
+		(SELECT id FROM integration_deliveries WHERE integration_id = ? ORDER BY id DESC LIMIT ?)`,
+		d.IntegrationID, d.IntegrationID, MaxDeliveriesPerIntegration).Error
+}
+
+// QueueDelivery stores the delivery and has the bot render and send it, as templates need the state of the bot
+func QueueDelivery(d *Delivery) error {
+	d.Status = DeliveryStatusQueued
+	err := SaveDelivery(d)
+	if err != nil {
+		return err
+	}
+
+	err = scheduledevents2.ScheduleEvent("integrations_deliver", d.GuildID, time.Now(), int64(d.ID))
+	if err != nil {
+		d.Status = DeliveryStatusFailed
+		d.Error = "failed queueing the delivery"
+		common.GORM.Save(d)
+		return err
+	}
+
+	return nil
+}
+
+var ErrNotFound = errors.New("integration not found")
+
+func FindIntegration(guildID int64, id int64) (*Integration, error) {
+	var i Integration
+	err := common.GORM.Where("guild_id = ? AND id = ?", guildID, id).First(&i).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &i, nil
+}
+
+func FindIntegrationByToken(token string) (*Integration, error) {
+	var i Integration
+	err := common.GORM.Where("token = ?", token).First(&i).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &i, nil
+}
+
+func GuildIntegrations(guildID int64) ([]*Integration, error) {
+	var result []*Integration
+	err := common.GORM.Where("guild_id = ?", guildID).Order("id asc").Find(&result).Error
+	return result, err
+}
+
+func IntegrationDeliveries(integrationID uint) ([]*Delivery, error) {
+	var result []*Delivery
+	err := common.GORM.Where("integration_id = ?", integrationID).Order("id desc").Find(&result).Error
+	return result, err
+}
+
+func CountIntegrations(guildID int64) (int, error) {
+	var count int
+	err := common.GORM.Model(&Integration{}).Where("guild_id = ?", guildID).Count(&count).Error
+	return count, err
+}
+
+var _ mqueue.PluginWithSourceDisabler = (*Plugin)(nil)
+
+// DisableFeed disables the integration when discord rejects its messages, like when the channel is gone
+func (p *Plugin) DisableFeed(elem *mqueue.QueuedElement, err error) {
+	id, _ := strconv.ParseInt(elem.SourceItemID, 10, 64)
+	reason := fmt.Sprintf("Disabled after failing to send: %v", err)
+
+	err = common.GORM.Model(&Integration{}).Where("guild_id = ? AND id = ?", elem.GuildID, id).
+		Updates(map[string]interface{}{"enabled": false, "last_error": reason}).Error
+	if err != nil {
+		logger.WithError(err).WithField("integration", id).Error("failed disabling integration")
+	}
+}
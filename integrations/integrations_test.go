@@ -0,0 +1,108 @@
+package integrations
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/mrbentarikau/pagst/common/templates"
+)
+
+func sign(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestProviderVerify(t *testing.T) {
+	body := []byte(`{"action":"opened"}`)
+	const secret = "hunter2"
+
+	cases := []struct {
+		Provider string
+		Header   string
+		Value    string
+		Valid    bool
+	}{
+		{"generic", "X-Signature-256", sign(body, secret), true},
+		{"generic", "X-Signature-256", "sha256=" + sign(body, secret), true},
+		{"generic", "X-Signature-256", sign(body, "wrong"), false},
+		{"generic", "X-Signature-256", "not hex", false},
+		{"generic", "X-Signature-256", "", false},
+		{"github", "X-Hub-Signature-256", "sha256=" + sign(body, secret), true},
+		{"github", "X-Signature-256", "sha256=" + sign(body, secret), false},
+		{"gitlab", "X-Gitlab-Token", secret, true},
+		{"gitlab", "X-Gitlab-Token", "hunter3", false},
+		{"gitea", "X-Gitea-Signature", sign(body, secret), true},
+		{"gitea", "X-Gitea-Signature", sign([]byte(`{}`), secret), false},
+	}
+
+	for i, c := range cases {
+		header := http.Header{}
+		header.Set(c.Header, c.Value)
+
+		err := VerifyDelivery(&Integration{Provider: c.Provider, SigningSecret: secret}, header, body)
+		if (err == nil) != c.Valid {
+			t.Errorf("case %d (%s %s): got %v, expected valid %t", i, c.Provider, c.Header, err, c.Valid)
+		}
+	}
+
+	// without a secret the url is the only check
+	if err := VerifyDelivery(&Integration{Provider: "github"}, http.Header{}, body); err != nil {
+		t.Errorf("expected no check without a secret, got %v", err)
+	}
+}
+
+func TestPayloadJSON(t *testing.T) {
+	cases := []struct {
+		ContentType string
+		Body        string
+		Expected    string
+		Valid       bool
+	}{
+		{"application/json", `{"a":1}`, `{"a":1}`, true},
+		{"application/x-www-form-urlencoded", `payload=%7B%22a%22%3A1%7D`, `{"a":1}`, true},
+		{"application/json", `not json`, "", false},
+		{"application/x-www-form-urlencoded", `other=1`, "", false},
+	}
+
+	for i, c := range cases {
+		payload, err := PayloadJSON(c.ContentType, []byte(c.Body))
+		if (err == nil) != c.Valid {
+			t.Errorf("case %d: got error %v, expected valid %t", i, err, c.Valid)
+			continue
+		}
+
+		if c.Valid && string(payload) != c.Expected {
+			t.Errorf("case %d: got %s, expected %s", i, payload, c.Expected)
+		}
+	}
+}
+
+func TestDecodePayload(t *testing.T) {
+	v, err := DecodePayload([]byte(`{"repository":{"full_name":"a/b"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	obj, ok := v.(templates.SDict)
+	if !ok {
+		t.Fatalf("expected an sdict, got %T", v)
+	}
+
+	repo, ok := obj["repository"].(map[string]interface{})
+	if !ok || repo["full_name"] != "a/b" {
+		t.Errorf("unexpected payload %#v", obj)
+	}
+
+	v, err = DecodePayload([]byte(`[1, 2]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if list, ok := v.([]interface{}); !ok || len(list) != 2 {
+		t.Errorf("expected a list, got %#v", v)
+	}
+}
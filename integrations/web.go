@@ -0,0 +1,397 @@
+package integrations
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/common/cplogs"
+	"github.com/mrbentarikau/pagst/common/multiratelimit"
+	"github.com/mrbentarikau/pagst/lib/discordgo"
+	"github.com/mrbentarikau/pagst/premium"
+	"github.com/mrbentarikau/pagst/web"
+	"goji.io"
+	"goji.io/pat"
+)
+
+//go:embed assets/integrations.html
+var PageHTML string
+
+var (
+	panelLogKeyAdded       = cplogs.RegisterActionFormat(&cplogs.ActionFormat{Key: "integrations_added", FormatString: "Added integration %s"})
+	panelLogKeyUpdated     = cplogs.RegisterActionFormat(&cplogs.ActionFormat{Key: "integrations_updated", FormatString: "Updated integration %s"})
+	panelLogKeyRemoved     = cplogs.RegisterActionFormat(&cplogs.ActionFormat{Key: "integrations_removed", FormatString: "Removed integration %s"})
+	panelLogKeyRegenerated = cplogs.RegisterActionFormat(&cplogs.ActionFormat{Key: "integrations_regenerated", FormatString: "Regenerated the URL of integration %s"})
+	panelLogKeyReplayed    = cplogs.RegisterActionFormat(&cplogs.ActionFormat{Key: "integrations_replayed", FormatString: "Replayed delivery #%d"})
+)
+
+const DefaultTemplate = `New **{{.Event}}** event from {{.IntegrationName}}`
+
+// Events a single integration can receive in a burst, it refills at one per second
+var hookRatelimiter = multiratelimit.NewMultiRatelimiter(1, 10)
+
+type Form struct {
+	Name          string `valid:",1,100,trimspace"`
+	Provider      string
+	ChannelID     int64  `valid:"channel,false"`
+	Template      string `valid:"template,2000"`
+	SigningSecret string `valid:",200,trimspace"`
+	Enabled       bool
+}
+
+func (f *Form) Validate(tmpl web.TemplateData, guildID int64) (ok bool) {
+	if strings.TrimSpace(f.Template) == "" {
+		tmpl.AddAlerts(web.ErrorAlert("The template can't be empty"))
+		return false
+	}
+
+	if FindProvider(f.Provider).Name != f.Provider {
+		tmpl.AddAlerts(web.ErrorAlert("Unknown provider"))
+		return false
+	}
+
+	return true
+}
+
+// IntegrationView is an integration with its URL and delivery log for the control panel
+type IntegrationView struct {
+	*Integration
+	URL         string
+	ChannelName string
+	Deliveries  []*Delivery
+}
+
+func (p *Plugin) InitWeb() {
+	web.AddHTMLTemplate("integrations/assets/integrations.html", PageHTML)
+	web.AddSidebarItem(web.SidebarCategoryFeeds, &web.SidebarItem{
+		Name: "Integrations",
+		URL:  "integrations",
+		Icon: "fas fa-plug",
+	})
+
+	muxer := goji.SubMux()
+	web.CPMux.Handle(pat.New("/integrations/*"), muxer)
+	web.CPMux.Handle(pat.New("/integrations"), muxer)
+
+	muxer.Use(web.RequireBotMemberMW)
+	muxer.Use(web.RequirePermMW(discordgo.PermissionManageWebhooks))
+
+	mainGetHandler := web.ControllerHandler(HandleIntegrations, "cp_integrations")
+
+	muxer.Handle(pat.Get("/"), mainGetHandler)
+	muxer.Handle(pat.Get(""), mainGetHandler)
+
+	newHandler := web.ControllerPostHandler(HandleNew, mainGetHandler, Form{})
+	muxer.Handle(pat.Post(""), newHandler)
+	muxer.Handle(pat.Post("/"), newHandler)
+	muxer.Handle(pat.Post("/:item/update"), web.ControllerPostHandler(BaseEditHandler(HandleEdit), mainGetHandler, Form{}))
+	muxer.Handle(pat.Post("/:item/delete"), web.ControllerPostHandler(BaseEditHandler(HandleDelete), mainGetHandler, nil))
+	muxer.Handle(pat.Post("/:item/regenerate"), web.ControllerPostHandler(BaseEditHandler(HandleRegenerate), mainGetHandler, nil))
+	muxer.Handle(pat.Post("/:item/deliveries/:delivery/replay"), web.ControllerPostHandler(BaseEditHandler(HandleReplay), mainGetHandler, nil))
+
+	// Where the other services send their events
+	web.RootMux.Handle(pat.Post("/integrations/hook/:token"), http.HandlerFunc(HandleHook))
+}
+
+func HandleIntegrations(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ag, templateData := web.GetBaseCPContextData(r.Context())
+
+	integrations, err := GuildIntegrations(ag.ID)
+	if err != nil {
+		return templateData, err
+	}
+
+	views := make([]*IntegrationView, 0, len(integrations))
+	for _, i := range integrations {
+		deliveries, err := IntegrationDeliveries(i.ID)
+		if err != nil {
+			return templateData, err
+		}
+
+		channelName := "deleted-channel"
+		if cs := ag.GetChannel(i.ChannelID); cs != nil {
+			channelName = cs.Name
+		}
+
+		views = append(views, &IntegrationView{
+			Integration: i,
+			URL:         i.HookURL(web.BaseURL()),
+			ChannelName: channelName,
+			Deliveries:  deliveries,
+		})
+	}
+
+	templateData["Integrations"] = views
+	templateData["NewIntegration"] = &IntegrationView{
+		Integration: &Integration{Provider: Providers[0].Name, Template: DefaultTemplate, Enabled: true},
+	}
+	templateData["Providers"] = Providers
+	templateData["MaxIntegrations"] = maxIntegrationsForContext(r.Context())
+
+	return templateData, nil
+}
+
+func HandleNew(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ctx := r.Context()
+	ag, templateData := web.GetBaseCPContextData(ctx)
+	form := ctx.Value(common.ContextKeyParsedForm).(*Form)
+
+	count, err := CountIntegrations(ag.ID)
+	if err != nil {
+		return templateData, err
+	}
+
+	if count >= maxIntegrationsForContext(ctx) {
+		return templateData.AddAlerts(web.ErrorAlert(fmt.Sprintf("Max %d integrations allowed (%d for premium servers)", MaxIntegrations, MaxIntegrationsPremium))), nil
+	}
+
+	i := &Integration{
+		GuildID: ag.ID,
+		Token:   web.RandBase64(24),
+	}
+	applyForm(i, form)
+
+	err = common.GORM.Create(i).Error
+	if err == nil {
+		go cplogs.RetryAddEntry(web.NewLogEntryFromContext(ctx, panelLogKeyAdded, &cplogs.Param{Type: cplogs.ParamTypeString, Value: i.Name}))
+	}
+
+	return templateData, err
+}
+
+type ContextKey int
+
+const (
+	ContextKeyIntegration ContextKey = iota
+)
+
+func BaseEditHandler(inner web.ControllerHandlerFunc) web.ControllerHandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+		ctx := r.Context()
+		ag, templateData := web.GetBaseCPContextData(ctx)
+
+		id, _ := strconv.ParseInt(pat.Param(r, "item"), 10, 64)
+		i, err := FindIntegration(ag.ID, id)
+		if err != nil {
+			return templateData.AddAlerts(web.ErrorAlert("Failed retrieving that integration")), err
+		}
+
+		ctx = context.WithValue(ctx, ContextKeyIntegration, i)
+		return inner(w, r.WithContext(ctx))
+	}
+}
+
+func HandleEdit(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ctx := r.Context()
+	_, templateData := web.GetBaseCPContextData(ctx)
+
+	i := ctx.Value(ContextKeyIntegration).(*Integration)
+	form := ctx.Value(common.ContextKeyParsedForm).(*Form)
+
+	applyForm(i, form)
+	i.LastError = ""
+
+	err := common.GORM.Save(i).Error
+	if err == nil {
+		go cplogs.RetryAddEntry(web.NewLogEntryFromContext(ctx, panelLogKeyUpdated, &cplogs.Param{Type: cplogs.ParamTypeString, Value: i.Name}))
+	}
+
+	return templateData, err
+}
+
+func HandleDelete(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ctx := r.Context()
+	_, templateData := web.GetBaseCPContextData(ctx)
+
+	i := ctx.Value(ContextKeyIntegration).(*Integration)
+
+	err := common.GORM.Where("integration_id = ?", i.ID).Delete(&Delivery{}).Error
+	if err != nil {
+		return templateData, err
+	}
+
+	err = common.GORM.Delete(i).Error
+	if err == nil {
+		go cplogs.RetryAddEntry(web.NewLogEntryFromContext(ctx, panelLogKeyRemoved, &cplogs.Param{Type: cplogs.ParamTypeString, Value: i.Name}))
+	}
+
+	return templateData, err
+}
+
+// HandleRegenerate gives the integration a new URL, for when the old one was leaked
+func HandleRegenerate(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ctx := r.Context()
+	_, templateData := web.GetBaseCPContextData(ctx)
+
+	i := ctx.Value(ContextKeyIntegration).(*Integration)
+	i.Token = web.RandBase64(24)
+
+	err := common.GORM.Model(i).Update("token", i.Token).Error
+	if err == nil {
+		templateData.AddAlerts(web.SucessAlert("Generated a new URL, remember to update it where the events are sent from"))
+		go cplogs.RetryAddEntry(web.NewLogEntryFromContext(ctx, panelLogKeyRegenerated, &cplogs.Param{Type: cplogs.ParamTypeString, Value: i.Name}))
+	}
+
+	return templateData, err
+}
+
+// HandleReplay sends a stored delivery again with the current template, useful for trying out template changes
+func HandleReplay(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ctx := r.Context()
+	_, templateData := web.GetBaseCPContextData(ctx)
+
+	i := ctx.Value(ContextKeyIntegration).(*Integration)
+
+	deliveryID, _ := strconv.ParseInt(pat.Param(r, "delivery"), 10, 64)
+	var original Delivery
+	err := common.GORM.Where("integration_id = ? AND id = ?", i.ID, deliveryID).First(&original).Error
+	if err != nil {
+		return templateData.AddAlerts(web.ErrorAlert("Failed retrieving that delivery")), err
+	}
+
+	if original.Status == DeliveryStatusRejected {
+		return templateData.AddAlerts(web.ErrorAlert("Rejected deliveries can't be replayed")), nil
+	}
+
+	d := &Delivery{
+		IntegrationID: i.ID,
+		GuildID:       i.GuildID,
+		Event:         original.Event,
+		Payload:       original.Payload,
+		ReplayOf:      original.ID,
+	}
+
+	err = QueueDelivery(d)
+	if err != nil {
+		return templateData, err
+	}
+
+	templateData.AddAlerts(web.SucessAlert("Queued the replay, its outcome shows up in the delivery log shortly"))
+
+	go cplogs.RetryAddEntry(web.NewLogEntryFromContext(ctx, panelLogKeyReplayed, &cplogs.Param{Type: cplogs.ParamTypeInt, Value: int64(original.ID)}))
+	return templateData, nil
+}
+
+// HandleHook receives an event for an integration and queues it for the bot to post
+func HandleHook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	i, err := FindIntegrationByToken(pat.Param(r, "token"))
+	if err != nil {
+		if err == ErrNotFound {
+			http.Error(w, "unknown integration", http.StatusNotFound)
+			return
+		}
+
+		web.CtxLogger(ctx).WithError(err).Error("failed retrieving integration")
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	if !i.Enabled {
+		http.Error(w, "integration is disabled", http.StatusForbidden)
+		return
+	}
+
+	if !hookRatelimiter.AllowN(i.ID, time.Now(), 1) {
+		http.Error(w, "too many events", http.StatusTooManyRequests)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, MaxPayloadSize+1))
+	if err != nil {
+		http.Error(w, "failed reading body", http.StatusBadRequest)
+		return
+	}
+
+	if len(body) > MaxPayloadSize {
+		http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	d := &Delivery{
+		IntegrationID: i.ID,
+		GuildID:       i.GuildID,
+		Event:         common.CutStringShort(r.Header.Get(FindProvider(i.Provider).EventHeader), 100),
+	}
+
+	if err := VerifyDelivery(i, r.Header, body); err != nil {
+		// the payload is not kept, it could be anything
+		d.Status = DeliveryStatusRejected
+		d.Error = err.Error()
+		saveHookDelivery(ctx, d)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	payload, err := PayloadJSON(r.Header.Get("Content-Type"), body)
+	if err != nil {
+		d.Status = DeliveryStatusRejected
+		d.Error = err.Error()
+		saveHookDelivery(ctx, d)
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	d.Payload = string(payload)
+
+	if err := QueueDelivery(d); err != nil {
+		web.CtxLogger(ctx).WithError(err).WithField("integration", i.ID).Error("failed queueing delivery")
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	// template errors are shown in the control panel, the sender can't do anything about them
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func saveHookDelivery(ctx context.Context, d *Delivery) {
+	if err := SaveDelivery(d); err != nil {
+		web.CtxLogger(ctx).WithError(err).WithField("integration", d.IntegrationID).Error("failed saving delivery")
+	}
+}
+
+func applyForm(i *Integration, form *Form) {
+	i.Name = form.Name
+	i.Provider = FindProvider(form.Provider).Name
+	i.ChannelID = form.ChannelID
+	i.Template = form.Template
+	i.SigningSecret = form.SigningSecret
+	i.Enabled = form.Enabled
+}
+
+func maxIntegrationsForContext(ctx context.Context) int {
+	if premium.ContextPremium(ctx) {
+		return MaxIntegrationsPremium
+	}
+
+	return MaxIntegrations
+}
+
+var _ web.PluginWithServerHomeWidget = (*Plugin)(nil)
+
+func (p *Plugin) LoadServerHomeWidget(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ag, templateData := web.GetBaseCPContextData(r.Context())
+
+	templateData["WidgetTitle"] = "Integrations"
+	templateData["SettingsPath"] = "/integrations"
+
+	count, err := CountIntegrations(ag.ID)
+	if count > 0 {
+		templateData["WidgetEnabled"] = true
+	} else {
+		templateData["WidgetDisabled"] = true
+	}
+
+	const format = `<p>Integrations: <code>%d</code></p>`
+	templateData["WidgetBody"] = template.HTML(fmt.Sprintf(format, count))
+
+	return templateData, err
+}
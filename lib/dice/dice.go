@@ -44,6 +44,22 @@ func addRollHandler(handler roller) {
 */
 
 func Roll(desc string) (RollResult, string, error) {
+	// expressions are tried first, the first word has to be the expression
+	trimmed := strings.TrimLeft(desc, " \t\r\n")
+	expr := trimmed
+	if i := strings.IndexAny(trimmed, " \t\r\n"); i != -1 {
+		expr = trimmed[:i]
+	}
+
+	result, err := RollExpression(expr)
+	if err == nil {
+		return *result, strings.Trim(trimmed[len(expr):], " \t\r\n"), nil
+	}
+
+	if _, ok := err.(*syntaxError); !ok {
+		return nil, "", err
+	}
+
 	for _, rollHandler := range rollHandlers {
 		rollHandler.Pattern().Longest()
 
@@ -0,0 +1,789 @@
+package dice
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+	Expressions: arithmetic (+ - * / and parentheses) over numbers and dice groups, example: 2d20kh1+1d4+3
+
+	Dice group: [x]d(y|F|%)[modifiers...]
+		F are Fate/Fudge dice (-1, 0 or +1), % is a d100
+	Modifiers, each can be used once per group:
+		k, kh, kl, d, dh, dl z  - keep or drop the highest or lowest z dice
+		ro[cond]                - reroll once if the die matches cond
+		r[cond]                 - reroll until the die doesn't match cond
+		!                       - explode, roll again and add when the die rolls its max
+		cond                    - count successes instead of summing, example: 10d10>=8
+		f[cond]                 - with success counting, subtract failures, example: 10d10>=8f1
+	A cond is an optional comparison (=, <, >, <=, >=, defaults to =) and a number.
+*/
+
+var (
+	// MaxRerolls is how many times a single die can be rerolled or explode
+	MaxRerolls = 100
+	// MaxExpressionLength is the max length of an expression
+	MaxExpressionLength = 200
+
+	maxSides        int64 = 1000000
+	maxNumber       int64 = 1000000000
+	maxValue        int64 = 1000000000000
+	maxParensDepth        = 10
+	maxDiceGroups         = 20
+	errResultTooBig       = errors.New("Result is too big")
+)
+
+// syntaxError means the input isn't an expression at all, so the other roll formats are tried
+type syntaxError struct {
+	msg string
+}
+
+func (e *syntaxError) Error() string { return e.msg }
+
+// ExprResult is the result of an expression roll
+type ExprResult struct {
+	basicRollResult
+	Total int
+	// Breakdown is the expression with every dice group replaced by its rolls
+	Breakdown string
+	Groups    []*DiceGroup
+}
+
+func (r ExprResult) String() string {
+	if len(r.Groups) == 1 && r.Groups[0].String() == r.Breakdown && len(r.Groups[0].Dice) == 1 && !r.Groups[0].hasModifiers() {
+		// a single plain die, the breakdown is just the total again
+		return strconv.Itoa(r.Total)
+	}
+
+	return fmt.Sprintf("%d (%s)", r.Total, r.Breakdown)
+}
+
+func (r ExprResult) Int() int {
+	return r.Total
+}
+
+// Die is a single rolled die in a group
+type Die struct {
+	// Value is the final value of the die, including explosions
+	Value int
+	// Base is the kept roll before explosions
+	Base int
+	// Rerolled are the rolls that were replaced by a reroll, in order
+	Rerolled []int
+	// Explosions are the extra rolls that were added to the die
+	Explosions []int
+
+	Dropped bool
+	Success bool
+	Failure bool
+
+	fate bool
+}
+
+func (d *Die) String() string {
+	var b strings.Builder
+	for _, v := range d.Rerolled {
+		b.WriteString("~~" + d.faceString(v) + "~~ ")
+	}
+
+	value := d.faceString(d.Base)
+	for _, v := range d.Explosions {
+		value += "!" + d.faceString(v)
+	}
+
+	switch {
+	case d.Dropped:
+		value = "~~" + value + "~~"
+	case d.Success:
+		value = "**" + value + "**"
+	case d.Failure:
+		value = "__" + value + "__"
+	}
+
+	b.WriteString(value)
+	return b.String()
+}
+
+func (d *Die) faceString(v int) string {
+	if !d.fate {
+		return strconv.Itoa(v)
+	}
+
+	switch {
+	case v > 0:
+		return "+"
+	case v < 0:
+		return "-"
+	}
+
+	return "0"
+}
+
+// Condition is used for rerolls and success/failure counting
+type Condition struct {
+	Op    string
+	Value int
+}
+
+func (c *Condition) Match(v int) bool {
+	switch c.Op {
+	case "<":
+		return v < c.Value
+	case ">":
+		return v > c.Value
+	case "<=":
+		return v <= c.Value
+	case ">=":
+		return v >= c.Value
+	}
+
+	return v == c.Value
+}
+
+func (c *Condition) String() string {
+	if c.Op == "=" {
+		return strconv.Itoa(c.Value)
+	}
+
+	return c.Op + strconv.Itoa(c.Value)
+}
+
+// DiceGroup is a group of dice in an expression, like 4d6kh3
+type DiceGroup struct {
+	Count int
+	Sides int
+	Fate  bool
+
+	Keep        string
+	KeepNum     int
+	RerollOnce  *Condition
+	RerollUntil *Condition
+	Explode     bool
+	SuccessOn   *Condition
+	FailureOn   *Condition
+
+	Dice []*Die
+	// Value is the sum of the kept dice, or successes minus failures when counting successes
+	Value     int64
+	Successes int
+	Failures  int
+
+	expr string
+}
+
+func (g *DiceGroup) hasModifiers() bool {
+	return g.Keep != "" || g.RerollOnce != nil || g.RerollUntil != nil || g.Explode || g.SuccessOn != nil
+}
+
+func (g *DiceGroup) String() string {
+	dice := make([]string, len(g.Dice))
+	for i, d := range g.Dice {
+		dice[i] = d.String()
+	}
+
+	return g.expr + " [" + strings.Join(dice, ", ") + "]"
+}
+
+func (g *DiceGroup) faces() (min, max int) {
+	if g.Fate {
+		return -1, 1
+	}
+
+	return 1, g.Sides
+}
+
+func (g *DiceGroup) rollOne() int {
+	if g.Fate {
+		return rand.Intn(3) - 1
+	}
+
+	return rand.Intn(g.Sides) + 1
+}
+
+// matchesAllFaces returns true if every face of the die matches the condition
+func (g *DiceGroup) matchesAllFaces(c *Condition) bool {
+	min, max := g.faces()
+	for v := min; v <= max; v++ {
+		if !c.Match(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (g *DiceGroup) validate() error {
+	if g.Count < 1 {
+		return errors.New("Count must be 1 or more")
+	}
+
+	if g.KeepNum < 0 {
+		return errors.New("Can't keep or drop a negative amount of dice")
+	}
+
+	if g.RerollUntil != nil && g.matchesAllFaces(g.RerollUntil) {
+		return errors.New("Reroll condition matches every side of the die")
+	}
+
+	if g.Explode {
+		if min, max := g.faces(); min == max {
+			return errors.New("Can't explode a die with one side")
+		}
+	}
+
+	if g.FailureOn != nil && g.SuccessOn == nil {
+		return errors.New("Failures can only be counted together with successes, example: 10d10>=8f1")
+	}
+
+	return nil
+}
+
+func (g *DiceGroup) roll(st *evalState) error {
+	if err := g.validate(); err != nil {
+		return err
+	}
+
+	st.dice += g.Count
+	if int64(st.dice) > MaxLoop {
+		return ErrTooManyLoops
+	}
+
+	_, max := g.faces()
+	g.Dice = make([]*Die, g.Count)
+	for i := range g.Dice {
+		d := &Die{fate: g.Fate}
+		d.Base = g.rollOne()
+
+		if g.RerollOnce != nil && g.RerollOnce.Match(d.Base) {
+			d.Rerolled = append(d.Rerolled, d.Base)
+			d.Base = g.rollOne()
+		}
+
+		if g.RerollUntil != nil {
+			for g.RerollUntil.Match(d.Base) && len(d.Rerolled) < MaxRerolls {
+				d.Rerolled = append(d.Rerolled, d.Base)
+				d.Base = g.rollOne()
+			}
+		}
+
+		d.Value = d.Base
+		if g.Explode {
+			last := d.Base
+			for last == max && len(d.Explosions) < MaxRerolls {
+				last = g.rollOne()
+				d.Explosions = append(d.Explosions, last)
+				d.Value += last
+			}
+		}
+
+		g.Dice[i] = d
+	}
+
+	g.applyKeep()
+
+	g.Value = 0
+	for _, d := range g.Dice {
+		if d.Dropped {
+			continue
+		}
+
+		if g.SuccessOn == nil {
+			g.Value += int64(d.Value)
+			continue
+		}
+
+		if g.SuccessOn.Match(d.Value) {
+			d.Success = true
+			g.Successes++
+		} else if g.FailureOn != nil && g.FailureOn.Match(d.Value) {
+			d.Failure = true
+			g.Failures++
+		}
+	}
+
+	if g.SuccessOn != nil {
+		g.Value = int64(g.Successes - g.Failures)
+	}
+
+	return nil
+}
+
+func (g *DiceGroup) applyKeep() {
+	if g.Keep == "" {
+		return
+	}
+
+	// sort indexes by value ascending, keeping the roll order for display
+	sorted := make([]int, len(g.Dice))
+	for i := range sorted {
+		sorted[i] = i
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return g.Dice[sorted[i]].Value < g.Dice[sorted[j]].Value })
+
+	n := g.KeepNum
+	if n > len(sorted) {
+		n = len(sorted)
+	}
+	size := len(sorted)
+
+	var drop []int
+	switch g.Keep {
+	case "k", "kh":
+		drop = sorted[:size-n]
+	case "kl":
+		drop = sorted[n:]
+	case "d", "dl":
+		drop = sorted[:n]
+	case "dh":
+		drop = sorted[size-n:]
+	}
+
+	for _, i := range drop {
+		g.Dice[i].Dropped = true
+	}
+}
+
+type evalState struct {
+	dice   int
+	groups []*DiceGroup
+}
+
+type exprNode interface {
+	eval(st *evalState) (int64, error)
+	String() string
+}
+
+type numberNode int64
+
+func (n numberNode) eval(st *evalState) (int64, error) { return int64(n), nil }
+func (n numberNode) String() string                    { return strconv.FormatInt(int64(n), 10) }
+
+type diceNode struct {
+	group *DiceGroup
+}
+
+func (n *diceNode) eval(st *evalState) (int64, error) {
+	if err := n.group.roll(st); err != nil {
+		return 0, err
+	}
+
+	st.groups = append(st.groups, n.group)
+	return n.group.Value, nil
+}
+
+func (n *diceNode) String() string { return n.group.String() }
+
+type negNode struct {
+	inner exprNode
+}
+
+func (n *negNode) eval(st *evalState) (int64, error) {
+	v, err := n.inner.eval(st)
+	return -v, err
+}
+
+func (n *negNode) String() string { return "-" + n.inner.String() }
+
+type parenNode struct {
+	inner exprNode
+}
+
+func (n *parenNode) eval(st *evalState) (int64, error) { return n.inner.eval(st) }
+func (n *parenNode) String() string                    { return "(" + n.inner.String() + ")" }
+
+type binaryNode struct {
+	op          byte
+	left, right exprNode
+}
+
+func (n *binaryNode) eval(st *evalState) (int64, error) {
+	l, err := n.left.eval(st)
+	if err != nil {
+		return 0, err
+	}
+
+	r, err := n.right.eval(st)
+	if err != nil {
+		return 0, err
+	}
+
+	var v int64
+	switch n.op {
+	case '+':
+		v = l + r
+	case '-':
+		v = l - r
+	case '*':
+		if l != 0 && (abs(r) > maxValue/abs(l)) {
+			return 0, errResultTooBig
+		}
+		v = l * r
+	case '/':
+		if r == 0 {
+			return 0, errors.New("Division by zero")
+		}
+		v = l / r
+	}
+
+	if abs(v) > maxValue {
+		return 0, errResultTooBig
+	}
+
+	return v, nil
+}
+
+func (n *binaryNode) String() string {
+	return n.left.String() + " " + string(n.op) + " " + n.right.String()
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// RollExpression parses and rolls a dice expression, the whole input has to be a valid expression
+func RollExpression(expr string) (*ExprResult, error) {
+	root, err := parseExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	st := &evalState{}
+	total, err := root.eval(st)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExprResult{
+		basicRollResult: basicRollResult{expr},
+		Total:           int(total),
+		Breakdown:       root.String(),
+		Groups:          st.groups,
+	}, nil
+}
+
+func parseExpression(expr string) (exprNode, error) {
+	if len(expr) > MaxExpressionLength {
+		return nil, fmt.Errorf("Expression can be max %d characters long", MaxExpressionLength)
+	}
+
+	p := &exprParser{input: strings.ToLower(expr)}
+	root, err := p.parseSum(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.input) {
+		return nil, p.errorf("unexpected %q", p.input[p.pos])
+	}
+
+	if p.groups < 1 {
+		return nil, &syntaxError{"expression has no dice"}
+	}
+
+	return root, nil
+}
+
+type exprParser struct {
+	input  string
+	pos    int
+	groups int
+}
+
+func (p *exprParser) errorf(format string, args ...interface{}) error {
+	return &syntaxError{fmt.Sprintf("col %d: ", p.pos+1) + fmt.Sprintf(format, args...)}
+}
+
+func (p *exprParser) peek() byte {
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *exprParser) consume(s string) bool {
+	if strings.HasPrefix(p.input[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) parseSum(depth int) (exprNode, error) {
+	left, err := p.parseProduct(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op := p.peek()
+		if op != '+' && op != '-' {
+			return left, nil
+		}
+		p.pos++
+
+		right, err := p.parseProduct(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseProduct(depth int) (exprNode, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op := p.peek()
+		if op != '*' && op != '/' {
+			return left, nil
+		}
+		p.pos++
+
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseUnary(depth int) (exprNode, error) {
+	switch c := p.peek(); {
+	case c == '-':
+		p.pos++
+		inner, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		return &negNode{inner: inner}, nil
+	case c == '(':
+		if depth >= maxParensDepth {
+			return nil, fmt.Errorf("Too many nested parentheses, max %d", maxParensDepth)
+		}
+		p.pos++
+		inner, err := p.parseSum(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(")") {
+			return nil, p.errorf("missing )")
+		}
+		return &parenNode{inner: inner}, nil
+	case c == 'd' || isDigit(c):
+		return p.parseOperand()
+	case c == 0:
+		return nil, p.errorf("unexpected end of expression")
+	}
+
+	return nil, p.errorf("unexpected %q", p.peek())
+}
+
+func (p *exprParser) parseOperand() (exprNode, error) {
+	start := p.pos
+
+	count := int64(1)
+	hasCount := isDigit(p.peek())
+	if hasCount {
+		var err error
+		if count, err = p.parseNumber(maxNumber); err != nil {
+			return nil, err
+		}
+	}
+
+	if !p.consume("d") {
+		return numberNode(count), nil
+	}
+
+	p.groups++
+	if p.groups > maxDiceGroups {
+		return nil, fmt.Errorf("Too many dice groups, max %d", maxDiceGroups)
+	}
+
+	if count > MaxLoop {
+		return nil, ErrTooManyLoops
+	}
+
+	g := &DiceGroup{Count: int(count)}
+	switch {
+	case p.consume("f"):
+		g.Fate = true
+	case p.consume("%"):
+		g.Sides = 100
+	default:
+		if !isDigit(p.peek()) {
+			return nil, p.errorf("expected the number of sides")
+		}
+
+		sides, err := p.parseNumber(maxSides)
+		if err != nil {
+			return nil, err
+		}
+		if sides < 1 {
+			return nil, errors.New("Die must have at least one side")
+		}
+		g.Sides = int(sides)
+	}
+
+	if err := p.parseModifiers(g); err != nil {
+		return nil, err
+	}
+
+	g.expr = p.input[start:p.pos]
+	return &diceNode{group: g}, nil
+}
+
+func (p *exprParser) parseModifiers(g *DiceGroup) error {
+	for p.pos < len(p.input) {
+		switch {
+		case p.consumeKeep(g):
+			if g.Keep != "" && g.KeepNum == -1 {
+				return p.errorf("expected a number after %s", g.Keep)
+			}
+		case p.consume("ro"):
+			if g.RerollOnce != nil {
+				return p.errorf("ro can only be used once")
+			}
+			c, err := p.parseCondition(false)
+			if err != nil {
+				return err
+			}
+			g.RerollOnce = c
+		case p.consume("r"):
+			if g.RerollUntil != nil {
+				return p.errorf("r can only be used once")
+			}
+			c, err := p.parseCondition(false)
+			if err != nil {
+				return err
+			}
+			g.RerollUntil = c
+		case p.consume("!"):
+			if g.Explode {
+				return p.errorf("! can only be used once")
+			}
+			g.Explode = true
+		case p.peek() == '<' || p.peek() == '>' || p.peek() == '=':
+			if g.SuccessOn != nil {
+				return p.errorf("successes can only be counted once")
+			}
+			c, err := p.parseCondition(true)
+			if err != nil {
+				return err
+			}
+			g.SuccessOn = c
+		case p.consume("f"):
+			if g.FailureOn != nil {
+				return p.errorf("failures can only be counted once")
+			}
+			c, err := p.parseCondition(false)
+			if err != nil {
+				return err
+			}
+			g.FailureOn = c
+		default:
+			return nil
+		}
+	}
+
+	return nil
+}
+
+var keepModifiers = []string{"kh", "kl", "dh", "dl", "k", "d"}
+
+// consumeKeep parses a keep or drop modifier, KeepNum is set to -1 if the number is missing
+func (p *exprParser) consumeKeep(g *DiceGroup) bool {
+	for _, m := range keepModifiers {
+		if !p.consume(m) {
+			continue
+		}
+
+		if g.Keep != "" {
+			p.pos -= len(m)
+			return false
+		}
+
+		g.Keep = m
+		g.KeepNum = -1
+		if isDigit(p.peek()) {
+			n, err := p.parseNumber(MaxLoop)
+			if err == nil {
+				g.KeepNum = int(n)
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+// parseCondition parses an optional comparison and a number, requireOp is for the success conditions
+func (p *exprParser) parseCondition(requireOp bool) (*Condition, error) {
+	c := &Condition{Op: "="}
+	hasOp := false
+	for _, op := range []string{"<=", ">=", "<", ">", "="} {
+		if p.consume(op) {
+			c.Op = op
+			hasOp = true
+			break
+		}
+	}
+
+	if requireOp && !hasOp {
+		return nil, p.errorf("expected a comparison")
+	}
+
+	// without a comparison a minus is a subtraction, not a negative number
+	negative := hasOp && p.consume("-")
+	if !isDigit(p.peek()) {
+		return nil, p.errorf("expected a number")
+	}
+
+	v, err := p.parseNumber(maxSides)
+	if err != nil {
+		return nil, err
+	}
+	if negative {
+		v = -v
+	}
+
+	c.Value = int(v)
+	return c, nil
+}
+
+func (p *exprParser) parseNumber(max int64) (int64, error) {
+	start := p.pos
+	for isDigit(p.peek()) {
+		p.pos++
+	}
+
+	if start == p.pos {
+		return 0, p.errorf("expected a number")
+	}
+
+	// anything longer can't be below the max anyways
+	if p.pos-start > 12 {
+		return 0, fmt.Errorf("Number too big, max %d", max)
+	}
+
+	v, _ := strconv.ParseInt(p.input[start:p.pos], 10, 64)
+	if v > max {
+		return 0, fmt.Errorf("Number too big, max %d", max)
+	}
+
+	return v, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
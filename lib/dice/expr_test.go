@@ -0,0 +1,188 @@
+package dice_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	. "github.com/mrbentarikau/pagst/lib/dice"
+)
+
+func TestExpression(t *testing.T) {
+	cases := []struct {
+		expr     string
+		min, max int
+	}{
+		{"2d20kh1+1d4+3", 5, 27},
+		{"4df", -4, 4},
+		{"4dF+2", -2, 6},
+		{"d%", 1, 100},
+		{"(1d6+2)*2", 6, 16},
+		{"10d1/3", 3, 3},
+		{"-1d4", -4, -1},
+		{"4d6dl1", 3, 18},
+		{"10d10>=8", 0, 10},
+		{"10d10>=8f1", -10, 10},
+		{"2d6r1", 4, 12},
+		{"2d6ro<3", 2, 12},
+		{"1d6!", 1, 6 * (MaxRerolls + 1)},
+	}
+
+	for _, c := range cases {
+		for i := 0; i < 50; i++ {
+			res, reason, err := Roll(c.expr)
+			if err != nil {
+				t.Fatalf("%s: %v", c.expr, err)
+			}
+
+			if reason != "" {
+				t.Fatalf("%s: unexpected reason %q", c.expr, reason)
+			}
+
+			if _, ok := res.(ExprResult); !ok {
+				t.Fatalf("%s is not an ExprResult", c.expr)
+			}
+
+			if res.Int() < c.min || res.Int() > c.max {
+				t.Fatalf("%s: %d is not between %d and %d", c.expr, res.Int(), c.min, c.max)
+			}
+		}
+	}
+}
+
+func TestExpressionErrors(t *testing.T) {
+	cases := []string{
+		"1d6/0",
+		"1d6r<=6",
+		"1d1!",
+		"1d6f1",
+		"1001d6",
+		"1d6+1d6+1d6+1d6+1d6+1d6+1d6+1d6+1d6+1d6+1d6+1d6+1d6+1d6+1d6+1d6+1d6+1d6+1d6+1d6+1d6",
+		"((((((((((((1d6))))))))))))",
+		"1d6" + strings.Repeat("*1000000", 3),
+	}
+
+	for _, c := range cases {
+		if _, _, err := Roll(c); err == nil {
+			t.Errorf("expected error for %s", c)
+		}
+	}
+}
+
+func TestExpressionBreakdown(t *testing.T) {
+	res, reason, err := Roll("5d1kh2+3 for science")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reason != "for science" {
+		t.Errorf("unexpected reason %q", reason)
+	}
+
+	expected := "5 (5d1kh2 [~~1~~, ~~1~~, ~~1~~, 1, 1] + 3)"
+	if res.String() != expected {
+		t.Errorf("expected %q, got %q", expected, res.String())
+	}
+
+	res, _, _ = Roll("3d1>=1")
+	if res.String() != "3 (3d1>=1 [**1**, **1**, **1**])" {
+		t.Errorf("unexpected success breakdown %q", res.String())
+	}
+
+	res, _, _ = Roll("1d1")
+	if res.String() != "1" {
+		t.Errorf("expected a single die to only show the total, got %q", res.String())
+	}
+}
+
+// the properties that hold for every roll of a valid expression
+func TestExpressionProperties(t *testing.T) {
+	for count := 1; count <= 8; count++ {
+		for sides := 1; sides <= 12; sides++ {
+			for keep := 0; keep <= count+1; keep++ {
+				expr := fmt.Sprintf("%dd%dkh%d", count, sides, keep)
+				res, err := RollExpression(expr)
+				if err != nil {
+					t.Fatalf("%s: %v", expr, err)
+				}
+
+				g := res.Groups[0]
+				kept, sum := 0, 0
+				for _, d := range g.Dice {
+					if d.Value < 1 || d.Value > sides {
+						t.Fatalf("%s: die out of range: %d", expr, d.Value)
+					}
+
+					if !d.Dropped {
+						kept++
+						sum += d.Value
+					}
+				}
+
+				expected := keep
+				if expected > count {
+					expected = count
+				}
+
+				if kept != expected {
+					t.Fatalf("%s: kept %d dice, expected %d", expr, kept, expected)
+				}
+
+				if sum != res.Total {
+					t.Fatalf("%s: total %d doesn't match the kept dice %d", expr, res.Total, sum)
+				}
+
+				// every kept die is at least as high as every dropped one
+				for _, a := range g.Dice {
+					for _, b := range g.Dice {
+						if !a.Dropped && b.Dropped && a.Value < b.Value {
+							t.Fatalf("%s: kept %d but dropped %d", expr, a.Value, b.Value)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	for i := 0; i < 200; i++ {
+		res, err := RollExpression("6d6r<3")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, d := range res.Groups[0].Dice {
+			if d.Value < 3 {
+				t.Fatalf("reroll until kept a %d", d.Value)
+			}
+		}
+
+		res, err = RollExpression("6d6ro1")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, d := range res.Groups[0].Dice {
+			if len(d.Rerolled) > 1 || (len(d.Rerolled) == 1 && d.Rerolled[0] != 1) {
+				t.Fatalf("reroll once rerolled %v", d.Rerolled)
+			}
+		}
+	}
+}
+
+func FuzzRoll(f *testing.F) {
+	seeds := []string{"2d20kh1+1d4+3", "4df", "10d10>=8f1", "1d6!", "(2d6+3)*2", "3d8v3", "1w1b2y", "2d6ro1r<2", "d%"}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, expr string) {
+		res, _, err := Roll(expr)
+		if err != nil {
+			return
+		}
+
+		// results have to be printable
+		_ = res.String()
+		_ = res.Description()
+	})
+}
@@ -81,6 +81,9 @@ func (StdRoller) Roll(matches []string) (RollResult, error) {
 
 	sort.Ints(result.Rolls)
 	size := len(result.Rolls)
+	if num > size {
+		num = size
+	}
 
 	switch keep {
 	case "k":
@@ -2646,6 +2646,22 @@ func (s *Session) WebhookExecute(webhookID int64, token string, wait bool, data
 	return
 }
 
+// WebhookThreadExecute executes a webhook in a thread.
+// webhookID: The ID of a webhook.
+// token    : The auth token for the webhook
+// threadID : The ID of the thread in the channel of the webhook
+func (s *Session) WebhookThreadExecute(webhookID int64, token string, wait bool, threadID int64, data *WebhookParams, options ...RequestOption) (err error) {
+	uri := EndpointWebhookToken(webhookID, token) + "?thread_id=" + StrID(threadID)
+
+	if wait {
+		uri += "&wait=true"
+	}
+
+	_, err = s.RequestWithBucketID("POST", uri, data, nil, EndpointWebhookToken(webhookID, token), options...)
+
+	return
+}
+
 // WebhookExecuteComplex executes a webhook.
 // webhookID: The ID of a webhook.
 // token    : The auth token for the webhook
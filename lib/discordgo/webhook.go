@@ -40,6 +40,9 @@ type WebhookParams struct {
 	// Name of the thread to create.
 	// NOTE: can only be used in forum channels.
 	ThreadName string `json:"thread_name,omitempty"`
+	// Tags to apply to the thread created with ThreadName.
+	// NOTE: can only be used in forum channels.
+	AppliedTags IDSlice `json:"applied_tags,omitempty"`
 	// Only MessageFlagsSuppressEmbeds and MessageFlagsEphemeral can be set.
 	// MessageFlagsEphemeral can only be set when using Followup Message Create endpoint.
 	Flags MessageFlags `json:"flags,omitempty"`
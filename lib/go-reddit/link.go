@@ -36,7 +36,9 @@ type Link struct {
 	Hidden            bool          `json:"hidden"`
 	HideScore         bool          `json:"hide_score"`
 	ID                string        `json:"id"`
+	IsGallery         bool          `json:"is_gallery"`
 	IsSelf            bool          `json:"is_self"`
+	IsVideo           bool          `json:"is_video"`
 	Likes             bool          `json:"likes"`
 	LinkFlairCSSClass string        `json:"link_flair_css_class"`
 	LinkFlairText     string        `json:"link_flair_text"`
@@ -118,6 +120,11 @@ func (c *Client) GetTopLinks(subreddit string) ([]*Link, error) {
 	return c.getLinks(subreddit, "top", "", "")
 }
 
+// GetUserSubmittedLinks retrieves a listing of the newest links submitted by the user.
+func (c *Client) GetUserSubmittedLinks(username string) ([]*Link, error) {
+	return c.fetchLinks(fmt.Sprintf("%s/user/%s/submitted.json?sort=new&limit=100&raw_json=1", baseURL, username))
+}
+
 // HideLink removes the given link from the user's default view of subreddit listings. Requires the 'report' OAuth scope.
 func (c *Client) HideLink(linkID string) error {
 	data := url.Values{}
@@ -152,6 +159,10 @@ func (c *Client) getLinks(subreddit string, sort, before, after string) ([]*Link
 		url += "&after=" + after
 	}
 
+	return c.fetchLinks(url)
+}
+
+func (c *Client) fetchLinks(url string) ([]*Link, error) {
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, err
@@ -6,6 +6,7 @@ package template
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -43,6 +44,9 @@ type state struct {
 	operations  int
 	returnValue reflect.Value
 
+	ctx  context.Context // cancels the execution, nil if it can't be
+	hook CallHook
+
 	parent *state
 }
 
@@ -253,11 +257,26 @@ func (t *Template) ExecuteTemplate(wr io.Writer, name string, data interface{})
 // If data is a reflect.Value, the template applies to the concrete
 // value that the reflect.Value holds, as in fmt.Print.
 func (t *Template) Execute(wr io.Writer, data interface{}) error {
-	return t.execute(wr, data)
+	_, err := t.execute(nil, wr, data)
+	return err
 }
 
-func (t *Template) execute(wr io.Writer, data interface{}) (err error) {
-	defer errRecover(&err)
+// ExecuteCountOps is like Execute but also returns the number of operations
+// the execution used, as counted towards the limit set with MaxOps.
+// The count is only tracked when a limit is set.
+func (t *Template) ExecuteCountOps(wr io.Writer, data interface{}) (int, error) {
+	return t.execute(nil, wr, data)
+}
+
+// ExecuteContext is like ExecuteCountOps but stops with an error once ctx is done,
+// use a context with a deadline to limit how long the execution can run for.
+// The context is checked between operations, so a single long running function
+// call is not interrupted.
+func (t *Template) ExecuteContext(ctx context.Context, wr io.Writer, data interface{}) (int, error) {
+	return t.execute(ctx, wr, data)
+}
+
+func (t *Template) execute(ctx context.Context, wr io.Writer, data interface{}) (ops int, err error) {
 	value, ok := data.(reflect.Value)
 	if !ok {
 		value = reflect.ValueOf(data)
@@ -266,7 +285,13 @@ func (t *Template) execute(wr io.Writer, data interface{}) (err error) {
 		tmpl: t,
 		wr:   wr,
 		vars: []variable{{"$", value}},
+		ctx:  ctx,
+		hook: t.hook,
 	}
+	defer func() {
+		ops = state.operations
+	}()
+	defer errRecover(&err)
 	if t.Tree == nil || t.Root == nil {
 		state.errorf("%q is an incomplete or empty template", t.Name())
 	}
@@ -944,6 +969,13 @@ func (s *state) evalCall(dot, fun reflect.Value, node parse.Node, name string, a
 	if v.Type() == reflectValueType {
 		v = v.Interface().(reflect.Value)
 	}
+	if s.hook != nil {
+		if err := s.hook(name, argv, v); err != nil {
+			// not a funcCallError, so try can't catch it
+			s.at(node)
+			s.errorf("%v", err)
+		}
+	}
 	return v
 }
 
@@ -1258,6 +1290,18 @@ func (s *state) printValue(n parse.Node, v reflect.Value) {
 }
 
 func (s *state) incrOPs(num int) {
+	if s.ctx != nil {
+		select {
+		case <-s.ctx.Done():
+			// the cause is DeadlineExceeded for both a context deadline and one cancelled with that cause
+			if errors.Is(context.Cause(s.ctx), context.DeadlineExceeded) {
+				s.errorf("exceeded max execution time")
+			}
+			s.errorf("execution cancelled")
+		default:
+		}
+	}
+
 	if s.tmpl.maxOps == 0 {
 		return
 	}
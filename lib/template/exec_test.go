@@ -6,6 +6,7 @@ package template
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"flag"
 	"fmt"
@@ -13,6 +14,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 var debug = flag.Bool("debug", false, "show the errors produced by the tests")
@@ -1557,6 +1559,120 @@ func TestMaxExecTemplateDepth(t *testing.T) {
 	}
 }
 
+func TestExecuteCountOps(t *testing.T) {
+	tmpl := Must(New("tmpl").MaxOps(1000).Parse(`{{range .}}{{.}}{{end}}`))
+	ops, err := tmpl.ExecuteCountOps(io.Discard, []int{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ops == 0 {
+		t.Errorf("expected operations to be counted")
+	}
+
+	more, err := tmpl.ExecuteCountOps(io.Discard, []int{1, 2, 3, 4, 5, 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if more <= ops {
+		t.Errorf("expected more operations for a longer range, got %d and %d", ops, more)
+	}
+
+	tmpl = Must(New("tmpl").MaxOps(10).Parse(`{{range .}}{{.}}{{end}}`))
+	ops, err = tmpl.ExecuteCountOps(io.Discard, make([]int, 100))
+	if err == nil || !strings.Contains(err.Error(), "exceeded max operations") {
+		t.Fatalf("expected max operations error, got %v", err)
+	}
+	if ops <= 10 {
+		t.Errorf("expected the operations at the time of the error to be returned, got %d", ops)
+	}
+}
+
+func TestExecuteContext(t *testing.T) {
+	tmpl := Must(New("tmpl").Parse(`{{while true}}{{end}}`))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	ops, err := tmpl.ExecuteContext(ctx, io.Discard, nil)
+	if err == nil || !strings.Contains(err.Error(), "exceeded max execution time") {
+		t.Fatalf("expected max execution time error, got %v", err)
+	}
+	if time.Since(started) > 5*time.Second {
+		t.Errorf("execution was not stopped in time, took %s", time.Since(started))
+	}
+	if ops != 0 {
+		t.Errorf("expected no operations to be counted without a limit, got %d", ops)
+	}
+
+	// errors from a done context can't be caught
+	tmpl = Must(New("tmpl").Parse(`{{try}}{{range 10}}{{.}}{{end}}{{catch}}caught{{end}}`))
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	var buf strings.Builder
+	_, err = tmpl.ExecuteContext(ctx, &buf, nil)
+	if err == nil || !strings.Contains(err.Error(), "execution cancelled") {
+		t.Fatalf("expected cancelled error, got %v", err)
+	}
+	if strings.Contains(buf.String(), "caught") {
+		t.Errorf("cancellation should not be caught by try")
+	}
+
+	// a context that isn't done doesn't affect the result
+	tmpl = Must(New("tmpl").Parse(`{{range 3}}{{.}}{{end}}`))
+	buf.Reset()
+	_, err = tmpl.ExecuteContext(context.Background(), &buf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "012" {
+		t.Errorf("got %q, expected %q", buf.String(), "012")
+	}
+}
+
+func TestCallHook(t *testing.T) {
+	var calls []string
+	budget := 10
+	hook := func(name string, args []reflect.Value, result reflect.Value) error {
+		calls = append(calls, name)
+		if result.Kind() == reflect.String {
+			budget -= result.Len()
+		}
+		if budget < 0 {
+			return errors.New("exceeded budget")
+		}
+		return nil
+	}
+
+	funcs := FuncMap{"repeat": strings.Repeat}
+	tmpl := Must(New("tmpl").Funcs(funcs).Hook(hook).Parse(`{{define "inner"}}{{repeat "a" 3}}{{end}}{{repeat "b" 2}}{{template "inner"}}{{.Method0}}`))
+
+	var buf strings.Builder
+	err := tmpl.Execute(&buf, tVal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "bbaaaM0" {
+		t.Errorf("got %q", buf.String())
+	}
+
+	expected := []string{"repeat", "repeat", "Method0"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("hook saw calls %v, expected %v", calls, expected)
+	}
+
+	// the budget is used up now, returned errors stop the execution and can't be caught
+	tmpl = Must(New("tmpl").Funcs(funcs).Hook(hook).Parse(`{{try}}{{repeat "c" 10}}{{catch}}caught{{end}}`))
+	buf.Reset()
+	err = tmpl.Execute(&buf, nil)
+	if err == nil || !strings.Contains(err.Error(), "exceeded budget") {
+		t.Fatalf("expected budget error, got %v", err)
+	}
+	if strings.Contains(buf.String(), "caught") {
+		t.Errorf("hook errors should not be caught by try")
+	}
+}
+
 func TestAddrOfIndex(t *testing.T) {
 	// golang.org/issue/14916.
 	// Before index worked on reflect.Values, the .String could not be
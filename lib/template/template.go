@@ -33,8 +33,14 @@ type Template struct {
 	leftDelim  string
 	rightDelim string
 	maxOps     int
+	hook       CallHook
 }
 
+// CallHook is called after every successful function and method call with the
+// name, arguments and result of the call. Returning an error stops the execution,
+// the error can't be caught by a try action.
+type CallHook func(name string, args []reflect.Value, result reflect.Value) error
+
 // New allocates a new, undefined template with the given name.
 func New(name string) *Template {
 	t := &Template{
@@ -164,6 +170,15 @@ func (t *Template) MaxOps(ops int) *Template {
 	return t
 }
 
+// Hook sets the function called after every function and method call during
+// execution, templates invoked from this one use it as well.
+// The return value is the template, so calls can be chained.
+func (t *Template) Hook(hook CallHook) *Template {
+	t.init()
+	t.hook = hook
+	return t
+}
+
 // Funcs adds the elements of the argument map to the template's function map.
 // It must be called before the template is parsed.
 // It panics if a value in the map is not a function with appropriate return
@@ -0,0 +1,54 @@
+package de
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/AlekSi/pointer"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+)
+
+func CasualDate(s rules.Strategy) rules.Rule {
+	overwrite := s == rules.Override
+
+	return &rules.F{
+		RegExp: regexp.MustCompile("(?i)(?:\\W|^)(jetzt|heute\\s*nacht|(?:gestern|letzte)\\s*nacht|(?:heute|am|diesen)\\s+morgen|übermorgen|vorgestern|heute|gestern|morgen)(?:\\P{L}|$)"),
+		Applier: func(m *rules.Match, c *rules.Context, o *rules.Options, ref time.Time) (bool, error) {
+			lower := strings.ToLower(strings.TrimSpace(m.String()))
+
+			switch {
+			case strings.Contains(lower, "heute") && strings.Contains(lower, "nacht"):
+				if c.Hour == nil && c.Minute == nil || overwrite {
+					c.Hour = pointer.ToInt(23)
+					c.Minute = pointer.ToInt(0)
+				}
+			case strings.Contains(lower, "nacht"):
+				if (c.Hour == nil && c.Duration == 0) || overwrite {
+					c.Hour = pointer.ToInt(23)
+					c.Duration -= time.Hour * 24
+				}
+			case strings.Contains(lower, " morgen"), strings.Contains(lower, "heute"):
+				// "heute morgen" is this morning, the time is left for CasualTime
+			case strings.Contains(lower, "übermorgen"):
+				if c.Duration == 0 || overwrite {
+					c.Duration += time.Hour * 48
+				}
+			case strings.Contains(lower, "vorgestern"):
+				if c.Duration == 0 || overwrite {
+					c.Duration -= time.Hour * 48
+				}
+			case strings.Contains(lower, "morgen"):
+				if c.Duration == 0 || overwrite {
+					c.Duration += time.Hour * 24
+				}
+			case strings.Contains(lower, "gestern"):
+				if c.Duration == 0 || overwrite {
+					c.Duration -= time.Hour * 24
+				}
+			}
+
+			return true, nil
+		},
+	}
+}
@@ -0,0 +1,63 @@
+package de_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+	"github.com/mrbentarikau/pagst/lib/when/rules/de"
+)
+
+func TestCasualDate(t *testing.T) {
+	fixt := []Fixture{
+		{"Die Frist ist jetzt, ok", 14, "jetzt", 0},
+		{"Die Frist ist heute", 14, "heute", 0},
+		{"Die Frist ist heute Nacht", 14, "heute Nacht", 23 * time.Hour},
+		{"Die Frist ist morgen Abend", 14, "morgen", time.Hour * 24},
+		{"Die Frist ist übermorgen", 14, "übermorgen", time.Hour * 48},
+		{"Die Frist war gestern Abend", 14, "gestern", -(time.Hour * 24)},
+		{"Die Frist war vorgestern", 14, "vorgestern", -(time.Hour * 48)},
+		{"Die Frist war heute Morgen", 14, "heute Morgen", 0},
+	}
+
+	w := when.New(nil)
+	w.Add(de.CasualDate(rules.Skip))
+
+	ApplyFixtures(t, "de.CasualDate", w, fixt)
+}
+
+func TestCasualTime(t *testing.T) {
+	fixt := []Fixture{
+		{"Die Frist war heute Morgen ", 14, "heute Morgen", 8 * time.Hour},
+		{"Die Frist war heute Mittag ", 14, "heute Mittag", 12 * time.Hour},
+		{"Die Frist war heute Nachmittag ", 14, "heute Nachmittag", 15 * time.Hour},
+		{"Die Frist war heute Abend ", 14, "heute Abend", 18 * time.Hour},
+		{"Die Frist ist morgen früh ", 21, "früh", 8 * time.Hour},
+	}
+
+	w := when.New(nil)
+	w.Add(de.CasualTime(rules.Skip))
+
+	ApplyFixtures(t, "de.CasualTime", w, fixt)
+
+	// a plain "morgen" is tomorrow, not the morning
+	ApplyFixturesNil(t, "de.CasualTime nil", w, []Fixture{
+		{"Die Frist ist morgen", 0, "", 0},
+	})
+}
+
+func TestCasualDateCasualTime(t *testing.T) {
+	fixt := []Fixture{
+		{"Die Frist ist morgen Nachmittag ", 14, "morgen Nachmittag", (15 + 24) * time.Hour},
+		{"Die Frist ist morgen früh ", 14, "morgen früh", (8 + 24) * time.Hour},
+	}
+
+	w := when.New(nil)
+	w.Add(
+		de.CasualDate(rules.Skip),
+		de.CasualTime(rules.Override),
+	)
+
+	ApplyFixtures(t, "de.CasualDate|de.CasualTime", w, fixt)
+}
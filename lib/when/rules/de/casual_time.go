@@ -0,0 +1,60 @@
+package de
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/AlekSi/pointer"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+)
+
+// a plain "morgen" means tomorrow, so the morning needs a prefix: "heute morgen", "am Morgen"
+func CasualTime(s rules.Strategy) rules.Rule {
+	overwrite := s == rules.Override
+
+	return &rules.F{
+		RegExp: regexp.MustCompile(`(?i)(?:\W|^)((?:heute|diesen|am)\s+morgen|(?:(?:heute|diesen|am)\s*)?(?:morgens|früh|vormittags?|nachmittags?|mittags?|abends?))(?:\P{L}|$)`),
+		Applier: func(m *rules.Match, c *rules.Context, o *rules.Options, ref time.Time) (bool, error) {
+
+			lower := strings.ToLower(strings.TrimSpace(m.String()))
+
+			if (c.Hour != nil || c.Minute != nil) && !overwrite {
+				return false, nil
+			}
+
+			switch {
+			case strings.Contains(lower, "nachmittag"):
+				if o.Afternoon != 0 {
+					c.Hour = &o.Afternoon
+				} else {
+					c.Hour = pointer.ToInt(15)
+				}
+				c.Minute = pointer.ToInt(0)
+			case strings.Contains(lower, "abend"):
+				if o.Evening != 0 {
+					c.Hour = &o.Evening
+				} else {
+					c.Hour = pointer.ToInt(18)
+				}
+				c.Minute = pointer.ToInt(0)
+			case strings.Contains(lower, "morgen"), strings.Contains(lower, "früh"), strings.Contains(lower, "vormittag"):
+				if o.Morning != 0 {
+					c.Hour = &o.Morning
+				} else {
+					c.Hour = pointer.ToInt(8)
+				}
+				c.Minute = pointer.ToInt(0)
+			case strings.Contains(lower, "mittag"):
+				if o.Noon != 0 {
+					c.Hour = &o.Noon
+				} else {
+					c.Hour = pointer.ToInt(12)
+				}
+				c.Minute = pointer.ToInt(0)
+			}
+
+			return true, nil
+		},
+	}
+}
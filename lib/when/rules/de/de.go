@@ -0,0 +1,126 @@
+package de
+
+import "github.com/mrbentarikau/pagst/lib/when/rules"
+
+var All = []rules.Rule{
+	Weekday(rules.Override),
+	CasualDate(rules.Override),
+	CasualTime(rules.Override),
+	Hour(rules.Override),
+	HourMinute(rules.Override),
+	Deadline(rules.Override),
+	PastTime(rules.Override),
+	ExactMonthDate(rules.Override),
+}
+
+var WEEKDAY_OFFSET = map[string]int{
+	"sonntag":    0,
+	"montag":     1,
+	"dienstag":   2,
+	"mittwoch":   3,
+	"donnerstag": 4,
+	"freitag":    5,
+	"samstag":    6,
+	"sonnabend":  6,
+}
+
+var WEEKDAY_OFFSET_PATTERN = "(?:sonntag|montag|dienstag|mittwoch|donnerstag|freitag|samstag|sonnabend)"
+
+var MONTH_OFFSET = map[string]int{
+	"januar":    1,
+	"jänner":    1,
+	"jan":       1,
+	"jan.":      1,
+	"februar":   2,
+	"feb":       2,
+	"feb.":      2,
+	"märz":      3,
+	"maerz":     3,
+	"mär":       3,
+	"mär.":      3,
+	"april":     4,
+	"apr":       4,
+	"apr.":      4,
+	"mai":       5,
+	"juni":      6,
+	"jun":       6,
+	"jun.":      6,
+	"juli":      7,
+	"jul":       7,
+	"jul.":      7,
+	"august":    8,
+	"aug":       8,
+	"aug.":      8,
+	"september": 9,
+	"sep":       9,
+	"sep.":      9,
+	"sept":      9,
+	"sept.":     9,
+	"oktober":   10,
+	"okt":       10,
+	"okt.":      10,
+	"november":  11,
+	"nov":       11,
+	"nov.":      11,
+	"dezember":  12,
+	"dez":       12,
+	"dez.":      12,
+}
+
+var MONTH_OFFSET_PATTERN = `(?:januar|jänner|jan\.?|februar|feb\.?|märz|maerz|mär\.?|april|apr\.?|mai|juni|jun\.?|juli|jul\.?|august|aug\.?|september|sept?\.?|oktober|okt\.?|november|nov\.?|dezember|dez\.?)`
+
+var INTEGER_WORDS = map[string]int{
+	"eins":   1,
+	"zwei":   2,
+	"drei":   3,
+	"vier":   4,
+	"fünf":   5,
+	"sechs":  6,
+	"sieben": 7,
+	"acht":   8,
+	"neun":   9,
+	"zehn":   10,
+	"elf":    11,
+	"zwölf":  12,
+}
+
+var INTEGER_WORDS_PATTERN = `(?:eins|zwei|drei|vier|fünf|sechs|sieben|acht|neun|zehn|elf|zwölf)`
+
+// ORDINAL_WORDS holds the stems of the ordinals, they're declined with
+// an -e, -en, -er, -em or -es ending: "dritte", "am dritten", "der dritter"
+var ORDINAL_WORDS = map[string]int{
+	"erst":               1,
+	"zweit":              2,
+	"dritt":              3,
+	"viert":              4,
+	"fünft":              5,
+	"sechst":             6,
+	"siebt":              7,
+	"acht":               8,
+	"neunt":              9,
+	"zehnt":              10,
+	"elft":               11,
+	"zwölft":             12,
+	"dreizehnt":          13,
+	"vierzehnt":          14,
+	"fünfzehnt":          15,
+	"sechzehnt":          16,
+	"siebzehnt":          17,
+	"achtzehnt":          18,
+	"neunzehnt":          19,
+	"zwanzigst":          20,
+	"einundzwanzigst":    21,
+	"zweiundzwanzigst":   22,
+	"dreiundzwanzigst":   23,
+	"vierundzwanzigst":   24,
+	"fünfundzwanzigst":   25,
+	"sechsundzwanzigst":  26,
+	"siebenundzwanzigst": 27,
+	"achtundzwanzigst":   28,
+	"neunundzwanzigst":   29,
+	"dreißigst":          30,
+	"einunddreißigst":    31,
+}
+
+// longer stems sharing a prefix with a shorter one come first ("achtzehnt" before "acht")
+var ORDINAL_WORDS_PATTERN = `(?:erst|zweiundzwanzigst|zweit|dreiundzwanzigst|dreizehnt|dreißigst|dritt|vierundzwanzigst|vierzehnt|viert|fünfundzwanzigst|fünfzehnt|fünft|sechsundzwanzigst|sechzehnt|sechst|siebenundzwanzigst|siebzehnt|siebt|achtundzwanzigst|achtzehnt|acht|neunundzwanzigst|neunzehnt|neunt|zehnt|elft|zwölft|zwanzigst|einundzwanzigst|einunddreißigst)`
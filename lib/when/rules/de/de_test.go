@@ -0,0 +1,65 @@
+package de_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when"
+	"github.com/mrbentarikau/pagst/lib/when/rules/de"
+	"github.com/stretchr/testify/require"
+)
+
+var null = time.Date(2016, time.January, 6, 0, 0, 0, 0, time.UTC)
+
+type Fixture struct {
+	Text   string
+	Index  int
+	Phrase string
+	Diff   time.Duration
+}
+
+func ApplyFixtures(t *testing.T, name string, w *when.Parser, fixt []Fixture) {
+	for i, f := range fixt {
+		res, err := w.Parse(f.Text, null)
+		require.Nil(t, err, "[%s] err #%d", name, i)
+		require.NotNil(t, res, "[%s] res #%d", name, i)
+		require.Equal(t, f.Index, res.Index, "[%s] index #%d", name, i)
+		require.Equal(t, f.Phrase, res.Text, "[%s] text #%d", name, i)
+		require.Equal(t, f.Diff, res.Time.Sub(null), "[%s] diff #%d", name, i)
+	}
+}
+
+func ApplyFixturesNil(t *testing.T, name string, w *when.Parser, fixt []Fixture) {
+	for i, f := range fixt {
+		res, err := w.Parse(f.Text, null)
+		require.Nil(t, err, "[%s] err #%d", name, i)
+		require.Nil(t, res, "[%s] res #%d", name, i)
+	}
+}
+
+func ApplyFixturesErr(t *testing.T, name string, w *when.Parser, fixt []Fixture) {
+	for i, f := range fixt {
+		_, err := w.Parse(f.Text, null)
+		require.NotNil(t, err, "[%s] err #%d", name, i)
+		require.Equal(t, f.Phrase, err.Error(), "[%s] err text #%d", name, i)
+	}
+}
+
+func TestAll(t *testing.T) {
+	w := when.New(nil)
+	w.Add(de.All...)
+
+	// complex cases
+	fixt := []Fixture{
+		{"heute Nacht um 23:10", 0, "heute Nacht um 23:10", (23 * time.Hour) + (10 * time.Minute)},
+		{"am Freitag Nachmittag", 3, "Freitag Nachmittag", ((2 * 24) + 15) * time.Hour},
+		{"am nächsten Dienstag um 14:00", 3, "nächsten Dienstag um 14:00", ((6 * 24) + 14) * time.Hour},
+		{"am nächsten Dienstag um 2 Uhr nachmittags", 3, "nächsten Dienstag um 2 Uhr nachmittags", ((6 * 24) + 14) * time.Hour},
+		{"am nächsten Mittwoch um 2:25 nachmittags", 3, "nächsten Mittwoch um 2:25 nachmittags", (((7 * 24) + 14) * time.Hour) + (25 * time.Minute)},
+		{"um 11 Uhr letzten Dienstag", 3, "11 Uhr letzten Dienstag", -13 * time.Hour},
+		{"morgen früh um 9 Uhr", 0, "morgen früh um 9 Uhr", 33 * time.Hour},
+		{"übermorgen Abend", 0, "übermorgen Abend", 66 * time.Hour},
+	}
+
+	ApplyFixtures(t, "de.All...", w, fixt)
+}
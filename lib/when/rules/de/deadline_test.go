@@ -0,0 +1,34 @@
+package de_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+	"github.com/mrbentarikau/pagst/lib/when/rules/de"
+)
+
+func TestDeadline(t *testing.T) {
+	fixt := []Fixture{
+		{"innerhalb einer halben Stunde", 0, "innerhalb einer halben Stunde", time.Hour / 2},
+		{"innerhalb von 1 Stunde", 0, "innerhalb von 1 Stunde", time.Hour},
+		{"in 5 Minuten", 0, "in 5 Minuten", time.Minute * 5},
+		{"In 5 Minuten gehe ich nach Hause", 0, "In 5 Minuten", time.Minute * 5},
+		{"wir müssen das binnen 10 Tagen erledigen.", 16, "binnen 10 Tagen", 10 * 24 * time.Hour},
+		{"wir müssen das in fünf Tagen erledigen.", 16, "in fünf Tagen", 5 * 24 * time.Hour},
+		{"wir müssen das in 5 Tagen erledigen.", 16, "in 5 Tagen", 5 * 24 * time.Hour},
+		{"In 5 Sekunden muss das Auto weg", 0, "In 5 Sekunden", 5 * time.Second},
+		{"innerhalb von zwei Wochen", 0, "innerhalb von zwei Wochen", 14 * 24 * time.Hour},
+		{"innerhalb eines Monats", 0, "innerhalb eines Monats", 31 * 24 * time.Hour},
+		{"in einem Monat", 0, "in einem Monat", 31 * 24 * time.Hour},
+		{"in ein paar Monaten", 0, "in ein paar Monaten", 91 * 24 * time.Hour},
+		{"in einem Jahr", 0, "in einem Jahr", 366 * 24 * time.Hour},
+		{"in einer Woche", 0, "in einer Woche", 7 * 24 * time.Hour},
+	}
+
+	w := when.New(nil)
+	w.Add(de.Deadline(rules.Skip))
+
+	ApplyFixtures(t, "de.Deadline", w, fixt)
+}
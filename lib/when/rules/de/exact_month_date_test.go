@@ -0,0 +1,39 @@
+package de_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+	"github.com/mrbentarikau/pagst/lib/when/rules/de"
+)
+
+func TestExactMonthDate(t *testing.T) {
+	w := when.New(nil)
+	w.Add(de.ExactMonthDate(rules.Override))
+
+	fixtok := []Fixture{
+		{"dritter März", 0, "dritter März", 1368 * time.Hour},
+		{"am dritten März", 3, "dritten März", 1368 * time.Hour},
+		{"3. März", 0, "3. März", 1368 * time.Hour},
+		{"3 März", 0, "3 März", 1368 * time.Hour},
+		{"3. Maerz", 0, "3. Maerz", 1368 * time.Hour},
+		{"1 September", 0, "1 September", 5736 * time.Hour},
+		{"1 sept", 0, "1 sept", 5736 * time.Hour},
+		{"1. Sept.", 0, "1. Sept.", 5736 * time.Hour},
+		{"ersten September", 0, "ersten September", 5736 * time.Hour},
+		{"7. März", 0, "7. März", 1464 * time.Hour},
+		{"einundzwanzigsten Oktober", 0, "einundzwanzigsten Oktober", 6936 * time.Hour},
+		{"zwanzigster Dezember", 0, "zwanzigster Dezember", 8376 * time.Hour},
+		{"achtzehnten März", 0, "achtzehnten März", 1728 * time.Hour},
+		{"10. März", 0, "10. März", 1536 * time.Hour},
+		{"4. Jan.", 0, "4. Jan.", -48 * time.Hour},
+		{"Februar", 0, "Februar", 744 * time.Hour},
+		{"Oktober", 0, "Oktober", 6576 * time.Hour},
+		{"Jul.", 0, "Jul.", 4368 * time.Hour},
+		{"Juni", 0, "Juni", 3648 * time.Hour},
+	}
+
+	ApplyFixtures(t, "de.ExactMonthDate", w, fixtok)
+}
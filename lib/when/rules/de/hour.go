@@ -0,0 +1,88 @@
+package de
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+)
+
+/*
+	"5 Uhr"
+	"17 Uhr"
+	"um 5 Uhr nachmittags"
+	"um fünf Uhr abends"
+	"2 Uhr nachts"
+*/
+
+var PERIOD_PATTERN = `(?:morgens|früh|vormittags|nachmittags|abends|nachts)`
+
+// applyPeriod moves the hour to the afternoon for the periods that need it,
+// "nachts" is only the evening for hours after six: "11 Uhr nachts", but "2 Uhr nachts"
+func applyPeriod(hour int, period string) (int, bool) {
+	if period == "" {
+		return hour, hour < 24
+	}
+
+	if hour > 12 {
+		return 0, false
+	}
+
+	switch strings.ToLower(period) {
+	case "nachmittags", "abends":
+		if hour < 12 {
+			hour += 12
+		}
+	case "nachts":
+		if hour >= 6 && hour < 12 {
+			hour += 12
+		}
+	}
+
+	return hour, true
+}
+
+func Hour(s rules.Strategy) rules.Rule {
+
+	return &rules.F{
+		RegExp: regexp.MustCompile("(?i)(?:\\W|^)" +
+			"(" + INTEGER_WORDS_PATTERN + "|ein|\\d{1,2})" +
+			"\\s*(uhr)" +
+			"(?:\\s*(" + PERIOD_PATTERN + "))?" +
+			"(?:\\P{L}|$)"),
+		Applier: func(m *rules.Match, c *rules.Context, o *rules.Options, ref time.Time) (bool, error) {
+			if c.Hour != nil && s != rules.Override {
+				return false, nil
+			}
+
+			var hour int
+			var err error
+
+			lower := strings.ToLower(m.Captures[0])
+			if n, ok := INTEGER_WORDS[lower]; ok {
+				hour = n
+			} else if lower == "ein" {
+				hour = 1
+			} else {
+				hour, err = strconv.Atoi(m.Captures[0])
+				if err != nil {
+					return false, errors.Wrap(err, "hour rule")
+				}
+			}
+
+			hour, ok := applyPeriod(hour, m.Captures[2])
+			if !ok {
+				return false, nil
+			}
+
+			zero := 0
+			c.Hour = &hour
+			c.Minute = &zero
+			return true, nil
+		},
+	}
+}
@@ -0,0 +1,71 @@
+package de
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+	"github.com/pkg/errors"
+)
+
+/*
+	{"17:30", 0, "17:30", 0},
+	{"17:30 Uhr", 0, "17:30 Uhr", 0},
+	{"17.30 Uhr", 0, "17.30 Uhr", 0},
+	{"5:30 nachmittags", 0, "5:30 nachmittags", 0},
+
+	a dot only separates the hour and minutes when followed by "Uhr",
+	otherwise "06.01." would be read as a time
+*/
+
+// 1. - int
+// 2. - int, separated with a colon
+// 3. - uhr?
+// 4. - int, separated with a dot
+// 5. - uhr
+// 6. - ext?
+
+func HourMinute(s rules.Strategy) rules.Rule {
+	return &rules.F{
+		RegExp: regexp.MustCompile("(?i)(?:\\W|^)" +
+			"((?:[0-1]{0,1}[0-9])|(?:2[0-3]))" +
+			"(?:(?:\\:|：)((?:[0-5][0-9]))(?:\\s*(uhr))?|\\.((?:[0-5][0-9]))\\s*(uhr))" +
+			"(?:\\s*(" + PERIOD_PATTERN + "))?" +
+			"(?:\\P{L}|$)"),
+		Applier: func(m *rules.Match, c *rules.Context, o *rules.Options, ref time.Time) (bool, error) {
+			if (c.Hour != nil || c.Minute != nil) && s != rules.Override {
+				return false, nil
+			}
+
+			hour, err := strconv.Atoi(m.Captures[0])
+			if err != nil {
+				return false, errors.Wrap(err, "hour minute rule")
+			}
+
+			minutesStr := m.Captures[1]
+			if minutesStr == "" {
+				minutesStr = m.Captures[3]
+			}
+
+			minutes, err := strconv.Atoi(minutesStr)
+			if err != nil {
+				return false, errors.Wrap(err, "hour minute rule")
+			}
+
+			if minutes > 59 {
+				return false, nil
+			}
+
+			hour, ok := applyPeriod(hour, m.Captures[5])
+			if !ok {
+				return false, nil
+			}
+
+			c.Minute = &minutes
+			c.Hour = &hour
+
+			return true, nil
+		},
+	}
+}
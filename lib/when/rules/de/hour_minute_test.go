@@ -0,0 +1,47 @@
+package de_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+	"github.com/mrbentarikau/pagst/lib/when/rules/de"
+)
+
+func TestHourMinute(t *testing.T) {
+	w := when.New(nil)
+	w.Add(de.HourMinute(rules.Override))
+
+	fixtok := []Fixture{
+		{"17:30", 0, "17:30", (17 * time.Hour) + (30 * time.Minute)},
+		{"um 17:30 Uhr", 3, "17:30 Uhr", (17 * time.Hour) + (30 * time.Minute)},
+		{"um 17.59 Uhr", 3, "17.59 Uhr", (17 * time.Hour) + (59 * time.Minute)},
+		{"um 5:59 nachmittags", 3, "5:59 nachmittags", (17 * time.Hour) + (59 * time.Minute)},
+		{"um 17:59 nachher", 3, "17:59", (17 * time.Hour) + (59 * time.Minute)},
+		{"bis 11:10 abends", 4, "11:10 abends", (23 * time.Hour) + (10 * time.Minute)},
+	}
+
+	fixtnil := []Fixture{
+		{"28:30 Uhr", 0, "", 0},
+		{"12:61 Uhr", 0, "", 0},
+		{"24:10", 0, "", 0},
+		{"am 06.01.", 0, "", 0},
+	}
+
+	ApplyFixtures(t, "de.HourMinute", w, fixtok)
+	ApplyFixturesNil(t, "de.HourMinute nil", w, fixtnil)
+
+	w.Add(de.Hour(rules.Skip))
+	ApplyFixtures(t, "de.HourMinute|de.Hour", w, fixtok)
+	ApplyFixturesNil(t, "de.HourMinute|de.Hour nil", w, fixtnil)
+
+	w = when.New(nil)
+	w.Add(
+		de.Hour(rules.Override),
+		de.HourMinute(rules.Override),
+	)
+
+	ApplyFixtures(t, "de.Hour|de.HourMinute", w, fixtok)
+	ApplyFixturesNil(t, "de.Hour|de.HourMinute nil", w, fixtnil)
+}
@@ -0,0 +1,35 @@
+package de_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+	"github.com/mrbentarikau/pagst/lib/when/rules/de"
+)
+
+func TestHour(t *testing.T) {
+	fixt := []Fixture{
+		{"17 Uhr", 0, "17 Uhr", 17 * time.Hour},
+		{"um 17 Uhr", 3, "17 Uhr", 17 * time.Hour},
+		{"um 5 Uhr nachmittags", 3, "5 Uhr nachmittags", 17 * time.Hour},
+		{"um 12 Uhr mittags", 3, "12 Uhr", 12 * time.Hour},
+		{"um 1 Uhr nachmittags", 3, "1 Uhr nachmittags", 13 * time.Hour},
+		{"um 5 Uhr morgens", 3, "5 Uhr morgens", 5 * time.Hour},
+		{"um fünf Uhr abends", 3, "fünf Uhr abends", 17 * time.Hour},
+		{"um ein Uhr", 3, "ein Uhr", 1 * time.Hour},
+		{"2 Uhr nachts", 0, "2 Uhr nachts", 2 * time.Hour},
+		{"11 Uhr nachts", 0, "11 Uhr nachts", 23 * time.Hour},
+	}
+
+	w := when.New(nil)
+	w.Add(de.Hour(rules.Override))
+
+	ApplyFixtures(t, "de.Hour", w, fixt)
+
+	ApplyFixturesNil(t, "de.Hour nil", w, []Fixture{
+		{"25 Uhr", 0, "", 0},
+		{"17 Uhr abends", 0, "", 0},
+	})
+}
@@ -0,0 +1,33 @@
+package de_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+	"github.com/mrbentarikau/pagst/lib/when/rules/de"
+)
+
+func TestPastTime(t *testing.T) {
+	fixt := []Fixture{
+		{"vor einer halben Stunde", 0, "vor einer halben Stunde", -(time.Hour / 2)},
+		{"vor 1 Stunde", 0, "vor 1 Stunde", -(time.Hour)},
+		{"vor 5 Minuten", 0, "vor 5 Minuten", -(time.Minute * 5)},
+		{"vor 5 Minuten war ich im Zoo", 0, "vor 5 Minuten", -(time.Minute * 5)},
+		{"wir haben das vor 10 Tagen gemacht.", 14, "vor 10 Tagen", -(10 * 24 * time.Hour)},
+		{"wir haben das vor fünf Tagen gemacht.", 14, "vor fünf Tagen", -(5 * 24 * time.Hour)},
+		{"wir haben das vor 5 Tagen gemacht.", 14, "vor 5 Tagen", -(5 * 24 * time.Hour)},
+		{"vor 5 Sekunden wurde ein Auto bewegt", 0, "vor 5 Sekunden", -(5 * time.Second)},
+		{"vor zwei Wochen", 0, "vor zwei Wochen", -(14 * 24 * time.Hour)},
+		{"vor einem Monat", 0, "vor einem Monat", -(31 * 24 * time.Hour)},
+		{"vor ein paar Monaten", 0, "vor ein paar Monaten", -(92 * 24 * time.Hour)},
+		{"vor einem Jahr", 0, "vor einem Jahr", -(365 * 24 * time.Hour)},
+		{"vor einer Woche", 0, "vor einer Woche", -(7 * 24 * time.Hour)},
+	}
+
+	w := when.New(nil)
+	w.Add(de.PastTime(rules.Skip))
+
+	ApplyFixtures(t, "de.PastTime", w, fixt)
+}
@@ -0,0 +1,38 @@
+package de_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+	"github.com/mrbentarikau/pagst/lib/when/rules/de"
+)
+
+func TestWeekday(t *testing.T) {
+	// current is Wednesday
+	fixt := []Fixture{
+		// letzte/vergangene
+		{"mach es für letzten Montag", 13, "letzten Montag", -(2 * 24 * time.Hour)},
+		{"letzten Samstag", 0, "letzten Samstag", -(4 * 24 * time.Hour)},
+		{"vergangenen Freitag", 0, "vergangenen Freitag", -(5 * 24 * time.Hour)},
+		{"letzten Mittwoch", 0, "letzten Mittwoch", -(7 * 24 * time.Hour)},
+		{"letzten Dienstag", 0, "letzten Dienstag", -(24 * time.Hour)},
+		{"Dienstag letzter Woche", 0, "Dienstag letzter Woche", -(24 * time.Hour)},
+		// nächste/kommende
+		{"nächsten Dienstag", 0, "nächsten Dienstag", 6 * 24 * time.Hour},
+		{"schreib mir am nächsten Mittwoch", 15, "nächsten Mittwoch", 7 * 24 * time.Hour},
+		{"kommenden Samstag", 0, "kommenden Samstag", 3 * 24 * time.Hour},
+		{"am Sonnabend", 3, "Sonnabend", 3 * 24 * time.Hour},
+		// diese
+		{"diesen Dienstag", 0, "diesen Dienstag", -(24 * time.Hour)},
+		{"schreib mir an diesem Mittwoch", 15, "diesem Mittwoch", 0},
+		{"diesen Samstag", 0, "diesen Samstag", 3 * 24 * time.Hour},
+	}
+
+	w := when.New(nil)
+
+	w.Add(de.Weekday(rules.Override))
+
+	ApplyFixtures(t, "de.Weekday", w, fixt)
+}
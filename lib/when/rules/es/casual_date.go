@@ -0,0 +1,54 @@
+package es
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/AlekSi/pointer"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+)
+
+func CasualDate(s rules.Strategy) rules.Rule {
+	overwrite := s == rules.Override
+
+	return &rules.F{
+		RegExp: regexp.MustCompile("(?i)(?:\\W|^)(ahora|esta\\s*noche|anoche|pasado\\s*mañana|anteayer|antier|(?:esta|la|por\\s+la)\\s+mañana|hoy|ayer|mañana)(?:\\P{L}|$)"),
+		Applier: func(m *rules.Match, c *rules.Context, o *rules.Options, ref time.Time) (bool, error) {
+			lower := strings.ToLower(strings.TrimSpace(m.String()))
+
+			switch {
+			case strings.HasPrefix(lower, "esta") && strings.Contains(lower, "noche"):
+				if c.Hour == nil && c.Minute == nil || overwrite {
+					c.Hour = pointer.ToInt(23)
+					c.Minute = pointer.ToInt(0)
+				}
+			case strings.Contains(lower, "anoche"):
+				if (c.Hour == nil && c.Duration == 0) || overwrite {
+					c.Hour = pointer.ToInt(23)
+					c.Duration -= time.Hour * 24
+				}
+			case strings.Contains(lower, "pasado"):
+				if c.Duration == 0 || overwrite {
+					c.Duration += time.Hour * 48
+				}
+			case strings.Contains(lower, "anteayer"), strings.Contains(lower, "antier"):
+				if c.Duration == 0 || overwrite {
+					c.Duration -= time.Hour * 48
+				}
+			case strings.Contains(lower, " mañana"), strings.Contains(lower, "hoy"):
+				// "esta mañana" is this morning, the time is left for CasualTime
+			case strings.Contains(lower, "mañana"):
+				if c.Duration == 0 || overwrite {
+					c.Duration += time.Hour * 24
+				}
+			case strings.Contains(lower, "ayer"):
+				if c.Duration == 0 || overwrite {
+					c.Duration -= time.Hour * 24
+				}
+			}
+
+			return true, nil
+		},
+	}
+}
@@ -0,0 +1,63 @@
+package es_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+	"github.com/mrbentarikau/pagst/lib/when/rules/es"
+)
+
+func TestCasualDate(t *testing.T) {
+	fixt := []Fixture{
+		{"El plazo es ahora, ok", 12, "ahora", 0},
+		{"El plazo es hoy", 12, "hoy", 0},
+		{"El plazo es esta noche", 12, "esta noche", 23 * time.Hour},
+		{"El plazo es mañana por la tarde", 12, "mañana", time.Hour * 24},
+		{"El plazo es pasado mañana", 12, "pasado mañana", time.Hour * 48},
+		{"El plazo fue ayer por la tarde", 13, "ayer", -(time.Hour * 24)},
+		{"El plazo fue anteayer", 13, "anteayer", -(time.Hour * 48)},
+		{"El plazo fue anoche", 13, "anoche", -time.Hour},
+		{"El plazo fue esta mañana", 13, "esta mañana", 0},
+	}
+
+	w := when.New(nil)
+	w.Add(es.CasualDate(rules.Skip))
+
+	ApplyFixtures(t, "es.CasualDate", w, fixt)
+}
+
+func TestCasualTime(t *testing.T) {
+	fixt := []Fixture{
+		{"El plazo fue esta mañana ", 13, "esta mañana", 8 * time.Hour},
+		{"El plazo fue al mediodía ", 13, "al mediodía", 12 * time.Hour},
+		{"El plazo fue esta tarde ", 13, "esta tarde", 15 * time.Hour},
+		{"El plazo fue por la noche ", 13, "por la noche", 18 * time.Hour},
+	}
+
+	w := when.New(nil)
+	w.Add(es.CasualTime(rules.Skip))
+
+	ApplyFixtures(t, "es.CasualTime", w, fixt)
+
+	// a plain "mañana" is tomorrow, not the morning
+	ApplyFixturesNil(t, "es.CasualTime nil", w, []Fixture{
+		{"El plazo es mañana", 0, "", 0},
+	})
+}
+
+func TestCasualDateCasualTime(t *testing.T) {
+	fixt := []Fixture{
+		{"El plazo es mañana por la tarde ", 12, "mañana por la tarde", (15 + 24) * time.Hour},
+		{"El plazo es mañana por la mañana ", 12, "mañana por la mañana", (8 + 24) * time.Hour},
+	}
+
+	w := when.New(nil)
+	w.Add(
+		es.CasualDate(rules.Skip),
+		es.CasualTime(rules.Override),
+	)
+
+	ApplyFixtures(t, "es.CasualDate|es.CasualTime", w, fixt)
+}
@@ -0,0 +1,60 @@
+package es
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/AlekSi/pointer"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+)
+
+// a plain "mañana" means tomorrow, so the morning needs a prefix: "esta mañana", "por la mañana"
+func CasualTime(s rules.Strategy) rules.Rule {
+	overwrite := s == rules.Override
+
+	return &rules.F{
+		RegExp: regexp.MustCompile(`(?i)(?:\W|^)((?:esta|por\s+la|en\s+la|a\s+la)\s+(?:mañana|tarde)|(?:por|en|a)\s+la\s+noche|(?:al\s+)?mediod[ií]a)(?:\P{L}|$)`),
+		Applier: func(m *rules.Match, c *rules.Context, o *rules.Options, ref time.Time) (bool, error) {
+
+			lower := strings.ToLower(strings.TrimSpace(m.String()))
+
+			if (c.Hour != nil || c.Minute != nil) && !overwrite {
+				return false, nil
+			}
+
+			switch {
+			case strings.Contains(lower, "tarde"):
+				if o.Afternoon != 0 {
+					c.Hour = &o.Afternoon
+				} else {
+					c.Hour = pointer.ToInt(15)
+				}
+				c.Minute = pointer.ToInt(0)
+			case strings.Contains(lower, "noche"):
+				if o.Evening != 0 {
+					c.Hour = &o.Evening
+				} else {
+					c.Hour = pointer.ToInt(18)
+				}
+				c.Minute = pointer.ToInt(0)
+			case strings.Contains(lower, "mañana"):
+				if o.Morning != 0 {
+					c.Hour = &o.Morning
+				} else {
+					c.Hour = pointer.ToInt(8)
+				}
+				c.Minute = pointer.ToInt(0)
+			case strings.Contains(lower, "mediod"):
+				if o.Noon != 0 {
+					c.Hour = &o.Noon
+				} else {
+					c.Hour = pointer.ToInt(12)
+				}
+				c.Minute = pointer.ToInt(0)
+			}
+
+			return true, nil
+		},
+	}
+}
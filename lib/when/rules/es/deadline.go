@@ -0,0 +1,123 @@
+package es
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AlekSi/pointer"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+	"github.com/pkg/errors"
+)
+
+// NUMBER_PATTERN matches the amounts used with durations, "un par de", "unos" or "media" included
+var NUMBER_PATTERN = "(?:un\\s+par\\s+de|unos\\s+pocos|unas\\s+pocas|unos|unas|algunos|algunas|pocos|pocas|medio|media|" +
+	INTEGER_WORDS_PATTERN[3:len(INTEGER_WORDS_PATTERN)-1] + "|[0-9]+)"
+
+var UNIT_PATTERN = "(?:segundos?|min(?:uto)?s?|horas?|días?|dias?|semanas?|mes(?:es)?|años?)"
+
+// parseAmount returns the amount for a NUMBER_PATTERN match, half is true for "media" and "medio"
+func parseAmount(numStr string) (num int, half bool, err error) {
+	numStr = strings.ToLower(numStr)
+
+	if n, ok := INTEGER_WORDS[numStr]; ok {
+		return n, false, nil
+	}
+
+	switch {
+	case strings.HasPrefix(numStr, "medi"):
+		return 0, true, nil
+	case strings.Contains(numStr, "par"):
+		return 2, false, nil
+	case strings.HasPrefix(numStr, "un"), strings.HasPrefix(numStr, "algun"), strings.HasPrefix(numStr, "poc"):
+		return 3, false, nil
+	}
+
+	num, err = strconv.Atoi(numStr)
+	if err != nil {
+		return 0, false, errors.Wrapf(err, "convert '%s' to int", numStr)
+	}
+
+	return num, false, nil
+}
+
+func Deadline(s rules.Strategy) rules.Rule {
+	overwrite := s == rules.Override
+
+	return &rules.F{
+		RegExp: regexp.MustCompile(
+			"(?i)(?:\\W|^)(dentro\\s+de|en)\\s*" +
+				"(" + NUMBER_PATTERN + ")\\s*" +
+				"(" + UNIT_PATTERN + ")\\s*" +
+				"(?:\\P{L}|$)"),
+		Applier: func(m *rules.Match, c *rules.Context, o *rules.Options, ref time.Time) (bool, error) {
+
+			num, half, err := parseAmount(strings.TrimSpace(m.Captures[1]))
+			if err != nil {
+				return false, err
+			}
+
+			exponent := strings.ToLower(strings.TrimSpace(m.Captures[2]))
+
+			if !half {
+				switch {
+				case strings.Contains(exponent, "segundo"):
+					if c.Duration == 0 || overwrite {
+						c.Duration = time.Duration(num) * time.Second
+					}
+				case strings.Contains(exponent, "min"):
+					if c.Duration == 0 || overwrite {
+						c.Duration = time.Duration(num) * time.Minute
+					}
+				case strings.Contains(exponent, "hora"):
+					if c.Duration == 0 || overwrite {
+						c.Duration = time.Duration(num) * time.Hour
+					}
+				case strings.Contains(exponent, "día"), strings.Contains(exponent, "dia"):
+					if c.Duration == 0 || overwrite {
+						c.Duration = time.Duration(num) * 24 * time.Hour
+					}
+				case strings.Contains(exponent, "semana"):
+					if c.Duration == 0 || overwrite {
+						c.Duration = time.Duration(num) * 7 * 24 * time.Hour
+					}
+				case strings.Contains(exponent, "mes"):
+					if c.Month == nil || overwrite {
+						c.Month = pointer.ToInt((int(ref.Month()) + num) % 12)
+					}
+				case strings.Contains(exponent, "año"):
+					if c.Year == nil || overwrite {
+						c.Year = pointer.ToInt(ref.Year() + num)
+					}
+				}
+			} else {
+				switch {
+				case strings.Contains(exponent, "hora"):
+					if c.Duration == 0 || overwrite {
+						c.Duration = 30 * time.Minute
+					}
+				case strings.Contains(exponent, "día"), strings.Contains(exponent, "dia"):
+					if c.Duration == 0 || overwrite {
+						c.Duration = 12 * time.Hour
+					}
+				case strings.Contains(exponent, "semana"):
+					if c.Duration == 0 || overwrite {
+						c.Duration = 7 * 12 * time.Hour
+					}
+				case strings.Contains(exponent, "mes"):
+					if c.Duration == 0 || overwrite {
+						// 2 weeks
+						c.Duration = 14 * 24 * time.Hour
+					}
+				case strings.Contains(exponent, "año"):
+					if c.Month == nil || overwrite {
+						c.Month = pointer.ToInt((int(ref.Month()) + 6) % 12)
+					}
+				}
+			}
+
+			return true, nil
+		},
+	}
+}
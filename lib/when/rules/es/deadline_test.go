@@ -0,0 +1,34 @@
+package es_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+	"github.com/mrbentarikau/pagst/lib/when/rules/es"
+)
+
+func TestDeadline(t *testing.T) {
+	fixt := []Fixture{
+		{"dentro de media hora", 0, "dentro de media hora", time.Hour / 2},
+		{"dentro de 1 hora", 0, "dentro de 1 hora", time.Hour},
+		{"en 5 minutos", 0, "en 5 minutos", time.Minute * 5},
+		{"En 5 minutos me voy a casa", 0, "En 5 minutos", time.Minute * 5},
+		{"tenemos que hacer algo dentro de 10 días.", 23, "dentro de 10 días", 10 * 24 * time.Hour},
+		{"tenemos que hacer algo en cinco días.", 23, "en cinco días", 5 * 24 * time.Hour},
+		{"tenemos que hacer algo en 5 días.", 23, "en 5 días", 5 * 24 * time.Hour},
+		{"En 5 segundos hay que mover un coche", 0, "En 5 segundos", 5 * time.Second},
+		{"dentro de dos semanas", 0, "dentro de dos semanas", 14 * 24 * time.Hour},
+		{"dentro de un mes", 0, "dentro de un mes", 31 * 24 * time.Hour},
+		{"en unos meses", 0, "en unos meses", 91 * 24 * time.Hour},
+		{"en un par de días", 0, "en un par de días", 2 * 24 * time.Hour},
+		{"dentro de un año", 0, "dentro de un año", 366 * 24 * time.Hour},
+		{"en una semana", 0, "en una semana", 7 * 24 * time.Hour},
+	}
+
+	w := when.New(nil)
+	w.Add(es.Deadline(rules.Skip))
+
+	ApplyFixtures(t, "es.Deadline", w, fixt)
+}
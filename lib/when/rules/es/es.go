@@ -0,0 +1,101 @@
+package es
+
+import "github.com/mrbentarikau/pagst/lib/when/rules"
+
+var All = []rules.Rule{
+	Weekday(rules.Override),
+	CasualDate(rules.Override),
+	CasualTime(rules.Override),
+	Hour(rules.Override),
+	HourMinute(rules.Override),
+	Deadline(rules.Override),
+	PastTime(rules.Override),
+	ExactMonthDate(rules.Override),
+}
+
+var WEEKDAY_OFFSET = map[string]int{
+	"domingo":   0,
+	"lunes":     1,
+	"martes":    2,
+	"miércoles": 3,
+	"miercoles": 3,
+	"jueves":    4,
+	"viernes":   5,
+	"sábado":    6,
+	"sabado":    6,
+}
+
+var WEEKDAY_OFFSET_PATTERN = "(?:domingo|lunes|martes|miércoles|miercoles|jueves|viernes|sábado|sabado)"
+
+var MONTH_OFFSET = map[string]int{
+	"enero":      1,
+	"ene":        1,
+	"ene.":       1,
+	"febrero":    2,
+	"feb":        2,
+	"feb.":       2,
+	"marzo":      3,
+	"mar":        3,
+	"mar.":       3,
+	"abril":      4,
+	"abr":        4,
+	"abr.":       4,
+	"mayo":       5,
+	"may":        5,
+	"may.":       5,
+	"junio":      6,
+	"jun":        6,
+	"jun.":       6,
+	"julio":      7,
+	"jul":        7,
+	"jul.":       7,
+	"agosto":     8,
+	"ago":        8,
+	"ago.":       8,
+	"septiembre": 9,
+	"setiembre":  9,
+	"sep":        9,
+	"sep.":       9,
+	"sept":       9,
+	"sept.":      9,
+	"octubre":    10,
+	"oct":        10,
+	"oct.":       10,
+	"noviembre":  11,
+	"nov":        11,
+	"nov.":       11,
+	"diciembre":  12,
+	"dic":        12,
+	"dic.":       12,
+}
+
+var MONTH_OFFSET_PATTERN = `(?:enero|ene\.?|febrero|feb\.?|marzo|mar\.?|abril|abr\.?|mayo|may\.?|junio|jun\.?|julio|jul\.?|agosto|ago\.?|septiembre|setiembre|sept?\.?|octubre|oct\.?|noviembre|nov\.?|diciembre|dic\.?)`
+
+var INTEGER_WORDS = map[string]int{
+	"una":    1,
+	"uno":    1,
+	"un":     1,
+	"dos":    2,
+	"tres":   3,
+	"cuatro": 4,
+	"cinco":  5,
+	"seis":   6,
+	"siete":  7,
+	"ocho":   8,
+	"nueve":  9,
+	"diez":   10,
+	"once":   11,
+	"doce":   12,
+}
+
+var INTEGER_WORDS_PATTERN = `(?:una|uno|un|dos|tres|cuatro|cinco|seis|siete|ocho|nueve|diez|once|doce)`
+
+// days of the month are cardinal numbers in spanish, only the first one has an ordinal form
+var ORDINAL_WORDS = map[string]int{
+	"primero": 1,
+	"1º":      1,
+	"1°":      1,
+	"1ro":     1,
+}
+
+var ORDINAL_WORDS_PATTERN = `(?:primero|1º|1°|1ro)`
@@ -0,0 +1,65 @@
+package es_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when"
+	"github.com/mrbentarikau/pagst/lib/when/rules/es"
+	"github.com/stretchr/testify/require"
+)
+
+var null = time.Date(2016, time.January, 6, 0, 0, 0, 0, time.UTC)
+
+type Fixture struct {
+	Text   string
+	Index  int
+	Phrase string
+	Diff   time.Duration
+}
+
+func ApplyFixtures(t *testing.T, name string, w *when.Parser, fixt []Fixture) {
+	for i, f := range fixt {
+		res, err := w.Parse(f.Text, null)
+		require.Nil(t, err, "[%s] err #%d", name, i)
+		require.NotNil(t, res, "[%s] res #%d", name, i)
+		require.Equal(t, f.Index, res.Index, "[%s] index #%d", name, i)
+		require.Equal(t, f.Phrase, res.Text, "[%s] text #%d", name, i)
+		require.Equal(t, f.Diff, res.Time.Sub(null), "[%s] diff #%d", name, i)
+	}
+}
+
+func ApplyFixturesNil(t *testing.T, name string, w *when.Parser, fixt []Fixture) {
+	for i, f := range fixt {
+		res, err := w.Parse(f.Text, null)
+		require.Nil(t, err, "[%s] err #%d", name, i)
+		require.Nil(t, res, "[%s] res #%d", name, i)
+	}
+}
+
+func ApplyFixturesErr(t *testing.T, name string, w *when.Parser, fixt []Fixture) {
+	for i, f := range fixt {
+		_, err := w.Parse(f.Text, null)
+		require.NotNil(t, err, "[%s] err #%d", name, i)
+		require.Equal(t, f.Phrase, err.Error(), "[%s] err text #%d", name, i)
+	}
+}
+
+func TestAll(t *testing.T) {
+	w := when.New(nil)
+	w.Add(es.All...)
+
+	// complex cases
+	fixt := []Fixture{
+		{"esta noche a las 23:10", 0, "esta noche a las 23:10", (23 * time.Hour) + (10 * time.Minute)},
+		{"el viernes por la tarde", 3, "viernes por la tarde", ((2 * 24) + 15) * time.Hour},
+		{"el próximo martes a las 14:00", 3, "próximo martes a las 14:00", ((6 * 24) + 14) * time.Hour},
+		{"el próximo martes a las 2 de la tarde", 3, "próximo martes a las 2 de la tarde", ((6 * 24) + 14) * time.Hour},
+		{"el próximo miércoles a las 2:25 p. m.", 3, "próximo miércoles a las 2:25 p. m.", (((7 * 24) + 14) * time.Hour) + (25 * time.Minute)},
+		{"a las 11 de la mañana del martes pasado", 0, "a las 11 de la mañana del martes pasado", -13 * time.Hour},
+		{"mañana por la mañana a las 9", 0, "mañana por la mañana a las 9", 33 * time.Hour},
+		{"pasado mañana por la noche", 0, "pasado mañana por la noche", 66 * time.Hour},
+	}
+
+	ApplyFixtures(t, "es.All...", w, fixt)
+}
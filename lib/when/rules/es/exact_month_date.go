@@ -0,0 +1,78 @@
+package es
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+)
+
+// <[]string{"3 de marzo", "", "3", "marzo", ""}>
+// <[]string{"el 3 de marzo", "", "3", "marzo", ""}>
+// <[]string{"primero de septiembre", "primero", "", "septiembre", ""}>
+// <[]string{"1º de sept.", "1º", "", "sept.", ""}>
+// <[]string{"marzo 3", "", "", "marzo", "3"}>
+// <[]string{"febrero", "", "", "febrero", ""}>
+
+// 1. - ordinal day?
+// 2. - numeric day?
+// 3. - month
+// 4. - numeric day?
+
+func ExactMonthDate(s rules.Strategy) rules.Rule {
+	overwrite := s == rules.Override
+
+	return &rules.F{
+		RegExp: regexp.MustCompile("(?i)" +
+			"(?:\\W|^)" +
+			"(?:(?:(" + ORDINAL_WORDS_PATTERN[3:] + "|([0-9]{1,2}))\\s*(?:de\\s+)?)?" +
+			"(" + MONTH_OFFSET_PATTERN[3:] + // skip '(?:'
+			"(?:\\s*([0-9]{1,2}))?" +
+			"(?:\\P{L}|$)",
+		),
+
+		Applier: func(m *rules.Match, c *rules.Context, o *rules.Options, ref time.Time) (bool, error) {
+			_ = overwrite
+
+			ord := strings.ToLower(strings.TrimSpace(m.Captures[0]))
+			num1 := strings.ToLower(strings.TrimSpace(m.Captures[1]))
+			mon := strings.ToLower(strings.TrimSpace(m.Captures[2]))
+			num2 := strings.ToLower(strings.TrimSpace(m.Captures[3]))
+
+			monInt, ok := MONTH_OFFSET[mon]
+			if !ok {
+				return false, nil
+			}
+
+			c.Month = &monInt
+
+			if ord != "" {
+				ordInt, ok := ORDINAL_WORDS[ord]
+				if !ok {
+					return false, nil
+				}
+
+				c.Day = &ordInt
+			}
+
+			for _, num := range []string{num1, num2} {
+				if num == "" {
+					continue
+				}
+
+				n, err := strconv.ParseInt(num, 10, 8)
+				if err != nil {
+					return false, nil
+				}
+
+				day := int(n)
+
+				c.Day = &day
+			}
+
+			return true, nil
+		},
+	}
+}
@@ -0,0 +1,37 @@
+package es_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+	"github.com/mrbentarikau/pagst/lib/when/rules/es"
+)
+
+func TestExactMonthDate(t *testing.T) {
+	w := when.New(nil)
+	w.Add(es.ExactMonthDate(rules.Override))
+
+	fixtok := []Fixture{
+		{"3 de marzo", 0, "3 de marzo", 1368 * time.Hour},
+		{"el 3 de marzo", 3, "3 de marzo", 1368 * time.Hour},
+		{"marzo 3", 0, "marzo 3", 1368 * time.Hour},
+		{"1 de septiembre", 0, "1 de septiembre", 5736 * time.Hour},
+		{"1 sept", 0, "1 sept", 5736 * time.Hour},
+		{"1º de sept.", 0, "1º de sept.", 5736 * time.Hour},
+		{"primero de septiembre", 0, "primero de septiembre", 5736 * time.Hour},
+		{"1 de setiembre", 0, "1 de setiembre", 5736 * time.Hour},
+		{"7 de marzo", 0, "7 de marzo", 1464 * time.Hour},
+		{"21 de octubre", 0, "21 de octubre", 6936 * time.Hour},
+		{"20 de diciembre", 0, "20 de diciembre", 8376 * time.Hour},
+		{"10 de marzo", 0, "10 de marzo", 1536 * time.Hour},
+		{"ene. 4", 0, "ene. 4", -48 * time.Hour},
+		{"febrero", 0, "febrero", 744 * time.Hour},
+		{"octubre", 0, "octubre", 6576 * time.Hour},
+		{"jul.", 0, "jul.", 4368 * time.Hour},
+		{"junio", 0, "junio", 3648 * time.Hour},
+	}
+
+	ApplyFixtures(t, "es.ExactMonthDate", w, fixtok)
+}
@@ -0,0 +1,116 @@
+package es
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+)
+
+/*
+	"a las 5 de la tarde"
+	"a las cinco de la tarde"
+	"a las 17 h"
+	"17h"
+	"5 de la mañana"
+	"a la una p.m."
+
+	a plain number needs "a las", a period or an "h" to be an hour,
+	"en 5 horas" is a deadline and not five o'clock. "17h30" is left
+	for HourMinute
+*/
+
+var PERIOD_PATTERN = `(?:de\s+la\s+mañana|de\s+la\s+madrugada|de\s+la\s+tarde|de\s+la\s+noche|a\.\s*m\.|p\.\s*m\.|am|pm)`
+
+// applyPeriod moves the hour to the afternoon for the periods that need it,
+// "de la noche" is only the evening for hours after six: "11 de la noche", but "2 de la noche"
+func applyPeriod(hour int, period string) (int, bool) {
+	if period == "" {
+		return hour, hour < 24
+	}
+
+	if hour > 12 {
+		return 0, false
+	}
+
+	period = strings.ToLower(period)
+	switch {
+	case strings.Contains(period, "tarde"), strings.HasPrefix(period, "p"):
+		if hour < 12 {
+			hour += 12
+		}
+	case strings.Contains(period, "noche"):
+		if hour >= 6 && hour < 12 {
+			hour += 12
+		}
+	}
+
+	return hour, true
+}
+
+// 1. - "a las"
+// 2. - int
+// 3. - suffix?
+// 4. - ext?
+// 5. - int directly followed by an "h"
+// 6. - h
+// 7. - ext?
+// 8. - int followed by a period
+// 9. - ext
+
+func Hour(s rules.Strategy) rules.Rule {
+	number := "(" + INTEGER_WORDS_PATTERN + "|\\d{1,2})"
+	period := "(" + PERIOD_PATTERN + ")"
+
+	return &rules.F{
+		RegExp: regexp.MustCompile("(?i)(?:\\W|^)" +
+			"(?:" +
+			"(a\\s+las?)\\s+" + number + "(?:\\s*(h|hrs?|horas?))?(?:\\s*" + period + ")?" +
+			"|(\\d{1,2})(h)(?:\\s*" + period + ")?" +
+			"|" + number + "\\s*" + period +
+			")" +
+			"(?:[^\\p{L}\\d]|$)"),
+		Applier: func(m *rules.Match, c *rules.Context, o *rules.Options, ref time.Time) (bool, error) {
+			if c.Hour != nil && s != rules.Override {
+				return false, nil
+			}
+
+			var numStr, periodStr string
+			switch {
+			case m.Captures[1] != "":
+				numStr, periodStr = m.Captures[1], m.Captures[3]
+			case m.Captures[4] != "":
+				numStr, periodStr = m.Captures[4], m.Captures[6]
+			default:
+				numStr, periodStr = m.Captures[7], m.Captures[8]
+			}
+			numStr = strings.ToLower(numStr)
+
+			var hour int
+			var err error
+
+			if n, ok := INTEGER_WORDS[numStr]; ok {
+				hour = n
+			} else {
+				hour, err = strconv.Atoi(numStr)
+				if err != nil {
+					return false, errors.Wrap(err, "hour rule")
+				}
+			}
+
+			hour, ok := applyPeriod(hour, periodStr)
+			if !ok {
+				return false, nil
+			}
+
+			zero := 0
+			c.Hour = &hour
+			c.Minute = &zero
+			return true, nil
+		},
+	}
+}
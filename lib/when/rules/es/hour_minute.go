@@ -0,0 +1,65 @@
+package es
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+	"github.com/pkg/errors"
+)
+
+/*
+	{"17:30", 0, "17:30", 0},
+	{"17.30", 0, "17.30", 0},
+	{"17h30", 0, "17h30", 0},
+	{"17:30 h", 0, "17:30 h", 0},
+	{"5:30 de la tarde", 0, "5:30 de la tarde", 0},
+	{"5:30 pm", 0, "5:30 pm", 0},
+*/
+
+// 1. - int
+// 2. - int
+// 3. - suffix?
+// 4. - ext?
+
+func HourMinute(s rules.Strategy) rules.Rule {
+	return &rules.F{
+		RegExp: regexp.MustCompile("(?i)(?:\\W|^)" +
+			"((?:[0-1]{0,1}[0-9])|(?:2[0-3]))" +
+			"(?:\\:|：|\\.|h)" +
+			"((?:[0-5][0-9]))" +
+			"(?:\\s*(h|hrs?|horas?))?" +
+			"(?:\\s*(" + PERIOD_PATTERN + "))?" +
+			"(?:\\P{L}|$)"),
+		Applier: func(m *rules.Match, c *rules.Context, o *rules.Options, ref time.Time) (bool, error) {
+			if (c.Hour != nil || c.Minute != nil) && s != rules.Override {
+				return false, nil
+			}
+
+			hour, err := strconv.Atoi(m.Captures[0])
+			if err != nil {
+				return false, errors.Wrap(err, "hour minute rule")
+			}
+
+			minutes, err := strconv.Atoi(m.Captures[1])
+			if err != nil {
+				return false, errors.Wrap(err, "hour minute rule")
+			}
+
+			if minutes > 59 {
+				return false, nil
+			}
+
+			hour, ok := applyPeriod(hour, m.Captures[3])
+			if !ok {
+				return false, nil
+			}
+
+			c.Minute = &minutes
+			c.Hour = &hour
+
+			return true, nil
+		},
+	}
+}
@@ -0,0 +1,47 @@
+package es_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+	"github.com/mrbentarikau/pagst/lib/when/rules/es"
+)
+
+func TestHourMinute(t *testing.T) {
+	w := when.New(nil)
+	w.Add(es.HourMinute(rules.Override))
+
+	fixtok := []Fixture{
+		{"17:30", 0, "17:30", (17 * time.Hour) + (30 * time.Minute)},
+		{"sobre las 17.30", 10, "17.30", (17 * time.Hour) + (30 * time.Minute)},
+		{"desde las 17h30", 10, "17h30", (17 * time.Hour) + (30 * time.Minute)},
+		{"sobre las 5:59 de la tarde", 10, "5:59 de la tarde", (17 * time.Hour) + (59 * time.Minute)},
+		{"sobre las 5:59 pm", 10, "5:59 pm", (17 * time.Hour) + (59 * time.Minute)},
+		{"sobre las 17:59 h", 10, "17:59 h", (17 * time.Hour) + (59 * time.Minute)},
+		{"hasta las 11:10 de la noche", 10, "11:10 de la noche", (23 * time.Hour) + (10 * time.Minute)},
+	}
+
+	fixtnil := []Fixture{
+		{"28:30", 0, "", 0},
+		{"12:61", 0, "", 0},
+		{"24:10", 0, "", 0},
+	}
+
+	ApplyFixtures(t, "es.HourMinute", w, fixtok)
+	ApplyFixturesNil(t, "es.HourMinute nil", w, fixtnil)
+
+	w.Add(es.Hour(rules.Skip))
+	ApplyFixtures(t, "es.HourMinute|es.Hour", w, fixtok)
+	ApplyFixturesNil(t, "es.HourMinute|es.Hour nil", w, fixtnil)
+
+	w = when.New(nil)
+	w.Add(
+		es.Hour(rules.Override),
+		es.HourMinute(rules.Override),
+	)
+
+	ApplyFixtures(t, "es.Hour|es.HourMinute", w, fixtok)
+	ApplyFixturesNil(t, "es.Hour|es.HourMinute nil", w, fixtnil)
+}
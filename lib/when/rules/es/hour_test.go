@@ -0,0 +1,36 @@
+package es_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+	"github.com/mrbentarikau/pagst/lib/when/rules/es"
+)
+
+func TestHour(t *testing.T) {
+	fixt := []Fixture{
+		{"a las 5 de la tarde", 0, "a las 5 de la tarde", 17 * time.Hour},
+		{"a las cinco de la tarde", 0, "a las cinco de la tarde", 17 * time.Hour},
+		{"a las 17 h", 0, "a las 17 h", 17 * time.Hour},
+		{"nos vemos a las 17", 10, "a las 17", 17 * time.Hour},
+		{"17h", 0, "17h", 17 * time.Hour},
+		{"5 de la mañana", 0, "5 de la mañana", 5 * time.Hour},
+		{"a la una de la tarde", 0, "a la una de la tarde", 13 * time.Hour},
+		{"a las 12 pm", 0, "a las 12 pm", 12 * time.Hour},
+		{"a las 11 de la noche", 0, "a las 11 de la noche", 23 * time.Hour},
+		{"a las 2 de la madrugada", 0, "a las 2 de la madrugada", 2 * time.Hour},
+	}
+
+	w := when.New(nil)
+	w.Add(es.Hour(rules.Override))
+
+	ApplyFixtures(t, "es.Hour", w, fixt)
+
+	ApplyFixturesNil(t, "es.Hour nil", w, []Fixture{
+		{"en 5 horas", 0, "", 0},
+		{"a las 25", 0, "", 0},
+		{"17 de la tarde", 0, "", 0},
+	})
+}
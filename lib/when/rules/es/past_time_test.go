@@ -0,0 +1,33 @@
+package es_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+	"github.com/mrbentarikau/pagst/lib/when/rules/es"
+)
+
+func TestPastTime(t *testing.T) {
+	fixt := []Fixture{
+		{"hace media hora", 0, "hace media hora", -(time.Hour / 2)},
+		{"hace 1 hora", 0, "hace 1 hora", -(time.Hour)},
+		{"hace 5 minutos", 0, "hace 5 minutos", -(time.Minute * 5)},
+		{"hace 5 minutos fui al zoo", 0, "hace 5 minutos", -(time.Minute * 5)},
+		{"hicimos algo hace 10 días.", 13, "hace 10 días", -(10 * 24 * time.Hour)},
+		{"hicimos algo hace cinco días.", 13, "hace cinco días", -(5 * 24 * time.Hour)},
+		{"hicimos algo hace 5 días.", 13, "hace 5 días", -(5 * 24 * time.Hour)},
+		{"hace 5 segundos se movió un coche", 0, "hace 5 segundos", -(5 * time.Second)},
+		{"hace dos semanas", 0, "hace dos semanas", -(14 * 24 * time.Hour)},
+		{"hace un mes", 0, "hace un mes", -(31 * 24 * time.Hour)},
+		{"hace unos meses", 0, "hace unos meses", -(92 * 24 * time.Hour)},
+		{"hace un año", 0, "hace un año", -(365 * 24 * time.Hour)},
+		{"hace una semana", 0, "hace una semana", -(7 * 24 * time.Hour)},
+	}
+
+	w := when.New(nil)
+	w.Add(es.PastTime(rules.Skip))
+
+	ApplyFixtures(t, "es.PastTime", w, fixt)
+}
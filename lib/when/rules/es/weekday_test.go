@@ -0,0 +1,36 @@
+package es_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+	"github.com/mrbentarikau/pagst/lib/when/rules/es"
+)
+
+func TestWeekday(t *testing.T) {
+	// current is Wednesday
+	fixt := []Fixture{
+		// pasado/último
+		{"hazlo para el lunes pasado", 14, "lunes pasado", -(2 * 24 * time.Hour)},
+		{"el sábado pasado", 3, "sábado pasado", -(4 * 24 * time.Hour)},
+		{"el último viernes", 3, "último viernes", -(5 * 24 * time.Hour)},
+		{"el miércoles pasado", 3, "miércoles pasado", -(7 * 24 * time.Hour)},
+		{"el martes pasado", 3, "martes pasado", -(24 * time.Hour)},
+		// próximo/que viene
+		{"el próximo martes", 3, "próximo martes", 6 * 24 * time.Hour},
+		{"escríbeme el miércoles que viene", 14, "miércoles que viene", 7 * 24 * time.Hour},
+		{"el sábado", 3, "sábado", 3 * 24 * time.Hour},
+		// este
+		{"este martes", 0, "este martes", -(24 * time.Hour)},
+		{"escríbeme este miércoles", 11, "este miércoles", 0},
+		{"este sábado", 0, "este sábado", 3 * 24 * time.Hour},
+	}
+
+	w := when.New(nil)
+
+	w.Add(es.Weekday(rules.Override))
+
+	ApplyFixtures(t, "es.Weekday", w, fixt)
+}
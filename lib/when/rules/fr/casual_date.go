@@ -0,0 +1,54 @@
+package fr
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/AlekSi/pointer"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+)
+
+func CasualDate(s rules.Strategy) rules.Rule {
+	overwrite := s == rules.Override
+
+	return &rules.F{
+		RegExp: regexp.MustCompile("(?i)(?:\\W|^)(maintenant|aujourd['’]hui|cette\\s*nuit|la\\s+nuit\\s+dernière|hier\\s*soir|apr[eè]s-demain|avant-hier|demain|hier)(?:\\P{L}|$)"),
+		Applier: func(m *rules.Match, c *rules.Context, o *rules.Options, ref time.Time) (bool, error) {
+			lower := strings.ToLower(strings.TrimSpace(m.String()))
+
+			switch {
+			case strings.Contains(lower, "cette"):
+				if c.Hour == nil && c.Minute == nil || overwrite {
+					c.Hour = pointer.ToInt(23)
+					c.Minute = pointer.ToInt(0)
+				}
+			case strings.Contains(lower, "aujourd"):
+				// c.Hour = pointer.ToInt(18)
+			case strings.Contains(lower, "après-demain"), strings.Contains(lower, "apres-demain"):
+				if c.Duration == 0 || overwrite {
+					c.Duration += time.Hour * 48
+				}
+			case strings.Contains(lower, "avant-hier"):
+				if c.Duration == 0 || overwrite {
+					c.Duration -= time.Hour * 48
+				}
+			case strings.Contains(lower, "demain"):
+				if c.Duration == 0 || overwrite {
+					c.Duration += time.Hour * 24
+				}
+			case strings.Contains(lower, "soir"), strings.Contains(lower, "dernière"):
+				if (c.Hour == nil && c.Duration == 0) || overwrite {
+					c.Hour = pointer.ToInt(23)
+					c.Duration -= time.Hour * 24
+				}
+			case strings.Contains(lower, "hier"):
+				if c.Duration == 0 || overwrite {
+					c.Duration -= time.Hour * 24
+				}
+			}
+
+			return true, nil
+		},
+	}
+}
@@ -0,0 +1,57 @@
+package fr_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+	"github.com/mrbentarikau/pagst/lib/when/rules/fr"
+)
+
+func TestCasualDate(t *testing.T) {
+	fixt := []Fixture{
+		{"La date limite est maintenant, ok", 19, "maintenant", 0},
+		{"La date limite est aujourd'hui", 19, "aujourd'hui", 0},
+		{"La date limite est cette nuit", 19, "cette nuit", 23 * time.Hour},
+		{"La date limite est demain soir", 19, "demain", time.Hour * 24},
+		{"La date limite est après-demain", 19, "après-demain", time.Hour * 48},
+		{"La date limite était hier", 22, "hier", -(time.Hour * 24)},
+		{"La date limite était avant-hier", 22, "avant-hier", -(time.Hour * 48)},
+		{"La date limite était hier soir", 22, "hier soir", -time.Hour},
+	}
+
+	w := when.New(nil)
+	w.Add(fr.CasualDate(rules.Skip))
+
+	ApplyFixtures(t, "fr.CasualDate", w, fixt)
+}
+
+func TestCasualTime(t *testing.T) {
+	fixt := []Fixture{
+		{"La date limite était ce matin ", 22, "ce matin", 8 * time.Hour},
+		{"La date limite était à midi ", 25, "midi", 12 * time.Hour},
+		{"La date limite était cet après-midi ", 22, "cet après-midi", 15 * time.Hour},
+		{"La date limite était ce soir ", 22, "ce soir", 18 * time.Hour},
+	}
+
+	w := when.New(nil)
+	w.Add(fr.CasualTime(rules.Skip))
+
+	ApplyFixtures(t, "fr.CasualTime", w, fixt)
+}
+
+func TestCasualDateCasualTime(t *testing.T) {
+	fixt := []Fixture{
+		{"La date limite est demain après-midi ", 19, "demain après-midi", (15 + 24) * time.Hour},
+		{"La date limite est demain matin ", 19, "demain matin", (8 + 24) * time.Hour},
+	}
+
+	w := when.New(nil)
+	w.Add(
+		fr.CasualDate(rules.Skip),
+		fr.CasualTime(rules.Override),
+	)
+
+	ApplyFixtures(t, "fr.CasualDate|fr.CasualTime", w, fixt)
+}
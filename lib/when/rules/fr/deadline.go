@@ -0,0 +1,121 @@
+package fr
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AlekSi/pointer"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+	"github.com/pkg/errors"
+)
+
+// NUMBER_PATTERN matches the amounts used with durations, "une demi" or "quelques" included
+var NUMBER_PATTERN = "(?:une?\\s+demie?-?|demie?-?|quelques|" +
+	INTEGER_WORDS_PATTERN[3:len(INTEGER_WORDS_PATTERN)-1] + "|[0-9]+)"
+
+var UNIT_PATTERN = "(?:secondes?|min(?:ute)?s?|heures?|jours?|semaines?|mois|années?|ans?)"
+
+// parseAmount returns the amount for a NUMBER_PATTERN match, half is true for "demi"
+func parseAmount(numStr string) (num int, half bool, err error) {
+	numStr = strings.ToLower(numStr)
+
+	if n, ok := INTEGER_WORDS[numStr]; ok {
+		return n, false, nil
+	}
+
+	switch {
+	case strings.Contains(numStr, "demi"):
+		return 0, true, nil
+	case strings.HasPrefix(numStr, "quelques"):
+		return 3, false, nil
+	}
+
+	num, err = strconv.Atoi(numStr)
+	if err != nil {
+		return 0, false, errors.Wrapf(err, "convert '%s' to int", numStr)
+	}
+
+	return num, false, nil
+}
+
+func Deadline(s rules.Strategy) rules.Rule {
+	overwrite := s == rules.Override
+
+	return &rules.F{
+		RegExp: regexp.MustCompile(
+			"(?i)(?:\\W|^)(dans|d['’]ici)\\s*" +
+				"(" + NUMBER_PATTERN + ")\\s*" +
+				"(" + UNIT_PATTERN + ")\\s*" +
+				"(?:\\P{L}|$)"),
+		Applier: func(m *rules.Match, c *rules.Context, o *rules.Options, ref time.Time) (bool, error) {
+
+			num, half, err := parseAmount(strings.TrimSpace(m.Captures[1]))
+			if err != nil {
+				return false, err
+			}
+
+			exponent := strings.ToLower(strings.TrimSpace(m.Captures[2]))
+
+			if !half {
+				switch {
+				case strings.Contains(exponent, "seconde"):
+					if c.Duration == 0 || overwrite {
+						c.Duration = time.Duration(num) * time.Second
+					}
+				case strings.Contains(exponent, "min"):
+					if c.Duration == 0 || overwrite {
+						c.Duration = time.Duration(num) * time.Minute
+					}
+				case strings.Contains(exponent, "heure"):
+					if c.Duration == 0 || overwrite {
+						c.Duration = time.Duration(num) * time.Hour
+					}
+				case strings.Contains(exponent, "jour"):
+					if c.Duration == 0 || overwrite {
+						c.Duration = time.Duration(num) * 24 * time.Hour
+					}
+				case strings.Contains(exponent, "semaine"):
+					if c.Duration == 0 || overwrite {
+						c.Duration = time.Duration(num) * 7 * 24 * time.Hour
+					}
+				case strings.Contains(exponent, "mois"):
+					if c.Month == nil || overwrite {
+						c.Month = pointer.ToInt((int(ref.Month()) + num) % 12)
+					}
+				case strings.HasPrefix(exponent, "an"):
+					if c.Year == nil || overwrite {
+						c.Year = pointer.ToInt(ref.Year() + num)
+					}
+				}
+			} else {
+				switch {
+				case strings.Contains(exponent, "heure"):
+					if c.Duration == 0 || overwrite {
+						c.Duration = 30 * time.Minute
+					}
+				case strings.Contains(exponent, "jour"):
+					if c.Duration == 0 || overwrite {
+						c.Duration = 12 * time.Hour
+					}
+				case strings.Contains(exponent, "semaine"):
+					if c.Duration == 0 || overwrite {
+						c.Duration = 7 * 12 * time.Hour
+					}
+				case strings.Contains(exponent, "mois"):
+					if c.Duration == 0 || overwrite {
+						// 2 weeks
+						c.Duration = 14 * 24 * time.Hour
+					}
+				case strings.HasPrefix(exponent, "an"):
+					if c.Month == nil || overwrite {
+						c.Month = pointer.ToInt((int(ref.Month()) + 6) % 12)
+					}
+				}
+			}
+
+			return true, nil
+		},
+	}
+}
@@ -0,0 +1,33 @@
+package fr_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+	"github.com/mrbentarikau/pagst/lib/when/rules/fr"
+)
+
+func TestDeadline(t *testing.T) {
+	fixt := []Fixture{
+		{"dans une demi-heure", 0, "dans une demi-heure", time.Hour / 2},
+		{"d'ici 1 heure", 0, "d'ici 1 heure", time.Hour},
+		{"dans 5 minutes", 0, "dans 5 minutes", time.Minute * 5},
+		{"Dans 5 minutes je rentre", 0, "Dans 5 minutes", time.Minute * 5},
+		{"nous devons faire quelque chose d'ici 10 jours.", 32, "d'ici 10 jours", 10 * 24 * time.Hour},
+		{"nous devons faire quelque chose dans cinq jours.", 32, "dans cinq jours", 5 * 24 * time.Hour},
+		{"nous devons faire quelque chose dans 5 jours.", 32, "dans 5 jours", 5 * 24 * time.Hour},
+		{"Dans 5 secondes une voiture doit partir", 0, "Dans 5 secondes", 5 * time.Second},
+		{"d'ici deux semaines", 0, "d'ici deux semaines", 14 * 24 * time.Hour},
+		{"dans un mois", 0, "dans un mois", 31 * 24 * time.Hour},
+		{"dans quelques mois", 0, "dans quelques mois", 91 * 24 * time.Hour},
+		{"dans un an", 0, "dans un an", 366 * 24 * time.Hour},
+		{"dans une semaine", 0, "dans une semaine", 7 * 24 * time.Hour},
+	}
+
+	w := when.New(nil)
+	w.Add(fr.Deadline(rules.Skip))
+
+	ApplyFixtures(t, "fr.Deadline", w, fixt)
+}
@@ -0,0 +1,70 @@
+package fr
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+)
+
+// <[]string{"3 mars", "", "3", "mars"}>
+// <[]string{"le 3 mars", "", "3", "mars"}>
+// <[]string{"1er septembre", "1er", "", "septembre"}>
+// <[]string{"premier sept.", "premier", "", "sept."}>
+// <[]string{"février", "", "", "février"}>
+
+// 1. - ordinal day?
+// 2. - numeric day?
+// 3. - month
+
+func ExactMonthDate(s rules.Strategy) rules.Rule {
+	overwrite := s == rules.Override
+
+	return &rules.F{
+		RegExp: regexp.MustCompile("(?i)" +
+			"(?:\\W|^)" +
+			"(?:(?:(" + ORDINAL_WORDS_PATTERN[3:] + "|([0-9]{1,2}))\\s*)?" +
+			"(" + MONTH_OFFSET_PATTERN[3:] + // skip '(?:'
+			"(?:\\P{L}|$)",
+		),
+
+		Applier: func(m *rules.Match, c *rules.Context, o *rules.Options, ref time.Time) (bool, error) {
+			_ = overwrite
+
+			ord := strings.ToLower(strings.TrimSpace(m.Captures[0]))
+			num := strings.ToLower(strings.TrimSpace(m.Captures[1]))
+			mon := strings.ToLower(strings.TrimSpace(m.Captures[2]))
+
+			monInt, ok := MONTH_OFFSET[mon]
+			if !ok {
+				return false, nil
+			}
+
+			c.Month = &monInt
+
+			if ord != "" {
+				ordInt, ok := ORDINAL_WORDS[ord]
+				if !ok {
+					return false, nil
+				}
+
+				c.Day = &ordInt
+			}
+
+			if num != "" {
+				n, err := strconv.ParseInt(num, 10, 8)
+				if err != nil {
+					return false, nil
+				}
+
+				day := int(n)
+
+				c.Day = &day
+			}
+
+			return true, nil
+		},
+	}
+}
@@ -0,0 +1,39 @@
+package fr_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+	"github.com/mrbentarikau/pagst/lib/when/rules/fr"
+)
+
+func TestExactMonthDate(t *testing.T) {
+	w := when.New(nil)
+	w.Add(fr.ExactMonthDate(rules.Override))
+
+	fixtok := []Fixture{
+		{"3 mars", 0, "3 mars", 1368 * time.Hour},
+		{"le 3 mars", 3, "3 mars", 1368 * time.Hour},
+		{"1er septembre", 0, "1er septembre", 5736 * time.Hour},
+		{"premier sept.", 0, "premier sept.", 5736 * time.Hour},
+		{"1 sept.", 0, "1 sept.", 5736 * time.Hour},
+		{"7 mars", 0, "7 mars", 1464 * time.Hour},
+		{"21 octobre", 0, "21 octobre", 6936 * time.Hour},
+		{"20 décembre", 0, "20 décembre", 8376 * time.Hour},
+		{"10 mars", 0, "10 mars", 1536 * time.Hour},
+		{"4 janv.", 0, "4 janv.", -48 * time.Hour},
+		{"février", 0, "février", 744 * time.Hour},
+		{"octobre", 0, "octobre", 6576 * time.Hour},
+		{"juil.", 0, "juil.", 4368 * time.Hour},
+		{"juin", 0, "juin", 3648 * time.Hour},
+	}
+
+	ApplyFixtures(t, "fr.ExactMonthDate", w, fixtok)
+
+	// a bare "sept" is seven
+	ApplyFixturesNil(t, "fr.ExactMonthDate nil", w, []Fixture{
+		{"dans sept jours", 0, "", 0},
+	})
+}
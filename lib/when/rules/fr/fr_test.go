@@ -0,0 +1,65 @@
+package fr_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when"
+	"github.com/mrbentarikau/pagst/lib/when/rules/fr"
+	"github.com/stretchr/testify/require"
+)
+
+var null = time.Date(2016, time.January, 6, 0, 0, 0, 0, time.UTC)
+
+type Fixture struct {
+	Text   string
+	Index  int
+	Phrase string
+	Diff   time.Duration
+}
+
+func ApplyFixtures(t *testing.T, name string, w *when.Parser, fixt []Fixture) {
+	for i, f := range fixt {
+		res, err := w.Parse(f.Text, null)
+		require.Nil(t, err, "[%s] err #%d", name, i)
+		require.NotNil(t, res, "[%s] res #%d", name, i)
+		require.Equal(t, f.Index, res.Index, "[%s] index #%d", name, i)
+		require.Equal(t, f.Phrase, res.Text, "[%s] text #%d", name, i)
+		require.Equal(t, f.Diff, res.Time.Sub(null), "[%s] diff #%d", name, i)
+	}
+}
+
+func ApplyFixturesNil(t *testing.T, name string, w *when.Parser, fixt []Fixture) {
+	for i, f := range fixt {
+		res, err := w.Parse(f.Text, null)
+		require.Nil(t, err, "[%s] err #%d", name, i)
+		require.Nil(t, res, "[%s] res #%d", name, i)
+	}
+}
+
+func ApplyFixturesErr(t *testing.T, name string, w *when.Parser, fixt []Fixture) {
+	for i, f := range fixt {
+		_, err := w.Parse(f.Text, null)
+		require.NotNil(t, err, "[%s] err #%d", name, i)
+		require.Equal(t, f.Phrase, err.Error(), "[%s] err text #%d", name, i)
+	}
+}
+
+func TestAll(t *testing.T) {
+	w := when.New(nil)
+	w.Add(fr.All...)
+
+	// complex cases
+	fixt := []Fixture{
+		{"cette nuit à 23:10", 0, "cette nuit à 23:10", (23 * time.Hour) + (10 * time.Minute)},
+		{"le vendredi après-midi", 3, "vendredi après-midi", ((2 * 24) + 15) * time.Hour},
+		{"mardi prochain à 14:00", 0, "mardi prochain à 14:00", ((6 * 24) + 14) * time.Hour},
+		{"mardi prochain à 2 heures de l'après-midi", 0, "mardi prochain à 2 heures de l'après-midi", ((6 * 24) + 14) * time.Hour},
+		{"mercredi prochain à 14h25", 0, "mercredi prochain à 14h25", (((7 * 24) + 14) * time.Hour) + (25 * time.Minute)},
+		{"à 11h mardi dernier", 3, "11h mardi dernier", -13 * time.Hour},
+		{"demain matin à 9h", 0, "demain matin à 9h", 33 * time.Hour},
+		{"après-demain soir", 0, "après-demain soir", 66 * time.Hour},
+	}
+
+	ApplyFixtures(t, "fr.All...", w, fixt)
+}
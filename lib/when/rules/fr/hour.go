@@ -0,0 +1,107 @@
+package fr
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+)
+
+/*
+	"17h"
+	"à 17 heures"
+	"à 5 heures du soir"
+	"5h du matin"
+	"à cinq heures de l'après-midi"
+
+	a plain number needs "à", a period or to be directly followed by "h"
+	to be an hour, "dans 5 heures" is a deadline and not five o'clock.
+	"17h30" is left for HourMinute
+*/
+
+var PERIOD_PATTERN = `(?:du\s+matin|de\s+l['’]apr[eè]s-midi|du\s+soir)`
+
+// applyPeriod moves the hour to the afternoon for the periods that need it
+func applyPeriod(hour int, period string) (int, bool) {
+	if period == "" {
+		return hour, hour < 24
+	}
+
+	if hour > 12 {
+		return 0, false
+	}
+
+	period = strings.ToLower(period)
+	if strings.Contains(period, "midi") || strings.Contains(period, "soir") {
+		if hour < 12 {
+			hour += 12
+		}
+	}
+
+	return hour, true
+}
+
+// 1. - int after "à"
+// 2. - h or heures
+// 3. - ext?
+// 4. - int directly followed by an "h"
+// 5. - h
+// 6. - ext?
+// 7. - int
+// 8. - h or heures
+// 9. - ext
+
+func Hour(s rules.Strategy) rules.Rule {
+	number := "(" + INTEGER_WORDS_PATTERN + "|\\d{1,2})"
+	period := "(" + PERIOD_PATTERN + ")"
+
+	return &rules.F{
+		RegExp: regexp.MustCompile("(?i)(?:\\W|^)" +
+			"(?:" +
+			"à\\s+" + number + "\\s*(h|heures?)(?:\\s*" + period + ")?" +
+			"|(\\d{1,2})(h)(?:\\s*" + period + ")?" +
+			"|" + number + "\\s*(h|heures?)\\s*" + period +
+			")" +
+			"(?:[^\\p{L}\\d]|$)"),
+		Applier: func(m *rules.Match, c *rules.Context, o *rules.Options, ref time.Time) (bool, error) {
+			if c.Hour != nil && s != rules.Override {
+				return false, nil
+			}
+
+			var numStr, periodStr string
+			for i := 0; i < len(m.Captures); i += 3 {
+				if m.Captures[i] != "" {
+					numStr, periodStr = m.Captures[i], m.Captures[i+2]
+					break
+				}
+			}
+			numStr = strings.ToLower(numStr)
+
+			var hour int
+			var err error
+
+			if n, ok := INTEGER_WORDS[numStr]; ok {
+				hour = n
+			} else {
+				hour, err = strconv.Atoi(numStr)
+				if err != nil {
+					return false, errors.Wrap(err, "hour rule")
+				}
+			}
+
+			hour, ok := applyPeriod(hour, periodStr)
+			if !ok {
+				return false, nil
+			}
+
+			zero := 0
+			c.Hour = &hour
+			c.Minute = &zero
+			return true, nil
+		},
+	}
+}
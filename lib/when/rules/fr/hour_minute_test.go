@@ -0,0 +1,45 @@
+package fr_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+	"github.com/mrbentarikau/pagst/lib/when/rules/fr"
+)
+
+func TestHourMinute(t *testing.T) {
+	w := when.New(nil)
+	w.Add(fr.HourMinute(rules.Override))
+
+	fixtok := []Fixture{
+		{"17h30", 0, "17h30", (17 * time.Hour) + (30 * time.Minute)},
+		{"à 17:30", 3, "17:30", (17 * time.Hour) + (30 * time.Minute)},
+		{"à 5h59 du soir", 3, "5h59 du soir", (17 * time.Hour) + (59 * time.Minute)},
+		{"à 17:59 environ", 3, "17:59", (17 * time.Hour) + (59 * time.Minute)},
+		{"jusqu'à 11h10 du soir", 9, "11h10 du soir", (23 * time.Hour) + (10 * time.Minute)},
+	}
+
+	fixtnil := []Fixture{
+		{"28:30", 0, "", 0},
+		{"12h61", 0, "", 0},
+		{"24:10", 0, "", 0},
+	}
+
+	ApplyFixtures(t, "fr.HourMinute", w, fixtok)
+	ApplyFixturesNil(t, "fr.HourMinute nil", w, fixtnil)
+
+	w.Add(fr.Hour(rules.Skip))
+	ApplyFixtures(t, "fr.HourMinute|fr.Hour", w, fixtok)
+	ApplyFixturesNil(t, "fr.HourMinute|fr.Hour nil", w, fixtnil)
+
+	w = when.New(nil)
+	w.Add(
+		fr.Hour(rules.Override),
+		fr.HourMinute(rules.Override),
+	)
+
+	ApplyFixtures(t, "fr.Hour|fr.HourMinute", w, fixtok)
+	ApplyFixturesNil(t, "fr.Hour|fr.HourMinute nil", w, fixtnil)
+}
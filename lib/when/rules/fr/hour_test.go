@@ -0,0 +1,34 @@
+package fr_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+	"github.com/mrbentarikau/pagst/lib/when/rules/fr"
+)
+
+func TestHour(t *testing.T) {
+	fixt := []Fixture{
+		{"17h", 0, "17h", 17 * time.Hour},
+		{"à 17 heures", 3, "17 heures", 17 * time.Hour},
+		{"à 5 heures du soir", 3, "5 heures du soir", 17 * time.Hour},
+		{"5h du matin", 0, "5h du matin", 5 * time.Hour},
+		{"à cinq heures de l'après-midi", 3, "cinq heures de l'après-midi", 17 * time.Hour},
+		{"à une heure de l'après-midi", 3, "une heure de l'après-midi", 13 * time.Hour},
+		{"à 12h", 3, "12h", 12 * time.Hour},
+		{"11h du soir", 0, "11h du soir", 23 * time.Hour},
+	}
+
+	w := when.New(nil)
+	w.Add(fr.Hour(rules.Override))
+
+	ApplyFixtures(t, "fr.Hour", w, fixt)
+
+	ApplyFixturesNil(t, "fr.Hour nil", w, []Fixture{
+		{"dans 5 heures", 0, "", 0},
+		{"25h", 0, "", 0},
+		{"17h du soir", 0, "", 0},
+	})
+}
@@ -0,0 +1,90 @@
+package fr
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/AlekSi/pointer"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+)
+
+func PastTime(s rules.Strategy) rules.Rule {
+	overwrite := s == rules.Override
+
+	return &rules.F{
+		RegExp: regexp.MustCompile(
+			"(?i)(?:\\W|^)(il\\s+y\\s+a)\\s*" +
+				"(" + NUMBER_PATTERN + ")\\s*" +
+				"(" + UNIT_PATTERN + ")\\s*" +
+				"(?:\\P{L}|$)"),
+		Applier: func(m *rules.Match, c *rules.Context, o *rules.Options, ref time.Time) (bool, error) {
+
+			num, half, err := parseAmount(strings.TrimSpace(m.Captures[1]))
+			if err != nil {
+				return false, err
+			}
+
+			exponent := strings.ToLower(strings.TrimSpace(m.Captures[2]))
+
+			if !half {
+				switch {
+				case strings.Contains(exponent, "seconde"):
+					if c.Duration == 0 || overwrite {
+						c.Duration = -(time.Duration(num) * time.Second)
+					}
+				case strings.Contains(exponent, "min"):
+					if c.Duration == 0 || overwrite {
+						c.Duration = -(time.Duration(num) * time.Minute)
+					}
+				case strings.Contains(exponent, "heure"):
+					if c.Duration == 0 || overwrite {
+						c.Duration = -(time.Duration(num) * time.Hour)
+					}
+				case strings.Contains(exponent, "jour"):
+					if c.Duration == 0 || overwrite {
+						c.Duration = -(time.Duration(num) * 24 * time.Hour)
+					}
+				case strings.Contains(exponent, "semaine"):
+					if c.Duration == 0 || overwrite {
+						c.Duration = -(time.Duration(num) * 7 * 24 * time.Hour)
+					}
+				case strings.Contains(exponent, "mois"):
+					if c.Month == nil || overwrite {
+						c.Month = pointer.ToInt((int(ref.Month()) - num) % 12)
+					}
+				case strings.HasPrefix(exponent, "an"):
+					if c.Year == nil || overwrite {
+						c.Year = pointer.ToInt(ref.Year() - num)
+					}
+				}
+			} else {
+				switch {
+				case strings.Contains(exponent, "heure"):
+					if c.Duration == 0 || overwrite {
+						c.Duration = -(30 * time.Minute)
+					}
+				case strings.Contains(exponent, "jour"):
+					if c.Duration == 0 || overwrite {
+						c.Duration = -(12 * time.Hour)
+					}
+				case strings.Contains(exponent, "semaine"):
+					if c.Duration == 0 || overwrite {
+						c.Duration = -(7 * 12 * time.Hour)
+					}
+				case strings.Contains(exponent, "mois"):
+					if c.Duration == 0 || overwrite {
+						// 2 weeks
+						c.Duration = -(14 * 24 * time.Hour)
+					}
+				case strings.HasPrefix(exponent, "an"):
+					if c.Month == nil || overwrite {
+						c.Month = pointer.ToInt((int(ref.Month()) - 6) % 12)
+					}
+				}
+			}
+
+			return true, nil
+		},
+	}
+}
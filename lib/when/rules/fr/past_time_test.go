@@ -0,0 +1,33 @@
+package fr_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+	"github.com/mrbentarikau/pagst/lib/when/rules/fr"
+)
+
+func TestPastTime(t *testing.T) {
+	fixt := []Fixture{
+		{"il y a une demi-heure", 0, "il y a une demi-heure", -(time.Hour / 2)},
+		{"il y a 1 heure", 0, "il y a 1 heure", -(time.Hour)},
+		{"il y a 5 minutes", 0, "il y a 5 minutes", -(time.Minute * 5)},
+		{"il y a 5 minutes je suis allé au zoo", 0, "il y a 5 minutes", -(time.Minute * 5)},
+		{"nous avons fait quelque chose il y a 10 jours.", 30, "il y a 10 jours", -(10 * 24 * time.Hour)},
+		{"nous avons fait quelque chose il y a cinq jours.", 30, "il y a cinq jours", -(5 * 24 * time.Hour)},
+		{"nous avons fait quelque chose il y a 5 jours.", 30, "il y a 5 jours", -(5 * 24 * time.Hour)},
+		{"il y a 5 secondes une voiture a bougé", 0, "il y a 5 secondes", -(5 * time.Second)},
+		{"il y a deux semaines", 0, "il y a deux semaines", -(14 * 24 * time.Hour)},
+		{"il y a un mois", 0, "il y a un mois", -(31 * 24 * time.Hour)},
+		{"il y a quelques mois", 0, "il y a quelques mois", -(92 * 24 * time.Hour)},
+		{"il y a un an", 0, "il y a un an", -(365 * 24 * time.Hour)},
+		{"il y a une semaine", 0, "il y a une semaine", -(7 * 24 * time.Hour)},
+	}
+
+	w := when.New(nil)
+	w.Add(fr.PastTime(rules.Skip))
+
+	ApplyFixtures(t, "fr.PastTime", w, fixt)
+}
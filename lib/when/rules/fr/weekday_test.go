@@ -0,0 +1,36 @@
+package fr_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrbentarikau/pagst/lib/when"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+	"github.com/mrbentarikau/pagst/lib/when/rules/fr"
+)
+
+func TestWeekday(t *testing.T) {
+	// current is Wednesday
+	fixt := []Fixture{
+		// dernier/passé
+		{"fais-le pour lundi dernier", 13, "lundi dernier", -(2 * 24 * time.Hour)},
+		{"samedi dernier", 0, "samedi dernier", -(4 * 24 * time.Hour)},
+		{"vendredi passé", 0, "vendredi passé", -(5 * 24 * time.Hour)},
+		{"mercredi dernier", 0, "mercredi dernier", -(7 * 24 * time.Hour)},
+		{"mardi dernier", 0, "mardi dernier", -(24 * time.Hour)},
+		// prochain/qui vient
+		{"mardi prochain", 0, "mardi prochain", 6 * 24 * time.Hour},
+		{"écris-moi mercredi prochain", 11, "mercredi prochain", 7 * 24 * time.Hour},
+		{"le samedi qui vient", 3, "samedi qui vient", 3 * 24 * time.Hour},
+		// ce
+		{"ce mardi", 0, "ce mardi", -(24 * time.Hour)},
+		{"écris-moi ce mercredi", 11, "ce mercredi", 0},
+		{"ce samedi", 0, "ce samedi", 3 * 24 * time.Hour},
+	}
+
+	w := when.New(nil)
+
+	w.Add(fr.Weekday(rules.Override))
+
+	ApplyFixtures(t, "fr.Weekday", w, fixt)
+}
@@ -7,7 +7,10 @@ import (
 	"github.com/mrbentarikau/pagst/lib/when/rules"
 	"github.com/mrbentarikau/pagst/lib/when/rules/br"
 	"github.com/mrbentarikau/pagst/lib/when/rules/common"
+	"github.com/mrbentarikau/pagst/lib/when/rules/de"
 	"github.com/mrbentarikau/pagst/lib/when/rules/en"
+	"github.com/mrbentarikau/pagst/lib/when/rules/es"
+	"github.com/mrbentarikau/pagst/lib/when/rules/fr"
 	"github.com/mrbentarikau/pagst/lib/when/rules/ru"
 	"github.com/pkg/errors"
 )
@@ -153,6 +156,15 @@ var RU *Parser
 // BR is a parser for Brazilian Portuguese language
 var BR *Parser
 
+// DE is a parser for German language
+var DE *Parser
+
+// ES is a parser for Spanish language
+var ES *Parser
+
+// FR is a parser for French language
+var FR *Parser
+
 func init() {
 	EN = New(nil)
 	EN.Add(en.All...)
@@ -165,4 +177,16 @@ func init() {
 	BR = New(nil)
 	BR.Add(br.All...)
 	BR.Add(common.All...)
+
+	DE = New(nil)
+	DE.Add(de.All...)
+	DE.Add(common.All...)
+
+	ES = New(nil)
+	ES.Add(es.All...)
+	ES.Add(common.All...)
+
+	FR = New(nil)
+	FR.Add(fr.All...)
+	FR.Add(common.All...)
 }
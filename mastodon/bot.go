@@ -0,0 +1,56 @@
+package mastodon
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/common/mqueue"
+)
+
+func (p *Plugin) Status() (string, string) {
+	var numFeeds int
+	err := common.GORM.Model(&Feed{}).Where("enabled = ?", true).Count(&numFeeds).Error
+	if err != nil {
+		logger.WithError(err).Error("failed fetching status")
+		return "Mastodon feeds", "error"
+	}
+
+	return "Mastodon feeds", fmt.Sprintf("%d", numFeeds)
+}
+
+var _ mqueue.PluginWithSourceDisabler = (*Plugin)(nil)
+
+func (p *Plugin) DisableFeed(elem *mqueue.QueuedElement, err error) {
+	feedID, err := strconv.ParseInt(elem.SourceItemID, 10, 64)
+	if err != nil {
+		logger.WithError(err).WithField("source_id", elem.SourceItemID).Error("failed parsing sourceID!??!")
+		return
+	}
+
+	err = common.GORM.Model(&Feed{}).Where("id = ?", feedID).Update("enabled", false).Error
+	if err != nil {
+		logger.WithError(err).WithField("feed_id", feedID).Error("failed disabling feed")
+	}
+}
+
+func (p *Plugin) OnRemovedPremiumGuild(guildID int64) error {
+	logger.WithField("guild_id", guildID).Infof("Removed Excess Mastodon Feeds")
+
+	var excess []*Feed
+	err := common.GORM.Where("guild_id = ? AND enabled = ?", guildID, true).Order("id asc").Offset(GuildMaxFeeds).Find(&excess).Error
+	if err != nil {
+		logger.WithError(err).WithField("guild_id", guildID).Error("failed retrieving excess feeds")
+		return err
+	}
+
+	for _, f := range excess {
+		err = common.GORM.Model(f).Update("enabled", false).Error
+		if err != nil {
+			logger.WithError(err).WithField("guild_id", guildID).Error("failed disabling feed for missing premium")
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package mastodon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mrbentarikau/pagst/common"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+var httpClient = &http.Client{
+	Timeout: time.Second * 15,
+}
+
+// ErrAccountNotFound is returned when the account no longer exists on the instance
+var ErrAccountNotFound = errors.New("account not found")
+
+type Account struct {
+	ID          string `json:"id"`
+	Username    string `json:"username"`
+	Acct        string `json:"acct"`
+	DisplayName string `json:"display_name"`
+	URL         string `json:"url"`
+	Avatar      string `json:"avatar"`
+}
+
+// Name returns the display name, falling back to the username as it can be empty
+func (a *Account) Name() string {
+	if a.DisplayName != "" {
+		return a.DisplayName
+	}
+
+	return a.Username
+}
+
+type MediaAttachment struct {
+	Type        string `json:"type"`
+	URL         string `json:"url"`
+	PreviewURL  string `json:"preview_url"`
+	Description string `json:"description"`
+}
+
+type Status struct {
+	ID                 string            `json:"id"`
+	CreatedAt          time.Time         `json:"created_at"`
+	InReplyToID        *string           `json:"in_reply_to_id"`
+	InReplyToAccountID *string           `json:"in_reply_to_account_id"`
+	Sensitive          bool              `json:"sensitive"`
+	SpoilerText        string            `json:"spoiler_text"`
+	Visibility         string            `json:"visibility"`
+	URL                string            `json:"url"`
+	Content            string            `json:"content"`
+	Account            Account           `json:"account"`
+	MediaAttachments   []MediaAttachment `json:"media_attachments"`
+	Reblog             *Status           `json:"reblog"`
+}
+
+var (
+	reAccountAddress = regexp.MustCompile(`^@?([\w.]+)@([\w.-]+\.[a-zA-Z]{2,})$`)
+	reProfileURL     = regexp.MustCompile(`^https?://([\w.-]+\.[a-zA-Z]{2,})/(?:@|users/)([\w.]+)/?$`)
+)
+
+// ParseAccountAddress returns the username and instance from either a @user@instance address or a profile URL
+func ParseAccountAddress(s string) (username, instance string, err error) {
+	s = strings.TrimSpace(s)
+
+	if m := reAccountAddress.FindStringSubmatch(s); m != nil {
+		return m[1], strings.ToLower(m[2]), nil
+	}
+
+	if m := reProfileURL.FindStringSubmatch(s); m != nil {
+		return m[2], strings.ToLower(m[1]), nil
+	}
+
+	return "", "", errors.New("Not a mastodon account, use the @user@instance format or a link to the profile")
+}
+
+// LookupAccount finds the account with the username on the instance
+func LookupAccount(ctx context.Context, instance, username string) (*Account, error) {
+	var account *Account
+	err := getJSON(ctx, "https://"+instance+"/api/v1/accounts/lookup?acct="+url.QueryEscape(username), &account)
+	return account, err
+}
+
+// AccountStatuses returns the public statuses of the account newer than sinceID, newest first
+func AccountStatuses(ctx context.Context, instance, accountID, sinceID string) ([]*Status, error) {
+	query := url.Values{}
+	query.Set("limit", "20")
+	if sinceID != "" {
+		query.Set("since_id", sinceID)
+	}
+
+	var statuses []*Status
+	err := getJSON(ctx, "https://"+instance+"/api/v1/accounts/"+url.PathEscape(accountID)+"/statuses?"+query.Encode(), &statuses)
+	return statuses, err
+}
+
+func getJSON(ctx context.Context, u string, dst interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", common.ConfBotName.GetString()+" (https://"+common.ConfHost.GetString()+")")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrAccountNotFound
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(dst)
+}
+
+var (
+	stripTagsPolicy = bluemonday.StripTagsPolicy()
+
+	reLineBreak = regexp.MustCompile(`(?i)<br\s*/?>`)
+	reParagraph = regexp.MustCompile(`(?i)</p>\s*<p[^>]*>`)
+)
+
+// ContentText converts the HTML content of a status to plain text, keeping line breaks and paragraphs.
+// Mastodon hides parts of long links in invisible spans, stripping the tags gives back the full link.
+func ContentText(content string) string {
+	content = reParagraph.ReplaceAllString(content, "\n\n")
+	content = reLineBreak.ReplaceAllString(content, "\n")
+	return strings.TrimSpace(html.UnescapeString(stripTagsPolicy.Sanitize(content)))
+}
@@ -0,0 +1,292 @@
+package mastodon
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrbentarikau/pagst/analytics"
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/common/mqueue"
+	"github.com/mrbentarikau/pagst/feeds"
+	"github.com/mrbentarikau/pagst/lib/discordgo"
+
+	"github.com/mediocregopher/radix/v3"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// At most this many statuses are posted per account and poll, so catching up after downtime doesn't flood channels
+	maxStatusesPerPoll = 5
+	// Older statuses are not posted
+	maxStatusAge = time.Hour * 12
+
+	embedColor = 0x6364FF
+)
+
+var _ feeds.Plugin = (*Plugin)(nil)
+
+func (p *Plugin) StartFeed() {
+	p.Stop = make(chan *sync.WaitGroup)
+	go p.runFeedLoop()
+}
+
+func (p *Plugin) StopFeed(wg *sync.WaitGroup) {
+	if p.Stop != nil {
+		p.Stop <- wg
+	} else {
+		wg.Done()
+	}
+}
+
+func (p *Plugin) runFeedLoop() {
+	ticker := time.NewTicker(time.Minute * time.Duration(confMastodonPollFrequency.GetInt()))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.poll()
+		case wg := <-p.Stop:
+			wg.Done()
+			return
+		}
+	}
+}
+
+// poll checks every followed account once, with a few accounts at a time
+func (p *Plugin) poll() {
+	var enabled []*Feed
+	err := common.GORM.Where("enabled = ?", true).Order("id asc").Find(&enabled).Error
+	if err != nil {
+		logger.WithError(err).Error("failed retrieving feeds")
+		return
+	}
+
+	byAccount := make(map[string][]*Feed)
+	for _, f := range enabled {
+		byAccount[f.AccountKey()] = append(byAccount[f.AccountKey()], f)
+	}
+
+	work := make(chan []*Feed)
+	var wg sync.WaitGroup
+	for i := 0; i < confMastodonPollWorkers.GetInt(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for accountFeeds := range work {
+				p.checkAccount(accountFeeds)
+			}
+		}()
+	}
+
+	for _, accountFeeds := range byAccount {
+		work <- accountFeeds
+	}
+	close(work)
+	wg.Wait()
+}
+
+// checkAccount posts the new statuses of the account of the feeds, which all follow the same account
+func (p *Plugin) checkAccount(accountFeeds []*Feed) {
+	instance, accountID := accountFeeds[0].Instance, accountFeeds[0].AccountID
+	l := logger.WithField("account", accountFeeds[0].Acct)
+
+	var lastID string
+	err := common.RedisPool.Do(radix.Cmd(&lastID, "GET", KeyLastStatusID(instance, accountID)))
+	if err != nil {
+		l.WithError(err).Error("failed retrieving last status id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	statuses, err := AccountStatuses(ctx, instance, accountID, lastID)
+	if err != nil {
+		if err == ErrAccountNotFound {
+			l.Info("account is gone, disabling its feeds")
+			disableAccountFeeds(instance, accountID)
+			return
+		}
+
+		l.WithError(err).Warn("failed retrieving statuses")
+		return
+	}
+
+	if len(statuses) == 0 {
+		return
+	}
+
+	err = common.RedisPool.Do(radix.Cmd(nil, "SET", KeyLastStatusID(instance, accountID), statuses[0].ID))
+	if err != nil {
+		l.WithError(err).Error("failed saving last status id")
+		return
+	}
+
+	if lastID == "" {
+		// first poll of the account, only remember where we are
+		return
+	}
+
+	if len(statuses) > maxStatusesPerPoll {
+		statuses = statuses[:maxStatusesPerPoll]
+	}
+
+	// oldest first
+	for i := len(statuses) - 1; i >= 0; i-- {
+		if time.Since(statuses[i].CreatedAt) > maxStatusAge {
+			continue
+		}
+
+		p.handleStatus(accountFeeds, statuses[i])
+	}
+}
+
+// ShouldPost returns whether the status goes to the feed. Replies to the account itself continue
+// a thread and are posted like any other status.
+func ShouldPost(f *Feed, s *Status) bool {
+	if s.Visibility == "private" || s.Visibility == "direct" {
+		return false
+	}
+
+	if s.Reblog != nil {
+		return f.IncludeBoosts
+	}
+
+	if s.InReplyToID != nil && (s.InReplyToAccountID == nil || *s.InReplyToAccountID != f.AccountID) {
+		return f.IncludeReplies
+	}
+
+	return true
+}
+
+func (p *Plugin) handleStatus(accountFeeds []*Feed, s *Status) {
+	relevantFeeds := make([]*Feed, 0, len(accountFeeds))
+
+OUTER:
+	for _, f := range accountFeeds {
+		if !ShouldPost(f, s) {
+			continue
+		}
+
+		for _, r := range relevantFeeds {
+			// skip multiple feeds to the same channel
+			if f.ChannelID == r.ChannelID {
+				continue OUTER
+			}
+		}
+
+		relevantFeeds = append(relevantFeeds, f)
+	}
+
+	if len(relevantFeeds) < 1 {
+		return
+	}
+
+	webhookUsername := "Mastodon • " + common.ConfBotName.GetString()
+	embed := CreateStatusEmbed(s)
+	for _, f := range relevantFeeds {
+		go analytics.RecordActiveUnit(f.GuildID, p, "posted_mastodon_message")
+
+		parseMentions := []discordgo.AllowedMentionType{}
+		var content string
+		if f.MentionRole != 0 {
+			parseMentions = []discordgo.AllowedMentionType{discordgo.AllowedMentionTypeRoles}
+			content = fmt.Sprintf("Hey <@&%d>, a new post from @%s!", f.MentionRole, f.Acct)
+		}
+
+		mqueue.QueueMessage(&mqueue.QueuedElement{
+			Source:       "mastodon",
+			SourceItemID: strconv.FormatUint(uint64(f.ID), 10),
+
+			GuildID:   f.GuildID,
+			ChannelID: f.ChannelID,
+
+			MessageStr:      content,
+			MessageEmbed:    embed,
+			UseWebhook:      true,
+			WebhookUsername: webhookUsername,
+
+			AllowedMentions: discordgo.AllowedMentions{
+				Parse: parseMentions,
+			},
+
+			Priority: 5, // same as twitter
+		})
+	}
+
+	feeds.MetricPostedMessages.With(prometheus.Labels{"source": "mastodon"}).Add(float64(len(relevantFeeds)))
+}
+
+// CreateStatusEmbed renders the status, boosts show the boosted status. Content warnings and
+// sensitive media are put behind spoilers, as embed images can't be.
+func CreateStatusEmbed(status *Status) *discordgo.MessageEmbed {
+	s := status
+	var text strings.Builder
+	if status.Reblog != nil {
+		s = status.Reblog
+		text.WriteString(fmt.Sprintf("🔁 [%s](%s) boosted\n\n", status.Account.Name(), status.Account.URL))
+	} else if s.InReplyToID != nil {
+		text.WriteString("↩️ Reply\n\n")
+	}
+
+	content := ContentText(s.Content)
+	if s.SpoilerText != "" {
+		text.WriteString("**CW: " + s.SpoilerText + "**\n")
+		if content != "" {
+			text.WriteString("||" + content + "||")
+		}
+	} else {
+		text.WriteString(content)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Author: &discordgo.MessageEmbedAuthor{
+			Name:    s.Account.Name() + " (@" + s.Account.Acct + ")",
+			URL:     s.Account.URL,
+			IconURL: s.Account.Avatar,
+		},
+		URL:       s.URL,
+		Color:     embedColor,
+		Timestamp: s.CreatedAt.Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Mastodon",
+		},
+	}
+
+	hideMedia := s.Sensitive || s.SpoilerText != ""
+	var links []string
+	for i, m := range s.MediaAttachments {
+		if i == 0 && !hideMedia && (m.Type == "image" || m.Type == "gifv") {
+			embed.Image = &discordgo.MessageEmbedImage{URL: m.PreviewURL}
+			if m.Type == "image" {
+				embed.Image.URL = m.URL
+			}
+			continue
+		}
+
+		link := fmt.Sprintf("[%s %d](%s)", m.Type, i+1, m.URL)
+		if hideMedia {
+			link = "||" + link + "||"
+		}
+		links = append(links, link)
+	}
+
+	if len(links) > 0 {
+		text.WriteString("\n\n" + strings.Join(links, " "))
+	}
+
+	embed.Description = common.CutStringShort(text.String(), 4000)
+	return embed
+}
+
+func disableAccountFeeds(instance, accountID string) {
+	err := common.GORM.Model(&Feed{}).Where("instance = ? AND account_id = ?", instance, accountID).Update("enabled", false).Error
+	if err != nil {
+		logger.WithError(err).WithField("account_id", accountID).Error("failed disabling feeds")
+	}
+}
@@ -0,0 +1,82 @@
+package mastodon
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/common/config"
+	"github.com/mrbentarikau/pagst/common/mqueue"
+)
+
+const (
+	GuildMaxFeeds        = 5
+	GuildMaxFeedsPremium = 25
+)
+
+var (
+	logger                    = common.GetPluginLogger(&Plugin{})
+	confMastodonPollFrequency = config.RegisterOption("yagpdb.mastodon.poll_frequency", "Delay in minutes between polls of all mastodon feeds", 5)
+	confMastodonPollWorkers   = config.RegisterOption("yagpdb.mastodon.poll_workers", "Number of accounts polled concurrently", 5)
+)
+
+func KeyLastStatusID(instance, accountID string) string {
+	return "mastodon_last_status_id:" + instance + ":" + accountID
+}
+
+type Plugin struct {
+	Stop chan *sync.WaitGroup
+}
+
+func (p *Plugin) PluginInfo() *common.PluginInfo {
+	return &common.PluginInfo{
+		Name:     "Mastodon",
+		SysName:  "mastodon",
+		Category: common.PluginCategoryFeeds,
+	}
+}
+
+func RegisterPlugin() {
+	err := common.GORM.AutoMigrate(&Feed{}).Error
+	if err != nil {
+		panic(err)
+	}
+
+	p := &Plugin{}
+	mqueue.RegisterSource("mastodon", p)
+	common.RegisterPlugin(p)
+}
+
+// Feed posts the statuses of a mastodon account in a channel
+type Feed struct {
+	common.SmallModel
+
+	GuildID     int64 `gorm:"index"`
+	ChannelID   int64
+	MentionRole int64
+
+	// Instance is the host of the server the account is on, AccountID the id of the account on it
+	Instance  string
+	AccountID string
+	// Acct is the full address of the account, user@instance
+	Acct string
+
+	IncludeBoosts  bool
+	IncludeReplies bool
+	Enabled        bool
+}
+
+func (f *Feed) TableName() string {
+	return "mastodon_feeds"
+}
+
+// AccountKey identifies the account of the feed, feeds of the same account are polled once
+func (f *Feed) AccountKey() string {
+	return f.Instance + "/" + f.AccountID
+}
+
+// ProfileURL returns the link to the profile of the account
+func (f *Feed) ProfileURL() string {
+	username := strings.TrimSuffix(f.Acct, "@"+f.Instance)
+	return "https://" + f.Instance + "/@" + username
+}
@@ -0,0 +1,107 @@
+package mastodon
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseAccountAddress(t *testing.T) {
+	cases := []struct {
+		Input    string
+		Username string
+		Instance string
+		Valid    bool
+	}{
+		{"@Gargron@mastodon.social", "Gargron", "mastodon.social", true},
+		{"gargron@Mastodon.Social", "gargron", "mastodon.social", true},
+		{"https://mastodon.social/@Gargron", "Gargron", "mastodon.social", true},
+		{"https://fosstodon.org/users/some_one/", "some_one", "fosstodon.org", true},
+		{"@gargron", "", "", false},
+		{"https://mastodon.social/@Gargron/1234", "", "", false},
+		{"gargron@localhost", "", "", false},
+	}
+
+	for _, c := range cases {
+		username, instance, err := ParseAccountAddress(c.Input)
+		if (err == nil) != c.Valid {
+			t.Errorf("%q: got error %v, expected valid %t", c.Input, err, c.Valid)
+			continue
+		}
+
+		if username != c.Username || instance != c.Instance {
+			t.Errorf("%q: got %q %q, expected %q %q", c.Input, username, instance, c.Username, c.Instance)
+		}
+	}
+}
+
+func TestContentText(t *testing.T) {
+	content := `<p>Hello <a href="https://example.social/@bob" class="u-url mention">@<span>bob</span></a> &amp; friends<br>second line</p><p>see <a href="https://example.com/a/long/path"><span class="invisible">https://</span><span class="ellipsis">example.com/a/long</span><span class="invisible">/path</span></a></p>`
+	expected := "Hello @bob & friends\nsecond line\n\nsee https://example.com/a/long/path"
+
+	if got := ContentText(content); got != expected {
+		t.Errorf("got %q, expected %q", got, expected)
+	}
+}
+
+func TestShouldPost(t *testing.T) {
+	self := "1"
+	other := "2"
+	statusID := "10"
+
+	cases := []struct {
+		Name     string
+		Status   *Status
+		Boosts   bool
+		Replies  bool
+		Expected bool
+	}{
+		{"post", &Status{Visibility: "public"}, false, false, true},
+		{"boost excluded", &Status{Visibility: "public", Reblog: &Status{}}, false, false, false},
+		{"boost included", &Status{Visibility: "public", Reblog: &Status{}}, true, false, true},
+		{"reply excluded", &Status{Visibility: "public", InReplyToID: &statusID, InReplyToAccountID: &other}, false, false, false},
+		{"reply included", &Status{Visibility: "public", InReplyToID: &statusID, InReplyToAccountID: &other}, false, true, true},
+		{"thread", &Status{Visibility: "public", InReplyToID: &statusID, InReplyToAccountID: &self}, false, false, true},
+		{"followers only", &Status{Visibility: "private"}, true, true, false},
+	}
+
+	for _, c := range cases {
+		f := &Feed{AccountID: self, IncludeBoosts: c.Boosts, IncludeReplies: c.Replies}
+		if got := ShouldPost(f, c.Status); got != c.Expected {
+			t.Errorf("%s: got %t, expected %t", c.Name, got, c.Expected)
+		}
+	}
+}
+
+func TestCreateStatusEmbedSpoilers(t *testing.T) {
+	status := &Status{
+		Content:     "<p>the plot twist</p>",
+		SpoilerText: "movie spoilers",
+		Sensitive:   true,
+		Account:     Account{Username: "bob", Acct: "bob"},
+		MediaAttachments: []MediaAttachment{
+			{Type: "image", URL: "https://example.social/a.png"},
+		},
+	}
+
+	embed := CreateStatusEmbed(status)
+	if embed.Image != nil {
+		t.Errorf("sensitive media should not be embedded")
+	}
+
+	for _, part := range []string{"**CW: movie spoilers**", "||the plot twist||", "||[image 1](https://example.social/a.png)||"} {
+		if !strings.Contains(embed.Description, part) {
+			t.Errorf("expected %q in %q", part, embed.Description)
+		}
+	}
+
+	status.SpoilerText = ""
+	status.Sensitive = false
+	embed = CreateStatusEmbed(status)
+	if embed.Image == nil || embed.Image.URL != "https://example.social/a.png" {
+		t.Errorf("expected the image to be embedded, got %+v", embed.Image)
+	}
+
+	if embed.Description != "the plot twist" {
+		t.Errorf("unexpected description %q", embed.Description)
+	}
+}
@@ -0,0 +1,249 @@
+package mastodon
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/common/cplogs"
+	"github.com/mrbentarikau/pagst/lib/discordgo"
+	"github.com/mrbentarikau/pagst/premium"
+	"github.com/mrbentarikau/pagst/web"
+	"goji.io"
+	"goji.io/pat"
+)
+
+//go:embed assets/mastodon.html
+var PageHTML string
+
+type Form struct {
+	Account        string `valid:",1,256,trimspace"`
+	DiscordChannel int64  `valid:"channel,false"`
+	MentionRole    int64  `valid:"role,true"`
+	IncludeBoosts  bool
+	IncludeReplies bool
+}
+
+type EditForm struct {
+	DiscordChannel int64 `valid:"channel,false"`
+	MentionRole    int64 `valid:"role,true"`
+	IncludeBoosts  bool
+	IncludeReplies bool
+	Enabled        bool
+}
+
+var (
+	panelLogKeyAddedFeed   = cplogs.RegisterActionFormat(&cplogs.ActionFormat{Key: "mastodon_added_feed", FormatString: "Added mastodon feed from %s"})
+	panelLogKeyRemovedFeed = cplogs.RegisterActionFormat(&cplogs.ActionFormat{Key: "mastodon_removed_feed", FormatString: "Removed mastodon feed from %s"})
+	panelLogKeyUpdatedFeed = cplogs.RegisterActionFormat(&cplogs.ActionFormat{Key: "mastodon_updated_feed", FormatString: "Updated mastodon feed from %s"})
+)
+
+func (p *Plugin) InitWeb() {
+	web.AddHTMLTemplate("mastodon/assets/mastodon.html", PageHTML)
+	web.AddSidebarItem(web.SidebarCategoryFeeds, &web.SidebarItem{
+		Name: "Mastodon Feeds",
+		URL:  "mastodon",
+		Icon: "fab fa-mastodon",
+	})
+
+	mux := goji.SubMux()
+	mux.Use(web.RequireBotMemberMW)
+	mux.Use(web.RequirePermMW(discordgo.PermissionManageWebhooks))
+	web.CPMux.Handle(pat.New("/mastodon/*"), mux)
+	web.CPMux.Handle(pat.New("/mastodon"), mux)
+
+	mainGetHandler := web.ControllerHandler(p.HandleMastodon, "cp_mastodon")
+
+	mux.Handle(pat.Get("/"), mainGetHandler)
+	mux.Handle(pat.Get(""), mainGetHandler)
+
+	addHandler := web.ControllerPostHandler(p.HandleNew, mainGetHandler, Form{})
+
+	mux.Handle(pat.Post(""), addHandler)
+	mux.Handle(pat.Post("/"), addHandler)
+	mux.Handle(pat.Post("/:item/update"), web.ControllerPostHandler(BaseEditHandler(p.HandleEdit), mainGetHandler, EditForm{}))
+	mux.Handle(pat.Post("/:item/delete"), web.ControllerPostHandler(BaseEditHandler(p.HandleRemove), mainGetHandler, nil))
+}
+
+func (p *Plugin) HandleMastodon(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ag, templateData := web.GetBaseCPContextData(r.Context())
+
+	var result []*Feed
+	err := common.GORM.Where("guild_id = ?", ag.ID).Order("id asc").Find(&result).Error
+	if err != nil {
+		return templateData, err
+	}
+
+	templateData["FeedItems"] = result
+	templateData["MaxFeeds"] = maxFeedsForContext(r.Context())
+
+	return templateData, nil
+}
+
+func (p *Plugin) HandleNew(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ctx := r.Context()
+	activeGuild, templateData := web.GetBaseCPContextData(ctx)
+	form := ctx.Value(common.ContextKeyParsedForm).(*Form)
+
+	count, err := countEnabledFeeds(activeGuild.ID)
+	if err != nil {
+		return templateData, err
+	}
+
+	if count >= maxFeedsForContext(ctx) {
+		return templateData.AddAlerts(web.ErrorAlert(fmt.Sprintf("Max %d enabled Mastodon feeds allowed (%d for premium servers)", GuildMaxFeeds, GuildMaxFeedsPremium))), nil
+	}
+
+	username, instance, err := ParseAccountAddress(form.Account)
+	if err != nil {
+		return templateData.AddAlerts(web.ErrorAlert(err)), nil
+	}
+
+	lookupCtx, cancel := context.WithTimeout(ctx, time.Second*15)
+	defer cancel()
+
+	account, err := LookupAccount(lookupCtx, instance, username)
+	if err != nil {
+		return templateData.AddAlerts(web.ErrorAlert("Account not found on " + instance)), nil
+	}
+
+	var existing int
+	err = common.GORM.Model(&Feed{}).Where("guild_id = ? AND instance = ? AND account_id = ? AND channel_id = ?", activeGuild.ID, instance, account.ID, form.DiscordChannel).Count(&existing).Error
+	if err != nil {
+		return templateData, err
+	}
+
+	if existing > 0 {
+		return templateData.AddAlerts(web.ErrorAlert("That account is already followed in that channel")), nil
+	}
+
+	f := &Feed{
+		GuildID:        activeGuild.ID,
+		ChannelID:      form.DiscordChannel,
+		MentionRole:    form.MentionRole,
+		Instance:       instance,
+		AccountID:      account.ID,
+		Acct:           account.Username + "@" + instance,
+		IncludeBoosts:  form.IncludeBoosts,
+		IncludeReplies: form.IncludeReplies,
+		Enabled:        true,
+	}
+
+	err = common.GORM.Create(f).Error
+	if err == nil {
+		go cplogs.RetryAddEntry(web.NewLogEntryFromContext(r.Context(), panelLogKeyAddedFeed, &cplogs.Param{Type: cplogs.ParamTypeString, Value: f.Acct}))
+	}
+	return templateData, err
+}
+
+type ContextKey int
+
+const (
+	ContextKeySub ContextKey = iota
+)
+
+func BaseEditHandler(inner web.ControllerHandlerFunc) web.ControllerHandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+		ctx := r.Context()
+		activeGuild, templateData := web.GetBaseCPContextData(ctx)
+
+		id, _ := strconv.ParseInt(pat.Param(r, "item"), 10, 64)
+
+		var f Feed
+		err := common.GORM.Where("guild_id = ? AND id = ?", activeGuild.ID, id).First(&f).Error
+		if err != nil {
+			return templateData.AddAlerts(web.ErrorAlert("Failed retrieving that feed item")), err
+		}
+
+		ctx = context.WithValue(ctx, ContextKeySub, &f)
+
+		return inner(w, r.WithContext(ctx))
+	}
+}
+
+func (p *Plugin) HandleEdit(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ctx := r.Context()
+	activeGuild, templateData := web.GetBaseCPContextData(ctx)
+
+	f := ctx.Value(ContextKeySub).(*Feed)
+	data := ctx.Value(common.ContextKeyParsedForm).(*EditForm)
+
+	if data.Enabled && !f.Enabled {
+		count, err := countEnabledFeeds(activeGuild.ID)
+		if err != nil {
+			return templateData, err
+		}
+
+		if count >= maxFeedsForContext(ctx) {
+			return templateData.AddAlerts(web.ErrorAlert(fmt.Sprintf("Max %d enabled Mastodon feeds allowed (%d for premium servers)", GuildMaxFeeds, GuildMaxFeedsPremium))), nil
+		}
+	}
+
+	f.ChannelID = data.DiscordChannel
+	f.MentionRole = data.MentionRole
+	f.IncludeBoosts = data.IncludeBoosts
+	f.IncludeReplies = data.IncludeReplies
+	f.Enabled = data.Enabled
+
+	err := common.GORM.Save(f).Error
+	if err == nil {
+		go cplogs.RetryAddEntry(web.NewLogEntryFromContext(r.Context(), panelLogKeyUpdatedFeed, &cplogs.Param{Type: cplogs.ParamTypeString, Value: f.Acct}))
+	}
+	return templateData, err
+}
+
+func (p *Plugin) HandleRemove(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ctx := r.Context()
+	_, templateData := web.GetBaseCPContextData(ctx)
+
+	f := ctx.Value(ContextKeySub).(*Feed)
+	err := common.GORM.Delete(f).Error
+	if err == nil {
+		go cplogs.RetryAddEntry(web.NewLogEntryFromContext(r.Context(), panelLogKeyRemovedFeed, &cplogs.Param{Type: cplogs.ParamTypeString, Value: f.Acct}))
+	}
+	return templateData, err
+}
+
+func countEnabledFeeds(guildID int64) (int, error) {
+	var count int
+	err := common.GORM.Model(&Feed{}).Where("guild_id = ? AND enabled = ?", guildID, true).Count(&count).Error
+	return count, err
+}
+
+func maxFeedsForContext(ctx context.Context) int {
+	if premium.ContextPremium(ctx) {
+		return GuildMaxFeedsPremium
+	}
+
+	return GuildMaxFeeds
+}
+
+var _ web.PluginWithServerHomeWidget = (*Plugin)(nil)
+
+func (p *Plugin) LoadServerHomeWidget(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ag, templateData := web.GetBaseCPContextData(r.Context())
+
+	templateData["WidgetTitle"] = "Mastodon feeds"
+	templateData["SettingsPath"] = "/mastodon"
+
+	numFeeds, err := countEnabledFeeds(ag.ID)
+	if err != nil {
+		return templateData, err
+	}
+
+	if numFeeds > 0 {
+		templateData["WidgetEnabled"] = true
+	} else {
+		templateData["WidgetDisabled"] = true
+	}
+
+	const format = `<p>Active Mastodon feeds: <code>%d</code></p>`
+	templateData["WidgetBody"] = template.HTML(fmt.Sprintf(format, numFeeds))
+
+	return templateData, nil
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/mrbentarikau/pagst/bot/paginatedmessages"
 	"github.com/mrbentarikau/pagst/commands"
 	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/common/i18n"
 	"github.com/mrbentarikau/pagst/common/scheduledevents2"
 	"github.com/mrbentarikau/pagst/lib/dcmd"
 	"github.com/mrbentarikau/pagst/lib/discordgo"
@@ -177,7 +178,7 @@ var ModerationCommands = []*commands.YAGCommand{
 			}
 
 			if utf8.RuneCountInString(reason) > 470 {
-				return "Error: Reason too long (can be max 470 characters).", nil
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.reason_too_long", 470), nil
 			}
 
 			err = checkHierarchy(parsed, parsed.Args[0].Int64())
@@ -352,7 +353,7 @@ var ModerationCommands = []*commands.YAGCommand{
 			}
 			targetMem, _ := bot.GetMember(parsed.GuildData.GS.ID, targetID)
 			if targetMem != nil {
-				return "User is not banned!", nil
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.not_banned"), nil
 			}
 
 			isNotBanned, err := UnbanUser(config, parsed.GuildData.GS.ID, parsed.Author, reason, target)
@@ -361,7 +362,7 @@ var ModerationCommands = []*commands.YAGCommand{
 				return nil, err
 			}
 			if isNotBanned {
-				return "User is not banned!", nil
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.not_banned"), nil
 			}
 
 			return GenericCmdResp(MAUnbanned, target, 0, true, true), nil
@@ -399,11 +400,11 @@ var ModerationCommands = []*commands.YAGCommand{
 
 			member, err := bot.GetMember(parsed.GuildData.GS.ID, target.ID)
 			if err != nil || member == nil {
-				return "Member not found", err
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.member_not_found"), err
 			}
 
 			if utf8.RuneCountInString(reason) > 470 {
-				return "Error: Reason too long (can be max 470 characters).", nil
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.reason_too_long", 470), nil
 			}
 
 			toDel := -1
@@ -452,7 +453,7 @@ var ModerationCommands = []*commands.YAGCommand{
 			}
 
 			if config.MuteRole == "" {
-				return fmt.Sprintf("No mute role selected. Select one at <%s/manage/%d/moderation>", common.ConfHost.GetString(), parsed.GuildData.GS.ID), nil
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.no_mute_role_selected", common.ConfHost.GetString(), parsed.GuildData.GS.ID), nil
 			}
 
 			reason := parsed.Args[2].Str()
@@ -473,7 +474,7 @@ var ModerationCommands = []*commands.YAGCommand{
 
 			member, err := bot.GetMember(parsed.GuildData.GS.ID, target.ID)
 			if err != nil || member == nil {
-				return "Member not found", err
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.member_not_found"), err
 			}
 
 			var msg *discordgo.Message
@@ -534,7 +535,7 @@ var ModerationCommands = []*commands.YAGCommand{
 
 			member, err := bot.GetMember(parsed.GuildData.GS.ID, target.ID)
 			if err != nil || member == nil {
-				return "Member not found", err
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.member_not_found"), err
 			}
 
 			var msg *discordgo.Message
@@ -578,12 +579,12 @@ var ModerationCommands = []*commands.YAGCommand{
 
 			member, err := bot.GetMember(parsed.GuildData.GS.ID, target.ID)
 			if err != nil || member == nil {
-				return "Member not found", err
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.member_not_found"), err
 			}
 
 			memberTimeout := member.Member.TimeoutExpiresAt
 			if memberTimeout == nil || memberTimeout.Before(time.Now()) {
-				return "Member is not timed out", nil
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.not_timed_out"), nil
 			}
 
 			err = RemoveTimeout(config, parsed.GuildData.GS.ID, parsed.Author, reason, &member.User)
@@ -616,7 +617,7 @@ var ModerationCommands = []*commands.YAGCommand{
 			}
 
 			if config.MuteRole == "" {
-				return "No mute role set up, assign a mute role in the control panel", nil
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.no_mute_role"), nil
 			}
 
 			reason := parsed.Args[1].Str()
@@ -627,7 +628,7 @@ var ModerationCommands = []*commands.YAGCommand{
 
 			member, err := bot.GetMember(parsed.GuildData.GS.ID, target.ID)
 			if err != nil || member == nil {
-				return "Member not found", err
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.member_not_found"), err
 			}
 
 			var msg *discordgo.Message
@@ -675,14 +676,14 @@ var ModerationCommands = []*commands.YAGCommand{
 			target := temp.User
 
 			if target.ID == parsed.Author.ID {
-				return "You can't report yourself, silly.", nil
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.report_self"), nil
 			}
 
 			logLink := CreateLogs(parsed.GuildData.GS.ID, parsed.GuildData.CS.ID, parsed.Author)
 
 			channelID := config.IntReportChannel()
 			if channelID == 0 {
-				return "No report channel set up", nil
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.no_report_channel"), nil
 			}
 
 			topContent := fmt.Sprintf("%s reported **%s (ID %d)**", parsed.Author.Mention(), target.String(), target.ID)
@@ -709,12 +710,12 @@ var ModerationCommands = []*commands.YAGCommand{
 
 			_, err = common.BotSession.ChannelMessageSendComplex(channelID, send)
 			if err != nil {
-				return "Something went wrong while sending your report!", err
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.report_failed"), err
 			}
 
 			// Don't bother sending confirmation if it is done in the report channel
 			if channelID != parsed.ChannelID || parsed.SlashCommandTriggerData != nil {
-				return "User reported to the proper authorities!", nil
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.reported"), nil
 			}
 
 			return nil, nil
@@ -915,7 +916,7 @@ var ModerationCommands = []*commands.YAGCommand{
 
 			numDeleted, err := AdvancedDeleteMessages(parsed.GuildData.GS.ID, parsed.ChannelID, triggerID, userFilter, re, invertRegexMatch, toID, fromID, ma, minAge, pe, onlyBots, onlyNotBots, ignoreUser, attachments, messagesOnly, embeds, noEmbeds, num, limitFetch)
 
-			return dcmd.NewTemporaryResponse(time.Second*5, fmt.Sprintf("Deleted %d message(s)! :')", numDeleted), true), err
+			return dcmd.NewTemporaryResponse(time.Second*5, i18n.N(i18n.LocaleFromData(parsed), "moderation.messages_deleted", numDeleted), true), err
 		},
 	},
 	{
@@ -943,7 +944,7 @@ var ModerationCommands = []*commands.YAGCommand{
 			}
 
 			if config.ActionChannel == "" {
-				return "No mod log channel set up", nil
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.no_modlog_channel"), nil
 			}
 
 			msg, err := common.BotSession.ChannelMessage(config.IntActionChannel(), parsed.Args[0].Int64())
@@ -952,11 +953,11 @@ var ModerationCommands = []*commands.YAGCommand{
 			}
 
 			if msg.Author.ID != common.BotUser.ID {
-				return "I didn't make that message", nil
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.not_my_message"), nil
 			}
 
 			if len(msg.Embeds) < 1 {
-				return "This entry is either too old or you're trying to mess with me...", nil
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.entry_too_old"), nil
 			}
 
 			embed := msg.Embeds[0]
@@ -995,7 +996,7 @@ var ModerationCommands = []*commands.YAGCommand{
 
 			member, err := bot.GetMember(parsed.GuildData.GS.ID, target.ID)
 			if err != nil || member == nil {
-				return "Member not found", err
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.member_not_found"), err
 			}
 
 			var msg *discordgo.Message
@@ -1046,7 +1047,7 @@ var ModerationCommands = []*commands.YAGCommand{
 					return nil, err
 				}
 				if len(warn) == 0 {
-					return fmt.Sprintf("Warning with given id : `%d` does not exist.", parsed.Switches["id"].Int()), nil
+					return i18n.T(i18n.LocaleFromData(parsed), "moderation.warning_not_found", parsed.Switches["id"].Int()), nil
 				}
 
 				username := warn[0].Username
@@ -1116,7 +1117,7 @@ var ModerationCommands = []*commands.YAGCommand{
 				"message", fmt.Sprintf("%s (updated by %s (%d))", parsed.Args[1].Str(), parsed.Author.String(), parsed.Author.ID)).RowsAffected
 
 			if rows < 1 {
-				return "Failed updating, most likely couldn't find the warning", nil
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.warning_update_failed"), nil
 			}
 
 			return "👌", nil
@@ -1149,7 +1150,7 @@ var ModerationCommands = []*commands.YAGCommand{
 
 			rows := common.GORM.Where("guild_id = ? AND id = ?", parsed.GuildData.GS.ID, parsed.Args[0].Int()).Delete(WarningModel{}).RowsAffected
 			if rows < 1 {
-				return "Failed deleting, most likely couldn't find the warning", nil
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.warning_delete_failed"), nil
 			}
 
 			return "👌", nil
@@ -1188,7 +1189,7 @@ var ModerationCommands = []*commands.YAGCommand{
 			if err != nil {
 				return "failed sending modlog", err
 			}
-			return fmt.Sprintf("Deleted %d warnings.", rows), nil
+			return i18n.N(i18n.LocaleFromData(parsed), "moderation.warnings_deleted", int(rows)), nil
 		},
 	},
 	{
@@ -1288,23 +1289,23 @@ var ModerationCommands = []*commands.YAGCommand{
 
 			member, err := bot.GetMember(parsed.GuildData.GS.ID, target.ID)
 			if err != nil || member == nil {
-				return "Member not found", err
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.member_not_found"), err
 			}
 
 			role := parsed.Args[1].Value.(*discordgo.Role)
 			if role == nil {
-				return "Couldn't find the specified role", nil
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.role_not_found"), nil
 			}
 
 			if !bot.IsMemberAboveRole(parsed.GuildData.GS, parsed.GuildData.MS, role) {
-				return "Can't give roles above you", nil
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.role_above_give"), nil
 			}
 
 			dur := parsed.Args[2].Value.(time.Duration)
 
 			// no point if the user has the role and is not updating the expiry
 			if common.ContainsInt64Slice(member.Member.Roles, role.ID) && dur <= 0 {
-				return "That user already has that role", nil
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.role_already_given"), nil
 			}
 
 			err = common.AddRoleDS(member, role.ID)
@@ -1364,16 +1365,16 @@ var ModerationCommands = []*commands.YAGCommand{
 
 			member, err := bot.GetMember(parsed.GuildData.GS.ID, target.ID)
 			if err != nil || member == nil {
-				return "Member not found", err
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.member_not_found"), err
 			}
 
 			role := parsed.Args[1].Value.(*discordgo.Role)
 			if role == nil {
-				return "Couldn't find the specified role", nil
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.role_not_found"), nil
 			}
 
 			if !bot.IsMemberAboveRole(parsed.GuildData.GS, parsed.GuildData.MS, role) {
-				return "Can't remove roles above you", nil
+				return i18n.T(i18n.LocaleFromData(parsed), "moderation.role_above_remove"), nil
 			}
 
 			err = common.RemoveRoleDS(member, role.ID)
@@ -0,0 +1,294 @@
+package reddit
+
+import (
+	"fmt"
+	"html"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/feeds"
+	"github.com/mrbentarikau/pagst/lib/go-reddit"
+	"github.com/mrbentarikau/pagst/reddit/models"
+)
+
+const (
+	MaxFiltersPerList = 50
+	MaxFilterLength   = 200
+)
+
+// Post types a feed can be limited to, stored as a bitmask in RedditFeed.PostTypes where 0 means all of them
+const (
+	PostTypeSelf = 1 << iota
+	PostTypeLink
+	PostTypeImage
+	PostTypeVideo
+	PostTypeGallery
+)
+
+type PostTypeOption struct {
+	Bit  int
+	Name string
+}
+
+// PostTypeOptions are shown in the control panel
+var PostTypeOptions = []PostTypeOption{
+	{PostTypeSelf, "Text"},
+	{PostTypeLink, "Link"},
+	{PostTypeImage, "Image"},
+	{PostTypeVideo, "Video"},
+	{PostTypeGallery, "Gallery"},
+}
+
+var imageExtensions = []string{".jpg", ".jpeg", ".png", ".gif", ".webp"}
+
+// PostType returns the type of the post, crossposts are the type of the original post
+func PostType(post *reddit.Link) int {
+	if post.CrosspostParent != "" && len(post.CrosspostParentList) > 0 {
+		post = post.CrosspostParentList[0]
+	}
+
+	switch {
+	case post.IsSelf:
+		return PostTypeSelf
+	case post.IsGallery:
+		return PostTypeGallery
+	case post.IsVideo || strings.HasSuffix(post.PostHint, ":video"):
+		return PostTypeVideo
+	case post.PostHint == "image":
+		return PostTypeImage
+	}
+
+	if common.ContainsStringSliceFold(imageExtensions, path.Ext(strings.SplitN(post.URL, "?", 2)[0])) {
+		return PostTypeImage
+	}
+
+	return PostTypeLink
+}
+
+// PostForumLabels returns the labels forum tags can be mapped from: the subreddit, the flair,
+// the type of the post and whether it's nsfw or a spoiler
+func PostForumLabels(post *reddit.Link) []string {
+	labels := []string{post.Subreddit}
+	if flair := strings.TrimSpace(html.UnescapeString(post.LinkFlairText)); flair != "" {
+		labels = append(labels, flair)
+	}
+
+	postType := PostType(post)
+	for _, option := range PostTypeOptions {
+		if option.Bit == postType {
+			labels = append(labels, option.Name)
+		}
+	}
+
+	if post.Over18 {
+		labels = append(labels, "nsfw")
+	}
+
+	if post.Spoiler {
+		labels = append(labels, "spoiler")
+	}
+
+	return labels
+}
+
+// titleFilter matches the title of posts on a plain word or a regex
+type titleFilter struct {
+	Source string
+	re     *regexp.Regexp
+}
+
+// FeedFilters are the flair, title, author and post type filters of a feed
+type FeedFilters struct {
+	IncludeFlairs  []string
+	ExcludeFlairs  []string
+	IncludeTitles  []*titleFilter
+	ExcludeTitles  []*titleFilter
+	IncludeAuthors []string
+	ExcludeAuthors []string
+	PostTypes      int
+}
+
+// splitFilterList returns the non empty lines of s
+func splitFilterList(s string) ([]string, error) {
+	var result []string
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if len(line) > MaxFilterLength {
+			return nil, fmt.Errorf("filter `%s` is too long (max %d characters)", common.CutStringShort(line, 50), MaxFilterLength)
+		}
+
+		result = append(result, line)
+	}
+
+	if len(result) > MaxFiltersPerList {
+		return nil, fmt.Errorf("too many filters (%d/%d)", len(result), MaxFiltersPerList)
+	}
+
+	return result, nil
+}
+
+// ParseAuthors parses a list of reddit usernames, one per line, with or without the u/ prefix
+func ParseAuthors(s string) ([]string, error) {
+	authors, err := splitFilterList(s)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, v := range authors {
+		authors[i] = TrimUserPrefix(v)
+	}
+
+	return authors, nil
+}
+
+// TrimUserPrefix removes the u/ or /u/ in front of a reddit username
+func TrimUserPrefix(name string) string {
+	name = strings.TrimPrefix(strings.TrimSpace(name), "/")
+	lower := strings.ToLower(name)
+	for _, prefix := range []string{"u/", "user/"} {
+		if strings.HasPrefix(lower, prefix) {
+			return name[len(prefix):]
+		}
+	}
+
+	return name
+}
+
+func parseTitleFilters(s string) ([]*titleFilter, error) {
+	lines, err := splitFilterList(s)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*titleFilter, 0, len(lines))
+	for _, line := range lines {
+		re, err := feeds.CompileFilterPattern(line)
+		if err != nil {
+			return nil, fmt.Errorf("filter `%s` is not a valid regex: %v", line, err)
+		}
+
+		result = append(result, &titleFilter{Source: line, re: re})
+	}
+
+	return result, nil
+}
+
+// ParseFeedFilters parses the filters of the feed
+func ParseFeedFilters(feed *models.RedditFeed) (*FeedFilters, error) {
+	var err error
+	filters := &FeedFilters{PostTypes: feed.PostTypes}
+
+	if filters.IncludeFlairs, err = splitFilterList(feed.IncludeFlairs); err != nil {
+		return nil, fmt.Errorf("include flairs: %w", err)
+	}
+
+	if filters.ExcludeFlairs, err = splitFilterList(feed.ExcludeFlairs); err != nil {
+		return nil, fmt.Errorf("exclude flairs: %w", err)
+	}
+
+	if filters.IncludeTitles, err = parseTitleFilters(feed.IncludeKeywords); err != nil {
+		return nil, fmt.Errorf("include title filters: %w", err)
+	}
+
+	if filters.ExcludeTitles, err = parseTitleFilters(feed.ExcludeKeywords); err != nil {
+		return nil, fmt.Errorf("exclude title filters: %w", err)
+	}
+
+	if filters.IncludeAuthors, err = ParseAuthors(feed.IncludeAuthors); err != nil {
+		return nil, fmt.Errorf("allowed authors: %w", err)
+	}
+
+	if filters.ExcludeAuthors, err = ParseAuthors(feed.ExcludeAuthors); err != nil {
+		return nil, fmt.Errorf("blocked authors: %w", err)
+	}
+
+	return filters, nil
+}
+
+// Check returns whether the post passes the filters, and if not the reason why
+func (f *FeedFilters) Check(post *reddit.Link) (bool, string) {
+	if f.PostTypes != 0 && f.PostTypes&PostType(post) == 0 {
+		return false, "post type"
+	}
+
+	if common.ContainsStringSliceFold(f.ExcludeAuthors, post.Author) {
+		return false, "blocked author"
+	}
+
+	if len(f.IncludeAuthors) > 0 && !common.ContainsStringSliceFold(f.IncludeAuthors, post.Author) {
+		return false, "author not allowed"
+	}
+
+	flair := strings.TrimSpace(html.UnescapeString(post.LinkFlairText))
+	if flair != "" && common.ContainsStringSliceFold(f.ExcludeFlairs, flair) {
+		return false, "excluded flair"
+	}
+
+	if len(f.IncludeFlairs) > 0 && !common.ContainsStringSliceFold(f.IncludeFlairs, flair) {
+		return false, "flair not included"
+	}
+
+	title := html.UnescapeString(post.Title)
+	for _, filter := range f.ExcludeTitles {
+		if filter.re.MatchString(title) {
+			return false, "title excluded by `" + filter.Source + "`"
+		}
+	}
+
+	if len(f.IncludeTitles) == 0 {
+		return true, ""
+	}
+
+	for _, filter := range f.IncludeTitles {
+		if filter.re.MatchString(title) {
+			return true, ""
+		}
+	}
+
+	return false, "no title filter matched"
+}
+
+type cachedFeedFilters struct {
+	feed    models.RedditFeed
+	filters *FeedFilters
+}
+
+// feedFiltersCache holds the parsed filters per feed id, so the regexes aren't compiled for every post
+var feedFiltersCache sync.Map
+
+// feedFilters returns the parsed filters of the feed, nil if it has none
+func feedFilters(feed *models.RedditFeed) (*FeedFilters, error) {
+	if feed.IncludeFlairs == "" && feed.ExcludeFlairs == "" && feed.IncludeKeywords == "" && feed.ExcludeKeywords == "" &&
+		feed.IncludeAuthors == "" && feed.ExcludeAuthors == "" && feed.PostTypes == 0 {
+		return nil, nil
+	}
+
+	if v, ok := feedFiltersCache.Load(feed.ID); ok {
+		cached := v.(*cachedFeedFilters)
+		if sameFilters(&cached.feed, feed) {
+			return cached.filters, nil
+		}
+	}
+
+	filters, err := ParseFeedFilters(feed)
+	if err != nil {
+		return nil, err
+	}
+
+	feedFiltersCache.Store(feed.ID, &cachedFeedFilters{feed: *feed, filters: filters})
+	return filters, nil
+}
+
+func sameFilters(a, b *models.RedditFeed) bool {
+	return a.IncludeFlairs == b.IncludeFlairs && a.ExcludeFlairs == b.ExcludeFlairs &&
+		a.IncludeKeywords == b.IncludeKeywords && a.ExcludeKeywords == b.ExcludeKeywords &&
+		a.IncludeAuthors == b.IncludeAuthors && a.ExcludeAuthors == b.ExcludeAuthors &&
+		a.PostTypes == b.PostTypes
+}
@@ -0,0 +1,119 @@
+package reddit
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mrbentarikau/pagst/lib/go-reddit"
+	"github.com/mrbentarikau/pagst/reddit/models"
+)
+
+func TestPostType(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Post     *reddit.Link
+		Expected int
+	}{
+		{"self", &reddit.Link{IsSelf: true}, PostTypeSelf},
+		{"gallery", &reddit.Link{IsGallery: true, URL: "https://www.reddit.com/gallery/abc"}, PostTypeGallery},
+		{"video", &reddit.Link{IsVideo: true, URL: "https://v.redd.it/abc"}, PostTypeVideo},
+		{"embedded video", &reddit.Link{PostHint: "rich:video", URL: "https://youtu.be/abc"}, PostTypeVideo},
+		{"image hint", &reddit.Link{PostHint: "image", URL: "https://i.redd.it/abc"}, PostTypeImage},
+		{"image extension", &reddit.Link{URL: "https://i.imgur.com/abc.PNG?width=100"}, PostTypeImage},
+		{"link", &reddit.Link{URL: "https://example.com/article"}, PostTypeLink},
+		{"crosspost", &reddit.Link{CrosspostParent: "t3_abc", URL: "/r/pics/comments/abc", CrosspostParentList: []*reddit.Link{{IsSelf: true}}}, PostTypeSelf},
+	}
+
+	for _, c := range cases {
+		if got := PostType(c.Post); got != c.Expected {
+			t.Errorf("%s: got %d, expected %d", c.Name, got, c.Expected)
+		}
+	}
+}
+
+func TestTrimUserPrefix(t *testing.T) {
+	cases := map[string]string{
+		"spez":         "spez",
+		"u/spez":       "spez",
+		"/u/spez":      "spez",
+		"U/Spez":       "Spez",
+		" user/spez ":  "spez",
+		"/user/spez":   "spez",
+		"username_u/x": "username_u/x",
+	}
+
+	for input, expected := range cases {
+		if got := TrimUserPrefix(input); got != expected {
+			t.Errorf("%q: got %q, expected %q", input, got, expected)
+		}
+	}
+}
+
+func TestFeedFiltersCheck(t *testing.T) {
+	feed := &models.RedditFeed{
+		IncludeFlairs:   "News\nDiscussion",
+		ExcludeKeywords: "spoiler\n/leak(ed)?/",
+		IncludeKeywords: "patch\nupdate",
+		ExcludeAuthors:  "u/AutoModerator",
+		PostTypes:       PostTypeSelf | PostTypeLink,
+	}
+
+	filters, err := ParseFeedFilters(feed)
+	if err != nil {
+		t.Fatalf("failed parsing filters: %v", err)
+	}
+
+	cases := []struct {
+		Name     string
+		Post     *reddit.Link
+		Expected bool
+	}{
+		{"match", &reddit.Link{IsSelf: true, Author: "bob", LinkFlairText: "news", Title: "New Patch notes"}, true},
+		{"escaped flair", &reddit.Link{IsSelf: true, Author: "bob", LinkFlairText: "Discussion ", Title: "update &amp; more"}, true},
+		{"wrong type", &reddit.Link{PostHint: "image", Author: "bob", LinkFlairText: "News", Title: "patch"}, false},
+		{"blocked author", &reddit.Link{IsSelf: true, Author: "automoderator", LinkFlairText: "News", Title: "patch"}, false},
+		{"no flair", &reddit.Link{IsSelf: true, Author: "bob", Title: "patch"}, false},
+		{"other flair", &reddit.Link{IsSelf: true, Author: "bob", LinkFlairText: "Meme", Title: "patch"}, false},
+		{"excluded word", &reddit.Link{IsSelf: true, Author: "bob", LinkFlairText: "News", Title: "Patch SPOILER inside"}, false},
+		{"excluded regex", &reddit.Link{IsSelf: true, Author: "bob", LinkFlairText: "News", Title: "leaked patch"}, false},
+		{"partial word", &reddit.Link{IsSelf: true, Author: "bob", LinkFlairText: "News", Title: "dispatch"}, false},
+	}
+
+	for _, c := range cases {
+		if got, reason := filters.Check(c.Post); got != c.Expected {
+			t.Errorf("%s: got %t (%s), expected %t", c.Name, got, reason, c.Expected)
+		}
+	}
+
+	filters, err = ParseFeedFilters(&models.RedditFeed{IncludeAuthors: "alice\n/u/Bob"})
+	if err != nil {
+		t.Fatalf("failed parsing filters: %v", err)
+	}
+
+	if ok, _ := filters.Check(&reddit.Link{Author: "bob", URL: "https://example.com"}); !ok {
+		t.Errorf("expected allowed author to pass")
+	}
+
+	if ok, _ := filters.Check(&reddit.Link{Author: "carol", URL: "https://example.com"}); ok {
+		t.Errorf("expected other author to be filtered")
+	}
+}
+
+func TestParseFeedFiltersInvalid(t *testing.T) {
+	if _, err := ParseFeedFilters(&models.RedditFeed{IncludeKeywords: "/a(b/"}); err == nil {
+		t.Errorf("expected an error for an invalid regex")
+	}
+
+	if f, _ := feedFilters(&models.RedditFeed{ID: 1}); f != nil {
+		t.Errorf("expected no filters for a feed without any")
+	}
+}
+
+func TestPostForumLabels(t *testing.T) {
+	post := &reddit.Link{Subreddit: "pics", LinkFlairText: "OC &amp; more", PostHint: "image", Over18: true}
+	expected := []string{"pics", "OC & more", "Image", "nsfw"}
+
+	if got := PostForumLabels(post); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+}
@@ -24,72 +24,127 @@ import (
 
 // RedditFeed is an object representing the database table.
 type RedditFeed struct {
-	ID           int64            `boil:"id" json:"id" toml:"id" yaml:"id"`
-	GuildID      int64            `boil:"guild_id" json:"guild_id" toml:"guild_id" yaml:"guild_id"`
-	ChannelID    int64            `boil:"channel_id" json:"channel_id" toml:"channel_id" yaml:"channel_id"`
-	Subreddit    string           `boil:"subreddit" json:"subreddit" toml:"subreddit" yaml:"subreddit"`
-	FilterNSFW   int              `boil:"filter_nsfw" json:"filter_nsfw" toml:"filter_nsfw" yaml:"filter_nsfw"`
-	MinUpvotes   int              `boil:"min_upvotes" json:"min_upvotes" toml:"min_upvotes" yaml:"min_upvotes"`
-	UseEmbeds    bool             `boil:"use_embeds" json:"use_embeds" toml:"use_embeds" yaml:"use_embeds"`
-	Slow         bool             `boil:"slow" json:"slow" toml:"slow" yaml:"slow"`
-	Disabled     bool             `boil:"disabled" json:"disabled" toml:"disabled" yaml:"disabled"`
-	MentionRole  types.Int64Array `boil:"mention_role" json:"mention_role,omitempty" toml:"mention_role" yaml:"mention_role,omitempty"`
-	ShowSpoilers bool             `boil:"show_spoilers" json:"show_spoilers" toml:"show_spoilers" yaml:"show_spoilers"`
+	ID              int64            `boil:"id" json:"id" toml:"id" yaml:"id"`
+	GuildID         int64            `boil:"guild_id" json:"guild_id" toml:"guild_id" yaml:"guild_id"`
+	ChannelID       int64            `boil:"channel_id" json:"channel_id" toml:"channel_id" yaml:"channel_id"`
+	Subreddit       string           `boil:"subreddit" json:"subreddit" toml:"subreddit" yaml:"subreddit"`
+	FilterNSFW      int              `boil:"filter_nsfw" json:"filter_nsfw" toml:"filter_nsfw" yaml:"filter_nsfw"`
+	MinUpvotes      int              `boil:"min_upvotes" json:"min_upvotes" toml:"min_upvotes" yaml:"min_upvotes"`
+	UseEmbeds       bool             `boil:"use_embeds" json:"use_embeds" toml:"use_embeds" yaml:"use_embeds"`
+	Slow            bool             `boil:"slow" json:"slow" toml:"slow" yaml:"slow"`
+	Disabled        bool             `boil:"disabled" json:"disabled" toml:"disabled" yaml:"disabled"`
+	MentionRole     types.Int64Array `boil:"mention_role" json:"mention_role,omitempty" toml:"mention_role" yaml:"mention_role,omitempty"`
+	ShowSpoilers    bool             `boil:"show_spoilers" json:"show_spoilers" toml:"show_spoilers" yaml:"show_spoilers"`
+	AnnounceMsg     string           `boil:"announce_msg" json:"announce_msg" toml:"announce_msg" yaml:"announce_msg"`
+	UserFeed        bool             `boil:"user_feed" json:"user_feed" toml:"user_feed" yaml:"user_feed"`
+	IncludeFlairs   string           `boil:"include_flairs" json:"include_flairs" toml:"include_flairs" yaml:"include_flairs"`
+	ExcludeFlairs   string           `boil:"exclude_flairs" json:"exclude_flairs" toml:"exclude_flairs" yaml:"exclude_flairs"`
+	IncludeKeywords string           `boil:"include_keywords" json:"include_keywords" toml:"include_keywords" yaml:"include_keywords"`
+	ExcludeKeywords string           `boil:"exclude_keywords" json:"exclude_keywords" toml:"exclude_keywords" yaml:"exclude_keywords"`
+	IncludeAuthors  string           `boil:"include_authors" json:"include_authors" toml:"include_authors" yaml:"include_authors"`
+	ExcludeAuthors  string           `boil:"exclude_authors" json:"exclude_authors" toml:"exclude_authors" yaml:"exclude_authors"`
+	PostTypes       int              `boil:"post_types" json:"post_types" toml:"post_types" yaml:"post_types"`
+	ForumTags       string           `boil:"forum_tags" json:"forum_tags" toml:"forum_tags" yaml:"forum_tags"`
+	ThreadID        int64            `boil:"thread_id" json:"thread_id" toml:"thread_id" yaml:"thread_id"`
 
 	R *redditFeedR `boil:"-" json:"-" toml:"-" yaml:"-"`
 	L redditFeedL  `boil:"-" json:"-" toml:"-" yaml:"-"`
 }
 
 var RedditFeedColumns = struct {
-	ID           string
-	GuildID      string
-	ChannelID    string
-	Subreddit    string
-	FilterNSFW   string
-	MinUpvotes   string
-	UseEmbeds    string
-	Slow         string
-	Disabled     string
-	MentionRole  string
-	ShowSpoilers string
+	ID              string
+	GuildID         string
+	ChannelID       string
+	Subreddit       string
+	FilterNSFW      string
+	MinUpvotes      string
+	UseEmbeds       string
+	Slow            string
+	Disabled        string
+	MentionRole     string
+	ShowSpoilers    string
+	AnnounceMsg     string
+	UserFeed        string
+	IncludeFlairs   string
+	ExcludeFlairs   string
+	IncludeKeywords string
+	ExcludeKeywords string
+	IncludeAuthors  string
+	ExcludeAuthors  string
+	PostTypes       string
+	ForumTags       string
+	ThreadID        string
 }{
-	ID:           "id",
-	GuildID:      "guild_id",
-	ChannelID:    "channel_id",
-	Subreddit:    "subreddit",
-	FilterNSFW:   "filter_nsfw",
-	MinUpvotes:   "min_upvotes",
-	UseEmbeds:    "use_embeds",
-	Slow:         "slow",
-	Disabled:     "disabled",
-	MentionRole:  "mention_role",
-	ShowSpoilers: "show_spoilers",
+	ID:              "id",
+	GuildID:         "guild_id",
+	ChannelID:       "channel_id",
+	Subreddit:       "subreddit",
+	FilterNSFW:      "filter_nsfw",
+	MinUpvotes:      "min_upvotes",
+	UseEmbeds:       "use_embeds",
+	Slow:            "slow",
+	Disabled:        "disabled",
+	MentionRole:     "mention_role",
+	ShowSpoilers:    "show_spoilers",
+	AnnounceMsg:     "announce_msg",
+	UserFeed:        "user_feed",
+	IncludeFlairs:   "include_flairs",
+	ExcludeFlairs:   "exclude_flairs",
+	IncludeKeywords: "include_keywords",
+	ExcludeKeywords: "exclude_keywords",
+	IncludeAuthors:  "include_authors",
+	ExcludeAuthors:  "exclude_authors",
+	PostTypes:       "post_types",
+	ForumTags:       "forum_tags",
+	ThreadID:        "thread_id",
 }
 
 var RedditFeedTableColumns = struct {
-	ID           string
-	GuildID      string
-	ChannelID    string
-	Subreddit    string
-	FilterNSFW   string
-	MinUpvotes   string
-	UseEmbeds    string
-	Slow         string
-	Disabled     string
-	MentionRole  string
-	ShowSpoilers string
+	ID              string
+	GuildID         string
+	ChannelID       string
+	Subreddit       string
+	FilterNSFW      string
+	MinUpvotes      string
+	UseEmbeds       string
+	Slow            string
+	Disabled        string
+	MentionRole     string
+	ShowSpoilers    string
+	AnnounceMsg     string
+	UserFeed        string
+	IncludeFlairs   string
+	ExcludeFlairs   string
+	IncludeKeywords string
+	ExcludeKeywords string
+	IncludeAuthors  string
+	ExcludeAuthors  string
+	PostTypes       string
+	ForumTags       string
+	ThreadID        string
 }{
-	ID:           "reddit_feeds.id",
-	GuildID:      "reddit_feeds.guild_id",
-	ChannelID:    "reddit_feeds.channel_id",
-	Subreddit:    "reddit_feeds.subreddit",
-	FilterNSFW:   "reddit_feeds.filter_nsfw",
-	MinUpvotes:   "reddit_feeds.min_upvotes",
-	UseEmbeds:    "reddit_feeds.use_embeds",
-	Slow:         "reddit_feeds.slow",
-	Disabled:     "reddit_feeds.disabled",
-	MentionRole:  "reddit_feeds.mention_role",
-	ShowSpoilers: "reddit_feeds.show_spoilers",
+	ID:              "reddit_feeds.id",
+	GuildID:         "reddit_feeds.guild_id",
+	ChannelID:       "reddit_feeds.channel_id",
+	Subreddit:       "reddit_feeds.subreddit",
+	FilterNSFW:      "reddit_feeds.filter_nsfw",
+	MinUpvotes:      "reddit_feeds.min_upvotes",
+	UseEmbeds:       "reddit_feeds.use_embeds",
+	Slow:            "reddit_feeds.slow",
+	Disabled:        "reddit_feeds.disabled",
+	MentionRole:     "reddit_feeds.mention_role",
+	ShowSpoilers:    "reddit_feeds.show_spoilers",
+	AnnounceMsg:     "reddit_feeds.announce_msg",
+	UserFeed:        "reddit_feeds.user_feed",
+	IncludeFlairs:   "reddit_feeds.include_flairs",
+	ExcludeFlairs:   "reddit_feeds.exclude_flairs",
+	IncludeKeywords: "reddit_feeds.include_keywords",
+	ExcludeKeywords: "reddit_feeds.exclude_keywords",
+	IncludeAuthors:  "reddit_feeds.include_authors",
+	ExcludeAuthors:  "reddit_feeds.exclude_authors",
+	PostTypes:       "reddit_feeds.post_types",
+	ForumTags:       "reddit_feeds.forum_tags",
+	ThreadID:        "reddit_feeds.thread_id",
 }
 
 // Generated where
@@ -197,29 +252,51 @@ func (w whereHelpertypes_Int64Array) IsNull() qm.QueryMod    { return qmhelper.W
 func (w whereHelpertypes_Int64Array) IsNotNull() qm.QueryMod { return qmhelper.WhereIsNotNull(w.field) }
 
 var RedditFeedWhere = struct {
-	ID           whereHelperint64
-	GuildID      whereHelperint64
-	ChannelID    whereHelperint64
-	Subreddit    whereHelperstring
-	FilterNSFW   whereHelperint
-	MinUpvotes   whereHelperint
-	UseEmbeds    whereHelperbool
-	Slow         whereHelperbool
-	Disabled     whereHelperbool
-	MentionRole  whereHelpertypes_Int64Array
-	ShowSpoilers whereHelperbool
+	ID              whereHelperint64
+	GuildID         whereHelperint64
+	ChannelID       whereHelperint64
+	Subreddit       whereHelperstring
+	FilterNSFW      whereHelperint
+	MinUpvotes      whereHelperint
+	UseEmbeds       whereHelperbool
+	Slow            whereHelperbool
+	Disabled        whereHelperbool
+	MentionRole     whereHelpertypes_Int64Array
+	ShowSpoilers    whereHelperbool
+	AnnounceMsg     whereHelperstring
+	UserFeed        whereHelperbool
+	IncludeFlairs   whereHelperstring
+	ExcludeFlairs   whereHelperstring
+	IncludeKeywords whereHelperstring
+	ExcludeKeywords whereHelperstring
+	IncludeAuthors  whereHelperstring
+	ExcludeAuthors  whereHelperstring
+	PostTypes       whereHelperint
+	ForumTags       whereHelperstring
+	ThreadID        whereHelperint64
 }{
-	ID:           whereHelperint64{field: "\"reddit_feeds\".\"id\""},
-	GuildID:      whereHelperint64{field: "\"reddit_feeds\".\"guild_id\""},
-	ChannelID:    whereHelperint64{field: "\"reddit_feeds\".\"channel_id\""},
-	Subreddit:    whereHelperstring{field: "\"reddit_feeds\".\"subreddit\""},
-	FilterNSFW:   whereHelperint{field: "\"reddit_feeds\".\"filter_nsfw\""},
-	MinUpvotes:   whereHelperint{field: "\"reddit_feeds\".\"min_upvotes\""},
-	UseEmbeds:    whereHelperbool{field: "\"reddit_feeds\".\"use_embeds\""},
-	Slow:         whereHelperbool{field: "\"reddit_feeds\".\"slow\""},
-	Disabled:     whereHelperbool{field: "\"reddit_feeds\".\"disabled\""},
-	MentionRole:  whereHelpertypes_Int64Array{field: "\"reddit_feeds\".\"mention_role\""},
-	ShowSpoilers: whereHelperbool{field: "\"reddit_feeds\".\"show_spoilers\""},
+	ID:              whereHelperint64{field: "\"reddit_feeds\".\"id\""},
+	GuildID:         whereHelperint64{field: "\"reddit_feeds\".\"guild_id\""},
+	ChannelID:       whereHelperint64{field: "\"reddit_feeds\".\"channel_id\""},
+	Subreddit:       whereHelperstring{field: "\"reddit_feeds\".\"subreddit\""},
+	FilterNSFW:      whereHelperint{field: "\"reddit_feeds\".\"filter_nsfw\""},
+	MinUpvotes:      whereHelperint{field: "\"reddit_feeds\".\"min_upvotes\""},
+	UseEmbeds:       whereHelperbool{field: "\"reddit_feeds\".\"use_embeds\""},
+	Slow:            whereHelperbool{field: "\"reddit_feeds\".\"slow\""},
+	Disabled:        whereHelperbool{field: "\"reddit_feeds\".\"disabled\""},
+	MentionRole:     whereHelpertypes_Int64Array{field: "\"reddit_feeds\".\"mention_role\""},
+	ShowSpoilers:    whereHelperbool{field: "\"reddit_feeds\".\"show_spoilers\""},
+	AnnounceMsg:     whereHelperstring{field: "\"reddit_feeds\".\"announce_msg\""},
+	UserFeed:        whereHelperbool{field: "\"reddit_feeds\".\"user_feed\""},
+	IncludeFlairs:   whereHelperstring{field: "\"reddit_feeds\".\"include_flairs\""},
+	ExcludeFlairs:   whereHelperstring{field: "\"reddit_feeds\".\"exclude_flairs\""},
+	IncludeKeywords: whereHelperstring{field: "\"reddit_feeds\".\"include_keywords\""},
+	ExcludeKeywords: whereHelperstring{field: "\"reddit_feeds\".\"exclude_keywords\""},
+	IncludeAuthors:  whereHelperstring{field: "\"reddit_feeds\".\"include_authors\""},
+	ExcludeAuthors:  whereHelperstring{field: "\"reddit_feeds\".\"exclude_authors\""},
+	PostTypes:       whereHelperint{field: "\"reddit_feeds\".\"post_types\""},
+	ForumTags:       whereHelperstring{field: "\"reddit_feeds\".\"forum_tags\""},
+	ThreadID:        whereHelperint64{field: "\"reddit_feeds\".\"thread_id\""},
 }
 
 // RedditFeedRels is where relationship names are stored.
@@ -239,9 +316,9 @@ func (*redditFeedR) NewStruct() *redditFeedR {
 type redditFeedL struct{}
 
 var (
-	redditFeedAllColumns            = []string{"id", "guild_id", "channel_id", "subreddit", "filter_nsfw", "min_upvotes", "use_embeds", "slow", "disabled", "mention_role", "show_spoilers"}
+	redditFeedAllColumns            = []string{"id", "guild_id", "channel_id", "subreddit", "filter_nsfw", "min_upvotes", "use_embeds", "slow", "disabled", "mention_role", "show_spoilers", "announce_msg", "user_feed", "include_flairs", "exclude_flairs", "include_keywords", "exclude_keywords", "include_authors", "exclude_authors", "post_types", "forum_tags", "thread_id"}
 	redditFeedColumnsWithoutDefault = []string{"guild_id", "channel_id", "subreddit", "filter_nsfw", "min_upvotes", "use_embeds", "slow"}
-	redditFeedColumnsWithDefault    = []string{"id", "disabled", "mention_role", "show_spoilers"}
+	redditFeedColumnsWithDefault    = []string{"id", "disabled", "mention_role", "show_spoilers", "announce_msg", "user_feed", "include_flairs", "exclude_flairs", "include_keywords", "exclude_keywords", "include_authors", "exclude_authors", "post_types", "forum_tags", "thread_id"}
 	redditFeedPrimaryKeyColumns     = []string{"id"}
 	redditFeedGeneratedColumns      = []string{}
 )
@@ -13,7 +13,9 @@ import (
 	"github.com/mrbentarikau/pagst/common"
 	"github.com/mrbentarikau/pagst/common/cplogs"
 	"github.com/mrbentarikau/pagst/common/pubsub"
+	"github.com/mrbentarikau/pagst/feeds"
 	"github.com/mrbentarikau/pagst/lib/discordgo"
+	"github.com/mrbentarikau/pagst/lib/go-reddit"
 	"github.com/mrbentarikau/pagst/reddit/models"
 	"github.com/mrbentarikau/pagst/web"
 	"github.com/volatiletech/sqlboiler/v4/boil"
@@ -53,6 +55,71 @@ type UpdateForm struct {
 	MentionRole  []int64 `schema:"mention_role" valid:"role,true"`
 	FeedEnabled  bool    `schema:"feed_enabled"`
 	ShowSpoilers bool    `schema:"show_spoilers"`
+	AnnounceMsg  string  `schema:"announce_msg" valid:"template,2000"`
+
+	IncludeFlairs   string `schema:"include_flairs" valid:",5000"`
+	ExcludeFlairs   string `schema:"exclude_flairs" valid:",5000"`
+	IncludeKeywords string `schema:"include_keywords" valid:",5000"`
+	ExcludeKeywords string `schema:"exclude_keywords" valid:",5000"`
+	IncludeAuthors  string `schema:"include_authors" valid:",5000"`
+	ExcludeAuthors  string `schema:"exclude_authors" valid:",5000"`
+	PostTypes       []int  `schema:"post_types"`
+
+	ForumTags string `schema:"forum_tags" valid:",2000"`
+	ThreadID  int64  `schema:"thread_id"`
+}
+
+// filtersModel returns a feed with only the filters of the form set
+func (f *UpdateForm) filtersModel() *models.RedditFeed {
+	feed := &models.RedditFeed{
+		IncludeFlairs:   strings.TrimSpace(f.IncludeFlairs),
+		ExcludeFlairs:   strings.TrimSpace(f.ExcludeFlairs),
+		IncludeKeywords: strings.TrimSpace(f.IncludeKeywords),
+		ExcludeKeywords: strings.TrimSpace(f.ExcludeKeywords),
+		IncludeAuthors:  strings.TrimSpace(f.IncludeAuthors),
+		ExcludeAuthors:  strings.TrimSpace(f.ExcludeAuthors),
+	}
+
+	for _, v := range f.PostTypes {
+		for _, option := range PostTypeOptions {
+			if v == option.Bit {
+				feed.PostTypes |= v
+			}
+		}
+	}
+
+	return feed
+}
+
+func (f *UpdateForm) Validate(tmpl web.TemplateData, guildID int64) (ok bool) {
+	if _, err := ParseFeedFilters(f.filtersModel()); err != nil {
+		tmpl.AddAlerts(web.ErrorAlert("Filters: ", err))
+		return false
+	}
+
+	if _, err := feeds.ParseForumTagMapping(f.ForumTags); err != nil {
+		tmpl.AddAlerts(web.ErrorAlert("Forum tags: ", err))
+		return false
+	}
+
+	return true
+}
+
+// FeedDisplayName returns r/subreddit, or u/user for user feeds
+func FeedDisplayName(feed *models.RedditFeed) string {
+	if feed.UserFeed {
+		return "u/" + feed.Subreddit
+	}
+
+	return "r/" + feed.Subreddit
+}
+
+// AnnouncePreview is the announcement template of a feed rendered with an example post
+type AnnouncePreview struct {
+	FeedID      int64
+	AnnounceMsg string
+	Content     string
+	Error       string
 }
 
 var (
@@ -89,6 +156,7 @@ func (p *Plugin) InitWeb() {
 	redditMux.Handle(pat.Post("/"), addHandler)
 	redditMux.Handle(pat.Post("/:item/update"), web.FormParserMW(web.RenderHandler(HandleModify, "cp_reddit"), UpdateForm{}))
 	redditMux.Handle(pat.Post("/:item/delete"), web.RenderHandler(HandleRemove, "cp_reddit"))
+	redditMux.Handle(pat.Post("/:item/preview"), web.FormParserMW(web.RenderHandler(HandlePreview, "cp_reddit"), UpdateForm{}))
 }
 
 // Adds the current config to the context
@@ -97,6 +165,7 @@ func baseData(inner http.Handler) http.Handler {
 		ctx := r.Context()
 		activeGuild, templateData := web.GetBaseCPContextData(ctx)
 		templateData["VisibleURL"] = "/manage/" + discordgo.StrID(activeGuild.ID) + "/reddit/"
+		templateData["PostTypeOptions"] = PostTypeOptions
 
 		feeds, err := models.RedditFeeds(models.RedditFeedWhere.GuildID.EQ(activeGuild.ID)).AllG(ctx)
 		if web.CheckErr(templateData, err, "Failed retrieving config", web.CtxLogger(ctx).Error) {
@@ -134,7 +203,20 @@ func (p *Plugin) HandleNew(w http.ResponseWriter, r *http.Request) (web.Template
 
 	newElem := ctx.Value(common.ContextKeyParsedForm).(*CreateForm)
 
-	if !newElem.DisableSubredditSearch {
+	// u/name follows the posts of a reddit user instead of a subreddit
+	name := strings.TrimSpace(newElem.Subreddit)
+	userName := TrimUserPrefix(name)
+	userFeed := userName != name && userName != ""
+
+	if userFeed {
+		name = userName
+		if !newElem.DisableSubredditSearch {
+			account, err := p.redditClient.GetUserInfo(name)
+			if err != nil || account.Name == "" {
+				return templateData.AddAlerts(web.ErrorAlert(fmt.Sprintf(`No such reddit user u/%s<span class="ui-pnotify-text">Or try disabling the search...</span>`, name))), nil
+			}
+		}
+	} else if !newElem.DisableSubredditSearch {
 		if ok, err := p.redditClient.IsThereSubredditName(strings.ToLower(newElem.Subreddit)); !ok {
 			if err != nil {
 				return templateData.AddAlerts(web.ErrorAlert(fmt.Sprintf("Error quering Reddit %s", err))), err
@@ -156,7 +238,8 @@ func (p *Plugin) HandleNew(w http.ResponseWriter, r *http.Request) (web.Template
 	watchItem := &models.RedditFeed{
 		GuildID:      activeGuild.ID,
 		ChannelID:    newElem.Channel,
-		Subreddit:    strings.ToLower(strings.TrimSpace(newElem.Subreddit)),
+		Subreddit:    strings.ToLower(name),
+		UserFeed:     userFeed,
 		UseEmbeds:    newElem.UseEmbeds,
 		FilterNSFW:   newElem.NSFWMode,
 		MentionRole:  newElem.MentionRole,
@@ -184,12 +267,13 @@ func (p *Plugin) HandleNew(w http.ResponseWriter, r *http.Request) (web.Template
 	})
 
 	templateData["RedditConfig"] = currentConfig
-	templateData.AddAlerts(web.SucessAlert("Successfully added subreddit feed for /r/" + watchItem.Subreddit))
+	templateData.AddAlerts(web.SucessAlert("Successfully added reddit feed for " + FeedDisplayName(watchItem)))
 
-	go cplogs.RetryAddEntry(web.NewLogEntryFromContext(r.Context(), panelLogKeyAddedFeed, &cplogs.Param{Type: cplogs.ParamTypeString, Value: watchItem.Subreddit}))
+	go cplogs.RetryAddEntry(web.NewLogEntryFromContext(r.Context(), panelLogKeyAddedFeed, &cplogs.Param{Type: cplogs.ParamTypeString, Value: FeedDisplayName(watchItem)}))
 	go pubsub.Publish("reddit_clear_subreddit_cache", -1, PubSubSubredditEventData{
-		Subreddit: strings.ToLower(strings.TrimSpace(newElem.Subreddit)),
+		Subreddit: watchItem.Subreddit,
 		Slow:      newElem.Slow,
+		UserFeed:  userFeed,
 	})
 
 	return templateData, err
@@ -197,7 +281,7 @@ func (p *Plugin) HandleNew(w http.ResponseWriter, r *http.Request) (web.Template
 
 func HandleModify(w http.ResponseWriter, r *http.Request) interface{} {
 	ctx := r.Context()
-	_, templateData := web.GetBaseCPContextData(ctx)
+	activeGuild, templateData := web.GetBaseCPContextData(ctx)
 
 	currentConfig := ctx.Value(CurrentConfig).(models.RedditFeedSlice)
 	templateData["RedditConfig"] = currentConfig
@@ -213,12 +297,34 @@ func HandleModify(w http.ResponseWriter, r *http.Request) interface{} {
 		return templateData.AddAlerts(web.ErrorAlert("Unknown id"))
 	}
 
+	// an archived thread isn't in the state, keep it if nothing changed
+	if updated.ThreadID != item.ThreadID || updated.Channel != item.ChannelID {
+		if err := feeds.ValidateThread(activeGuild, updated.Channel, updated.ThreadID); err != nil {
+			return templateData.AddAlerts(web.ErrorAlert("Thread: ", err))
+		}
+	}
+
 	item.ChannelID = updated.Channel
+	item.ThreadID = updated.ThreadID
 	item.UseEmbeds = updated.UseEmbeds
 	item.FilterNSFW = updated.NSFWMode
 	item.MentionRole = updated.MentionRole
 	item.ShowSpoilers = updated.ShowSpoilers
+	item.AnnounceMsg = updated.AnnounceMsg
+	if strings.TrimSpace(item.AnnounceMsg) == "" {
+		item.AnnounceMsg = ""
+	}
 	item.Disabled = !updated.FeedEnabled
+
+	filters := updated.filtersModel()
+	item.IncludeFlairs = filters.IncludeFlairs
+	item.ExcludeFlairs = filters.ExcludeFlairs
+	item.IncludeKeywords = filters.IncludeKeywords
+	item.ExcludeKeywords = filters.ExcludeKeywords
+	item.IncludeAuthors = filters.IncludeAuthors
+	item.ExcludeAuthors = filters.ExcludeAuthors
+	item.PostTypes = filters.PostTypes
+	item.ForumTags = strings.TrimSpace(updated.ForumTags)
 	if item.Slow {
 		item.MinUpvotes = updated.MinUpvotes
 		item.MentionRole = updated.MentionRole
@@ -227,7 +333,8 @@ func HandleModify(w http.ResponseWriter, r *http.Request) interface{} {
 	if item.ChannelID == 0 {
 		item.Disabled = true
 	}
-	_, err := item.UpdateG(ctx, boil.Whitelist("channel_id", "use_embeds", "filter_nsfw", "min_upvotes", "disabled", "mention_role", "show_spoilers"))
+	_, err := item.UpdateG(ctx, boil.Whitelist("channel_id", "use_embeds", "filter_nsfw", "min_upvotes", "disabled", "mention_role", "show_spoilers", "announce_msg",
+		"include_flairs", "exclude_flairs", "include_keywords", "exclude_keywords", "include_authors", "exclude_authors", "post_types", "forum_tags", "thread_id"))
 
 	if web.CheckErr(templateData, err, "Failed saving item :'(", web.CtxLogger(ctx).Error) {
 		return templateData
@@ -235,15 +342,85 @@ func HandleModify(w http.ResponseWriter, r *http.Request) interface{} {
 
 	templateData.AddAlerts(web.SucessAlert("Successfully updated reddit feed! :D"))
 
-	go cplogs.RetryAddEntry(web.NewLogEntryFromContext(r.Context(), panelLogKeyUpdatedFeed, &cplogs.Param{Type: cplogs.ParamTypeString, Value: item.Subreddit}))
+	go cplogs.RetryAddEntry(web.NewLogEntryFromContext(r.Context(), panelLogKeyUpdatedFeed, &cplogs.Param{Type: cplogs.ParamTypeString, Value: FeedDisplayName(item)}))
 	go pubsub.Publish("reddit_clear_subreddit_cache", -1, PubSubSubredditEventData{
 		Subreddit: strings.ToLower(strings.TrimSpace(item.Subreddit)),
 		Slow:      item.Slow,
+		UserFeed:  item.UserFeed,
 	})
 
 	return templateData
 }
 
+// previewPost is the example post announcement previews are rendered with
+func previewPost(feed *models.RedditFeed) *reddit.Link {
+	subreddit, author := feed.Subreddit, "example_user"
+	if feed.UserFeed {
+		subreddit, author = "example", feed.Subreddit
+	}
+
+	return &reddit.Link{
+		ID:            "abc123",
+		Title:         "Example post",
+		Author:        author,
+		Subreddit:     subreddit,
+		URL:           "https://www.reddit.com/r/" + subreddit + "/comments/abc123/example_post/",
+		Permalink:     "/r/" + subreddit + "/comments/abc123/example_post/",
+		LinkFlairText: "Discussion",
+		Score:         42,
+		IsSelf:        true,
+		Selftext:      "The text of the post",
+	}
+}
+
+// HandlePreview renders the announcement template of the form with an example post, without saving it
+func HandlePreview(w http.ResponseWriter, r *http.Request) interface{} {
+	ctx := r.Context()
+	activeGuild, templateData := web.GetBaseCPContextData(ctx)
+
+	currentConfig := ctx.Value(CurrentConfig).(models.RedditFeedSlice)
+	templateData["RedditConfig"] = currentConfig
+
+	updated := ctx.Value(common.ContextKeyParsedForm).(*UpdateForm)
+	ok := ctx.Value(common.ContextKeyFormOk).(bool)
+	if !ok {
+		return templateData
+	}
+
+	item := FindFeed(currentConfig, updated.ID)
+	if item == nil {
+		return templateData.AddAlerts(web.ErrorAlert("Unknown id"))
+	}
+
+	preview := &AnnouncePreview{
+		FeedID:      item.ID,
+		AnnounceMsg: updated.AnnounceMsg,
+	}
+	templateData["AnnouncePreview"] = preview
+
+	if strings.TrimSpace(updated.AnnounceMsg) == "" {
+		preview.Error = "The template is empty, the default format is used"
+		return templateData
+	}
+
+	data := PostTemplateData(previewPost(item))
+	var mentionRole int64
+	if len(updated.MentionRole) > 0 {
+		mentionRole = updated.MentionRole[0]
+	}
+	data["SelectedRoleID"] = mentionRole
+
+	content, err := feeds.PreviewTemplate(activeGuild, activeGuild.GetChannel(updated.Channel), updated.AnnounceMsg, data)
+	if err != nil {
+		preview.Error = err.Error()
+	} else if content == "" {
+		preview.Error = "The template produced no message, nothing would be sent"
+	}
+	preview.Content = content
+
+	return templateData
+}
+
 func HandleRemove(w http.ResponseWriter, r *http.Request) interface{} {
 	ctx := r.Context()
 	_, templateData := web.GetBaseCPContextData(ctx)
@@ -268,7 +445,7 @@ func HandleRemove(w http.ResponseWriter, r *http.Request) interface{} {
 		return templateData
 	}
 
-	templateData.AddAlerts(web.SucessAlert("Successfully removed subreddit feed for /r/" + item.Subreddit))
+	templateData.AddAlerts(web.SucessAlert("Successfully removed reddit feed for " + FeedDisplayName(item)))
 
 	// Remove it form the displayed list
 	for k, c := range currentConfig {
@@ -279,10 +456,11 @@ func HandleRemove(w http.ResponseWriter, r *http.Request) interface{} {
 
 	templateData["RedditConfig"] = currentConfig
 
-	go cplogs.RetryAddEntry(web.NewLogEntryFromContext(r.Context(), panelLogKeyRemovedFeed, &cplogs.Param{Type: cplogs.ParamTypeString, Value: item.Subreddit}))
+	go cplogs.RetryAddEntry(web.NewLogEntryFromContext(r.Context(), panelLogKeyRemovedFeed, &cplogs.Param{Type: cplogs.ParamTypeString, Value: FeedDisplayName(item)}))
 	go pubsub.Publish("reddit_clear_subreddit_cache", -1, PubSubSubredditEventData{
 		Subreddit: strings.ToLower(strings.TrimSpace(item.Subreddit)),
 		Slow:      item.Slow,
+		UserFeed:  item.UserFeed,
 	})
 
 	return templateData
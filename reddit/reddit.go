@@ -87,17 +87,20 @@ func RegisterPlugin() {
 
 	pubsub.AddHandler("reddit_clear_subreddit_cache", func(evt *pubsub.Event) {
 		dataCast := evt.Data.(*PubSubSubredditEventData)
-		if dataCast.Slow {
-			configCache.Delete(KeySlowFeeds(strings.ToLower(dataCast.Subreddit)))
-		} else {
-			configCache.Delete(KeyFastFeeds(strings.ToLower(dataCast.Subreddit)))
+		configCache.Delete(configCacheKey(strings.ToLower(dataCast.Subreddit), dataCast.Slow, dataCast.UserFeed))
+		if dataCast.UserFeed {
+			if err := loadFollowedUsers(); err != nil {
+				logger.WithError(err).Error("failed refreshing followed reddit users")
+			}
 		}
 	}, PubSubSubredditEventData{})
 }
 
 type PubSubSubredditEventData struct {
+	// Subreddit is the reddit user for user feeds
 	Subreddit string `json:"subreddit"`
 	Slow      bool   `json:"slow"`
+	UserFeed  bool   `json:"user_feed"`
 }
 
 const (
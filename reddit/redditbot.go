@@ -11,14 +11,17 @@ import (
 	"sync"
 	"time"
 
+	"emperror.dev/errors"
 	"github.com/mrbentarikau/pagst/analytics"
 	"github.com/mrbentarikau/pagst/common"
 	"github.com/mrbentarikau/pagst/common/config"
 	"github.com/mrbentarikau/pagst/common/mqueue"
+	"github.com/mrbentarikau/pagst/common/templates"
 	"github.com/mrbentarikau/pagst/feeds"
 	"github.com/mrbentarikau/pagst/lib/discordgo"
 	"github.com/mrbentarikau/pagst/lib/go-reddit"
 	"github.com/mrbentarikau/pagst/reddit/models"
+	"github.com/mrbentarikau/pagst/web/discorddata"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 	"github.com/volatiletech/sqlboiler/v4/queries/qm"
@@ -34,9 +37,11 @@ var (
 	confMaxPostsHourFast = config.RegisterOption("yagpdb.reddit.fast_max_posts_hour", "Max posts per hour per guild for fast feed", 60)
 	confMaxPostsHourSlow = config.RegisterOption("yagpdb.reddit.slow_max_posts_hour", "Max posts per hour per guild for slow feed", 120)
 
-	feedLock sync.Mutex
-	fastFeed *PostFetcher
-	slowFeed *PostFetcher
+	feedLock     sync.Mutex
+	fastFeed     *PostFetcher
+	slowFeed     *PostFetcher
+	fastUserFeed *UserPostFetcher
+	slowUserFeed *UserPostFetcher
 )
 
 func (p *Plugin) StartFeed() {
@@ -68,6 +73,17 @@ func (p *Plugin) StopFeed(wg *sync.WaitGroup) {
 		wg.Done()
 	}
 
+	for _, uf := range []*UserPostFetcher{fastUserFeed, slowUserFeed} {
+		if uf != nil {
+			wg.Add(1)
+			go func(uf *UserPostFetcher) {
+				uf.StopChan <- wg
+			}(uf)
+		}
+	}
+	fastUserFeed = nil
+	slowUserFeed = nil
+
 	feedLock.Unlock()
 }
 
@@ -85,19 +101,33 @@ func setupClient() *reddit.Client {
 func (p *Plugin) runBot() {
 	feedLock.Lock()
 
+	if err := loadFollowedUsers(); err != nil {
+		logger.WithError(err).Error("failed loading followed reddit users")
+	}
+
 	if os.Getenv("YAGPDB_REDDIT_FAST_FEED_DISABLED") == "" {
-		fastFeed = NewPostFetcher(p.redditClient, false, NewPostHandler(false, p.redditClient))
+		handler := NewPostHandler(false, p.redditClient)
+		fastFeed = NewPostFetcher(p.redditClient, false, handler)
 		go fastFeed.Run()
+
+		fastUserFeed = NewUserPostFetcher(p.redditClient, false, handler)
+		go fastUserFeed.Run()
 	}
 
-	slowFeed = NewPostFetcher(p.redditClient, true, NewPostHandler(true, p.redditClient))
+	handler := NewPostHandler(true, p.redditClient)
+	slowFeed = NewPostFetcher(p.redditClient, true, handler)
 	go slowFeed.Run()
 
+	slowUserFeed = NewUserPostFetcher(p.redditClient, true, handler)
+	go slowUserFeed.Run()
+
 	feedLock.Unlock()
 }
 
 type KeySlowFeeds string
 type KeyFastFeeds string
+type KeySlowUserFeeds string
+type KeyFastUserFeeds string
 
 var configCache sync.Map
 
@@ -107,7 +137,7 @@ type PostHandlerImpl struct {
 	redditClient *reddit.Client
 }
 
-func NewPostHandler(slow bool, redditClient *reddit.Client) PostHandler {
+func NewPostHandler(slow bool, redditClient *reddit.Client) *PostHandlerImpl {
 	rl := NewRatelimiter()
 	go rl.RunGCLoop()
 
@@ -128,18 +158,34 @@ func (p *PostHandlerImpl) HandleRedditPosts(links []*reddit.Link) {
 			continue
 		}
 
+		if isProfilePost(v) {
+			// the UserPostFetcher handles these
+			continue
+		}
+
 		// since := time.Since(time.Unix(int64(v.CreatedUtc), 0))
 		// logger.Debugf("[%5.2fs %6s] /r/%-20s: %s", since.Seconds(), v.ID, v.Subreddit, v.Title)
 		go p.handlePost(v, 0)
 	}
 }
 
-func (p *PostHandlerImpl) getConfigs(subreddit string) ([]*models.RedditFeed, error) {
-	var key interface{}
-	key = KeySlowFeeds(subreddit)
-	if !p.Slow {
-		key = KeyFastFeeds(subreddit)
+// configCacheKey returns the key of the feeds of the subreddit, or of the user for user feeds
+func configCacheKey(name string, slow, userFeed bool) interface{} {
+	switch {
+	case userFeed && slow:
+		return KeySlowUserFeeds(name)
+	case userFeed:
+		return KeyFastUserFeeds(name)
+	case slow:
+		return KeySlowFeeds(name)
+	default:
+		return KeyFastFeeds(name)
 	}
+}
+
+// getConfigs returns the feeds of the subreddit, or with userFeed the feeds of the reddit user
+func (p *PostHandlerImpl) getConfigs(name string, userFeed bool) ([]*models.RedditFeed, error) {
+	key := configCacheKey(name, p.Slow, userFeed)
 
 	v, ok := configCache.Load(key)
 	if ok {
@@ -147,7 +193,8 @@ func (p *PostHandlerImpl) getConfigs(subreddit string) ([]*models.RedditFeed, er
 	}
 
 	qms := []qm.QueryMod{
-		models.RedditFeedWhere.Subreddit.EQ(strings.ToLower(subreddit)),
+		models.RedditFeedWhere.Subreddit.EQ(strings.ToLower(name)),
+		models.RedditFeedWhere.UserFeed.EQ(userFeed),
 		models.RedditFeedWhere.Slow.EQ(p.Slow),
 		models.RedditFeedWhere.Disabled.EQ(false),
 	}
@@ -158,7 +205,10 @@ func (p *PostHandlerImpl) getConfigs(subreddit string) ([]*models.RedditFeed, er
 		return nil, err
 	}
 
-	configCache.Store(key, config)
+	// only followed users get here, so they're the only ones with a cache entry
+	if len(config) > 0 || !userFeed {
+		configCache.Store(key, config)
+	}
 
 	return config, nil
 }
@@ -168,12 +218,23 @@ func (p *PostHandlerImpl) handlePost(post *reddit.Link, filterGuild int64) error
 	// createdSince := time.Since(time.Unix(int64(post.CreatedUtc), 0))
 	// logger.Printf("[%5.1fs] /r/%-15s: %s, %s", createdSince.Seconds(), post.Subreddit, post.Title, post.ID)
 
-	config, err := p.getConfigs(strings.ToLower(post.Subreddit))
+	config, err := p.getConfigs(strings.ToLower(post.Subreddit), false)
 	if err != nil {
 		logger.WithError(err).Error("failed retrieving Reddit feeds for subreddit")
 		return err
 	}
 
+	// the feed process knows who is followed, the debug command checks all of them
+	if filterGuild > 0 || isFollowedUser(post.Author, p.Slow) {
+		userConfig, err := p.getConfigs(strings.ToLower(post.Author), true)
+		if err != nil {
+			logger.WithError(err).Error("failed retrieving Reddit feeds for user")
+			return err
+		}
+		// the cached slice is shared, so append to a copy
+		config = append(config[:len(config):len(config)], userConfig...)
+	}
+
 	if filterGuild > 0 {
 		filtered := make([]*models.RedditFeed, 0)
 		for _, v := range config {
@@ -199,6 +260,8 @@ func (p *PostHandlerImpl) handlePost(post *reddit.Link, filterGuild int64) error
 	}).Debug("Found matched Reddit post")
 
 	messageShowSpoilers, messageWithSpoilers, embedShowSpoilers, embedWithSpoilers := p.createPostMessage(post)
+	templateData := PostTemplateData(post)
+	forumLabels := PostForumLabels(post)
 	for _, item := range filteredItems {
 		message := messageWithSpoilers
 		embed := embedWithSpoilers
@@ -215,6 +278,18 @@ func (p *PostHandlerImpl) handlePost(post *reddit.Link, filterGuild int64) error
 		parseMentions := []discordgo.AllowedMentionType{}
 		if len(item.MentionRole) > 0 {
 			parseMentions = []discordgo.AllowedMentionType{discordgo.AllowedMentionTypeRoles}
+		}
+
+		if item.AnnounceMsg != "" {
+			content, err = executeAnnounceMsg(item, templateData)
+			if err != nil {
+				logger.WithError(err).WithField("guild", item.GuildID).Warn("Failed executing template on Reddit post")
+				continue
+			}
+			if content == "" { // Nothing to do
+				continue
+			}
+		} else if len(item.MentionRole) > 0 {
 			content += "Hey <@&" + strconv.FormatInt(item.MentionRole[0], 10) + ">, a new Reddit post!\n"
 		}
 
@@ -222,6 +297,7 @@ func (p *PostHandlerImpl) handlePost(post *reddit.Link, filterGuild int64) error
 
 			GuildID:         item.GuildID,
 			ChannelID:       item.ChannelID,
+			ThreadID:        item.ThreadID,
 			MessageStr:      content,
 			Source:          "reddit",
 			SourceItemID:    idStr,
@@ -231,6 +307,7 @@ func (p *PostHandlerImpl) handlePost(post *reddit.Link, filterGuild int64) error
 
 				Parse: parseMentions,
 			},
+			ForumPost: feeds.ForumPost(html.UnescapeString(post.Title), item.ForumTags, forumLabels),
 		}
 
 		var matureContentWarning string
@@ -243,7 +320,7 @@ func (p *PostHandlerImpl) handlePost(post *reddit.Link, filterGuild int64) error
 		if item.UseEmbeds {
 			qm.MessageEmbed = embed
 			qm.MessageEmbed.Description = matureContentWarning + embed.Description
-		} else {
+		} else if item.AnnounceMsg == "" {
 			qm.MessageStr += matureContentWarning + message
 		}
 
@@ -268,6 +345,19 @@ OUTER:
 			}
 		}
 
+		filters, err := feedFilters(c)
+		if err != nil {
+			// saved before the filters were validated, or the limits changed
+			logger.WithError(err).WithField("feed", c.ID).Warn("invalid reddit feed filters")
+			continue
+		}
+
+		if filters != nil {
+			if ok, _ := filters.Check(post); !ok {
+				continue
+			}
+		}
+
 		limit := confMaxPostsHourFast.GetInt()
 		if p.Slow {
 			limit = confMaxPostsHourSlow.GetInt()
@@ -299,6 +389,61 @@ OUTER:
 	return filteredItems
 }
 
+// PostTemplateData is the data announcement templates get about the post
+func PostTemplateData(post *reddit.Link) map[string]interface{} {
+	thumbnail := ""
+	if strings.HasPrefix(post.Thumbnail, "http") {
+		// self posts have "self", "default" and the like as thumbnail
+		thumbnail = post.Thumbnail
+	}
+
+	return map[string]interface{}{
+		"Title":     html.UnescapeString(post.Title),
+		"Author":    post.Author,
+		"URL":       "https://redd.it/" + post.ID,
+		"Link":      post.URL,
+		"Permalink": "https://www.reddit.com" + post.Permalink,
+		"Thumbnail": thumbnail,
+		"Flair":     html.UnescapeString(post.LinkFlairText),
+		"Score":     post.Score,
+		"Subreddit": post.Subreddit,
+		"PostID":    post.ID,
+		"SelfText":  html.UnescapeString(post.Selftext),
+		"IsSelf":    post.IsSelf,
+		"NSFW":      post.Over18,
+		"Spoiler":   post.Spoiler,
+
+		// the same names as in youtube announcements
+		"IsShort": false,
+		"IsLive":  false,
+	}
+}
+
+// executeAnnounceMsg renders the announcement template of the feed
+func executeAnnounceMsg(item *models.RedditFeed, data map[string]interface{}) (string, error) {
+	guildState, err := discorddata.GetFullGuild(item.GuildID)
+	if err != nil {
+		return "", err
+	}
+
+	if guildState == nil {
+		return "", errors.New("guild not found")
+	}
+
+	var mentionRole int64
+	if len(item.MentionRole) > 0 {
+		mentionRole = item.MentionRole[0]
+	}
+
+	ctx := templates.NewContext(guildState, guildState.GetChannel(item.ChannelID), nil)
+	for k, v := range data {
+		ctx.Data[k] = v
+	}
+	ctx.Data["SelectedRoleID"] = mentionRole
+
+	return ctx.Execute(item.AnnounceMsg)
+}
+
 func (p *PostHandlerImpl) createPostMessage(post *reddit.Link) (string, string, *discordgo.MessageEmbed, *discordgo.MessageEmbed) {
 	plainMessage := fmt.Sprintf("**%s**\n*by %s (<%s>)*\n",
 		html.UnescapeString(post.Title), post.Author, "https://redd.it/"+post.ID)
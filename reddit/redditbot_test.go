@@ -0,0 +1,48 @@
+package reddit
+
+import (
+	"testing"
+
+	"github.com/mrbentarikau/pagst/lib/go-reddit"
+)
+
+func TestPostTemplateData(t *testing.T) {
+	post := &reddit.Link{
+		ID:            "abc123",
+		Title:         "Tom &amp; Jerry",
+		Author:        "someone",
+		Subreddit:     "cartoons",
+		URL:           "https://i.redd.it/abc.png",
+		Permalink:     "/r/cartoons/comments/abc123/tom_jerry/",
+		Thumbnail:     "https://b.thumbs.redditmedia.com/abc.jpg",
+		LinkFlairText: "Fan Art",
+		Score:         42,
+		Over18:        true,
+	}
+
+	data := PostTemplateData(post)
+	expected := map[string]interface{}{
+		"Title":     "Tom & Jerry",
+		"Author":    "someone",
+		"URL":       "https://redd.it/abc123",
+		"Link":      "https://i.redd.it/abc.png",
+		"Permalink": "https://www.reddit.com/r/cartoons/comments/abc123/tom_jerry/",
+		"Thumbnail": "https://b.thumbs.redditmedia.com/abc.jpg",
+		"Flair":     "Fan Art",
+		"Score":     42,
+		"NSFW":      true,
+		"IsSelf":    false,
+		"IsShort":   false,
+	}
+
+	for k, v := range expected {
+		if data[k] != v {
+			t.Errorf("%s: got %v, expected %v", k, data[k], v)
+		}
+	}
+
+	post.Thumbnail = "self"
+	if thumbnail := PostTemplateData(post)["Thumbnail"]; thumbnail != "" {
+		t.Errorf("expected no thumbnail for self posts, got %v", thumbnail)
+	}
+}
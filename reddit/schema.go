@@ -25,4 +25,26 @@ ALTER TABLE reddit_feeds ADD COLUMN IF NOT EXISTS disabled BOOLEAN NOT NULL DEFA
 ALTER TABLE reddit_feeds ADD COLUMN IF NOT EXISTS show_spoilers BOOLEAN NOT NULL DEFAULT FALSE;
 `, `
 ALTER TABLE reddit_feeds ADD COLUMN IF NOT EXISTS mention_role BIGINT[];
+`, `
+ALTER TABLE reddit_feeds ADD COLUMN IF NOT EXISTS announce_msg TEXT NOT NULL DEFAULT '';
+`, `
+ALTER TABLE reddit_feeds ADD COLUMN IF NOT EXISTS user_feed BOOLEAN NOT NULL DEFAULT FALSE;
+`, `
+ALTER TABLE reddit_feeds ADD COLUMN IF NOT EXISTS include_flairs TEXT NOT NULL DEFAULT '';
+`, `
+ALTER TABLE reddit_feeds ADD COLUMN IF NOT EXISTS exclude_flairs TEXT NOT NULL DEFAULT '';
+`, `
+ALTER TABLE reddit_feeds ADD COLUMN IF NOT EXISTS include_keywords TEXT NOT NULL DEFAULT '';
+`, `
+ALTER TABLE reddit_feeds ADD COLUMN IF NOT EXISTS exclude_keywords TEXT NOT NULL DEFAULT '';
+`, `
+ALTER TABLE reddit_feeds ADD COLUMN IF NOT EXISTS include_authors TEXT NOT NULL DEFAULT '';
+`, `
+ALTER TABLE reddit_feeds ADD COLUMN IF NOT EXISTS exclude_authors TEXT NOT NULL DEFAULT '';
+`, `
+ALTER TABLE reddit_feeds ADD COLUMN IF NOT EXISTS post_types INT NOT NULL DEFAULT 0;
+`, `
+ALTER TABLE reddit_feeds ADD COLUMN IF NOT EXISTS forum_tags TEXT NOT NULL DEFAULT '';
+`, `
+ALTER TABLE reddit_feeds ADD COLUMN IF NOT EXISTS thread_id BIGINT NOT NULL DEFAULT 0;
 `}
@@ -0,0 +1,193 @@
+package reddit
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mediocregopher/radix/v3"
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/lib/go-reddit"
+	"github.com/mrbentarikau/pagst/reddit/models"
+	"github.com/sirupsen/logrus"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+var (
+	// the reddit users with feeds, so only their posts need a lookup of the user feeds
+	followedUsersMU   sync.RWMutex
+	followedUsersFast = make(map[string]bool)
+	followedUsersSlow = make(map[string]bool)
+)
+
+// loadFollowedUsers refreshes the set of reddit users with enabled feeds
+func loadFollowedUsers() error {
+	feeds, err := models.RedditFeeds(
+		qm.Select(models.RedditFeedColumns.Subreddit, models.RedditFeedColumns.Slow),
+		models.RedditFeedWhere.UserFeed.EQ(true),
+		models.RedditFeedWhere.Disabled.EQ(false)).AllG(context.Background())
+	if err != nil {
+		return err
+	}
+
+	fast := make(map[string]bool)
+	slow := make(map[string]bool)
+	for _, v := range feeds {
+		if v.Slow {
+			slow[strings.ToLower(v.Subreddit)] = true
+		} else {
+			fast[strings.ToLower(v.Subreddit)] = true
+		}
+	}
+
+	followedUsersMU.Lock()
+	followedUsersFast = fast
+	followedUsersSlow = slow
+	followedUsersMU.Unlock()
+
+	return nil
+}
+
+func isFollowedUser(name string, slow bool) bool {
+	followedUsersMU.RLock()
+	defer followedUsersMU.RUnlock()
+
+	if slow {
+		return followedUsersSlow[strings.ToLower(name)]
+	}
+
+	return followedUsersFast[strings.ToLower(name)]
+}
+
+func followedUserNames(slow bool) []string {
+	followedUsersMU.RLock()
+	defer followedUsersMU.RUnlock()
+
+	set := followedUsersFast
+	if slow {
+		set = followedUsersSlow
+	}
+
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// isProfilePost returns whether the post was made on the profile of its author, those are in the u_<name> subreddit
+// and are fetched by the UserPostFetcher instead of with the other posts
+func isProfilePost(post *reddit.Link) bool {
+	return strings.EqualFold(post.Subreddit, "u_"+post.Author)
+}
+
+// UserPostFetcher fetches the posts the followed reddit users made on their profile,
+// with the same delay as the PostFetcher of the same speed
+type UserPostFetcher struct {
+	Name     string
+	StopChan chan *sync.WaitGroup
+
+	// redis hash of the user and the creation time of the newest post handled
+	cursorKey string
+	slow      bool
+	delay     time.Duration
+	interval  time.Duration
+
+	redditClient *reddit.Client
+	handler      *PostHandlerImpl
+
+	log *logrus.Entry
+}
+
+func NewUserPostFetcher(redditClient *reddit.Client, slow bool, handler *PostHandlerImpl) *UserPostFetcher {
+	f := &UserPostFetcher{
+		Name:         "fast",
+		StopChan:     make(chan *sync.WaitGroup),
+		cursorKey:    "reddit_user_posts_fast",
+		slow:         slow,
+		delay:        time.Minute,
+		interval:     time.Minute * 5,
+		redditClient: redditClient,
+		handler:      handler,
+	}
+
+	if slow {
+		f.Name = "slow"
+		f.cursorKey = "reddit_user_posts_slow"
+		f.delay = time.Minute * 15
+		f.interval = time.Minute * 15
+	}
+
+	f.log = logger.WithField("rfeed_type", f.Name+"_users")
+	return f
+}
+
+func (f *UserPostFetcher) Run() {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case wg := <-f.StopChan:
+			wg.Done()
+			return
+		case <-ticker.C:
+		}
+
+		for _, name := range followedUserNames(f.slow) {
+			if err := f.checkUser(name); err != nil {
+				f.log.WithError(err).WithField("user", name).Error("failed checking posts of reddit user")
+			}
+
+			// spread the requests out a bit
+			time.Sleep(time.Second)
+		}
+	}
+}
+
+func (f *UserPostFetcher) checkUser(name string) error {
+	var last int64
+	err := common.RedisPool.Do(radix.Cmd(&last, "HGET", f.cursorKey, name))
+	if err != nil {
+		return err
+	}
+
+	if last == 0 {
+		// newly followed, start from now instead of posting the history of the user
+		return common.RedisPool.Do(radix.FlatCmd(nil, "HSET", f.cursorKey, name, time.Now().Add(-f.delay).Unix()))
+	}
+
+	links, err := f.redditClient.GetUserSubmittedLinks(name)
+	if err != nil {
+		return err
+	}
+
+	newest := last
+	// the listing is newest first
+	for i := len(links) - 1; i >= 0; i-- {
+		link := links[i]
+		created := int64(link.CreatedUtc)
+		if created <= last {
+			continue
+		}
+
+		if time.Since(time.Unix(created, 0)) < f.delay {
+			break
+		}
+
+		newest = created
+		if !isProfilePost(link) || strings.EqualFold(link.Selftext, "[removed]") || strings.EqualFold(link.Selftext, "[deleted]") {
+			continue
+		}
+
+		go f.handler.handlePost(link, 0)
+	}
+
+	if newest == last {
+		return nil
+	}
+
+	return common.RedisPool.Do(radix.FlatCmd(nil, "HSET", f.cursorKey, name, newest))
+}
@@ -0,0 +1,44 @@
+package reddit
+
+import (
+	"testing"
+
+	"github.com/mrbentarikau/pagst/lib/go-reddit"
+)
+
+func TestIsProfilePost(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Post     *reddit.Link
+		Expected bool
+	}{
+		{"profile", &reddit.Link{Subreddit: "u_Spez", Author: "spez"}, true},
+		{"subreddit", &reddit.Link{Subreddit: "pics", Author: "spez"}, false},
+		{"other profile", &reddit.Link{Subreddit: "u_bob", Author: "spez"}, false},
+	}
+
+	for _, c := range cases {
+		if got := isProfilePost(c.Post); got != c.Expected {
+			t.Errorf("%s: got %t, expected %t", c.Name, got, c.Expected)
+		}
+	}
+}
+
+func TestIsFollowedUser(t *testing.T) {
+	followedUsersMU.Lock()
+	followedUsersFast = map[string]bool{"spez": true}
+	followedUsersSlow = map[string]bool{}
+	followedUsersMU.Unlock()
+
+	if !isFollowedUser("Spez", false) {
+		t.Errorf("expected spez to be followed by the fast feed")
+	}
+
+	if isFollowedUser("spez", true) {
+		t.Errorf("expected spez not to be followed by the slow feed")
+	}
+
+	if isFollowedUser("bob", false) {
+		t.Errorf("expected bob not to be followed")
+	}
+}
@@ -0,0 +1,55 @@
+package reminders
+
+import (
+	"sort"
+
+	"github.com/mediocregopher/radix/v3"
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/lib/discordgo"
+)
+
+// DateLocales are the languages the -time flag of remindme understands
+var DateLocales = map[string]string{
+	"en": "English",
+	"de": "Deutsch",
+	"es": "Español",
+	"fr": "Français",
+}
+
+func dateLocaleKey(userID int64) string {
+	return "reminders_date_locale:" + discordgo.StrID(userID)
+}
+
+func dateLocaleCodes() []string {
+	codes := make([]string, 0, len(DateLocales))
+	for k := range DateLocales {
+		codes = append(codes, k)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// GetUserDateLocale returns the locale the user wants reminder times parsed in, defaulting to english
+func GetUserDateLocale(userID int64) string {
+	var locale string
+	err := common.RedisPool.Do(radix.Cmd(&locale, "GET", dateLocaleKey(userID)))
+	if err != nil {
+		logger.WithError(err).WithField("user", userID).Error("failed retrieving reminder date locale")
+		return "en"
+	}
+
+	if _, ok := DateLocales[locale]; !ok {
+		return "en"
+	}
+
+	return locale
+}
+
+// SetUserDateLocale stores the locale for the user, english is the default and simply clears the setting
+func SetUserDateLocale(userID int64, locale string) error {
+	if locale == "en" {
+		return common.RedisPool.Do(radix.Cmd(nil, "DEL", dateLocaleKey(userID)))
+	}
+
+	return common.RedisPool.Do(radix.Cmd(nil, "SET", dateLocaleKey(userID), locale))
+}
@@ -0,0 +1,183 @@
+package reminders
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mrbentarikau/pagst/common"
+)
+
+const (
+	icalUTCFormat   = "20060102T150405Z"
+	icalLocalFormat = "20060102T150405"
+)
+
+var icalTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// RemindersICS renders the reminders as an iCalendar (RFC 5545) file, recurring reminders
+// are expanded in the provided location so they keep their local time across DST changes
+func RemindersICS(reminders []*Reminder, loc *time.Location, now time.Time) string {
+	var b strings.Builder
+	writeLine := func(line string) {
+		b.WriteString(foldICalLine(line))
+		b.WriteString("\r\n")
+	}
+
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//" + common.ConfBotName.GetString() + "//Reminders//EN")
+	writeLine("CALSCALE:GREGORIAN")
+
+	if loc != time.UTC {
+		for _, v := range reminders {
+			if v.ParsedRecurrence() != nil {
+				// the recurring ones refer to the timezone by its TZID
+				for _, line := range icalTimezone(loc, now.Year()-1) {
+					writeLine(line)
+				}
+				break
+			}
+		}
+	}
+
+	for _, v := range reminders {
+		when := time.Unix(v.When, 0)
+
+		writeLine("BEGIN:VEVENT")
+		writeLine(fmt.Sprintf("UID:reminder-%d@%s", v.ID, common.ConfHost.GetString()))
+		writeLine("DTSTAMP:" + now.UTC().Format(icalUTCFormat))
+
+		if rec := v.ParsedRecurrence(); rec != nil {
+			if loc == time.UTC {
+				writeLine("DTSTART:" + when.UTC().Format(icalUTCFormat))
+			} else {
+				writeLine("DTSTART;TZID=" + loc.String() + ":" + when.In(loc).Format(icalLocalFormat))
+			}
+			writeLine("RRULE:" + rec.RRule())
+		} else {
+			writeLine("DTSTART:" + when.UTC().Format(icalUTCFormat))
+			if v.Repeat > 0 {
+				writeLine(fmt.Sprintf("RRULE:FREQ=MINUTELY;INTERVAL=%d", int64(time.Duration(v.Repeat)/time.Minute)))
+			}
+		}
+
+		writeLine("SUMMARY:" + icalTextEscaper.Replace(limitString(v.Message)))
+		writeLine("DESCRIPTION:" + icalTextEscaper.Replace(v.Message))
+		writeLine("END:VEVENT")
+	}
+
+	writeLine("END:VCALENDAR")
+	return b.String()
+}
+
+// icalTimezone returns the VTIMEZONE component describing loc from the given year on,
+// the transitions of that year are assumed to repeat yearly on the same weekday of the month like most DST rules do
+func icalTimezone(loc *time.Location, year int) []string {
+	lines := []string{"BEGIN:VTIMEZONE", "TZID:" + loc.String()}
+
+	transitions := yearTransitions(loc, year)
+	if len(transitions) == 0 {
+		name, offset := time.Date(year, 1, 1, 0, 0, 0, 0, loc).Zone()
+		lines = append(lines,
+			"BEGIN:STANDARD",
+			"DTSTART:19700101T000000",
+			"TZOFFSETFROM:"+icalOffset(offset),
+			"TZOFFSETTO:"+icalOffset(offset),
+			"TZNAME:"+name,
+			"END:STANDARD")
+	}
+
+	for _, t := range transitions {
+		_, offsetFrom := t.Add(-time.Second).In(loc).Zone()
+		name, offsetTo := t.In(loc).Zone()
+
+		kind := "STANDARD"
+		if t.In(loc).IsDST() {
+			kind = "DAYLIGHT"
+		}
+
+		// DTSTART of an observance is in the local time before the transition
+		start := t.Add(time.Duration(offsetFrom) * time.Second).UTC()
+
+		week := (start.Day()-1)/7 + 1
+		if start.AddDate(0, 0, 7).Month() != start.Month() {
+			week = -1
+		}
+
+		lines = append(lines,
+			"BEGIN:"+kind,
+			"DTSTART:"+start.Format(icalLocalFormat),
+			fmt.Sprintf("RRULE:FREQ=YEARLY;BYMONTH=%d;BYDAY=%d%s", start.Month(), week, icalWeekdays[start.Weekday()]),
+			"TZOFFSETFROM:"+icalOffset(offsetFrom),
+			"TZOFFSETTO:"+icalOffset(offsetTo),
+			"TZNAME:"+name,
+			"END:"+kind)
+	}
+
+	return append(lines, "END:VTIMEZONE")
+}
+
+// yearTransitions returns the instants the utc offset of loc changes in the year
+func yearTransitions(loc *time.Location, year int) []time.Time {
+	var result []time.Time
+
+	prev := time.Date(year, 1, 1, 0, 0, 0, 0, loc)
+	end := time.Date(year+1, 1, 1, 0, 0, 0, 0, loc)
+	for prev.Before(end) {
+		next := prev.Add(time.Hour * 24)
+		_, prevOffset := prev.Zone()
+		if _, nextOffset := next.In(loc).Zone(); nextOffset != prevOffset {
+			// narrow it down to the second
+			lo, hi := prev.Unix(), next.Unix()
+			for hi-lo > 1 {
+				mid := lo + (hi-lo)/2
+				if _, offset := time.Unix(mid, 0).In(loc).Zone(); offset == prevOffset {
+					lo = mid
+				} else {
+					hi = mid
+				}
+			}
+			result = append(result, time.Unix(hi, 0))
+		}
+		prev = next.In(loc)
+	}
+
+	return result
+}
+
+func icalOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+
+	out := fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds%3600/60)
+	if seconds%60 != 0 {
+		out += fmt.Sprintf("%02d", seconds%60)
+	}
+
+	return out
+}
+
+// foldICalLine splits lines longer than 75 octets, continuation lines start with a space
+func foldICalLine(line string) string {
+	if len(line) <= 75 {
+		return line
+	}
+
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > 75 {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+
+	return b.String()
+}
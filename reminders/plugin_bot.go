@@ -1,6 +1,7 @@
 package reminders
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
@@ -21,7 +22,10 @@ import (
 	whn "github.com/mrbentarikau/pagst/lib/when"
 	"github.com/mrbentarikau/pagst/lib/when/rules"
 	wcommon "github.com/mrbentarikau/pagst/lib/when/rules/common"
+	"github.com/mrbentarikau/pagst/lib/when/rules/de"
 	"github.com/mrbentarikau/pagst/lib/when/rules/en"
+	"github.com/mrbentarikau/pagst/lib/when/rules/es"
+	"github.com/mrbentarikau/pagst/lib/when/rules/fr"
 	"github.com/mrbentarikau/pagst/rsvp"
 	"github.com/mrbentarikau/pagst/timezonecompanion"
 	"github.com/mrbentarikau/pagst/timezonecompanion/trules"
@@ -50,6 +54,9 @@ func (p *Plugin) BotInit() {
 		}
 		go p.handleRemindmeInteractionCreate(ic)
 	}, eventsystem.EventInteractionCreate)
+	eventsystem.AddHandlerAsyncLastLegacy(p, func(evt *eventsystem.EventData) {
+		handleSnoozeInteraction(evt.EvtInterface.(*discordgo.InteractionCreate))
+	}, eventsystem.EventInteractionCreate)
 
 	// scheduledevents.RegisterEventHandler("reminders_check_user", checkUserEvtHandlerLegacy)
 	scheduledevents2.RegisterHandler("reminders_check_user", int64(0), checkUserScheduledEvent)
@@ -61,7 +68,7 @@ var cmds = []*commands.YAGCommand{
 	{
 		CmdCategory:  commands.CategoryTool,
 		Name:         "Remindme",
-		Description:  "Schedules a reminder, example: 'remindme 1h30min are you still alive?'\n\nSwitch -repeat will repeat the reminder starting with min duration of 1 hour.\nFlag -time needs quoted date in either your registered time zone (using the `setz` command) or UTC. Adds first argument's duration and if repeat, sets duration to 24h. 'remindme 5m are you still alive? -time \"tomorrow 12:45\"'.\n Using -time and 0 duration, first command argument is made to reminder text.",
+		Description:  "Schedules a reminder, example: 'remindme 1h30min are you still alive?'\n\nSwitch -repeat will repeat the reminder starting with min duration of 1 hour.\nFlag -time needs quoted date in either your registered time zone (using the `setz` command) or UTC. Adds first argument's duration and if repeat, sets duration to 24h. 'remindme 5m are you still alive? -time \"tomorrow 12:45\"'.\n Using -time and 0 duration, first command argument is made to reminder text.\nThe language of -time can be changed with the `remindmelocale` command.\nFlag -every makes the reminder recur in your time zone: 'remindme standup meeting -every \"weekday at 9:00\"', other examples are \"monday and thursday at 17:30\" or \"last friday of the month\".",
 		Aliases:      []string{"remind", "reminder"},
 		RequiredArgs: 2,
 		Arguments: []*dcmd.ArgDef{
@@ -72,6 +79,7 @@ var cmds = []*commands.YAGCommand{
 			{Name: "channel", Type: dcmd.Channel},
 			{Name: "repeat", Help: "Repeat the reminder at set duration"},
 			{Name: "time", Help: "Exact time for the reminder", Type: dcmd.String},
+			{Name: "every", Help: "Recurrence in your time zone, e.g. \"weekday at 9:00\" or \"first monday of the month\"", Type: dcmd.String},
 			{Name: "noping", Help: "Don't ping the user in reminder response"},
 		},
 		ApplicationCommandEnabled: true,
@@ -110,7 +118,7 @@ var cmds = []*commands.YAGCommand{
 			}
 
 			if switchTime.Value != nil {
-				dateParser := createDateParser()
+				dateParser := createDateParser(GetUserDateLocale(parsed.Author.ID))
 				if parsed.Switch("repeat").Value != nil && parsed.Switch("repeat").Value.(bool) {
 					repeatDuration = time.Duration(24 * time.Hour)
 				}
@@ -129,6 +137,14 @@ var cmds = []*commands.YAGCommand{
 				durString = common.HumanizeDuration(common.DurationPrecisionSeconds, time.Until(when))
 			}
 
+			var recurrence *Recurrence
+			if switchEvery := parsed.Switch("every"); switchEvery.Value != nil {
+				recurrence, err = ParseRecurrence(switchEvery.Str())
+				if err != nil {
+					return err.Error(), nil
+				}
+			}
+
 			tUnix := fmt.Sprint(when.Unix())
 			if when.After(time.Now().Add(time.Hour * 24 * 366)) {
 				return "Can be max 365 days from now...", nil
@@ -149,6 +165,20 @@ var cmds = []*commands.YAGCommand{
 				}
 			}
 			reminderMessage += parsed.Args[1].Str()
+			if recurrence != nil {
+				registeredTimezone := timezonecompanion.GetUserTimezone(parsed.Author.ID)
+				if registeredTimezone == nil {
+					registeredTimezone = time.UTC
+				}
+
+				reminder, err := NewRecurringReminder(parsed.Author.ID, parsed.GuildData.GS.ID, id, reminderMessage, recurrence, registeredTimezone, disableMention)
+				if err != nil {
+					return nil, err
+				}
+
+				return fmt.Sprintf("Set a reminder %s, the first one is <t:%d:f>\nView reminders with the reminders command", recurrence, reminder.When), nil
+			}
+
 			_, err = NewReminder(parsed.Author.ID, parsed.GuildData.GS.ID, id, reminderMessage, when, repeatDuration, disableMention)
 			if err != nil {
 				return nil, err
@@ -185,7 +215,7 @@ var cmds = []*commands.YAGCommand{
 				if len(currentReminders) > 0 {
 					out = "Your reminders:\n"
 					out += stringReminders(currentReminders, false)
-					out += "\nRemove a reminder with `delreminder/rmreminder (id)` where id is the first number for each reminder above.\nTo clear all reminders, use `delreminder` with the `-a` switch."
+					out += "\nRemove a reminder with `delreminder/rmreminder (id)` where id is the first number for each reminder above.\nEdit or snooze one with `editreminder (id)`, export them all to your calendar with `exportreminders`.\nTo clear all reminders, use `delreminder` with the `-a` switch."
 				}
 				return out, nil
 			}
@@ -371,6 +401,179 @@ var cmds = []*commands.YAGCommand{
 			return delMsg, nil
 		},
 	},
+	{
+		CmdCategory:  commands.CategoryTool,
+		Name:         "EditReminder",
+		Aliases:      []string{"editremind"},
+		Description:  "Edits one of your reminders, use the switches to change its message, time, channel or recurrence.\n-time accepts a duration from now like 2h or a date in your registered time zone, -every accepts the same as on `remindme`, or none to stop it from recurring.",
+		RequiredArgs: 1,
+		Arguments: []*dcmd.ArgDef{
+			{Name: "ID", Type: dcmd.Int},
+		},
+		ArgSwitches: []*dcmd.ArgDef{
+			{Name: "message", Help: "New reminder text", Type: dcmd.String},
+			{Name: "time", Help: "New duration from now or date", Type: dcmd.String},
+			{Name: "channel", Help: "New channel", Type: dcmd.Channel},
+			{Name: "every", Help: "New recurrence, none to remove", Type: dcmd.String},
+		},
+		ApplicationCommandEnabled: true,
+		IsResponseEphemeral:       true,
+		DefaultEnabled:            true,
+		RunFunc: func(parsed *dcmd.Data) (interface{}, error) {
+			var reminder Reminder
+			err := common.GORM.Where(parsed.Args[0].Int()).First(&reminder).Error
+			if err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return "No reminder by that id found", nil
+				}
+				return "Error retrieving reminder", err
+			}
+
+			if reminder.UserID != discordgo.StrID(parsed.Author.ID) {
+				return "You can only edit your own reminders", nil
+			}
+
+			edited := false
+			if m := parsed.Switch("message"); m.Value != nil {
+				reminder.Message = m.Str()
+				edited = true
+			}
+
+			if c := parsed.Switch("channel"); c.Value != nil {
+				if reminder.GuildID != parsed.GuildData.GS.ID {
+					return "You can only move reminders to a channel in the server they were created in", nil
+				}
+
+				id := c.Value.(*dstate.ChannelState).ID
+				hasPerms, err := bot.AdminOrPermMS(parsed.GuildData.GS.ID, id, parsed.GuildData.MS, discordgo.PermissionSendMessages|discordgo.PermissionViewChannel)
+				if err != nil {
+					return "Failed checking permissions, please try again or join the support server.", err
+				}
+
+				if !hasPerms || !commands.CanExecuteInChannel(parsed, id) {
+					return "You do not have permissions to send messages there", nil
+				}
+
+				reminder.ChannelID = discordgo.StrID(id)
+				edited = true
+			}
+
+			registeredTimezone := timezonecompanion.GetUserTimezone(parsed.Author.ID)
+			if registeredTimezone == nil {
+				registeredTimezone = time.UTC
+			}
+
+			if e := parsed.Switch("every"); e.Value != nil {
+				switch strings.ToLower(e.Str()) {
+				case "none", "off", "never":
+					reminder.Recurrence = ""
+				default:
+					rec, err := ParseRecurrence(e.Str())
+					if err != nil {
+						return err.Error(), nil
+					}
+
+					reminder.Recurrence = rec.String()
+					reminder.Repeat = 0
+					reminder.When = rec.Next(time.Now().In(registeredTimezone)).Unix()
+				}
+				edited = true
+			}
+
+			if t := parsed.Switch("time"); t.Value != nil {
+				when, err := ParseTime(t.Str(), parsed.Author.ID, registeredTimezone)
+				if err != nil {
+					return "Couldn't understand that time", nil
+				}
+
+				if when.After(time.Now().Add(time.Hour * 24 * 366)) {
+					return "Can be max 365 days from now...", nil
+				}
+
+				reminder.When = when.Unix()
+				edited = true
+			}
+
+			if !edited {
+				return "Nothing to edit, use the -message, -time, -channel or -every switches", nil
+			}
+
+			err = common.GORM.Save(&reminder).Error
+			if err != nil {
+				return nil, err
+			}
+
+			err = scheduledevents2.ScheduleEvent("reminders_check_user", reminder.GuildID, time.Unix(reminder.When, 0), parsed.Author.ID)
+			if err != nil {
+				return nil, err
+			}
+
+			return fmt.Sprintf("Updated reminder **#%d**: '%s' (<t:%d:f>)", reminder.ID, limitString(reminder.Message), reminder.When), nil
+		},
+	},
+	{
+		CmdCategory:               commands.CategoryTool,
+		Name:                      "ExportReminders",
+		Aliases:                   []string{"remindersics"},
+		Description:               "Exports your reminders as an iCalendar (.ics) file you can import into your calendar",
+		ApplicationCommandEnabled: true,
+		IsResponseEphemeral:       true,
+		DefaultEnabled:            true,
+		RunFunc: func(parsed *dcmd.Data) (interface{}, error) {
+			currentReminders, err := GetUserReminders(parsed.Author.ID)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(currentReminders) < 1 {
+				return "You have no reminders. Create reminders with the `Remindme` command.", nil
+			}
+
+			registeredTimezone := timezonecompanion.GetUserTimezone(parsed.Author.ID)
+			if registeredTimezone == nil {
+				registeredTimezone = time.UTC
+			}
+
+			return &discordgo.MessageSend{
+				Content: fmt.Sprintf("Your %d reminders", len(currentReminders)),
+				File: &discordgo.File{
+					ContentType: "text/calendar",
+					Name:        "reminders.ics",
+					Reader:      strings.NewReader(RemindersICS(currentReminders, registeredTimezone, time.Now())),
+				},
+			}, nil
+		},
+	},
+	{
+		CmdCategory: commands.CategoryTool,
+		Name:        "RemindmeLocale",
+		Aliases:     []string{"reminderlocale", "remindlocale"},
+		Description: "Sets the language used to understand the -time flag of the Remindme command, run without arguments to see your current setting.\nAvailable: " + strings.Join(dateLocaleCodes(), ", "),
+		Arguments: []*dcmd.ArgDef{
+			{Name: "Locale", Type: dcmd.String},
+		},
+		ApplicationCommandEnabled: true,
+		IsResponseEphemeral:       true,
+		DefaultEnabled:            true,
+		RunFunc: func(parsed *dcmd.Data) (interface{}, error) {
+			if parsed.Args[0].Value == nil {
+				current := GetUserDateLocale(parsed.Author.ID)
+				return fmt.Sprintf("Your reminder time language is **%s** (%s)\nAvailable: %s", DateLocales[current], current, strings.Join(dateLocaleCodes(), ", ")), nil
+			}
+
+			locale := strings.ToLower(parsed.Args[0].Str())
+			if _, ok := DateLocales[locale]; !ok {
+				return fmt.Sprintf("Unknown locale, available: %s", strings.Join(dateLocaleCodes(), ", ")), nil
+			}
+
+			err := SetUserDateLocale(parsed.Author.ID, locale)
+			if err != nil {
+				return nil, err
+			}
+
+			return fmt.Sprintf("Reminder times will now be read in **%s**", DateLocales[locale]), nil
+		},
+	},
 }
 
 func stringReminders(reminders []*Reminder, displayUsernames bool) string {
@@ -383,7 +586,9 @@ func stringReminders(reminders []*Reminder, displayUsernames bool) string {
 		tUnix := t.Unix()
 		timeFromNow := common.HumanizeTime(common.DurationPrecisionMinutes, t)
 
-		if v.Repeat > 0 {
+		if v.Recurrence != "" {
+			repeatedReminder = "- " + v.Recurrence
+		} else if v.Repeat > 0 {
 			repeatedReminder = "- repeated reminder"
 		} else {
 			repeatedReminder = ""
@@ -452,7 +657,7 @@ func limitString(s string) string {
 func embedCreator(currentReminders []*Reminder, i, ml, flag int, parsed *dcmd.Data) *discordgo.MessageEmbed {
 	member := parsed.GuildData.MS
 	embedTitle := []string{"Your reminders:", "Reminders in this channel:"}
-	embedDescription := []string{"Remove a reminder with `delreminder/rmreminder (id)` where id is the first number for each reminder above.\nTo clear all reminders, use `delreminder` with the `-a` switch.", "Remove a reminder with `delreminder/rmreminder (id)` where id is the first number for each reminder above."}
+	embedDescription := []string{"Remove a reminder with `delreminder/rmreminder (id)` where id is the first number for each reminder above.\nEdit or snooze one with `editreminder (id)`, export them all to your calendar with `exportreminders`.\nTo clear all reminders, use `delreminder` with the `-a` switch.", "Remove a reminder with `delreminder/rmreminder (id)` where id is the first number for each reminder above."}
 
 	embed := &discordgo.MessageEmbed{
 		Title:       fmt.Sprintf("%s count %d", embedTitle[flag], len(currentReminders)),
@@ -474,7 +679,9 @@ func embedCreator(currentReminders []*Reminder, i, ml, flag int, parsed *dcmd.Da
 				}
 			}
 			repeat := ""
-			if v.Repeat > 0 {
+			if v.Recurrence != "" {
+				repeat = "`Recurring " + v.Recurrence + "`"
+			} else if v.Repeat > 0 {
 				repeat = "`Repeated`"
 			}
 			t := time.Unix(v.When, 0)
@@ -486,20 +693,75 @@ func embedCreator(currentReminders []*Reminder, i, ml, flag int, parsed *dcmd.Da
 	return embed
 }
 
-func createDateParser() *whn.Parser {
+// ParseTime parses either a duration from now or a date in the date locale of the user and the time zone
+func ParseTime(input string, userID int64, tz *time.Location) (time.Time, error) {
+	if d, err := common.ParseDuration(input); err == nil && d > 0 {
+		return time.Now().Add(d), nil
+	}
+
+	if rsvp.UTCRegex.MatchString(input) {
+		tz = time.UTC
+	}
+
+	t, err := createDateParser(GetUserDateLocale(userID)).Parse(input, time.Now().In(tz))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if t == nil {
+		return time.Time{}, errors.New("no date found")
+	}
+
+	return t.Time, nil
+}
+
+func createDateParser(locale string) *whn.Parser {
 	dateParser := whn.New(&rules.Options{
 		Distance:     10,
 		MatchByOrder: true})
 
-	dateParser.Add(
-		en.Weekday(rules.Override),
-		en.CasualDate(rules.Override),
-		en.CasualTime(rules.Override),
-		trules.Hour(rules.Override),
-		trules.HourMinute(rules.Override),
-		en.Deadline(rules.Override),
-		en.ExactMonthDate(rules.Override),
-	)
+	switch locale {
+	case "de":
+		dateParser.Add(
+			de.Weekday(rules.Override),
+			de.CasualDate(rules.Override),
+			de.CasualTime(rules.Override),
+			de.Hour(rules.Override),
+			de.HourMinute(rules.Override),
+			de.Deadline(rules.Override),
+			de.ExactMonthDate(rules.Override),
+		)
+	case "es":
+		dateParser.Add(
+			es.Weekday(rules.Override),
+			es.CasualDate(rules.Override),
+			es.CasualTime(rules.Override),
+			es.Hour(rules.Override),
+			es.HourMinute(rules.Override),
+			es.Deadline(rules.Override),
+			es.ExactMonthDate(rules.Override),
+		)
+	case "fr":
+		dateParser.Add(
+			fr.Weekday(rules.Override),
+			fr.CasualDate(rules.Override),
+			fr.CasualTime(rules.Override),
+			fr.Hour(rules.Override),
+			fr.HourMinute(rules.Override),
+			fr.Deadline(rules.Override),
+			fr.ExactMonthDate(rules.Override),
+		)
+	default:
+		dateParser.Add(
+			en.Weekday(rules.Override),
+			en.CasualDate(rules.Override),
+			en.CasualTime(rules.Override),
+			trules.Hour(rules.Override),
+			trules.HourMinute(rules.Override),
+			en.Deadline(rules.Override),
+			en.ExactMonthDate(rules.Override),
+		)
+	}
 	dateParser.Add(wcommon.All...)
 
 	return dateParser
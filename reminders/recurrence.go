@@ -0,0 +1,278 @@
+package reminders
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type RecurrenceKind int
+
+const (
+	RecurrenceDaily RecurrenceKind = iota
+	RecurrenceWeekly
+	RecurrenceMonthly
+)
+
+// Recurrence describes a repeating reminder such as "every weekday at 9:00" or "first monday of the month"
+type Recurrence struct {
+	Kind RecurrenceKind
+
+	// Weekly reminders trigger on all of these, monthly ones use the first entry
+	Weekdays []time.Weekday
+
+	// Which occurrence of the weekday in the month monthly reminders trigger on, 1-4 or -1 for the last one
+	Nth int
+
+	Hour   int
+	Minute int
+}
+
+var (
+	ErrInvalidRecurrence = errors.New("invalid recurrence, examples: `every day at 8:30`, `every weekday at 9:00`, `every monday and friday at 17:00`, `first monday of the month at 10:00`")
+
+	recurrenceAtRegex      = regexp.MustCompile(`^(.*?)\s*\bat\s+(.+)$`)
+	recurrenceTimeRegex    = regexp.MustCompile(`^(\d{1,2})(?:[:.](\d{2}))?\s*(am|pm)?$`)
+	recurrenceMonthlyRegex = regexp.MustCompile(`^(first|second|third|fourth|last|1st|2nd|3rd|4th)\s+([a-z]+)\s+(?:of\s+)?(?:the|every|each)\s+month$`)
+	recurrenceSplitRegex   = regexp.MustCompile(`\s*(?:,|&|\band\b|\s)\s*`)
+)
+
+var recurrenceWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+var recurrenceOrdinals = map[string]int{
+	"first": 1, "1st": 1,
+	"second": 2, "2nd": 2,
+	"third": 3, "3rd": 3,
+	"fourth": 4, "4th": 4,
+	"last": -1,
+}
+
+var workWeek = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+// ParseRecurrence parses a recurrence in plain english, the time of day defaults to 9:00 if left out
+func ParseRecurrence(s string) (*Recurrence, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.TrimPrefix(s, "every ")
+	s = strings.TrimPrefix(s, "each ")
+
+	r := &Recurrence{Hour: 9}
+
+	if m := recurrenceAtRegex.FindStringSubmatch(s); m != nil {
+		hour, minute, err := parseRecurrenceTime(m[2])
+		if err != nil {
+			return nil, err
+		}
+		r.Hour, r.Minute = hour, minute
+		s = m[1]
+	}
+
+	switch s {
+	case "", "day", "daily", "days":
+		r.Kind = RecurrenceDaily
+		return r, nil
+	case "weekday", "weekdays", "workday", "workdays":
+		r.Kind = RecurrenceWeekly
+		r.Weekdays = workWeek
+		return r, nil
+	case "weekend", "weekends":
+		r.Kind = RecurrenceWeekly
+		r.Weekdays = []time.Weekday{time.Saturday, time.Sunday}
+		return r, nil
+	}
+
+	if m := recurrenceMonthlyRegex.FindStringSubmatch(s); m != nil {
+		wd, ok := parseRecurrenceWeekday(m[2])
+		if !ok {
+			return nil, ErrInvalidRecurrence
+		}
+
+		r.Kind = RecurrenceMonthly
+		r.Nth = recurrenceOrdinals[m[1]]
+		r.Weekdays = []time.Weekday{wd}
+		return r, nil
+	}
+
+	r.Kind = RecurrenceWeekly
+	for _, v := range recurrenceSplitRegex.Split(s, -1) {
+		if v == "" {
+			continue
+		}
+
+		wd, ok := parseRecurrenceWeekday(v)
+		if !ok {
+			return nil, ErrInvalidRecurrence
+		}
+
+		if !r.hasWeekday(wd) {
+			r.Weekdays = append(r.Weekdays, wd)
+		}
+	}
+
+	if len(r.Weekdays) < 1 {
+		return nil, ErrInvalidRecurrence
+	}
+
+	return r, nil
+}
+
+func parseRecurrenceWeekday(s string) (time.Weekday, bool) {
+	wd, ok := recurrenceWeekdays[s]
+	if !ok && strings.HasSuffix(s, "s") {
+		// plural, "mondays"
+		wd, ok = recurrenceWeekdays[strings.TrimSuffix(s, "s")]
+	}
+
+	return wd, ok
+}
+
+func parseRecurrenceTime(s string) (hour, minute int, err error) {
+	m := recurrenceTimeRegex.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, 0, ErrInvalidRecurrence
+	}
+
+	hour, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+
+	switch m[3] {
+	case "am":
+		if hour > 12 {
+			return 0, 0, ErrInvalidRecurrence
+		}
+		if hour == 12 {
+			hour = 0
+		}
+	case "pm":
+		if hour > 12 {
+			return 0, 0, ErrInvalidRecurrence
+		}
+		if hour < 12 {
+			hour += 12
+		}
+	}
+
+	if hour > 23 || minute > 59 {
+		return 0, 0, ErrInvalidRecurrence
+	}
+
+	return hour, minute, nil
+}
+
+func (r *Recurrence) hasWeekday(wd time.Weekday) bool {
+	for _, v := range r.Weekdays {
+		if v == wd {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Recurrence) isWorkWeek() bool {
+	if len(r.Weekdays) != len(workWeek) {
+		return false
+	}
+
+	for _, v := range workWeek {
+		if !r.hasWeekday(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *Recurrence) matches(t time.Time) bool {
+	switch r.Kind {
+	case RecurrenceWeekly:
+		return r.hasWeekday(t.Weekday())
+	case RecurrenceMonthly:
+		if t.Weekday() != r.Weekdays[0] {
+			return false
+		}
+
+		if r.Nth < 0 {
+			// last occurrence if a week later is in the next month
+			return t.AddDate(0, 0, 7).Month() != t.Month()
+		}
+
+		return (t.Day()-1)/7+1 == r.Nth
+	}
+
+	return true
+}
+
+// Next returns the first occurrence strictly after the provided time, in the location of that time
+func (r *Recurrence) Next(after time.Time) time.Time {
+	loc := after.Location()
+	y, m, d := after.Date()
+
+	// the longest gap between two occurrences is a monthly one, a bit over 5 weeks
+	for i := 0; i < 40; i++ {
+		candidate := time.Date(y, m, d+i, r.Hour, r.Minute, 0, 0, loc)
+		if candidate.After(after) && r.matches(candidate) {
+			return candidate
+		}
+	}
+
+	// should never happen with a valid recurrence
+	return after.Add(time.Hour * 24)
+}
+
+func (r *Recurrence) String() string {
+	at := fmt.Sprintf(" at %02d:%02d", r.Hour, r.Minute)
+
+	switch r.Kind {
+	case RecurrenceDaily:
+		return "every day" + at
+	case RecurrenceMonthly:
+		ordinal := "last"
+		if r.Nth > 0 {
+			ordinal = [...]string{"first", "second", "third", "fourth"}[r.Nth-1]
+		}
+		return ordinal + " " + strings.ToLower(r.Weekdays[0].String()) + " of the month" + at
+	}
+
+	if r.isWorkWeek() {
+		return "every weekday" + at
+	}
+
+	days := make([]string, 0, len(r.Weekdays))
+	for _, v := range r.Weekdays {
+		days = append(days, strings.ToLower(v.String()))
+	}
+
+	return "every " + strings.Join(days, ", ") + at
+}
+
+var icalWeekdays = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// RRule returns the iCalendar (RFC 5545) RRULE value for this recurrence
+func (r *Recurrence) RRule() string {
+	switch r.Kind {
+	case RecurrenceDaily:
+		return "FREQ=DAILY"
+	case RecurrenceMonthly:
+		return fmt.Sprintf("FREQ=MONTHLY;BYDAY=%d%s", r.Nth, icalWeekdays[r.Weekdays[0]])
+	}
+
+	days := make([]string, 0, len(r.Weekdays))
+	for _, v := range r.Weekdays {
+		days = append(days, icalWeekdays[v])
+	}
+
+	return "FREQ=WEEKLY;BYDAY=" + strings.Join(days, ",")
+}
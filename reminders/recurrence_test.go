@@ -0,0 +1,123 @@
+package reminders
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	cases := []struct {
+		In     string
+		String string
+		RRule  string
+	}{
+		{"every day at 8:30", "every day at 08:30", "FREQ=DAILY"},
+		{"daily", "every day at 09:00", "FREQ=DAILY"},
+		{"every weekday at 9:00", "every weekday at 09:00", "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"},
+		{"weekends at 10am", "every saturday, sunday at 10:00", "FREQ=WEEKLY;BYDAY=SA,SU"},
+		{"every monday and friday at 5:30pm", "every monday, friday at 17:30", "FREQ=WEEKLY;BYDAY=MO,FR"},
+		{"Tuesdays, thurs & sat at 12am", "every tuesday, thursday, saturday at 00:00", "FREQ=WEEKLY;BYDAY=TU,TH,SA"},
+		{"every saturday at 7", "every saturday at 07:00", "FREQ=WEEKLY;BYDAY=SA"},
+		{"first monday of the month", "first monday of the month at 09:00", "FREQ=MONTHLY;BYDAY=1MO"},
+		{"last friday of every month at 16:00", "last friday of the month at 16:00", "FREQ=MONTHLY;BYDAY=-1FR"},
+	}
+
+	for _, c := range cases {
+		r, err := ParseRecurrence(c.In)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", c.In, err)
+			continue
+		}
+
+		if r.String() != c.String {
+			t.Errorf("%q: got string %q, expected %q", c.In, r.String(), c.String)
+		}
+
+		if r.RRule() != c.RRule {
+			t.Errorf("%q: got rrule %q, expected %q", c.In, r.RRule(), c.RRule)
+		}
+
+		// the stored form has to parse back to the same thing
+		again, err := ParseRecurrence(r.String())
+		if err != nil || again.String() != r.String() {
+			t.Errorf("%q: stored form %q did not round trip: %v", c.In, r.String(), err)
+		}
+	}
+}
+
+func TestParseRecurrenceInvalid(t *testing.T) {
+	for _, in := range []string{"every blursday", "monday at 25:00", "every day at 13pm", "fifth monday of the month", "tomorrow"} {
+		if _, err := ParseRecurrence(in); err == nil {
+			t.Errorf("%q: expected an error", in)
+		}
+	}
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no tzdata available:", err)
+	}
+
+	// a wednesday
+	ref := time.Date(2024, 3, 27, 10, 0, 0, 0, loc)
+
+	cases := []struct {
+		In       string
+		From     time.Time
+		Expected time.Time
+	}{
+		{"every day at 11:00", ref, time.Date(2024, 3, 27, 11, 0, 0, 0, loc)},
+		{"every day at 10:00", ref, time.Date(2024, 3, 28, 10, 0, 0, 0, loc)},
+		{"every weekday at 9:00", time.Date(2024, 3, 29, 9, 0, 0, 0, loc), time.Date(2024, 4, 1, 9, 0, 0, 0, loc)},
+		{"every monday at 9:00", ref, time.Date(2024, 4, 1, 9, 0, 0, 0, loc)},
+		{"first monday of the month at 9:00", ref, time.Date(2024, 4, 1, 9, 0, 0, 0, loc)},
+		{"second tuesday of the month at 9:00", ref, time.Date(2024, 4, 9, 9, 0, 0, 0, loc)},
+		{"last friday of the month at 9:00", ref, time.Date(2024, 3, 29, 9, 0, 0, 0, loc)},
+		{"last wednesday of the month at 9:00", ref, time.Date(2024, 4, 24, 9, 0, 0, 0, loc)},
+	}
+
+	for _, c := range cases {
+		r, err := ParseRecurrence(c.In)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", c.In, err)
+		}
+
+		if next := r.Next(c.From); !next.Equal(c.Expected) {
+			t.Errorf("%q: got %s, expected %s", c.In, next, c.Expected)
+		}
+	}
+
+	// keeps the local time of day across the DST change on the 31st
+	r, _ := ParseRecurrence("every day at 9:00")
+	next := r.Next(time.Date(2024, 3, 30, 12, 0, 0, 0, loc))
+	if next.Hour() != 9 || next.UTC().Hour() != 7 {
+		t.Errorf("got %s, expected 09:00 CEST", next)
+	}
+}
+
+func TestRemindersICS(t *testing.T) {
+	r := &Reminder{Message: "standup, with the team; bring\nnotes", When: time.Date(2024, 4, 1, 7, 0, 0, 0, time.UTC).Unix(), Recurrence: "every weekday at 09:00"}
+	r.ID = 5
+
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no tzdata available:", err)
+	}
+
+	out := RemindersICS([]*Reminder{r}, loc, time.Date(2024, 3, 27, 0, 0, 0, 0, time.UTC))
+	for _, expected := range []string{
+		"BEGIN:VEVENT\r\n",
+		"DTSTART;TZID=Europe/Berlin:20240401T090000\r\n",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR\r\n",
+		`DESCRIPTION:standup\, with the team\; bring\nnotes` + "\r\n",
+		"BEGIN:VTIMEZONE\r\nTZID:Europe/Berlin\r\n",
+		"BEGIN:DAYLIGHT\r\nDTSTART:20230326T020000\r\nRRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU\r\nTZOFFSETFROM:+0100\r\nTZOFFSETTO:+0200\r\nTZNAME:CEST\r\nEND:DAYLIGHT\r\n",
+		"BEGIN:STANDARD\r\nDTSTART:20231029T030000\r\nRRULE:FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU\r\nTZOFFSETFROM:+0200\r\nTZOFFSETTO:+0100\r\nTZNAME:CET\r\nEND:STANDARD\r\n",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("missing %q in:\n%s", expected, out)
+		}
+	}
+}
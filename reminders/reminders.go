@@ -11,6 +11,7 @@ import (
 	"github.com/mrbentarikau/pagst/common/mqueue"
 	"github.com/mrbentarikau/pagst/common/scheduledevents2"
 	"github.com/mrbentarikau/pagst/lib/discordgo"
+	"github.com/mrbentarikau/pagst/timezonecompanion"
 	"github.com/jinzhu/gorm"
 	"github.com/sirupsen/logrus"
 )
@@ -53,6 +54,7 @@ type Reminder struct {
 	Message        string
 	When           int64
 	Repeat         int64
+	Recurrence     string
 	AppCommand     bool
 	DisableMention bool
 }
@@ -67,9 +69,43 @@ func (r *Reminder) ChannelIDInt() (i int64) {
 	return
 }
 
+// ParsedRecurrence returns the recurrence of the reminder, or nil if it doesn't recur
+func (r *Reminder) ParsedRecurrence() *Recurrence {
+	if r.Recurrence == "" {
+		return nil
+	}
+
+	rec, err := ParseRecurrence(r.Recurrence)
+	if err != nil {
+		logger.WithError(err).WithField("id", r.ID).Error("invalid stored reminder recurrence")
+		return nil
+	}
+
+	return rec
+}
+
 func (r *Reminder) Trigger() error {
 	reminderRepeat := "**Reminder** for"
-	if r.Repeat > 0 {
+	if rec := r.ParsedRecurrence(); rec != nil {
+		reminderRepeat = "**Recurring reminder** for"
+		userID := r.UserIDInt()
+		tz := timezonecompanion.GetUserTimezone(userID)
+		if tz == nil {
+			tz = time.UTC
+		}
+
+		when := rec.Next(time.Now().In(tz))
+		r.When = when.Unix()
+
+		err := common.GORM.Save(r).Error
+		if err != nil {
+			logger.WithError(err).WithField("id", r.ID).Error("failed saving recurring reminder")
+		}
+		err = scheduledevents2.ScheduleEvent("reminders_check_user", r.GuildID, when, userID)
+		if err != nil {
+			logger.Info("Reminders recurrence scheduler:", err)
+		}
+	} else if r.Repeat > 0 {
 		reminderRepeat = "**Repeated reminder** for"
 		userID := r.UserIDInt()
 		repeatDuration := time.Duration(r.Repeat) * time.Nanosecond
@@ -127,6 +163,9 @@ func (r *Reminder) Trigger() error {
 			}
 
 			msgSend.Embeds = []*discordgo.MessageEmbed{embed}
+			for _, v := range snoozeComponents(r.ID) {
+				msgSend.Components = append(msgSend.Components, v)
+			}
 
 			_, err = common.BotSession.ChannelMessageSendComplex(channel.ID, msgSend)
 			if err != nil {
@@ -150,6 +189,7 @@ func (r *Reminder) Trigger() error {
 				MessageEmbed:    embed,
 				MessageStr:      reminderRepeat + " <@" + r.UserID + ">", // : " + common.ReplaceServerInvites(r.Message, r.GuildID, "(removed-invite)"),
 				AllowedMentions: disabledMentions,
+				Components:      snoozeComponents(r.ID),
 
 				Priority: 10, // above all feeds
 			})
@@ -179,7 +219,7 @@ func NewReminder(userID int64, guildID int64, channelID int64, message string, w
 	whenUnix := when.Unix()
 
 	if len(isAppCmd) > 0 {
-		appCmd = true
+		appCmd = isAppCmd[0]
 	}
 
 	reminder := &Reminder{
@@ -203,6 +243,29 @@ func NewReminder(userID int64, guildID int64, channelID int64, message string, w
 	return reminder, err
 }
 
+// NewRecurringReminder creates a reminder that first triggers at the next occurrence of rec in loc
+func NewRecurringReminder(userID int64, guildID int64, channelID int64, message string, rec *Recurrence, loc *time.Location, disableMention bool) (*Reminder, error) {
+	when := rec.Next(time.Now().In(loc))
+
+	reminder := &Reminder{
+		UserID:         discordgo.StrID(userID),
+		ChannelID:      discordgo.StrID(channelID),
+		Message:        message,
+		When:           when.Unix(),
+		GuildID:        guildID,
+		Recurrence:     rec.String(),
+		DisableMention: disableMention,
+	}
+
+	err := common.GORM.Create(reminder).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = scheduledevents2.ScheduleEvent("reminders_check_user", guildID, when, userID)
+	return reminder, err
+}
+
 /*
 func checkUserEvtHandlerLegacy(evt string) error {
 	split := strings.Split(evt, ":")
@@ -159,7 +159,7 @@ func (p *Plugin) handleRemindmeInteractionCreate(ic *discordgo.InteractionCreate
 		if durationStr != "" {
 			duration, err = common.ParseDuration(durationStr)
 			if err != nil || duration == 0 {
-				dateParser := createDateParser()
+				dateParser := createDateParser(GetUserDateLocale(p.RemindmeData.AuthorID))
 
 				registeredTimezone := timezonecompanion.GetUserTimezone(p.RemindmeData.AuthorID)
 				if registeredTimezone == nil || rsvp.UTCRegex.MatchString(durationStr) {
@@ -0,0 +1,120 @@
+package reminders
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/lib/discordgo"
+)
+
+const snoozeCustomIDPrefix = "reminders_snooze_"
+
+var snoozeDurations = []struct {
+	Label    string
+	Duration time.Duration
+}{
+	{"10 minutes", time.Minute * 10},
+	{"1 hour", time.Hour},
+	{"1 day", time.Hour * 24},
+}
+
+// snoozeComponents returns the buttons attached to a delivered reminder, the custom id is
+// reminders_snooze_<reminder id>_<minutes>
+func snoozeComponents(reminderID uint) []discordgo.ActionsRow {
+	buttons := make([]discordgo.MessageComponent, 0, len(snoozeDurations))
+	for _, v := range snoozeDurations {
+		buttons = append(buttons, discordgo.Button{
+			Label:    "Snooze " + v.Label,
+			Style:    discordgo.SecondaryButton,
+			CustomID: fmt.Sprintf("%s%d_%d", snoozeCustomIDPrefix, reminderID, int(v.Duration.Minutes())),
+		})
+	}
+
+	return []discordgo.ActionsRow{{Components: buttons}}
+}
+
+func parseSnoozeCustomID(customID string) (reminderID uint, duration time.Duration, ok bool) {
+	if !strings.HasPrefix(customID, snoozeCustomIDPrefix) {
+		return 0, 0, false
+	}
+
+	split := strings.Split(strings.TrimPrefix(customID, snoozeCustomIDPrefix), "_")
+	if len(split) != 2 {
+		return 0, 0, false
+	}
+
+	id, err := strconv.ParseUint(split[0], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	minutes, err := strconv.Atoi(split[1])
+	if err != nil || minutes < 1 {
+		return 0, 0, false
+	}
+
+	return uint(id), time.Duration(minutes) * time.Minute, true
+}
+
+func handleSnoozeInteraction(ic *discordgo.InteractionCreate) {
+	if ic.Type != discordgo.InteractionMessageComponent {
+		return
+	}
+
+	reminderID, duration, ok := parseSnoozeCustomID(ic.MessageComponentData().CustomID)
+	if !ok {
+		return
+	}
+
+	var userID int64
+	if ic.Member != nil {
+		userID = ic.Member.User.ID
+	} else if ic.User != nil {
+		userID = ic.User.ID
+	}
+
+	respond := func(msg string) {
+		err := common.BotSession.CreateInteractionResponse(ic.ID, ic.Token, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: msg,
+				Flags:   64,
+			},
+		})
+		if err != nil {
+			logger.WithError(err).WithField("reminder", reminderID).Error("failed responding to snooze interaction")
+		}
+	}
+
+	// delivered one-off reminders are soft deleted, so look past that
+	var original Reminder
+	err := common.GORM.Unscoped().Where("id = ?", reminderID).First(&original).Error
+	if err != nil {
+		respond("That reminder no longer exists")
+		return
+	}
+
+	if original.UserIDInt() != userID {
+		respond("You can only snooze your own reminders")
+		return
+	}
+
+	currentReminders, _ := GetUserReminders(userID)
+	if len(currentReminders) >= 25 {
+		respond("You can have a maximum of 25 active reminders, list your reminders with the `reminders` command")
+		return
+	}
+
+	when := time.Now().Add(duration)
+	_, err = NewReminder(userID, original.GuildID, original.ChannelIDInt(), original.Message, when, 0, original.DisableMention, original.AppCommand)
+	if err != nil {
+		logger.WithError(err).WithField("reminder", reminderID).Error("failed snoozing reminder")
+		respond("Failed snoozing the reminder, please try again")
+		return
+	}
+
+	respond(fmt.Sprintf("Snoozed, I'll remind you again <t:%d:R>", when.Unix()))
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"html"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -27,7 +28,15 @@ import (
 	"github.com/volatiletech/sqlboiler/v4/queries/qm"
 )
 
-const GoFeedQueryInterval = time.Minute * 5
+const (
+	// GoFeedQueryInterval is how often feeds are polled until we know how often they update
+	GoFeedQueryInterval = time.Minute * 5
+	// How often feeds are checked for being due to be polled
+	feedSchedulerTick = time.Minute
+
+	maxItemsPerPost = 10
+	maxItemAge      = time.Hour * 12
+)
 
 func (p *Plugin) StartFeed() {
 	p.Stop = make(chan *sync.WaitGroup)
@@ -42,9 +51,9 @@ func (p *Plugin) StopFeed(wg *sync.WaitGroup) {
 	}
 }
 
-// keeps the subscriptions up to date by updating the ones soon to be expiring
+// polls the feeds that are due, each feed has its own interval based on how often it updates
 func (p *Plugin) runRSSFeedInit() {
-	goFeedQueryTicker := time.NewTicker(GoFeedQueryInterval)
+	goFeedQueryTicker := time.NewTicker(feedSchedulerTick)
 	startDelay := time.After(time.Second * 2)
 
 	for {
@@ -67,14 +76,33 @@ func (p *Plugin) checkGoFeed() {
 		return
 	}
 
+	now := time.Now()
 	for _, feed := range activeRSSFeeds {
-		rssClient := p.rssClient
-		parsedFeed, err := rssClient.ParseURL(feed.FeedURL)
+		state, err := getFeedPollState(feed.FeedURL)
+		if err != nil {
+			logger.WithError(err).WithField("feed_url", feed.FeedURL).Error("Failed retrieving RSS feed poll state")
+			continue
+		}
+
+		if now.Before(state.NextCheck) {
+			continue
+		}
+
+		parsedFeed, err := p.fetchFeed(context.Background(), feed.FeedURL, state)
+		if saveErr := state.save(feed.FeedURL); saveErr != nil {
+			logger.WithError(saveErr).WithField("feed_url", feed.FeedURL).Error("Failed saving RSS feed poll state")
+		}
+
 		if err != nil {
 			logger.WithError(err).WithField("feed_url", feed.FeedURL).Warn("goFeed fetching RSS feed erred")
 			continue
 		}
 
+		if parsedFeed == nil {
+			// not modified since the last poll
+			continue
+		}
+
 		go p.CheckRSSFeed(nil, parsedFeed, feed.FeedURL)
 	}
 }
@@ -127,92 +155,89 @@ func (p *Plugin) CheckRSSFeed(feedByte []byte, directFeed *gofeed.Feed, feedURL
 		return nil
 	}
 
-	parsedFeedURL := removeProtocol(parsedFeedURLProtocol)
-	lastRSSLink, lastRSSTime, err := p.getLastRSSFeedTimes(parsedFeedURL)
+	seen, err := getSeenFeedItems(parsedFeedURLProtocol)
 	if err != nil {
 		return err
 	}
 
-	if lastRSSLink == feed.Items[0].Link {
-		// the feed was already posted and was probably just edited
-		return nil
+	// feeds last polled before items were remembered by id only know when they last posted
+	var legacyLastTime time.Time
+	if len(seen) == 0 {
+		legacyLastTime, err = getLegacyLastRSSFeedTime(removeProtocol(parsedFeedURLProtocol))
+		if err != nil {
+			return err
+		}
 	}
 
-	parsedPublishedAt := feed.Items[0].PublishedParsed
-	if parsedPublishedAt == nil {
-		// the feed does not have any time information
-		return nil
-	}
+	var newItems []*gofeed.Item
+	for _, item := range feed.Items {
+		id := itemID(item)
+		if seen[id] {
+			// already posted, or edited after it was
+			continue
+		}
+		seen[id] = true
 
-	if time.Since(*parsedPublishedAt) > time.Minute*30 && lastRSSLink == feed.Items[0].Link {
-		// just a safeguard against empty parsedPublishedAt
-		return nil
+		publishedAt := item.PublishedParsed
+		if publishedAt == nil || time.Since(*publishedAt) > maxItemAge || !publishedAt.After(legacyLastTime) {
+			continue
+		}
+
+		newItems = append(newItems, item)
 	}
 
-	if lastRSSTime.After(*parsedPublishedAt) {
-		// wasn't a new feed
-		return nil
+	err = markFeedItemsSeen(parsedFeedURLProtocol, feed.Items)
+	if err != nil {
+		return err
 	}
 
-	// This is a new feed, post it
-	return p.postRSSFeed(subs, &lastRSSTime, parsedPublishedAt, lastRSSLink, parsedFeedURLProtocol, feed)
+	if !legacyLastTime.IsZero() {
+		common.RedisPool.Do(radix.Cmd(nil, "DEL", KeyLastRSSFeedTime(removeProtocol(parsedFeedURLProtocol)), KeyLastRSSFeedLink(removeProtocol(parsedFeedURLProtocol))))
+	}
 
-}
+	if len(newItems) == 0 {
+		return nil
+	}
 
-func (p *Plugin) postRSSFeed(subs models.RSSFeedSlice, lastRSSTime, publishedAt *time.Time, lastRSSLink, feedURL string, feed *gofeed.Feed) error {
-	var itemLink, lastLink string
+	// post the oldest first, feeds are not always sorted
+	sort.SliceStable(newItems, func(i, j int) bool {
+		return newItems[i].PublishedParsed.Before(*newItems[j].PublishedParsed)
+	})
 
-	// Let's reverse sort the feed last post last in items slice
-	for i, j := 0, len(feed.Items)-1; i < j; i, j = i+1, j-1 {
-		feed.Items[i], feed.Items[j] = feed.Items[j], feed.Items[i]
+	if len(newItems) > maxItemsPerPost {
+		newItems = newItems[len(newItems)-maxItemsPerPost:]
 	}
 
-	var filteredFeedItems []*gofeed.Item
-	var count int
-	for _, v := range feed.Items {
-		if count >= 10 {
-			break
-		}
-
-		if lastRSSTime.After(*v.PublishedParsed) || time.Since(*v.PublishedParsed) > time.Hour*12 || lastRSSLink == v.Link {
-			continue
-		}
+	return p.postRSSFeed(subs, feed, newItems)
+}
 
-		itemLink = v.Link
-		if lastLink == itemLink {
+func (p *Plugin) postRSSFeed(subs models.RSSFeedSlice, feed *gofeed.Feed, newItems []*gofeed.Item) error {
+	logger.Infof("NEW RSS FEED ITEMS FOUND %d - %s", len(newItems), feed.Title)
+	for _, sub := range subs {
+		if !sub.Enabled {
 			continue
 		}
 
-		lastLink = itemLink
-		publishedAt = v.PublishedParsed
-
-		filteredFeedItems = append(filteredFeedItems, v)
-		count++
-	}
-
-	if len(filteredFeedItems) > 0 {
-		logger.Infof("FILTERED RSS FEEDS FOUND %d - %s", len(filteredFeedItems), feed.Title)
-		for _, sub := range subs {
-			if sub.Enabled {
-				go p.sendNewRSSFeedMessage(sub.GuildID, sub.ChannelID, sub.MentionRole, feed, sub.FeedName, filteredFeedItems)
-				time.Sleep(100 * time.Millisecond)
-			}
+		subItems := newItems
+		itemFilters, err := SubItemFilters(sub)
+		if err != nil {
+			logger.WithError(err).WithField("feed_id", sub.ID).Warn("Failed parsing RSS feed filters, posting unfiltered")
+		} else {
+			subItems = itemFilters.Apply(newItems)
 		}
 
-		err := common.MultipleCmds(
-			radix.FlatCmd(nil, "SET", KeyLastRSSFeedTime(removeProtocol(feedURL)), publishedAt.Unix()),
-			radix.FlatCmd(nil, "SET", KeyLastRSSFeedLink(removeProtocol(feedURL)), lastLink),
-		)
-		if err != nil {
-			return err
+		if len(subItems) == 0 {
+			continue
 		}
 
+		go p.sendNewRSSFeedMessage(sub.GuildID, sub.ChannelID, sub.ThreadID, sub.MentionRole, feed, sub.FeedName, sub.ForumTags, subItems)
+		time.Sleep(100 * time.Millisecond)
 	}
 
 	return nil
 }
 
-func (p *Plugin) sendNewRSSFeedMessage(guildID, channelID, mentionRole int64, feed *gofeed.Feed, feedName string, filteredItems []*gofeed.Item) {
+func (p *Plugin) sendNewRSSFeedMessage(guildID, channelID, threadID, mentionRole int64, feed *gofeed.Feed, feedName, forumTags string, filteredItems []*gofeed.Item) {
 	var content string
 	var rssEmbed []*discordgo.MessageEmbed
 
@@ -222,6 +247,14 @@ func (p *Plugin) sendNewRSSFeedMessage(guildID, channelID, mentionRole int64, fe
 		return
 	}
 
+	// every item gets a post of its own in a forum
+	if cs := guildState.GetChannel(channelID); cs != nil && cs.Type.IsForum() && threadID == 0 && len(filteredItems) > 1 {
+		for _, item := range filteredItems {
+			p.sendNewRSSFeedMessage(guildID, channelID, threadID, mentionRole, feed, feedName, forumTags, []*gofeed.Item{item})
+		}
+		return
+	}
+
 	var rssFeedTemplate RSSFeedTemplate
 	var rssFeedFilteredItems RSSItemsFiltered
 	rFTemplate, err := json.Marshal(&feed)
@@ -246,20 +279,17 @@ func (p *Plugin) sendNewRSSFeedMessage(guildID, channelID, mentionRole int64, fe
 	}
 
 	if len(feed.Items) > 0 {
-		rssFeedTemplate.Item = rssFeedTemplate.Items[len(feed.Items)-1]
+		rssFeedTemplate.Item = rssFeedTemplate.Items[0]
 		rssFeedTemplate.ItemsFiltered = RSSItemsFiltered{}
 		if len(rssFeedFilteredItems) > 0 {
 			rssFeedTemplate.ItemsFiltered = rssFeedFilteredItems
+			// the newest item may have been left out by the feed filters
+			rssFeedTemplate.Item = &rssFeedFilteredItems[len(rssFeedFilteredItems)-1].Item
 		}
 		//rssFeedTemplate.Item = rssFeedTemplate.Items[feedNum]
 		//rssFeedTemplate.Items = rssFeedTemplate.Items[:feedNum+1]
 	}
 
-	// Let's revert feed back to last post first in items slice
-	for i, j := 0, len(rssFeedTemplate.Items)-1; i < j; i, j = i+1, j-1 {
-		rssFeedTemplate.Items[i], rssFeedTemplate.Items[j] = rssFeedTemplate.Items[j], rssFeedTemplate.Items[i]
-	}
-
 	ctx := templates.NewContext(guildState, guildState.GetChannel(channelID), nil) //needs GuildSet, ChannelState, MemberState
 	ctx.Data["RSSFeed"] = rssFeedTemplate
 	ctx.Data["RSSFeedItem"] = rssFeedTemplate.Item
@@ -318,6 +348,7 @@ func (p *Plugin) sendNewRSSFeedMessage(guildID, channelID, mentionRole int64, fe
 	qm := &mqueue.QueuedElement{
 		GuildID:          guildID,
 		ChannelID:        channelID,
+		ThreadID:         threadID,
 		Source:           "rss",
 		SourceItemID:     "",
 		MessageEmbeds:    rssEmbed,
@@ -328,6 +359,7 @@ func (p *Plugin) sendNewRSSFeedMessage(guildID, channelID, mentionRole int64, fe
 		AllowedMentions: discordgo.AllowedMentions{
 			Parse: parseMentions,
 		},
+		ForumPost: itemsForumPost(feedName, forumTags, filteredItems),
 	}
 
 	if strings.TrimSpace(strings.ReplaceAll(content, "\r\n", "")) != "" {
@@ -349,6 +381,21 @@ func (p *Plugin) sendNewRSSFeedMessage(guildID, channelID, mentionRole int64, fe
 	feeds.MetricPostedMessages.With(prometheus.Labels{"source": "rssfeeds"}).Inc()
 }
 
+// itemsForumPost returns the forum post of new items, the labels tags are mapped from are the categories of the items and the name of the feed.
+// In forums there's only one item per message.
+func itemsForumPost(feedName, forumTags string, items []*gofeed.Item) *mqueue.ForumPost {
+	var title string
+	labels := []string{feedName}
+	for _, item := range items {
+		labels = append(labels, item.Categories...)
+		if item.Title != "" {
+			title = item.Title
+		}
+	}
+
+	return feeds.ForumPost(html.UnescapeString(title), forumTags, labels)
+}
+
 func createRSSEmbed(feed *gofeed.Feed, filteredItems []*gofeed.Item, feedName string) []*discordgo.MessageEmbed {
 	var feedItem *gofeed.Item
 	var feedAuthor string
@@ -400,21 +447,15 @@ func createRSSEmbed(feed *gofeed.Feed, filteredItems []*gofeed.Item, feedName st
 	return embeds
 }
 
-func (p *Plugin) getLastRSSFeedTimes(feedURL string) (lastRSSLink string, lastRSSTime time.Time, err error) {
-	// Find the last time feed was posted
+// getLegacyLastRSSFeedTime returns when the feed last posted from before items were remembered by id
+func getLegacyLastRSSFeedTime(feedURL string) (time.Time, error) {
 	var unixSeconds int64
-	err = common.RedisPool.Do(radix.Cmd(&unixSeconds, "GET", KeyLastRSSFeedTime(feedURL)))
-
-	var lastProcessedRSSTime time.Time
+	err := common.RedisPool.Do(radix.Cmd(&unixSeconds, "GET", KeyLastRSSFeedTime(feedURL)))
 	if err != nil || unixSeconds == 0 {
-		lastProcessedRSSTime = time.Time{}
-	} else {
-		lastProcessedRSSTime = time.Unix(unixSeconds, 0)
+		return time.Time{}, err
 	}
 
-	var lastProcessedRSSLink string
-	err = common.RedisPool.Do(radix.Cmd(&lastProcessedRSSLink, "GET", KeyLastRSSFeedLink(feedURL)))
-	return lastProcessedRSSLink, lastProcessedRSSTime, err
+	return time.Unix(unixSeconds, 0), nil
 }
 
 func removeProtocol(url string) string {
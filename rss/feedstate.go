@@ -0,0 +1,289 @@
+package rss
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mrbentarikau/pagst/common"
+
+	"github.com/mediocregopher/radix/v3"
+	"github.com/mmcdole/gofeed"
+)
+
+const (
+	MinFeedQueryInterval = time.Minute * 2
+	MaxFeedQueryInterval = time.Hour
+
+	// How many item ids are remembered per feed, well above the length of most feeds
+	MaxSeenFeedItems = 500
+	// Seen items of feeds that are no longer polled are forgotten after this
+	seenFeedItemsTTL = time.Hour * 24 * 30
+
+	feedFetchTimeout = time.Second * 30
+)
+
+var feedHTTPClient = &http.Client{
+	Timeout: feedFetchTimeout,
+}
+
+func KeyRSSFeedSeenItems(feedURL string) string {
+	return "rss_feed_seen_items:" + url.QueryEscape(feedURL)
+}
+func KeyRSSFeedPollState(feedURL string) string {
+	return "rss_feed_poll_state:" + url.QueryEscape(feedURL)
+}
+
+// feedPollState is what we know about polling a feed, stored in a redis hash
+type feedPollState struct {
+	ETag         string
+	LastModified string
+	Interval     time.Duration
+	NextCheck    time.Time
+}
+
+func getFeedPollState(feedURL string) (*feedPollState, error) {
+	var fields map[string]string
+	err := common.RedisPool.Do(radix.Cmd(&fields, "HGETALL", KeyRSSFeedPollState(removeProtocol(feedURL))))
+	if err != nil {
+		return nil, err
+	}
+
+	state := &feedPollState{
+		ETag:         fields["etag"],
+		LastModified: fields["last_modified"],
+		Interval:     GoFeedQueryInterval,
+	}
+
+	if v, err := strconv.ParseInt(fields["interval"], 10, 64); err == nil && v > 0 {
+		state.Interval = time.Duration(v) * time.Second
+	}
+
+	if v, err := strconv.ParseInt(fields["next_check"], 10, 64); err == nil {
+		state.NextCheck = time.Unix(v, 0)
+	}
+
+	return state, nil
+}
+
+func (s *feedPollState) save(feedURL string) error {
+	s.NextCheck = time.Now().Add(s.Interval)
+
+	key := KeyRSSFeedPollState(removeProtocol(feedURL))
+	return common.MultipleCmds(
+		radix.FlatCmd(nil, "HSET", key,
+			"etag", s.ETag,
+			"last_modified", s.LastModified,
+			"interval", int64(s.Interval/time.Second),
+			"next_check", s.NextCheck.Unix()),
+		radix.FlatCmd(nil, "EXPIRE", key, int64(seenFeedItemsTTL/time.Second)),
+	)
+}
+
+// fetchFeed does a conditional GET of the feed using the ETag and Last-Modified from the previous fetch,
+// returning a nil feed if it was not modified. The poll state is updated with the new validators and interval.
+func (p *Plugin) fetchFeed(ctx context.Context, feedURL string, state *feedPollState) (*gofeed.Feed, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", p.rssClient.UserAgent)
+	if state.ETag != "" {
+		req.Header.Set("If-None-Match", state.ETag)
+	}
+	if state.LastModified != "" {
+		req.Header.Set("If-Modified-Since", state.LastModified)
+	}
+
+	resp, err := feedHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	maxAge := cacheMaxAge(resp.Header, time.Now())
+
+	if resp.StatusCode == http.StatusNotModified {
+		if maxAge > state.Interval {
+			state.Interval = clampFeedInterval(maxAge)
+		}
+		return nil, nil
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, gofeed.HTTPError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+		}
+	}
+
+	feed, err := p.rssClient.Parse(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	state.ETag = resp.Header.Get("ETag")
+	state.LastModified = resp.Header.Get("Last-Modified")
+	state.Interval = feedInterval(feed.Items, maxAge)
+
+	return feed, nil
+}
+
+// feedInterval picks how often a feed is polled from how often it published recently,
+// never polling more often than the server asks it to be cached for
+func feedInterval(items []*gofeed.Item, maxAge time.Duration) time.Duration {
+	interval := GoFeedQueryInterval
+	if gap := medianPublishGap(items, 20); gap > 0 {
+		// poll a few times per new item so posts don't lag too far behind
+		interval = gap / 4
+	}
+
+	if maxAge > interval {
+		interval = maxAge
+	}
+
+	return clampFeedInterval(interval)
+}
+
+func clampFeedInterval(interval time.Duration) time.Duration {
+	if interval < MinFeedQueryInterval {
+		return MinFeedQueryInterval
+	}
+
+	if interval > MaxFeedQueryInterval {
+		return MaxFeedQueryInterval
+	}
+
+	return interval
+}
+
+// medianPublishGap returns the median time between the n most recent dated items, 0 if there are less than 2
+func medianPublishGap(items []*gofeed.Item, n int) time.Duration {
+	var times []time.Time
+	for _, item := range items {
+		if t := itemTime(item); t != nil {
+			times = append(times, *t)
+		}
+	}
+
+	sort.Slice(times, func(i, j int) bool { return times[i].After(times[j]) })
+	if len(times) > n {
+		times = times[:n]
+	}
+
+	if len(times) < 2 {
+		return 0
+	}
+
+	gaps := make([]time.Duration, 0, len(times)-1)
+	for i := 1; i < len(times); i++ {
+		gaps = append(gaps, times[i-1].Sub(times[i]))
+	}
+
+	sort.Slice(gaps, func(i, j int) bool { return gaps[i] < gaps[j] })
+	return gaps[len(gaps)/2]
+}
+
+// cacheMaxAge returns how long the response may be cached for from the Cache-Control or Expires headers
+func cacheMaxAge(header http.Header, now time.Time) time.Duration {
+	if cc := header.Get("Cache-Control"); cc != "" {
+		for _, directive := range strings.Split(cc, ",") {
+			directive = strings.ToLower(strings.TrimSpace(directive))
+			if directive == "no-cache" || directive == "no-store" {
+				return 0
+			}
+
+			if strings.HasPrefix(directive, "max-age=") {
+				seconds, err := strconv.ParseInt(strings.TrimPrefix(directive, "max-age="), 10, 64)
+				if err != nil || seconds < 0 {
+					return 0
+				}
+				return time.Duration(seconds) * time.Second
+			}
+		}
+	}
+
+	if expires := header.Get("Expires"); expires != "" {
+		t, err := http.ParseTime(expires)
+		if err != nil {
+			return 0
+		}
+
+		if date, err := http.ParseTime(header.Get("Date")); err == nil {
+			now = date
+		}
+
+		if t.After(now) {
+			return t.Sub(now)
+		}
+	}
+
+	return 0
+}
+
+func itemTime(item *gofeed.Item) *time.Time {
+	if item.PublishedParsed != nil {
+		return item.PublishedParsed
+	}
+
+	return item.UpdatedParsed
+}
+
+// itemID identifies a feed item across polls, even if the feed reorders or edits it
+func itemID(item *gofeed.Item) string {
+	if item.GUID != "" {
+		return item.GUID
+	}
+
+	if item.Link != "" {
+		return item.Link
+	}
+
+	h := sha1.Sum([]byte(item.Title + "\x00" + item.Published))
+	return hex.EncodeToString(h[:])
+}
+
+func getSeenFeedItems(feedURL string) (map[string]bool, error) {
+	var ids []string
+	err := common.RedisPool.Do(radix.Cmd(&ids, "ZRANGE", KeyRSSFeedSeenItems(removeProtocol(feedURL)), "0", "-1"))
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		seen[id] = true
+	}
+
+	return seen, nil
+}
+
+// markFeedItemsSeen remembers the items of the feed, trimming the oldest so at most MaxSeenFeedItems are kept.
+// Items still in the feed are refreshed so they are never trimmed while the feed can return them.
+func markFeedItemsSeen(feedURL string, items []*gofeed.Item) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	key := KeyRSSFeedSeenItems(removeProtocol(feedURL))
+	now := time.Now().UnixNano()
+
+	args := make([]interface{}, 0, len(items)*2)
+	for i, item := range items {
+		// keep the feed order stable within a poll
+		args = append(args, now-int64(i), itemID(item))
+	}
+
+	return common.MultipleCmds(
+		radix.FlatCmd(nil, "ZADD", key, args...),
+		radix.FlatCmd(nil, "ZREMRANGEBYRANK", key, 0, -(MaxSeenFeedItems+1)),
+		radix.FlatCmd(nil, "EXPIRE", key, int64(seenFeedItemsTTL/time.Second)),
+	)
+}
@@ -0,0 +1,79 @@
+package rss
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+func TestFeedInterval(t *testing.T) {
+	now := time.Now()
+	itemsEvery := func(gap time.Duration, n int) []*gofeed.Item {
+		var items []*gofeed.Item
+		for i := 0; i < n; i++ {
+			published := now.Add(-gap * time.Duration(i))
+			items = append(items, &gofeed.Item{PublishedParsed: &published})
+		}
+		return items
+	}
+
+	cases := []struct {
+		Name     string
+		Items    []*gofeed.Item
+		MaxAge   time.Duration
+		Expected time.Duration
+	}{
+		{"no dated items", []*gofeed.Item{{}}, 0, GoFeedQueryInterval},
+		{"hourly", itemsEvery(time.Hour, 10), 0, time.Minute * 15},
+		{"very busy", itemsEvery(time.Minute, 10), 0, MinFeedQueryInterval},
+		{"daily", itemsEvery(time.Hour*24, 10), 0, MaxFeedQueryInterval},
+		{"cached longer", itemsEvery(time.Hour, 10), time.Minute * 30, time.Minute * 30},
+		{"cached shorter", itemsEvery(time.Hour, 10), time.Minute, time.Minute * 15},
+	}
+
+	for _, c := range cases {
+		if got := feedInterval(c.Items, c.MaxAge); got != c.Expected {
+			t.Errorf("%s: got %s, expected %s", c.Name, got, c.Expected)
+		}
+	}
+}
+
+func TestCacheMaxAge(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		Header   http.Header
+		Expected time.Duration
+	}{
+		{http.Header{}, 0},
+		{http.Header{"Cache-Control": {"public, max-age=600"}}, time.Minute * 10},
+		{http.Header{"Cache-Control": {"no-cache"}, "Expires": {"Fri, 15 Mar 2024 13:00:00 GMT"}}, 0},
+		{http.Header{"Expires": {"Fri, 15 Mar 2024 13:00:00 GMT"}}, time.Hour},
+		{http.Header{"Expires": {"Fri, 15 Mar 2024 13:00:00 GMT"}, "Date": {"Fri, 15 Mar 2024 12:30:00 GMT"}}, time.Minute * 30},
+		{http.Header{"Expires": {"0"}}, 0},
+	}
+
+	for i, c := range cases {
+		if got := cacheMaxAge(c.Header, now); got != c.Expected {
+			t.Errorf("case %d: got %s, expected %s", i, got, c.Expected)
+		}
+	}
+}
+
+func TestItemID(t *testing.T) {
+	if id := itemID(&gofeed.Item{GUID: "tag:example.com,2024:1", Link: "https://example.com/1"}); id != "tag:example.com,2024:1" {
+		t.Errorf("expected the GUID, got %q", id)
+	}
+
+	if id := itemID(&gofeed.Item{Link: "https://example.com/1"}); id != "https://example.com/1" {
+		t.Errorf("expected the link, got %q", id)
+	}
+
+	a := itemID(&gofeed.Item{Title: "a", Published: "today"})
+	b := itemID(&gofeed.Item{Title: "b", Published: "today"})
+	if a == b {
+		t.Error("items without GUID or link should be told apart by title")
+	}
+}
@@ -0,0 +1,200 @@
+package rss
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/feeds"
+	"github.com/mrbentarikau/pagst/rss/models"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/mmcdole/gofeed"
+)
+
+const (
+	MaxFiltersPerList = 25
+	MaxFilterLength   = 200
+)
+
+// Fields a filter can be limited to with a "field:" prefix
+const (
+	FilterFieldTitle       = "title"
+	FilterFieldDescription = "description"
+	FilterFieldCategory    = "category"
+	FilterFieldAuthor      = "author"
+)
+
+var filterFieldAliases = map[string]string{
+	"title":       FilterFieldTitle,
+	"description": FilterFieldDescription,
+	"desc":        FilterFieldDescription,
+	"category":    FilterFieldCategory,
+	"tag":         FilterFieldCategory,
+	"author":      FilterFieldAuthor,
+}
+
+var stripTagsPolicy = bluemonday.StripTagsPolicy()
+
+// FeedFilter matches feed items on a plain word or a regex, optionally limited to one field
+type FeedFilter struct {
+	// Source is the filter as written by the user
+	Source string
+	// Field is empty if the filter matches any field
+	Field string
+
+	re *regexp.Regexp
+}
+
+// ParseFilter parses a single filter line in the form "[field:]word" or "[field:]/regex/",
+// plain words are matched case insensitively as whole words
+func ParseFilter(line string) (*FeedFilter, error) {
+	line = strings.TrimSpace(line)
+	filter := &FeedFilter{Source: line}
+
+	if i := strings.Index(line, ":"); i > 0 {
+		if field, ok := filterFieldAliases[strings.ToLower(line[:i])]; ok {
+			filter.Field = field
+			line = strings.TrimSpace(line[i+1:])
+		}
+	}
+
+	if line == "" {
+		return nil, fmt.Errorf("filter `%s` is empty", filter.Source)
+	}
+
+	if len(line) > MaxFilterLength {
+		return nil, fmt.Errorf("filter `%s` is too long (max %d characters)", common.CutStringShort(filter.Source, 50), MaxFilterLength)
+	}
+
+	var err error
+	filter.re, err = feeds.CompileFilterPattern(line)
+	if err != nil {
+		return nil, fmt.Errorf("filter `%s` is not a valid regex: %v", filter.Source, err)
+	}
+
+	return filter, nil
+}
+
+// ParseFilters parses one filter per line, skipping empty lines
+func ParseFilters(s string) ([]*FeedFilter, error) {
+	var result []*FeedFilter
+	for _, line := range strings.Split(s, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		filter, err := ParseFilter(line)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, filter)
+	}
+
+	if len(result) > MaxFiltersPerList {
+		return nil, fmt.Errorf("too many filters (%d/%d)", len(result), MaxFiltersPerList)
+	}
+
+	return result, nil
+}
+
+// Matches returns true if the filter matches the item
+func (f *FeedFilter) Matches(item *gofeed.Item) bool {
+	for _, v := range filterFieldValues(item, f.Field) {
+		if f.re.MatchString(v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func filterFieldValues(item *gofeed.Item, field string) []string {
+	var result []string
+
+	if field == "" || field == FilterFieldTitle {
+		result = append(result, html.UnescapeString(item.Title))
+	}
+
+	if field == "" || field == FilterFieldDescription {
+		result = append(result, html.UnescapeString(stripTagsPolicy.Sanitize(item.Description)))
+	}
+
+	if field == "" || field == FilterFieldCategory {
+		result = append(result, item.Categories...)
+	}
+
+	if field == "" || field == FilterFieldAuthor {
+		if item.Author != nil {
+			result = append(result, item.Author.Name)
+		}
+		for _, author := range item.Authors {
+			if author != nil {
+				result = append(result, author.Name)
+			}
+		}
+	}
+
+	return result
+}
+
+// ItemFilters are the include and exclude filters of a feed subscription
+type ItemFilters struct {
+	Include []*FeedFilter
+	Exclude []*FeedFilter
+}
+
+// SubItemFilters parses the filters of the subscription
+func SubItemFilters(sub *models.RSSFeed) (*ItemFilters, error) {
+	include, err := ParseFilters(sub.IncludeFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	exclude, err := ParseFilters(sub.ExcludeFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ItemFilters{Include: include, Exclude: exclude}, nil
+}
+
+// Check returns whether the item passes the filters, and if not the reason why
+func (f *ItemFilters) Check(item *gofeed.Item) (bool, string) {
+	for _, filter := range f.Exclude {
+		if filter.Matches(item) {
+			return false, "excluded by `" + filter.Source + "`"
+		}
+	}
+
+	if len(f.Include) == 0 {
+		return true, ""
+	}
+
+	for _, filter := range f.Include {
+		if filter.Matches(item) {
+			return true, "included by `" + filter.Source + "`"
+		}
+	}
+
+	return false, "no include filter matched"
+}
+
+// Apply returns the items that pass the filters
+func (f *ItemFilters) Apply(items []*gofeed.Item) []*gofeed.Item {
+	if len(f.Include) == 0 && len(f.Exclude) == 0 {
+		return items
+	}
+
+	var result []*gofeed.Item
+	for _, item := range items {
+		if ok, _ := f.Check(item); ok {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,61 @@
+package rss
+
+import (
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+)
+
+func TestItemFilters(t *testing.T) {
+	item := &gofeed.Item{
+		Title:       "Go 1.22 released",
+		Description: "<p>The <b>Golang</b> team is happy to announce...</p>",
+		Categories:  []string{"Releases", "Programming"},
+		Authors:     []*gofeed.Person{{Name: "Jane Doe"}},
+	}
+
+	cases := []struct {
+		Include string
+		Exclude string
+		Posts   bool
+	}{
+		{"", "", true},
+		{"go", "", true},
+		{"goo", "", false},
+		{"golang", "", true},
+		{"title:golang", "", false},
+		{"description:golang", "", true},
+		{"tag:releases", "", true},
+		{"author:jane doe", "", true},
+		{"/go\\s+1\\.\\d+/", "", true},
+		{"rust\npython", "", false},
+		{"rust\ngo", "", true},
+		{"go", "category:releases", false},
+		{"", "/^golang/", true},
+		{"", "title:/^go /", false},
+	}
+
+	for _, c := range cases {
+		include, err := ParseFilters(c.Include)
+		if err != nil {
+			t.Fatalf("%q: %v", c.Include, err)
+		}
+		exclude, err := ParseFilters(c.Exclude)
+		if err != nil {
+			t.Fatalf("%q: %v", c.Exclude, err)
+		}
+
+		filters := &ItemFilters{Include: include, Exclude: exclude}
+		if posts, reason := filters.Check(item); posts != c.Posts {
+			t.Errorf("include %q exclude %q: got %t (%s), expected %t", c.Include, c.Exclude, posts, reason, c.Posts)
+		}
+	}
+}
+
+func TestParseFiltersInvalid(t *testing.T) {
+	for _, s := range []string{"title:", "/(unclosed/"} {
+		if _, err := ParseFilters(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
@@ -23,62 +23,82 @@ import (
 
 // RSSFeed is an object representing the database table.
 type RSSFeed struct {
-	ID          int64     `boil:"id" json:"id" toml:"id" yaml:"id"`
-	GuildID     int64     `boil:"guild_id" json:"guild_id" toml:"guild_id" yaml:"guild_id"`
-	CreatedAt   time.Time `boil:"created_at" json:"created_at" toml:"created_at" yaml:"created_at"`
-	Enabled     bool      `boil:"enabled" json:"enabled" toml:"enabled" yaml:"enabled"`
-	ChannelID   int64     `boil:"channel_id" json:"channel_id" toml:"channel_id" yaml:"channel_id"`
-	MentionRole int64     `boil:"mention_role" json:"mention_role" toml:"mention_role" yaml:"mention_role"`
-	FeedName    string    `boil:"feed_name" json:"feed_name" toml:"feed_name" yaml:"feed_name"`
-	FeedTitle   string    `boil:"feed_title" json:"feed_title" toml:"feed_title" yaml:"feed_title"`
-	FeedURL     string    `boil:"feed_url" json:"feed_url" toml:"feed_url" yaml:"feed_url"`
+	ID             int64     `boil:"id" json:"id" toml:"id" yaml:"id"`
+	GuildID        int64     `boil:"guild_id" json:"guild_id" toml:"guild_id" yaml:"guild_id"`
+	CreatedAt      time.Time `boil:"created_at" json:"created_at" toml:"created_at" yaml:"created_at"`
+	Enabled        bool      `boil:"enabled" json:"enabled" toml:"enabled" yaml:"enabled"`
+	ChannelID      int64     `boil:"channel_id" json:"channel_id" toml:"channel_id" yaml:"channel_id"`
+	MentionRole    int64     `boil:"mention_role" json:"mention_role" toml:"mention_role" yaml:"mention_role"`
+	FeedName       string    `boil:"feed_name" json:"feed_name" toml:"feed_name" yaml:"feed_name"`
+	FeedTitle      string    `boil:"feed_title" json:"feed_title" toml:"feed_title" yaml:"feed_title"`
+	FeedURL        string    `boil:"feed_url" json:"feed_url" toml:"feed_url" yaml:"feed_url"`
+	IncludeFilters string    `boil:"include_filters" json:"include_filters" toml:"include_filters" yaml:"include_filters"`
+	ExcludeFilters string    `boil:"exclude_filters" json:"exclude_filters" toml:"exclude_filters" yaml:"exclude_filters"`
+	ForumTags      string    `boil:"forum_tags" json:"forum_tags" toml:"forum_tags" yaml:"forum_tags"`
+	ThreadID       int64     `boil:"thread_id" json:"thread_id" toml:"thread_id" yaml:"thread_id"`
 
 	R *rssFeedR `boil:"-" json:"-" toml:"-" yaml:"-"`
 	L rssFeedL  `boil:"-" json:"-" toml:"-" yaml:"-"`
 }
 
 var RSSFeedColumns = struct {
-	ID          string
-	GuildID     string
-	CreatedAt   string
-	Enabled     string
-	ChannelID   string
-	MentionRole string
-	FeedName    string
-	FeedTitle   string
-	FeedURL     string
+	ID             string
+	GuildID        string
+	CreatedAt      string
+	Enabled        string
+	ChannelID      string
+	MentionRole    string
+	FeedName       string
+	FeedTitle      string
+	FeedURL        string
+	IncludeFilters string
+	ExcludeFilters string
+	ForumTags      string
+	ThreadID       string
 }{
-	ID:          "id",
-	GuildID:     "guild_id",
-	CreatedAt:   "created_at",
-	Enabled:     "enabled",
-	ChannelID:   "channel_id",
-	MentionRole: "mention_role",
-	FeedName:    "feed_name",
-	FeedTitle:   "feed_title",
-	FeedURL:     "feed_url",
+	ID:             "id",
+	GuildID:        "guild_id",
+	CreatedAt:      "created_at",
+	Enabled:        "enabled",
+	ChannelID:      "channel_id",
+	MentionRole:    "mention_role",
+	FeedName:       "feed_name",
+	FeedTitle:      "feed_title",
+	FeedURL:        "feed_url",
+	IncludeFilters: "include_filters",
+	ExcludeFilters: "exclude_filters",
+	ForumTags:      "forum_tags",
+	ThreadID:       "thread_id",
 }
 
 var RSSFeedTableColumns = struct {
-	ID          string
-	GuildID     string
-	CreatedAt   string
-	Enabled     string
-	ChannelID   string
-	MentionRole string
-	FeedName    string
-	FeedTitle   string
-	FeedURL     string
+	ID             string
+	GuildID        string
+	CreatedAt      string
+	Enabled        string
+	ChannelID      string
+	MentionRole    string
+	FeedName       string
+	FeedTitle      string
+	FeedURL        string
+	IncludeFilters string
+	ExcludeFilters string
+	ForumTags      string
+	ThreadID       string
 }{
-	ID:          "rss_feeds.id",
-	GuildID:     "rss_feeds.guild_id",
-	CreatedAt:   "rss_feeds.created_at",
-	Enabled:     "rss_feeds.enabled",
-	ChannelID:   "rss_feeds.channel_id",
-	MentionRole: "rss_feeds.mention_role",
-	FeedName:    "rss_feeds.feed_name",
-	FeedTitle:   "rss_feeds.feed_title",
-	FeedURL:     "rss_feeds.feed_url",
+	ID:             "rss_feeds.id",
+	GuildID:        "rss_feeds.guild_id",
+	CreatedAt:      "rss_feeds.created_at",
+	Enabled:        "rss_feeds.enabled",
+	ChannelID:      "rss_feeds.channel_id",
+	MentionRole:    "rss_feeds.mention_role",
+	FeedName:       "rss_feeds.feed_name",
+	FeedTitle:      "rss_feeds.feed_title",
+	FeedURL:        "rss_feeds.feed_url",
+	IncludeFilters: "rss_feeds.include_filters",
+	ExcludeFilters: "rss_feeds.exclude_filters",
+	ForumTags:      "rss_feeds.forum_tags",
+	ThreadID:       "rss_feeds.thread_id",
 }
 
 // Generated where
@@ -105,25 +125,33 @@ func (w whereHelpertime_Time) GTE(x time.Time) qm.QueryMod {
 }
 
 var RSSFeedWhere = struct {
-	ID          whereHelperint64
-	GuildID     whereHelperint64
-	CreatedAt   whereHelpertime_Time
-	Enabled     whereHelperbool
-	ChannelID   whereHelperint64
-	MentionRole whereHelperint64
-	FeedName    whereHelperstring
-	FeedTitle   whereHelperstring
-	FeedURL     whereHelperstring
+	ID             whereHelperint64
+	GuildID        whereHelperint64
+	CreatedAt      whereHelpertime_Time
+	Enabled        whereHelperbool
+	ChannelID      whereHelperint64
+	MentionRole    whereHelperint64
+	FeedName       whereHelperstring
+	FeedTitle      whereHelperstring
+	FeedURL        whereHelperstring
+	IncludeFilters whereHelperstring
+	ExcludeFilters whereHelperstring
+	ForumTags      whereHelperstring
+	ThreadID       whereHelperint64
 }{
-	ID:          whereHelperint64{field: "\"rss_feeds\".\"id\""},
-	GuildID:     whereHelperint64{field: "\"rss_feeds\".\"guild_id\""},
-	CreatedAt:   whereHelpertime_Time{field: "\"rss_feeds\".\"created_at\""},
-	Enabled:     whereHelperbool{field: "\"rss_feeds\".\"enabled\""},
-	ChannelID:   whereHelperint64{field: "\"rss_feeds\".\"channel_id\""},
-	MentionRole: whereHelperint64{field: "\"rss_feeds\".\"mention_role\""},
-	FeedName:    whereHelperstring{field: "\"rss_feeds\".\"feed_name\""},
-	FeedTitle:   whereHelperstring{field: "\"rss_feeds\".\"feed_title\""},
-	FeedURL:     whereHelperstring{field: "\"rss_feeds\".\"feed_url\""},
+	ID:             whereHelperint64{field: "\"rss_feeds\".\"id\""},
+	GuildID:        whereHelperint64{field: "\"rss_feeds\".\"guild_id\""},
+	CreatedAt:      whereHelpertime_Time{field: "\"rss_feeds\".\"created_at\""},
+	Enabled:        whereHelperbool{field: "\"rss_feeds\".\"enabled\""},
+	ChannelID:      whereHelperint64{field: "\"rss_feeds\".\"channel_id\""},
+	MentionRole:    whereHelperint64{field: "\"rss_feeds\".\"mention_role\""},
+	FeedName:       whereHelperstring{field: "\"rss_feeds\".\"feed_name\""},
+	FeedTitle:      whereHelperstring{field: "\"rss_feeds\".\"feed_title\""},
+	FeedURL:        whereHelperstring{field: "\"rss_feeds\".\"feed_url\""},
+	IncludeFilters: whereHelperstring{field: "\"rss_feeds\".\"include_filters\""},
+	ExcludeFilters: whereHelperstring{field: "\"rss_feeds\".\"exclude_filters\""},
+	ForumTags:      whereHelperstring{field: "\"rss_feeds\".\"forum_tags\""},
+	ThreadID:       whereHelperint64{field: "\"rss_feeds\".\"thread_id\""},
 }
 
 // RSSFeedRels is where relationship names are stored.
@@ -143,9 +171,9 @@ func (*rssFeedR) NewStruct() *rssFeedR {
 type rssFeedL struct{}
 
 var (
-	rssFeedAllColumns            = []string{"id", "guild_id", "created_at", "enabled", "channel_id", "mention_role", "feed_name", "feed_title", "feed_url"}
+	rssFeedAllColumns            = []string{"id", "guild_id", "created_at", "enabled", "channel_id", "mention_role", "feed_name", "feed_title", "feed_url", "include_filters", "exclude_filters", "forum_tags", "thread_id"}
 	rssFeedColumnsWithoutDefault = []string{"guild_id", "created_at", "enabled", "channel_id", "mention_role", "feed_name", "feed_title", "feed_url"}
-	rssFeedColumnsWithDefault    = []string{"id"}
+	rssFeedColumnsWithDefault    = []string{"id", "include_filters", "exclude_filters", "forum_tags", "thread_id"}
 	rssFeedPrimaryKeyColumns     = []string{"id"}
 	rssFeedGeneratedColumns      = []string{}
 )
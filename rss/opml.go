@@ -0,0 +1,343 @@
+package rss
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/lib/discordgo"
+	"github.com/mrbentarikau/pagst/lib/dstate"
+	"github.com/mrbentarikau/pagst/rss/models"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+)
+
+// How many feeds are fetched at once when validating an import
+const opmlImportConcurrency = 8
+
+// OPML is an outline of feed subscriptions, the format feed readers use to move subscriptions around
+type OPML struct {
+	XMLName xml.Name     `xml:"opml"`
+	Version string       `xml:"version,attr"`
+	Head    OPMLHead     `xml:"head"`
+	Outline []*OPMLEntry `xml:"body>outline"`
+}
+
+type OPMLHead struct {
+	Title       string `xml:"title"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+// OPMLEntry is a feed, or a folder of feeds in exports of other readers. Channel, mention role,
+// enabled and filters are extension attributes only we understand, other readers ignore them.
+type OPMLEntry struct {
+	Type   string `xml:"type,attr,omitempty"`
+	Text   string `xml:"text,attr"`
+	Title  string `xml:"title,attr,omitempty"`
+	XMLURL string `xml:"xmlUrl,attr,omitempty"`
+
+	Channel        string `xml:"channel,attr,omitempty"`
+	ChannelID      int64  `xml:"channelId,attr,omitempty"`
+	MentionRole    string `xml:"mentionRole,attr,omitempty"`
+	MentionRoleID  int64  `xml:"mentionRoleId,attr,omitempty"`
+	Enabled        string `xml:"enabled,attr,omitempty"`
+	IncludeFilters string `xml:"includeFilters,attr,omitempty"`
+	ExcludeFilters string `xml:"excludeFilters,attr,omitempty"`
+
+	Outline []*OPMLEntry `xml:"outline"`
+}
+
+// WriteOPML writes the feeds of the guild as an OPML document
+func WriteOPML(w io.Writer, gs *dstate.GuildSet, subs models.RSSFeedSlice) error {
+	doc := &OPML{
+		Version: "2.0",
+		Head: OPMLHead{
+			Title:       "RSS feeds of " + gs.Name,
+			DateCreated: time.Now().UTC().Format(time.RFC1123Z),
+		},
+	}
+
+	for _, sub := range subs {
+		entry := &OPMLEntry{
+			Type:           "rss",
+			Text:           sub.FeedName,
+			Title:          sub.FeedTitle,
+			XMLURL:         sub.FeedURL,
+			ChannelID:      sub.ChannelID,
+			MentionRoleID:  sub.MentionRole,
+			IncludeFilters: sub.IncludeFilters,
+			ExcludeFilters: sub.ExcludeFilters,
+		}
+
+		if entry.Text == "" {
+			entry.Text = sub.FeedTitle
+		}
+
+		if cs := gs.GetChannel(sub.ChannelID); cs != nil {
+			entry.Channel = cs.Name
+		}
+
+		if role := gs.GetRole(sub.MentionRole); role != nil {
+			entry.MentionRole = role.Name
+		}
+
+		if !sub.Enabled {
+			entry.Enabled = "false"
+		}
+
+		doc.Outline = append(doc.Outline, entry)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(doc)
+}
+
+// ParseOPML returns the feeds in an OPML document, flattening folders
+func ParseOPML(r io.Reader) ([]*OPMLEntry, error) {
+	var doc OPML
+	err := xml.NewDecoder(r).Decode(&doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*OPMLEntry
+	var walk func(entries []*OPMLEntry)
+	walk = func(entries []*OPMLEntry) {
+		for _, entry := range entries {
+			if entry.XMLURL != "" {
+				result = append(result, entry)
+			}
+			walk(entry.Outline)
+		}
+	}
+	walk(doc.Outline)
+
+	return result, nil
+}
+
+// OPMLImportResult is the outcome of importing a single feed
+type OPMLImportResult struct {
+	Name    string
+	URL     string
+	OK      bool
+	Message string
+}
+
+var reRedditFeed = regexp.MustCompile(`(reddit)(.com/)`)
+
+// ValidateFeedURL fetches the feed at the URL and checks that it can be followed,
+// the error is meant to be shown to the user
+func (p *Plugin) ValidateFeedURL(ctx context.Context, feedURL string) (*gofeed.Feed, error) {
+	if !common.LinkRegexProtocolStrict.MatchString(feedURL) {
+		return nil, errors.New("This is not a valid HTTP(S) URL...")
+	}
+
+	if reRedditFeed.MatchString(feedURL) {
+		reMatched := reRedditFeed.FindStringSubmatch(feedURL)[1]
+		return nil, errors.New("Use " + reMatched + " feeds plugin...")
+	}
+
+	feed, err := p.rssClient.ParseURLWithContext(feedURL, ctx)
+	if err != nil {
+		return nil, errors.New("RSS feed not found")
+	}
+
+	if len(feed.Items) == 0 {
+		return nil, errors.New("RSS feed has no items")
+	}
+
+	if feed.Items[0].PublishedParsed == nil {
+		return nil, errors.New("RSS feed has no valid formatting")
+	}
+
+	return feed, nil
+}
+
+// ImportOPML adds the feeds to the guild, mapping channels and roles by id if they are in the guild and
+// by name otherwise. Feeds without a matching channel go to defaultChannel, or fail if it's 0.
+func (p *Plugin) ImportOPML(ctx context.Context, gs *dstate.GuildSet, entries []*OPMLEntry, defaultChannel int64, maxFeeds int64) ([]*OPMLImportResult, error) {
+	existing, err := models.RSSFeeds(models.RSSFeedWhere.GuildID.EQ(gs.ID)).AllG(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	type followKey struct {
+		URL       string
+		ChannelID int64
+	}
+	followed := make(map[followKey]bool)
+	for _, sub := range existing {
+		followed[followKey{sub.FeedURL, sub.ChannelID}] = true
+	}
+
+	results := make([]*OPMLImportResult, len(entries))
+	feeds := make([]*gofeed.Feed, len(entries))
+	channels := make([]int64, len(entries))
+
+	// resolve everything that doesn't need the network first
+	for i, entry := range entries {
+		results[i] = &OPMLImportResult{
+			Name: strings.TrimSpace(entry.Text),
+			URL:  strings.TrimSpace(entry.XMLURL),
+		}
+		if results[i].Name == "" {
+			results[i].Name = results[i].URL
+		}
+
+		channels[i] = opmlEntryChannel(gs, entry, defaultChannel)
+		if channels[i] == 0 {
+			if entry.Channel != "" {
+				results[i].Message = "no channel named #" + entry.Channel + " and no default channel selected"
+			} else {
+				results[i].Message = "no channel in the file and no default channel selected"
+			}
+			continue
+		}
+
+		if _, err := ParseFilters(entry.IncludeFilters); err != nil {
+			results[i].Message = "include filters: " + err.Error()
+			continue
+		}
+
+		if _, err := ParseFilters(entry.ExcludeFilters); err != nil {
+			results[i].Message = "exclude filters: " + err.Error()
+			continue
+		}
+
+		key := followKey{results[i].URL, channels[i]}
+		if followed[key] {
+			results[i].Message = "already followed in this channel"
+			continue
+		}
+		followed[key] = true
+	}
+
+	// validate the feeds, a few at a time
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, opmlImportConcurrency)
+	for i := range entries {
+		if results[i].Message != "" {
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			fetchCtx, cancel := context.WithTimeout(ctx, feedFetchTimeout)
+			defer cancel()
+
+			feed, err := p.ValidateFeedURL(fetchCtx, results[i].URL)
+			if err != nil {
+				results[i].Message = err.Error()
+				return
+			}
+			feeds[i] = feed
+		}(i)
+	}
+	wg.Wait()
+
+	count := int64(len(existing))
+	for i, entry := range entries {
+		if feeds[i] == nil {
+			continue
+		}
+
+		if count >= maxFeeds {
+			results[i].Message = fmt.Sprintf("max %d RSS feeds reached", maxFeeds)
+			continue
+		}
+
+		name := strings.TrimSpace(entry.Text)
+		if name == feeds[i].Title {
+			name = ""
+		} else if runes := []rune(name); len(runes) > 50 {
+			name = string(runes[:50])
+		}
+
+		sub, err := p.AddRSSFeed(gs.ID, channels[i], opmlEntryRole(gs, entry), name, feeds[i].Title, results[i].URL)
+		if err != nil {
+			return results, err
+		}
+		count++
+
+		sub.IncludeFilters = strings.TrimSpace(entry.IncludeFilters)
+		sub.ExcludeFilters = strings.TrimSpace(entry.ExcludeFilters)
+		sub.Enabled = entry.Enabled != "false"
+		if sub.IncludeFilters != "" || sub.ExcludeFilters != "" || !sub.Enabled {
+			_, err = sub.UpdateG(ctx, boil.Whitelist("include_filters", "exclude_filters", "enabled"))
+			if err != nil {
+				return results, err
+			}
+		}
+
+		results[i].OK = true
+		results[i].Message = "added to #" + gs.GetChannel(channels[i]).Name
+	}
+
+	return results, nil
+}
+
+func opmlEntryChannel(gs *dstate.GuildSet, entry *OPMLEntry, defaultChannel int64) int64 {
+	if cs := gs.GetChannel(entry.ChannelID); cs != nil && isFeedChannel(cs) {
+		return cs.ID
+	}
+
+	if entry.Channel != "" {
+		name := strings.TrimPrefix(entry.Channel, "#")
+		for i := range gs.Channels {
+			if isFeedChannel(&gs.Channels[i]) && strings.EqualFold(gs.Channels[i].Name, name) {
+				return gs.Channels[i].ID
+			}
+		}
+	}
+
+	if defaultChannel != 0 && gs.GetChannel(defaultChannel) != nil {
+		return defaultChannel
+	}
+
+	return 0
+}
+
+func opmlEntryRole(gs *dstate.GuildSet, entry *OPMLEntry) int64 {
+	if role := gs.GetRole(entry.MentionRoleID); role != nil {
+		return role.ID
+	}
+
+	if entry.MentionRole != "" {
+		for _, role := range gs.Roles {
+			if strings.EqualFold(role.Name, entry.MentionRole) {
+				return role.ID
+			}
+		}
+	}
+
+	return 0
+}
+
+func isFeedChannel(cs *dstate.ChannelState) bool {
+	return cs.Type == discordgo.ChannelTypeGuildText || cs.Type == discordgo.ChannelTypeGuildNews
+}
+
+// opmlFileName returns the name of the export download
+func opmlFileName(guildID int64) string {
+	return "rss_feeds_" + strconv.FormatInt(guildID, 10) + ".opml"
+}
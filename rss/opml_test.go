@@ -0,0 +1,105 @@
+package rss
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mrbentarikau/pagst/lib/discordgo"
+	"github.com/mrbentarikau/pagst/lib/dstate"
+	"github.com/mrbentarikau/pagst/rss/models"
+)
+
+func testGuild() *dstate.GuildSet {
+	return &dstate.GuildSet{
+		GuildState: dstate.GuildState{ID: 1, Name: "Test"},
+		Channels: []dstate.ChannelState{
+			{ID: 10, Name: "news", Type: discordgo.ChannelTypeGuildText},
+			{ID: 11, Name: "Releases", Type: discordgo.ChannelTypeGuildNews},
+			{ID: 12, Name: "voice", Type: discordgo.ChannelTypeGuildVoice},
+		},
+		Roles: []discordgo.Role{{ID: 20, Name: "Readers"}},
+	}
+}
+
+func TestOPMLRoundTrip(t *testing.T) {
+	gs := testGuild()
+	subs := models.RSSFeedSlice{
+		{FeedName: "Blog", FeedTitle: "The Blog", FeedURL: "https://example.com/feed.xml", ChannelID: 10, MentionRole: 20, Enabled: true, IncludeFilters: "go\nrust"},
+		{FeedTitle: "Changelog", FeedURL: "https://example.com/changes.atom", ChannelID: 11},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteOPML(&buf, gs, subs); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ParseOPML(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	blog := entries[0]
+	if blog.Text != "Blog" || blog.XMLURL != "https://example.com/feed.xml" || blog.Channel != "news" || blog.MentionRole != "Readers" || blog.IncludeFilters != "go\nrust" || blog.Enabled != "" {
+		t.Errorf("unexpected first entry: %+v", blog)
+	}
+
+	changelog := entries[1]
+	if changelog.Text != "Changelog" || changelog.Channel != "Releases" || changelog.Enabled != "false" {
+		t.Errorf("unexpected second entry: %+v", changelog)
+	}
+}
+
+func TestParseOPMLFolders(t *testing.T) {
+	const doc = `<?xml version="1.0"?>
+<opml version="1.0">
+  <head><title>Subscriptions</title></head>
+  <body>
+    <outline text="Tech">
+      <outline type="rss" text="A" xmlUrl="https://a.example/rss"/>
+      <outline text="Nested"><outline type="rss" text="B" xmlUrl="https://b.example/rss"/></outline>
+    </outline>
+    <outline type="rss" text="C" xmlUrl="https://c.example/rss"/>
+  </body>
+</opml>`
+
+	entries, err := ParseOPML(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Text)
+	}
+
+	if strings.Join(names, ",") != "A,B,C" {
+		t.Errorf("expected A,B,C got %v", names)
+	}
+}
+
+func TestOPMLEntryChannel(t *testing.T) {
+	gs := testGuild()
+
+	cases := []struct {
+		Entry    *OPMLEntry
+		Default  int64
+		Expected int64
+	}{
+		{&OPMLEntry{ChannelID: 11, Channel: "news"}, 0, 11},
+		{&OPMLEntry{ChannelID: 999, Channel: "#releases"}, 0, 11},
+		{&OPMLEntry{Channel: "voice"}, 0, 0},
+		{&OPMLEntry{Channel: "voice"}, 10, 10},
+		{&OPMLEntry{}, 0, 0},
+	}
+
+	for i, c := range cases {
+		if got := opmlEntryChannel(gs, c.Entry, c.Default); got != c.Expected {
+			t.Errorf("case %d: got %d, expected %d", i, got, c.Expected)
+		}
+	}
+}
@@ -34,6 +34,8 @@ func (p *Plugin) WebhookAvatar() string {
 	return RSSIconPNGB64
 }
 
+// KeyLastRSSFeedTime and KeyLastRSSFeedLink were used to find new items before they were remembered by id,
+// they are only read to migrate feeds and removed after
 func KeyLastRSSFeedTime(feedURL string) string {
 	return "rss_last_feed_time:" + url.QueryEscape(feedURL)
 }
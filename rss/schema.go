@@ -26,5 +26,13 @@ CREATE TABLE IF NOT EXISTS rss_announcements (
 );
 `, `
 CREATE INDEX IF NOT EXISTS rss_announcements_guild_idx ON rss_announcements(guild_id);
+`, `
+ALTER TABLE rss_feeds ADD COLUMN IF NOT EXISTS include_filters TEXT NOT NULL DEFAULT '';
+`, `
+ALTER TABLE rss_feeds ADD COLUMN IF NOT EXISTS exclude_filters TEXT NOT NULL DEFAULT '';
+`, `
+ALTER TABLE rss_feeds ADD COLUMN IF NOT EXISTS forum_tags TEXT NOT NULL DEFAULT '';
+`, `
+ALTER TABLE rss_feeds ADD COLUMN IF NOT EXISTS thread_id BIGINT NOT NULL DEFAULT 0;
 `,
 }
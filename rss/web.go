@@ -5,14 +5,15 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"html"
 	"html/template"
 	"net/http"
-	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/mrbentarikau/pagst/common"
 	"github.com/mrbentarikau/pagst/common/cplogs"
+	"github.com/mrbentarikau/pagst/feeds"
 	"github.com/mrbentarikau/pagst/lib/discordgo"
 	"github.com/mrbentarikau/pagst/rss/models"
 	"github.com/mrbentarikau/pagst/web"
@@ -38,6 +39,7 @@ var (
 	panelLogKeyFeedAnnouncement = cplogs.RegisterActionFormat(&cplogs.ActionFormat{Key: "rss_feed_announcement", FormatString: "Updated RSS feed announcement"})
 	panelLogKeyRemovedFeed      = cplogs.RegisterActionFormat(&cplogs.ActionFormat{Key: "rss_removed_feed", FormatString: "Removed RSS feed %s"})
 	panelLogKeyUpdatedFeed      = cplogs.RegisterActionFormat(&cplogs.ActionFormat{Key: "rss_updated_feed", FormatString: "Updated RSS feed %s"})
+	panelLogKeyImportedFeeds    = cplogs.RegisterActionFormat(&cplogs.ActionFormat{Key: "rss_imported_feeds", FormatString: "Imported %d RSS feeds from OPML"})
 )
 
 type Form struct {
@@ -51,11 +53,54 @@ type Form struct {
 	Enabled            bool
 }
 
+type ImportOPMLForm struct {
+	DefaultChannel int64 `valid:"channel,true"`
+}
+
 type FormEdit struct {
 	FeedName       string `valid:",0,50"`
 	DiscordChannel int64  `valid:"channel,true"`
 	MentionRole    int64  `valid:"role,true"`
 	Enabled        bool
+	IncludeFilters string `valid:",5000"`
+	ExcludeFilters string `valid:",5000"`
+	ForumTags      string `valid:",2000"`
+	ThreadID       int64
+}
+
+func (f *FormEdit) Validate(tmpl web.TemplateData, guildID int64) (ok bool) {
+	if _, err := ParseFilters(f.IncludeFilters); err != nil {
+		tmpl.AddAlerts(web.ErrorAlert("Include filters: ", err))
+		return false
+	}
+
+	if _, err := ParseFilters(f.ExcludeFilters); err != nil {
+		tmpl.AddAlerts(web.ErrorAlert("Exclude filters: ", err))
+		return false
+	}
+
+	if _, err := feeds.ParseForumTagMapping(f.ForumTags); err != nil {
+		tmpl.AddAlerts(web.ErrorAlert("Forum tags: ", err))
+		return false
+	}
+
+	return true
+}
+
+// FilterPreviewItem is an item of the current feed and whether it passes the feed filters
+type FilterPreviewItem struct {
+	Title  string
+	Link   string
+	Posts  bool
+	Reason string
+}
+
+// FilterPreview is the result of testing unsaved filters of a feed, which are kept in the form
+type FilterPreview struct {
+	SubID          int64
+	IncludeFilters string
+	ExcludeFilters string
+	Items          []*FilterPreviewItem
 }
 
 func (p *Plugin) InitWeb() {
@@ -83,8 +128,11 @@ func (p *Plugin) InitWeb() {
 
 	rssMux.Handle(pat.Post(""), addHandler)
 	rssMux.Handle(pat.Post("/"), addHandler)
+	rssMux.Handle(pat.Get("/opml"), http.HandlerFunc(p.handleExportOPML))
+	rssMux.Handle(pat.Post("/opml"), web.ControllerPostHandler(p.HandleImportOPML, mainGetHandler, ImportOPMLForm{}))
 	rssMux.Handle(pat.Post("/handle_announce"), web.ControllerPostHandler(p.HandleAnnouncement, mainGetHandler, Form{}))
 	rssMux.Handle(pat.Post("/:item/update"), web.ControllerPostHandler(BaseEditHandler(p.HandleEdit), mainGetHandler, FormEdit{}))
+	rssMux.Handle(pat.Post("/:item/test_filters"), web.ControllerPostHandler(BaseEditHandler(p.HandleTestFilters), mainGetHandler, FormEdit{}))
 	rssMux.Handle(pat.Post("/:item/delete"), web.ControllerPostHandler(BaseEditHandler(p.HandleRemove), mainGetHandler, nil))
 	rssMux.Handle(pat.Get("/:item/delete"), web.ControllerPostHandler(BaseEditHandler(p.HandleRemove), mainGetHandler, nil))
 }
@@ -161,29 +209,10 @@ func (p *Plugin) HandleNew(w http.ResponseWriter, r *http.Request) (web.Template
 	formData := ctx.Value(common.ContextKeyParsedForm).(*Form)
 	url := formData.FeedURL
 
-	// validate URL
-	if !common.LinkRegexProtocolStrict.MatchString(url) {
-		return templateData.AddAlerts(web.ErrorAlert("This is not a valid HTTP(S) URL...")), nil
-	}
-
-	reRedditYT := regexp.MustCompile(`(reddit)(.com/)`)
-	if reRedditYT.MatchString(url) {
-		reMatched := reRedditYT.FindStringSubmatch(url)[1]
-		return templateData.AddAlerts(web.ErrorAlert("Use " + reMatched + " feeds plugin...")), nil
-	}
-
 	// search for the RSS feed
-	parsedURL, err := p.rssClient.ParseURL(url)
+	parsedURL, err := p.ValidateFeedURL(ctx, url)
 	if err != nil {
-		return templateData.AddAlerts(web.ErrorAlert("RSS feed not found")), nil
-	}
-
-	if len(parsedURL.Items) == 0 {
-		return templateData.AddAlerts(web.ErrorAlert("RSS feed has no items")), nil
-	}
-
-	if parsedURL.Items[0].PublishedParsed == nil {
-		return templateData.AddAlerts(web.ErrorAlert("RSS feed has no valid formatting")), nil
+		return templateData.AddAlerts(web.ErrorAlert(err)), nil
 	}
 
 	feedTitle := parsedURL.Title
@@ -225,14 +254,25 @@ func BaseEditHandler(inner web.ControllerHandlerFunc) web.ControllerHandlerFunc
 
 func (p *Plugin) HandleEdit(w http.ResponseWriter, r *http.Request) (templateData web.TemplateData, err error) {
 	ctx := r.Context()
-	_, templateData = web.GetBaseCPContextData(ctx)
+	activeGuild, templateData := web.GetBaseCPContextData(ctx)
 
 	sub := ctx.Value(ContextKeySub).(*models.RSSFeed)
 	data := ctx.Value(common.ContextKeyParsedForm).(*FormEdit)
 
+	// an archived thread isn't in the state, keep it if nothing changed
+	if data.ThreadID != sub.ThreadID || data.DiscordChannel != sub.ChannelID {
+		if err := feeds.ValidateThread(activeGuild, data.DiscordChannel, data.ThreadID); err != nil {
+			return templateData.AddAlerts(web.ErrorAlert("Thread: ", err)), nil
+		}
+	}
+
 	sub.ChannelID = data.DiscordChannel
+	sub.ThreadID = data.ThreadID
 	sub.MentionRole = data.MentionRole
 	sub.FeedName = strings.TrimSpace(data.FeedName)
+	sub.IncludeFilters = strings.TrimSpace(data.IncludeFilters)
+	sub.ExcludeFilters = strings.TrimSpace(data.ExcludeFilters)
+	sub.ForumTags = strings.TrimSpace(data.ForumTags)
 
 	if data.DiscordChannel == 0 {
 		sub.Enabled = false
@@ -240,13 +280,122 @@ func (p *Plugin) HandleEdit(w http.ResponseWriter, r *http.Request) (templateDat
 		sub.Enabled = data.Enabled
 	}
 
-	_, err = sub.UpdateG(ctx, boil.Whitelist("channel_id", "feed_name", "mention_role", "enabled"))
+	_, err = sub.UpdateG(ctx, boil.Whitelist("channel_id", "feed_name", "mention_role", "enabled", "include_filters", "exclude_filters", "forum_tags", "thread_id"))
 	if err == nil {
 		go cplogs.RetryAddEntry(web.NewLogEntryFromContext(r.Context(), panelLogKeyUpdatedFeed, &cplogs.Param{Type: cplogs.ParamTypeString, Value: sub.FeedURL}))
 	}
 	return
 }
 
+// HandleTestFilters runs the filters in the form, saved or not, against the current items of the feed
+func (p *Plugin) HandleTestFilters(w http.ResponseWriter, r *http.Request) (templateData web.TemplateData, err error) {
+	ctx := r.Context()
+	_, templateData = web.GetBaseCPContextData(ctx)
+
+	sub := ctx.Value(ContextKeySub).(*models.RSSFeed)
+	data := ctx.Value(common.ContextKeyParsedForm).(*FormEdit)
+
+	// validated by FormEdit.Validate
+	include, _ := ParseFilters(data.IncludeFilters)
+	exclude, _ := ParseFilters(data.ExcludeFilters)
+	itemFilters := &ItemFilters{Include: include, Exclude: exclude}
+
+	feed, err := p.rssClient.ParseURLWithContext(sub.FeedURL, ctx)
+	if err != nil {
+		return templateData.AddAlerts(web.ErrorAlert("Failed fetching the RSS feed")), nil
+	}
+
+	preview := &FilterPreview{
+		SubID:          sub.ID,
+		IncludeFilters: data.IncludeFilters,
+		ExcludeFilters: data.ExcludeFilters,
+	}
+	for _, item := range feed.Items {
+		posts, reason := itemFilters.Check(item)
+		preview.Items = append(preview.Items, &FilterPreviewItem{
+			Title:  common.CutStringShort(html.UnescapeString(item.Title), 240),
+			Link:   item.Link,
+			Posts:  posts,
+			Reason: reason,
+		})
+	}
+
+	templateData["FilterPreview"] = preview
+	return templateData, nil
+}
+
+// maxOPMLImportSize is the max size of an uploaded OPML file, 1MB
+const maxOPMLImportSize = 1000000
+
+func (p *Plugin) handleExportOPML(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	activeGuild, _ := web.GetBaseCPContextData(ctx)
+
+	subs, err := models.RSSFeeds(models.RSSFeedWhere.GuildID.EQ(activeGuild.ID), qm.OrderBy("id asc")).AllG(ctx)
+	if err != nil {
+		web.CtxLogger(ctx).WithError(err).Error("failed retrieving rss feeds for export")
+		http.Error(w, "Failed retrieving feeds", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/x-opml; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, opmlFileName(activeGuild.ID)))
+
+	err = WriteOPML(w, activeGuild, subs)
+	if err != nil {
+		web.CtxLogger(ctx).WithError(err).Error("failed exporting rss feeds")
+	}
+}
+
+func (p *Plugin) HandleImportOPML(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ctx := r.Context()
+	activeGuild, templateData := web.GetBaseCPContextData(ctx)
+	form := ctx.Value(common.ContextKeyParsedForm).(*ImportOPMLForm)
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return templateData.AddAlerts(web.ErrorAlert("No file uploaded")), nil
+	}
+	defer file.Close()
+
+	if header.Size > maxOPMLImportSize {
+		return templateData.AddAlerts(web.ErrorAlert("The file is larger than 1MB")), nil
+	}
+
+	entries, err := ParseOPML(file)
+	if err != nil {
+		return templateData.AddAlerts(web.ErrorAlert("Failed reading the OPML file: ", err.Error())), nil
+	}
+
+	if len(entries) == 0 {
+		return templateData.AddAlerts(web.ErrorAlert("The OPML file has no feeds")), nil
+	}
+
+	if len(entries) > GuildMaxFeedsPremium {
+		return templateData.AddAlerts(web.ErrorAlert(fmt.Sprintf("The OPML file has %d feeds, max %d can be imported", len(entries), GuildMaxFeedsPremium))), nil
+	}
+
+	results, err := p.ImportOPML(ctx, activeGuild, entries, form.DefaultChannel, MaxRSSFeedsForContext(ctx))
+	templateData["OPMLImportResults"] = results
+
+	imported := 0
+	for _, result := range results {
+		if result.OK {
+			imported++
+		}
+	}
+
+	if imported > 0 {
+		go cplogs.RetryAddEntry(web.NewLogEntryFromContext(ctx, panelLogKeyImportedFeeds, &cplogs.Param{Type: cplogs.ParamTypeInt, Value: int64(imported)}))
+	}
+
+	if err != nil {
+		return templateData, err
+	}
+
+	return templateData.AddAlerts(web.SucessAlert(fmt.Sprintf("Imported %d of %d feeds", imported, len(results)))), nil
+}
+
 func (p *Plugin) HandleRemove(w http.ResponseWriter, r *http.Request) (templateData web.TemplateData, err error) {
 	ctx := r.Context()
 	_, templateData = web.GetBaseCPContextData(ctx)
@@ -0,0 +1,105 @@
+package deadletters
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mrbentarikau/pagst/commands"
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/common/mqueue"
+	"github.com/mrbentarikau/pagst/lib/dcmd"
+	"github.com/mrbentarikau/pagst/stdcommands/util"
+)
+
+var Command = &commands.YAGCommand{
+	Cooldown:             2,
+	CmdCategory:          commands.CategoryDebug,
+	HideFromCommandsPage: true,
+	Name:                 "deadletters",
+	Aliases:              []string{"mqdead"},
+	Description:          "Inspects the messages the message queue gave up on.\n\n`list [source]` shows the number per source, or the newest of a source\n`replay <source> [id]` queues them again\n`purge <source> [id]` deletes them\n\nBot Owner Only.",
+	HideFromHelp:         true,
+	Arguments: []*dcmd.ArgDef{
+		{Name: "action", Type: dcmd.String, Default: "list"},
+		{Name: "source", Type: dcmd.String},
+		{Name: "id", Type: dcmd.BigInt},
+	},
+	RunFunc: util.RequireOwner(func(data *dcmd.Data) (interface{}, error) {
+		source := data.Args[1].Str()
+		id := data.Args[2].Int64()
+
+		switch strings.ToLower(data.Args[0].Str()) {
+		case "list":
+			if source == "" {
+				return listSources()
+			}
+
+			return listDeadLetters(source)
+		case "replay":
+			if source == "" {
+				return "Specify the source to replay", nil
+			}
+
+			n, err := mqueue.ReplayDeadLetters(source, id)
+			if err != nil {
+				return fmt.Sprintf("Replayed %d before failing: %v", n, err), err
+			}
+
+			return fmt.Sprintf("Queued %d dead letters from %s again", n, source), nil
+		case "purge":
+			if source == "" {
+				return "Specify the source to purge", nil
+			}
+
+			n, err := mqueue.PurgeDeadLetters(source, id)
+			if err != nil {
+				return nil, err
+			}
+
+			return fmt.Sprintf("Deleted %d dead letters from %s", n, source), nil
+		}
+
+		return "Unknown action, use list, replay or purge", nil
+	}),
+}
+
+func listSources() (interface{}, error) {
+	counts, err := mqueue.DeadLetterSources()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(counts) == 0 {
+		return "No dead letters", nil
+	}
+
+	var out strings.Builder
+	out.WriteString("```\n")
+	for _, c := range counts {
+		fmt.Fprintf(&out, "%-20s %d\n", c.Source, c.Count)
+	}
+	out.WriteString("```")
+
+	return out.String(), nil
+}
+
+func listDeadLetters(source string) (interface{}, error) {
+	deadLetters, err := mqueue.GetDeadLetters(source, 10)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(deadLetters) == 0 {
+		return "No dead letters from " + source, nil
+	}
+
+	var out strings.Builder
+	out.WriteString("```\n")
+	for _, dl := range deadLetters {
+		fmt.Fprintf(&out, "#%d %s g:%d c:%d item:%s attempts:%d\n  %s\n",
+			dl.ID, dl.FailedAt.UTC().Format("2006-01-02 15:04"), dl.GuildID, dl.ChannelID, dl.SourceItemID, dl.Attempts, common.CutStringShort(dl.LastError, 150))
+	}
+	out.WriteString("```")
+
+	return out.String(), nil
+}
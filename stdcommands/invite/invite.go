@@ -3,6 +3,7 @@ package invite
 import (
 	"github.com/mrbentarikau/pagst/commands"
 	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/common/i18n"
 	"github.com/mrbentarikau/pagst/lib/dcmd"
 )
 
@@ -13,6 +14,6 @@ var Command = &commands.YAGCommand{
 	RunInDM:     true,
 
 	RunFunc: func(data *dcmd.Data) (interface{}, error) {
-		return "Please add the bot through the website\nhttps://" + common.ConfHost.GetString(), nil
+		return i18n.T(i18n.LocaleFromData(data), "invite.response", common.ConfHost.GetString()), nil
 	},
 }
@@ -6,15 +6,18 @@ import (
 	"strings"
 
 	"github.com/mrbentarikau/pagst/commands"
+	"github.com/mrbentarikau/pagst/common"
 	"github.com/mrbentarikau/pagst/lib/dcmd"
 	"github.com/mrbentarikau/pagst/lib/dice"
 )
 
 var Command = &commands.YAGCommand{
-	CmdCategory:     commands.CategoryFun,
-	Name:            "Roll",
-	Description:     "Roll dices, specify nothing for 6 sides, specify a number for max sides, or rpg dice syntax.",
-	LongDescription: "Example: `-roll 2d6`",
+	CmdCategory: commands.CategoryFun,
+	Name:        "Roll",
+	Description: "Roll dices, specify nothing for 6 sides, specify a number for max sides, or rpg dice syntax.",
+	LongDescription: "Example: `-roll 2d6`\n\nDice expressions can be combined with `+ - * /` and parentheses, for example `-roll 2d20kh1+1d4+3`." +
+		"\n`dF` rolls Fate dice, `kh`/`kl`/`dh`/`dl` keep or drop dice, `ro1` rerolls ones once, `r<3` rerolls until 3 or higher, `!` explodes," +
+		"\n`>=8` counts successes instead of summing and `f1` subtracts failures, for example `-roll 10d10>=8f1`.",
 	Arguments: []*dcmd.ArgDef{
 		{Name: "Sides", Default: 0, Type: dcmd.Int},
 		{Name: "RPG-Dice", Type: dcmd.String},
@@ -31,8 +34,8 @@ var Command = &commands.YAGCommand{
 			}
 
 			output := r.String()
-			if len(output) > 100 {
-				output = output[:100] + "..."
+			if len(output) > 1000 {
+				output = common.CutStringShort(output, 1000)
 			} else {
 				output = strings.TrimSuffix(output, "([])")
 			}
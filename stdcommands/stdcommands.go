@@ -23,6 +23,7 @@ import (
 	"github.com/mrbentarikau/pagst/stdcommands/customembed"
 	"github.com/mrbentarikau/pagst/stdcommands/dadjoke"
 	"github.com/mrbentarikau/pagst/stdcommands/dcallvoice"
+	"github.com/mrbentarikau/pagst/stdcommands/deadletters"
 	"github.com/mrbentarikau/pagst/stdcommands/define"
 	"github.com/mrbentarikau/pagst/stdcommands/dictionary"
 	"github.com/mrbentarikau/pagst/stdcommands/dogfact"
@@ -147,6 +148,7 @@ func (p *Plugin) AddCommands() {
 		createinvite.Command,
 		currentshard.Command,
 		dcallvoice.Command,
+		deadletters.Command,
 		exportcustomcommands.Command,
 		exportuserdatabase.Command,
 		findserver.Command,
@@ -28,6 +28,7 @@ type TimezoneGuildConfig struct {
 	DisabledInChannels  types.Int64Array `boil:"disabled_in_channels" json:"disabled_in_channels,omitempty" toml:"disabled_in_channels" yaml:"disabled_in_channels,omitempty"`
 	EnabledInChannels   types.Int64Array `boil:"enabled_in_channels" json:"enabled_in_channels,omitempty" toml:"enabled_in_channels" yaml:"enabled_in_channels,omitempty"`
 	NewChannelsDisabled bool             `boil:"new_channels_disabled" json:"new_channels_disabled" toml:"new_channels_disabled" yaml:"new_channels_disabled"`
+	ConversionButton    bool             `boil:"conversion_button" json:"conversion_button" toml:"conversion_button" yaml:"conversion_button"`
 
 	R *timezoneGuildConfigR `boil:"-" json:"-" toml:"-" yaml:"-"`
 	L timezoneGuildConfigL  `boil:"-" json:"-" toml:"-" yaml:"-"`
@@ -38,11 +39,13 @@ var TimezoneGuildConfigColumns = struct {
 	DisabledInChannels  string
 	EnabledInChannels   string
 	NewChannelsDisabled string
+	ConversionButton    string
 }{
 	GuildID:             "guild_id",
 	DisabledInChannels:  "disabled_in_channels",
 	EnabledInChannels:   "enabled_in_channels",
 	NewChannelsDisabled: "new_channels_disabled",
+	ConversionButton:    "conversion_button",
 }
 
 var TimezoneGuildConfigTableColumns = struct {
@@ -50,11 +53,13 @@ var TimezoneGuildConfigTableColumns = struct {
 	DisabledInChannels  string
 	EnabledInChannels   string
 	NewChannelsDisabled string
+	ConversionButton    string
 }{
 	GuildID:             "timezone_guild_configs.guild_id",
 	DisabledInChannels:  "timezone_guild_configs.disabled_in_channels",
 	EnabledInChannels:   "timezone_guild_configs.enabled_in_channels",
 	NewChannelsDisabled: "timezone_guild_configs.new_channels_disabled",
+	ConversionButton:    "timezone_guild_configs.conversion_button",
 }
 
 // Generated where
@@ -120,11 +125,13 @@ var TimezoneGuildConfigWhere = struct {
 	DisabledInChannels  whereHelpertypes_Int64Array
 	EnabledInChannels   whereHelpertypes_Int64Array
 	NewChannelsDisabled whereHelperbool
+	ConversionButton    whereHelperbool
 }{
 	GuildID:             whereHelperint64{field: "\"timezone_guild_configs\".\"guild_id\""},
 	DisabledInChannels:  whereHelpertypes_Int64Array{field: "\"timezone_guild_configs\".\"disabled_in_channels\""},
 	EnabledInChannels:   whereHelpertypes_Int64Array{field: "\"timezone_guild_configs\".\"enabled_in_channels\""},
 	NewChannelsDisabled: whereHelperbool{field: "\"timezone_guild_configs\".\"new_channels_disabled\""},
+	ConversionButton:    whereHelperbool{field: "\"timezone_guild_configs\".\"conversion_button\""},
 }
 
 // TimezoneGuildConfigRels is where relationship names are stored.
@@ -144,9 +151,9 @@ func (*timezoneGuildConfigR) NewStruct() *timezoneGuildConfigR {
 type timezoneGuildConfigL struct{}
 
 var (
-	timezoneGuildConfigAllColumns            = []string{"guild_id", "disabled_in_channels", "enabled_in_channels", "new_channels_disabled"}
+	timezoneGuildConfigAllColumns            = []string{"guild_id", "disabled_in_channels", "enabled_in_channels", "new_channels_disabled", "conversion_button"}
 	timezoneGuildConfigColumnsWithoutDefault = []string{"guild_id", "new_channels_disabled"}
-	timezoneGuildConfigColumnsWithDefault    = []string{"disabled_in_channels", "enabled_in_channels"}
+	timezoneGuildConfigColumnsWithDefault    = []string{"disabled_in_channels", "enabled_in_channels", "conversion_button"}
 	timezoneGuildConfigPrimaryKeyColumns     = []string{"guild_id"}
 	timezoneGuildConfigGeneratedColumns      = []string{}
 )
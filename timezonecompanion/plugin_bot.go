@@ -26,6 +26,7 @@ var _ commands.CommandProvider = (*Plugin)(nil)
 
 func (p *Plugin) BotInit() {
 	eventsystem.AddHandlerAsyncLastLegacy(p, p.handleMessageCreate, eventsystem.EventMessageCreate)
+	eventsystem.AddHandlerAsyncLastLegacy(p, p.handleInteractionCreate, eventsystem.EventInteractionCreate)
 }
 
 func (p *Plugin) AddCommands() {
@@ -224,7 +225,58 @@ func (p *Plugin) AddCommands() {
 
 			return resp, nil
 		},
-	})
+	}, &commands.YAGCommand{
+		CmdCategory:         commands.CategoryTool,
+		Name:                "TimeConversionMode",
+		Aliases:             []string{"tconvmode", "tcm"},
+		Description:         "Sets how detected times are converted: `embed` replies with the time in everyones local time, `button` replies with a button that shows the conversion only to whoever clicks it",
+		RequireDiscordPerms: []int64{discordgo.PermissionManageMessages, discordgo.PermissionManageServer},
+		Arguments: []*dcmd.ArgDef{
+			{Name: "Mode", Type: dcmd.String},
+		},
+		RunFunc: func(parsed *dcmd.Data) (interface{}, error) {
+			conf, err := models.FindTimezoneGuildConfigG(parsed.Context(), parsed.GuildData.GS.ID)
+			insert := false
+			if err != nil {
+				if err != sql.ErrNoRows {
+					return nil, err
+				}
+
+				conf = &models.TimezoneGuildConfig{
+					GuildID: parsed.GuildData.GS.ID,
+				}
+				insert = true
+			}
+
+			current := "embed"
+			if conf.ConversionButton {
+				current = "button"
+			}
+
+			switch strings.ToLower(parsed.Args[0].Str()) {
+			case "":
+				return fmt.Sprintf("Time conversion mode is `%s`", current), nil
+			case "embed":
+				conf.ConversionButton = false
+			case "button":
+				conf.ConversionButton = true
+			default:
+				return "Unknown mode, use `embed` or `button`", nil
+			}
+
+			if insert {
+				err = conf.InsertG(parsed.Context(), boil.Infer())
+			} else {
+				_, err = conf.UpdateG(parsed.Context(), boil.Whitelist("conversion_button"))
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			return fmt.Sprintf("Time conversion mode set to `%s`", strings.ToLower(parsed.Args[0].Str())), nil
+		},
+	}, timeCommand)
 }
 
 func StrZone(zone string) string {
@@ -356,6 +408,16 @@ func (p *Plugin) handleMessageCreate(evt *eventsystem.EventData) {
 		return
 	}
 
+	if conf != nil && conf.ConversionButton {
+		common.BotSession.ChannelMessageSendComplex(m.ChannelID, &discordgo.MessageSend{
+			Content:         DiscordTimestamp(result.Time, "F"),
+			Components:      conversionComponents(result.Time),
+			Reference:       m.Reference(),
+			AllowedMentions: discordgo.AllowedMentions{},
+		})
+		return
+	}
+
 	common.BotSession.ChannelMessageSendEmbed(m.ChannelID, &discordgo.MessageEmbed{
 		Timestamp: result.Time.Format(time.RFC3339),
 		Footer: &discordgo.MessageEmbedFooter{
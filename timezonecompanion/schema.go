@@ -17,4 +17,6 @@ ALTER TABLE timezone_guild_configs ADD COLUMN IF NOT EXISTS new_channels_disable
 CREATE TABLE IF NOT EXISTS user_timezones(
 	user_id BIGINT PRIMARY KEY,
 	timezone_name TEXT NOT NULL
-);`}
+);`, `
+ALTER TABLE timezone_guild_configs ADD COLUMN IF NOT EXISTS conversion_button BOOLEAN NOT NULL DEFAULT FALSE;
+`}
@@ -0,0 +1,224 @@
+package timezonecompanion
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mrbentarikau/pagst/bot/eventsystem"
+	"github.com/mrbentarikau/pagst/commands"
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/common/templates"
+	"github.com/mrbentarikau/pagst/lib/dcmd"
+	"github.com/mrbentarikau/pagst/lib/discordgo"
+	"github.com/mrbentarikau/pagst/lib/when"
+	"github.com/mrbentarikau/pagst/lib/when/rules"
+	wcommon "github.com/mrbentarikau/pagst/lib/when/rules/common"
+	"github.com/mrbentarikau/pagst/lib/when/rules/en"
+	"github.com/mrbentarikau/pagst/timezonecompanion/trules"
+)
+
+// eventParser understands full expressions like "tomorrow 18:00" or "next friday 9pm",
+// unlike the message parser which only looks for times to keep false positives down
+var eventParser = newEventParser()
+
+func newEventParser() *when.Parser {
+	w := when.New(&rules.Options{
+		Distance:     10,
+		MatchByOrder: true})
+
+	w.Add(
+		en.Weekday(rules.Override),
+		en.CasualDate(rules.Override),
+		en.CasualTime(rules.Override),
+		trules.Hour(rules.Override),
+		trules.HourMinute(rules.Override),
+		en.Deadline(rules.Override),
+		en.ExactMonthDate(rules.Override),
+	)
+	w.Add(wcommon.All...)
+
+	return w
+}
+
+var ErrNoTimeFound = errors.New("no time found")
+
+// ParseEventTime parses a time expression relative to now in the location
+func ParseEventTime(input string, loc *time.Location) (time.Time, error) {
+	result, err := eventParser.Parse(input, time.Now().In(loc))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if result == nil {
+		return time.Time{}, ErrNoTimeFound
+	}
+
+	return result.Time, nil
+}
+
+// TimestampStyles are the discord timestamp formatting styles
+const TimestampStyles = "tTdDfFR"
+
+// DiscordTimestamp returns the discord timestamp markdown for t, which every viewer sees in their own time zone
+func DiscordTimestamp(t time.Time, style string) string {
+	if style == "" {
+		return fmt.Sprintf("<t:%d>", t.Unix())
+	}
+
+	return fmt.Sprintf("<t:%d:%s>", t.Unix(), style)
+}
+
+func validTimestampStyle(style string) bool {
+	return len(style) == 1 && strings.Contains(TimestampStyles, style)
+}
+
+func init() {
+	templates.RegisterSetupFunc(func(ctx *templates.Context) {
+		ctx.ContextFuncs["toDiscordTimestamp"] = tmplToDiscordTimestamp(ctx)
+	})
+}
+
+// tmplToDiscordTimestamp converts a time, unix timestamp or time expression to a discord timestamp,
+// time expressions like "tomorrow 18:00" are read in the time zone of the triggering user
+func tmplToDiscordTimestamp(ctx *templates.Context) interface{} {
+	return func(input interface{}, style ...string) (string, error) {
+		s := "f"
+		if len(style) > 0 {
+			s = style[0]
+			if !validTimestampStyle(s) {
+				return "", fmt.Errorf("unknown timestamp style %q, use one of %s", s, strings.Join(strings.Split(TimestampStyles, ""), ", "))
+			}
+		}
+
+		switch t := input.(type) {
+		case time.Time:
+			return DiscordTimestamp(t, s), nil
+		case *time.Time:
+			if t == nil {
+				return "", errors.New("nil time")
+			}
+			return DiscordTimestamp(*t, s), nil
+		case int, int32, int64, uint, uint32, uint64, float32, float64:
+			return DiscordTimestamp(time.Unix(templates.ToInt64(t), 0), s), nil
+		case string:
+			if ctx.IncreaseCheckGenericAPICall() {
+				return "", templates.ErrTooManyAPICalls
+			}
+
+			loc := time.UTC
+			if ctx.MS != nil {
+				if userLoc := GetUserTimezone(ctx.MS.User.ID); userLoc != nil {
+					loc = userLoc
+				}
+			}
+
+			parsed, err := ParseEventTime(t, loc)
+			if err != nil {
+				return "", fmt.Errorf("couldn't understand the time %q", t)
+			}
+
+			return DiscordTimestamp(parsed, s), nil
+		}
+
+		return "", fmt.Errorf("can't convert %T to a timestamp", input)
+	}
+}
+
+var timeCommand = &commands.YAGCommand{
+	CmdCategory:  commands.CategoryTool,
+	Name:         "Time",
+	Aliases:      []string{"timestamp", "ts"},
+	Description:  "Converts a time like \"tomorrow 18:00\" in your registered time zone (see `setz`) into discord timestamps, which everyone sees in their own time zone",
+	RequiredArgs: 1,
+	Arguments: []*dcmd.ArgDef{
+		{Name: "Time", Type: dcmd.String},
+	},
+	ApplicationCommandEnabled: true,
+	DefaultEnabled:            true,
+	RunFunc: func(parsed *dcmd.Data) (interface{}, error) {
+		loc := GetUserTimezone(parsed.Author.ID)
+		note := ""
+		if loc == nil {
+			loc = time.UTC
+			note = "\nYou don't have a registered time zone so UTC was used, set yours with the `setz` command"
+		}
+
+		t, err := ParseEventTime(parsed.Args[0].Str(), loc)
+		if err != nil {
+			return "Couldn't understand that time, try something like `tomorrow 18:00` or `friday 9pm`", nil
+		}
+
+		var out strings.Builder
+		fmt.Fprintf(&out, "%s (%s) in `%s`:\n", DiscordTimestamp(t, "F"), DiscordTimestamp(t, "R"), loc)
+		for _, style := range []string{"t", "f", "F", "R"} {
+			fmt.Fprintf(&out, "`%s` ", DiscordTimestamp(t, style))
+		}
+		out.WriteString(note)
+
+		return out.String(), nil
+	},
+}
+
+const convertCustomIDPrefix = "timezonecompanion_convert_"
+
+// conversionComponents returns the button that shows the time converted to the viewers time zone
+func conversionComponents(t time.Time) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "Show in my time zone",
+					Style:    discordgo.SecondaryButton,
+					Emoji:    &discordgo.ComponentEmoji{Name: "🕐"},
+					CustomID: convertCustomIDPrefix + strconv.FormatInt(t.Unix(), 10),
+				},
+			},
+		},
+	}
+}
+
+func (p *Plugin) handleInteractionCreate(evt *eventsystem.EventData) {
+	ic := evt.EvtInterface.(*discordgo.InteractionCreate)
+	if ic.Type != discordgo.InteractionMessageComponent {
+		return
+	}
+
+	customID := ic.MessageComponentData().CustomID
+	if !strings.HasPrefix(customID, convertCustomIDPrefix) {
+		return
+	}
+
+	unix, err := strconv.ParseInt(strings.TrimPrefix(customID, convertCustomIDPrefix), 10, 64)
+	if err != nil {
+		return
+	}
+
+	var userID int64
+	if ic.Member != nil {
+		userID = ic.Member.User.ID
+	} else if ic.User != nil {
+		userID = ic.User.ID
+	}
+
+	t := time.Unix(unix, 0)
+	content := fmt.Sprintf("%s (%s)", DiscordTimestamp(t, "F"), DiscordTimestamp(t, "R"))
+	if loc := GetUserTimezone(userID); loc != nil {
+		content += fmt.Sprintf("\nIn your time zone `%s` that's **%s**", loc, t.In(loc).Format("Mon Jan 2 15:04 MST"))
+	} else {
+		content += "\nRegister your time zone with the `setz` command to see the conversion here as well"
+	}
+
+	err = common.BotSession.CreateInteractionResponse(ic.ID, ic.Token, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   64,
+		},
+	})
+	if err != nil {
+		logger.WithError(err).Error("failed responding to time conversion interaction")
+	}
+}
@@ -0,0 +1,47 @@
+package timezonecompanion
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiscordTimestamp(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+
+	cases := []struct {
+		Style    string
+		Expected string
+	}{
+		{"", "<t:1700000000>"},
+		{"f", "<t:1700000000:f>"},
+		{"R", "<t:1700000000:R>"},
+	}
+
+	for _, c := range cases {
+		if got := DiscordTimestamp(ts, c.Style); got != c.Expected {
+			t.Errorf("style %q: got %q, expected %q", c.Style, got, c.Expected)
+		}
+	}
+
+	for _, style := range []string{"", "x", "ff", "r"} {
+		if validTimestampStyle(style) {
+			t.Errorf("style %q should not be valid", style)
+		}
+	}
+}
+
+func TestParseEventTime(t *testing.T) {
+	got, err := ParseEventTime("tomorrow 18:00", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := time.Now().UTC().AddDate(0, 0, 1)
+	if got.Hour() != 18 || got.Minute() != 0 || got.Day() != expected.Day() {
+		t.Errorf("got %s, expected tomorrow at 18:00", got)
+	}
+
+	if _, err = ParseEventTime("nothing here", time.UTC); err == nil {
+		t.Error("expected an error for input without a time")
+	}
+}
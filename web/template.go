@@ -227,6 +227,39 @@ func tmplChannelOpts(channelTypes []discordgo.ChannelType) interface{} {
 	}
 }
 
+// tmplThreadOpts returns the options of a thread picker: posting in the channel itself and the active threads of the channel
+func tmplThreadOpts(threads []dstate.ChannelState, parentID interface{}, selection interface{}) template.HTML {
+	parent := templates.ToInt64(parentID)
+	selected := templates.ToInt64(selection)
+
+	var builder strings.Builder
+	builder.WriteString(`<option value="0"`)
+	if selected == 0 {
+		builder.WriteString(" selected")
+	}
+	builder.WriteString(">None, post in the channel</option>\n")
+
+	found := selected == 0
+	for _, v := range threads {
+		if v.ParentID != parent || v.ID == 0 {
+			continue
+		}
+
+		builder.WriteString(`<option value="` + discordgo.StrID(v.ID) + `"`)
+		if v.ID == selected {
+			builder.WriteString(" selected")
+			found = true
+		}
+		builder.WriteString(">" + template.HTMLEscapeString(v.Name) + "</option>\n")
+	}
+
+	if !found {
+		builder.WriteString(`<option value="` + discordgo.StrID(selected) + `" selected>Unknown thread, archived or deleted</option>\n`)
+	}
+
+	return template.HTML(builder.String())
+}
+
 func tmplChannelOptsMulti(allowedChannelTypes []discordgo.ChannelType) func(channels []dstate.ChannelState, selections []int64) template.HTML {
 	return func(channels []dstate.ChannelState, selections []int64) template.HTML {
 		gen := &channelOptsHTMLGenState{allowedChannelTypes: allowedChannelTypes, channels: channels, selections: selections}
@@ -19,6 +19,7 @@ import (
 	"github.com/mrbentarikau/pagst/common/templates"
 	"github.com/mrbentarikau/pagst/feeds"
 	"github.com/mrbentarikau/pagst/lib/discordgo"
+	"github.com/mrbentarikau/pagst/lib/dstate"
 	"github.com/mrbentarikau/pagst/web/discorddata"
 
 	"github.com/mediocregopher/radix/v3"
@@ -66,6 +67,7 @@ func (p *Plugin) runWebsubChecker() {
 	go p.syncWebSubs()
 
 	websubTicker := time.NewTicker(WebSubCheckInterval)
+	livestreamTicker := time.NewTicker(LivestreamCheckInterval)
 	for {
 		select {
 		case wg := <-p.Stop:
@@ -73,6 +75,8 @@ func (p *Plugin) runWebsubChecker() {
 			return
 		case <-websubTicker.C:
 			p.checkExpiringWebsubs()
+		case <-livestreamTicker.C:
+			p.checkLivestreams()
 		}
 	}
 }
@@ -166,18 +170,47 @@ func (p *Plugin) syncWebSubs() {
 	}))
 }
 
-func (p *Plugin) sendNewVidMessage(sub *ChannelSubscription, ytVideo *youtube.Video) {
-	var content string
-
-	mentionRole := sub.MentionRole
-	mentionEveryone := sub.MentionEveryone
-
-	channelTitle := ytVideo.Snippet.ChannelTitle
-	liveBroadcastContent := ytVideo.Snippet.LiveBroadcastContent
+// VideoTemplateData is the data announcement templates get about the video
+func VideoTemplateData(sub *ChannelSubscription, ytVideo *youtube.Video, isShort bool) map[string]interface{} {
+	videoURL := "https://www.youtube.com/watch?v=" + ytVideo.Id
+	thumbnail := fmt.Sprintf("https://img.youtube.com/vi/%s/maxresdefault.jpg", ytVideo.Id)
+	parseMentionRole, _ := strconv.ParseInt(sub.MentionRole, 10, 64)
+
+	var videoDuration time.Duration
+	if ytVideo.ContentDetails != nil {
+		videoDurationString := strings.ToLower(strings.TrimPrefix(ytVideo.ContentDetails.Duration, "PT"))
+		videoDuration, _ = common.ParseDuration(videoDurationString)
+	}
+
+	return map[string]interface{}{
+		"ChannelName":          ytVideo.Snippet.ChannelTitle,
+		"ChannelID":            sub.YoutubeChannelID,
+		"ContentDetails":       ytVideo.ContentDetails,
+		"FullSnippet":          ytVideo.Snippet,
+		"LiveStream":           ytVideo.Snippet.LiveBroadcastContent,
+		"LiveStreamingDetails": ytVideo.LiveStreamingDetails,
+		"SelectedRoleID":       parseMentionRole,
+		"URL":                  videoURL,
+		"VideoID":              ytVideo.Id,
+		"VideoDescription":     ytVideo.Snippet.Description,
+		"VideoDurationSeconds": int(math.Round(videoDuration.Seconds())),
+		"VideoThumbnail":       thumbnail,
+
+		// the same names as in reddit announcements
+		"Title":      ytVideo.Snippet.Title,
+		"Author":     ytVideo.Snippet.ChannelTitle,
+		"Thumbnail":  thumbnail,
+		"IsShort":    isShort,
+		"IsLive":     ytVideo.Snippet.LiveBroadcastContent == "live",
+		"IsUpcoming": ytVideo.Snippet.LiveBroadcastContent == "upcoming",
+		"IsEnded":    LivestreamStatus(ytVideo) == LivestreamEnded,
+		"LiveStatus": LivestreamStatus(ytVideo),
+	}
+}
 
+func (p *Plugin) sendNewVidMessage(sub *ChannelSubscription, ytVideo *youtube.Video, isShort bool) {
 	parsedChannel, _ := strconv.ParseInt(sub.ChannelID, 10, 64)
 	parsedGuild, _ := strconv.ParseInt(sub.GuildID, 10, 64)
-	parseMentionRole, _ := strconv.ParseInt(mentionRole, 10, 64)
 
 	guildState, err := discorddata.GetFullGuild(parsedGuild)
 	if err != nil {
@@ -198,107 +231,166 @@ func (p *Plugin) sendNewVidMessage(sub *ChannelSubscription, ytVideo *youtube.Vi
 		return
 	}
 
-	videoURL := "https://www.youtube.com/watch?v=" + ytVideo.Id
-
-	videoDurationString := strings.ToLower(strings.TrimPrefix(ytVideo.ContentDetails.Duration, "PT"))
-	videoDuration, err := common.ParseDuration(videoDurationString)
+	content, publishAnnouncement, err := videoMessageContent(sub, guildState, channelState, ytVideo, isShort)
 	if err != nil {
-		videoDuration = time.Duration(0)
-	}
-
-	ctx := templates.NewContext(guildState, guildState.GetChannel(parsedChannel), nil) //needs GuildSet, ChannelState, MemberState
-	ctx.Data["ChannelName"] = channelTitle
-	ctx.Data["ChannelID"] = sub.YoutubeChannelID
-	ctx.Data["ContentDetails"] = ytVideo.ContentDetails
-	ctx.Data["FullSnippet"] = ytVideo.Snippet
-	ctx.Data["LiveStream"] = liveBroadcastContent
-	ctx.Data["LiveStreamingDetails"] = ytVideo.LiveStreamingDetails
-	ctx.Data["SelectedRoleID"] = parseMentionRole
-	ctx.Data["URL"] = videoURL
-	ctx.Data["VideoID"] = ytVideo.Id
-	ctx.Data["VideoDescription"] = ytVideo.Snippet.Description
-	ctx.Data["VideoDurationSeconds"] = int(math.Round(videoDuration.Seconds()))
-	ctx.Data["VideoThumbnail"] = fmt.Sprintf("https://img.youtube.com/vi/%s/maxresdefault.jpg", ytVideo.Id)
+		logger.WithError(err).WithField("guild", parsedGuild).Warn("Failed executing template on sendNewVidMessage")
+		return
+	}
+	if content == "" { // Nothing to do
+		return
+	}
+
+	go analytics.RecordActiveUnit(parsedGuild, p, "posted_youtube_message")
+	feeds.MetricPostedMessages.With(prometheus.Labels{"source": "youtube"}).Inc()
+
+	// livestream announcements are edited later on, for that we need to know where they end up
+	sourceItemID := ""
+	if status := LivestreamStatus(ytVideo); status == LivestreamUpcoming || status == LivestreamLive {
+		sourceItemID = ytVideo.Id + ":" + strconv.FormatUint(uint64(sub.ID), 10)
+	}
+
+	parsedThread, _ := strconv.ParseInt(sub.ThreadID, 10, 64)
+	mqueue.QueueMessage(&mqueue.QueuedElement{
+		GuildID:             parsedGuild,
+		ChannelID:           parsedChannel,
+		ThreadID:            parsedThread,
+		Source:              "youtube",
+		SourceItemID:        sourceItemID,
+		MessageStr:          content,
+		PublishAnnouncement: publishAnnouncement,
+		Priority:            2,
+		AllowedMentions:     videoAllowedMentions(sub),
+		ForumPost:           feeds.ForumPost(ytVideo.Snippet.Title, sub.ForumTags, videoForumLabels(ytVideo, isShort)),
+	})
+}
+
+// videoForumLabels returns the labels forum tags can be mapped from: the kind of video, the name of the channel and the tags of the video
+func videoForumLabels(ytVideo *youtube.Video, isShort bool) []string {
+	kind := "video"
+	switch status := LivestreamStatus(ytVideo); {
+	case status == LivestreamUpcoming:
+		kind = "upcoming"
+	case status == LivestreamLive:
+		kind = "live"
+	case status == LivestreamEnded:
+		kind = "livestream"
+	case isShort:
+		kind = "short"
+	}
+
+	labels := []string{kind, ytVideo.Snippet.ChannelTitle}
+	return append(labels, ytVideo.Snippet.Tags...)
+}
+
+// announcementTemplate returns the template the announcements of the subscription use,
+// empty if it uses the default format
+func announcementTemplate(sub *ChannelSubscription, guildID int64) string {
+	// the template of the feed takes precedence over the one of the server
+	if sub.AnnounceMsg != "" {
+		return sub.AnnounceMsg
+	}
 
 	var dbAnnounceMsg YoutubeAnnouncements
-	var announceMsg AnnouncementMessage
+	err := common.GORM.Model(&YoutubeAnnouncements{}).Where("guild_id = ?", guildID).First(&dbAnnounceMsg).Error
+	if err == nil && dbAnnounceMsg.Enabled {
+		return dbAnnounceMsg.Announcement
+	}
 
-	err = common.GORM.Model(&YoutubeAnnouncements{}).Where("guild_id = ?", parsedGuild).First(&dbAnnounceMsg).Error
-	if err == nil {
-		announceMsg.Enabled = dbAnnounceMsg.Enabled
-		announceMsg.AnnounceMsg = dbAnnounceMsg.Announcement
+	return ""
+}
+
+// needsIsShort returns whether the announcement of a video for the subscription depends on it being a short,
+// through the template or the forum tags, checking that takes a request to YouTube
+func needsIsShort(sub *ChannelSubscription) bool {
+	rules, _ := feeds.ParseForumTagMapping(sub.ForumTags)
+	for _, rule := range rules {
+		// the kind label is either of these for a video that isn't a livestream
+		if strings.EqualFold(rule.Label, "short") || strings.EqualFold(rule.Label, "video") {
+			return true
+		}
 	}
 
-	var publishAnnouncement bool
+	guildID, _ := strconv.ParseInt(sub.GuildID, 10, 64)
+	return strings.Contains(announcementTemplate(sub, guildID), "IsShort")
+}
 
-	if announceMsg.Enabled {
-		content, err = ctx.Execute(announceMsg.AnnounceMsg)
+// videoMessageContent renders the announcement of the video for the subscription,
+// an empty content means nothing should be posted
+func videoMessageContent(sub *ChannelSubscription, guildState *dstate.GuildSet, channelState *dstate.ChannelState, ytVideo *youtube.Video, isShort bool) (content string, publishAnnouncement bool, err error) {
+	mentionRole := sub.MentionRole
+	parseMentionRole, _ := strconv.ParseInt(mentionRole, 10, 64)
+	channelTitle := ytVideo.Snippet.ChannelTitle
+
+	ctx := templates.NewContext(guildState, channelState, nil) //needs GuildSet, ChannelState, MemberState
+	for k, v := range VideoTemplateData(sub, ytVideo, isShort) {
+		ctx.Data[k] = v
+	}
+
+	announcement := announcementTemplate(sub, guildState.ID)
+	if announcement != "" {
+		content, err = ctx.Execute(announcement)
 		if err != nil {
-			logger.WithError(err).WithField("guild", parsedGuild).Warn("Failed executing template on sendNewVidMessage")
-			return
-		}
-		if content == "" { // Nothing to do
-			return
+			return "", false, err
 		}
 
-		publishAnnouncement = ctx.CurrentFrame.PublishResponse
-	} else {
-		var scheduledAt, actualStartTime string
-		layout := "2006-01-02T15:04:05Z"
-		if ytVideo.LiveStreamingDetails != nil {
-			if scheduler := ytVideo.LiveStreamingDetails.ScheduledStartTime; scheduler != "" {
-				schedulerTime, _ := time.Parse(layout, scheduler)
-				scheduledAt = fmt.Sprintf("Scheduled <t:%d:R> at <t:%[1]d>...", schedulerTime.Unix())
-			}
+		return content, ctx.CurrentFrame.PublishResponse, nil
+	}
 
-			if startTime := ytVideo.LiveStreamingDetails.ActualStartTime; startTime != "" {
-				startedAtTime, _ := time.Parse(layout, startTime)
-				actualStartTime = fmt.Sprintf("Broadcast started <t:%d:R> at <t:%[1]d>...", startedAtTime.Unix())
-			}
+	videoURL := "https://www.youtube.com/watch?v=" + ytVideo.Id
+
+	var scheduledAt, actualStartTime string
+	layout := "2006-01-02T15:04:05Z"
+	if ytVideo.LiveStreamingDetails != nil {
+		if scheduler := ytVideo.LiveStreamingDetails.ScheduledStartTime; scheduler != "" {
+			schedulerTime, _ := time.Parse(layout, scheduler)
+			scheduledAt = fmt.Sprintf("Scheduled <t:%d:R> at <t:%[1]d>...", schedulerTime.Unix())
 		}
 
-		switch liveBroadcastContent {
-		case "live":
-			content = fmt.Sprintf("**%s** started a YouTube livestream now!\n%s", channelTitle, videoURL)
-		case "upcoming":
-			content = fmt.Sprintf("%s YouTube broadcast by **%s**! \n%s\n%s\n%s\n", liveBroadcastContent, channelTitle, scheduledAt, actualStartTime, videoURL)
-		case "none":
-			content = fmt.Sprintf("**%s** uploaded a new YouTube video!\n%s", channelTitle, videoURL)
-		default:
-			return
+		if startTime := ytVideo.LiveStreamingDetails.ActualStartTime; startTime != "" {
+			startedAtTime, _ := time.Parse(layout, startTime)
+			actualStartTime = fmt.Sprintf("Broadcast started <t:%d:R> at <t:%[1]d>...", startedAtTime.Unix())
 		}
+	}
 
-		if mentionEveryone {
-			content = fmt.Sprintf("Hey @everyone, %s", content)
-		} else if parseMentionRole > 0 {
-			content = fmt.Sprintf("Hey <@&%s>, %s", mentionRole, content)
-		} else {
-			content = fmt.Sprintf("Hey, %s", content)
+	switch LivestreamStatus(ytVideo) {
+	case LivestreamLive:
+		content = fmt.Sprintf("**%s** started a YouTube livestream now!\n%s\n%s", channelTitle, actualStartTime, videoURL)
+	case LivestreamUpcoming:
+		content = fmt.Sprintf("Upcoming YouTube broadcast by **%s**! \n%s\n%s\n", channelTitle, scheduledAt, videoURL)
+	case LivestreamEnded:
+		content = fmt.Sprintf("**%s** was live on YouTube for %s, the recording is available!\n%s", channelTitle,
+			common.HumanizeDuration(common.DurationPrecisionMinutes, livestreamDuration(ytVideo)), videoURL)
+	default:
+		if ytVideo.Snippet.LiveBroadcastContent != "none" {
+			return "", false, nil
 		}
+		content = fmt.Sprintf("**%s** uploaded a new YouTube video!\n%s", channelTitle, videoURL)
 	}
 
+	if sub.MentionEveryone {
+		content = fmt.Sprintf("Hey @everyone, %s", content)
+	} else if parseMentionRole > 0 {
+		content = fmt.Sprintf("Hey <@&%s>, %s", mentionRole, content)
+	} else {
+		content = fmt.Sprintf("Hey, %s", content)
+	}
+
+	return content, false, nil
+}
+
+func videoAllowedMentions(sub *ChannelSubscription) discordgo.AllowedMentions {
+	parseMentionRole, _ := strconv.ParseInt(sub.MentionRole, 10, 64)
+
 	parseMentions := []discordgo.AllowedMentionType{}
-	if mentionEveryone {
+	if sub.MentionEveryone {
 		parseMentions = []discordgo.AllowedMentionType{discordgo.AllowedMentionTypeEveryone}
 	} else if parseMentionRole > 0 {
 		parseMentions = []discordgo.AllowedMentionType{discordgo.AllowedMentionTypeRoles}
 	}
 
-	go analytics.RecordActiveUnit(parsedGuild, p, "posted_youtube_message")
-	feeds.MetricPostedMessages.With(prometheus.Labels{"source": "youtube"}).Inc()
-
-	mqueue.QueueMessage(&mqueue.QueuedElement{
-		GuildID:             parsedGuild,
-		ChannelID:           parsedChannel,
-		Source:              "youtube",
-		SourceItemID:        "",
-		MessageStr:          content,
-		PublishAnnouncement: publishAnnouncement,
-		Priority:            2,
-		AllowedMentions: discordgo.AllowedMentions{
-			Parse: parseMentions,
-		},
-	})
+	return discordgo.AllowedMentions{
+		Parse: parseMentions,
+	}
 }
 
 var (
@@ -559,6 +651,16 @@ func (p *Plugin) CheckVideo(parsedVideo XMLFeed) error {
 	videoID := parsedVideo.VideoId
 	channelID := parsedVideo.ChannelID
 
+	// an announced livestream that probably started or ended
+	tracked, err := isTrackedLivestream(videoID)
+	if err != nil {
+		return err
+	}
+
+	if tracked {
+		return p.checkLivestream(videoID)
+	}
+
 	parsedPublishedTime, err := time.Parse(time.RFC3339, parsedVideo.Published)
 	if err != nil {
 		return errors.New("Failed parsing YouTube timestamp: " + err.Error() + ": " + parsedVideo.Published)
@@ -628,9 +730,18 @@ func (p *Plugin) postVideo(subs []*ChannelSubscription, publishedAt time.Time, v
 	logger.Infof("Got a new video for channel %s, channelID %s with videoID %s, of type %s and publishing to %d subscriptions", video.Snippet.ChannelTitle, channelID, video.Id, contentType, len(subs))
 
 	isLivestream := contentType == "live" || contentType == "upcoming"
+	// checked only once, and only when needed as it takes a request to YouTube
 	isShortsCheckDone := false
 	isShorts := false
+	isShort := func() bool {
+		if !isShortsCheckDone {
+			isShorts = p.isShortsVideo(video)
+			isShortsCheckDone = true
+		}
+		return isShorts
+	}
 
+	announcedLivestream := false
 	for _, sub := range subs {
 		if sub.Enabled.Bool {
 			if isLivestream && !sub.PublishLivestream {
@@ -638,23 +749,21 @@ func (p *Plugin) postVideo(subs []*ChannelSubscription, publishedAt time.Time, v
 			}
 
 			//no need to check for shorts for a livestream
-			if !isLivestream && !sub.PublishShorts.Bool {
-				//check if a video is a short only when seeing the first shorts disabled subscription
-				//and cache in "isShorts" to reduce requests to YouTube to check for shorts.
-				if !isShortsCheckDone {
-					isShorts = p.isShortsVideo(video)
-					isShortsCheckDone = true
-				}
-
-				if isShorts {
-					continue
-				}
+			if !isLivestream && !sub.PublishShorts.Bool && isShort() {
+				continue
 			}
 
-			p.sendNewVidMessage(sub, video)
+			// templates and forum tags can tell shorts apart
+			p.sendNewVidMessage(sub, video, !isLivestream && (isShortsCheckDone || needsIsShort(sub)) && isShort())
+			announcedLivestream = announcedLivestream || isLivestream
 		}
 	}
 
+	if announcedLivestream {
+		// the announcements get edited when the stream starts and ends
+		return trackLivestream(video, channelID)
+	}
+
 	return nil
 }
 
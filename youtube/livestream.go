@@ -0,0 +1,276 @@
+package youtube
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mediocregopher/radix/v3"
+	"github.com/mrbentarikau/pagst/common"
+	"github.com/mrbentarikau/pagst/common/mqueue"
+	"github.com/mrbentarikau/pagst/lib/discordgo"
+	"github.com/mrbentarikau/pagst/web/discorddata"
+	"google.golang.org/api/youtube/v3"
+)
+
+const (
+	LivestreamUpcoming = "upcoming"
+	LivestreamLive     = "live"
+	LivestreamEnded    = "ended"
+
+	// WebSub doesn't always notify when a stream starts or ends, so the tracked ones are also checked every now and then
+	LivestreamCheckInterval = time.Minute * 5
+	// Scheduled streams can be far off, but not forever
+	LivestreamMaxTracked = time.Hour * 24 * 30
+
+	// Videos.List takes at most 50 ids at once
+	maxVideosPerList = 50
+)
+
+// LivestreamStatus returns the state of a livestream or premiere, or an empty string if the video is neither
+func LivestreamStatus(video *youtube.Video) string {
+	if details := video.LiveStreamingDetails; details != nil {
+		if details.ActualEndTime != "" {
+			return LivestreamEnded
+		}
+
+		if details.ActualStartTime != "" {
+			return LivestreamLive
+		}
+	}
+
+	switch video.Snippet.LiveBroadcastContent {
+	case "live":
+		return LivestreamLive
+	case "upcoming":
+		return LivestreamUpcoming
+	}
+
+	return ""
+}
+
+// livestreamDuration returns how long the stream went on for, falling back to the length of the recording
+func livestreamDuration(video *youtube.Video) time.Duration {
+	if details := video.LiveStreamingDetails; details != nil {
+		start, errStart := time.Parse(time.RFC3339, details.ActualStartTime)
+		end, errEnd := time.Parse(time.RFC3339, details.ActualEndTime)
+		if errStart == nil && errEnd == nil && end.After(start) {
+			return end.Sub(start).Round(time.Minute)
+		}
+	}
+
+	if video.ContentDetails != nil {
+		duration, _ := common.ParseDuration(strings.ToLower(strings.TrimPrefix(video.ContentDetails.Duration, "PT")))
+		return duration.Round(time.Minute)
+	}
+
+	return 0
+}
+
+// trackLivestream starts following an announced upcoming or live stream, so the announcements can be edited when it starts and ends
+func trackLivestream(video *youtube.Video, ytChannelID string) error {
+	status := LivestreamStatus(video)
+	if status != LivestreamUpcoming && status != LivestreamLive {
+		return nil
+	}
+
+	key := KeyLivestream(video.Id)
+	return common.MultipleCmds(
+		radix.Cmd(nil, "HSET", key, "status", status, "channel", ytChannelID),
+		radix.FlatCmd(nil, "EXPIRE", key, int(LivestreamMaxTracked.Seconds())),
+		radix.FlatCmd(nil, "ZADD", RedisKeyLivestreams, time.Now().Unix(), video.Id),
+	)
+}
+
+func untrackLivestream(videoID string) error {
+	return common.MultipleCmds(
+		radix.Cmd(nil, "DEL", KeyLivestream(videoID)),
+		radix.Cmd(nil, "ZREM", RedisKeyLivestreams, videoID),
+	)
+}
+
+func isTrackedLivestream(videoID string) (bool, error) {
+	mn := radix.MaybeNil{}
+	err := common.RedisPool.Do(radix.Cmd(&mn, "ZSCORE", RedisKeyLivestreams, videoID))
+	return !mn.Nil, err
+}
+
+var _ mqueue.PluginWithMessageSent = (*Plugin)(nil)
+
+// OnMessageSent remembers where the livestream announcements ended up, the source item id of those is "videoID:subID"
+func (p *Plugin) OnMessageSent(elem *mqueue.QueuedElement, msg *discordgo.Message) {
+	videoID, subID, ok := strings.Cut(elem.SourceItemID, ":")
+	if !ok {
+		return
+	}
+
+	key := KeyLivestream(videoID)
+	err := common.MultipleCmds(
+		radix.Cmd(nil, "HSET", key, "msg:"+subID, discordgo.StrID(msg.ChannelID)+":"+discordgo.StrID(msg.ID)),
+		radix.FlatCmd(nil, "EXPIRE", key, int(LivestreamMaxTracked.Seconds())),
+	)
+	if err != nil {
+		logger.WithError(err).WithField("video", videoID).Error("Failed saving YouTube livestream announcement")
+	}
+}
+
+// checkLivestreams updates the announcements of all tracked livestreams that changed state
+func (p *Plugin) checkLivestreams() {
+	maxScore := time.Now().Add(-LivestreamMaxTracked).Unix()
+	err := common.RedisPool.Do(radix.FlatCmd(nil, "ZREMRANGEBYSCORE", RedisKeyLivestreams, "-inf", maxScore))
+	if err != nil {
+		logger.WithError(err).Error("Failed removing old YouTube livestreams")
+		return
+	}
+
+	var videoIDs []string
+	err = common.RedisPool.Do(radix.Cmd(&videoIDs, "ZRANGE", RedisKeyLivestreams, "0", "-1"))
+	if err != nil {
+		logger.WithError(err).Error("Failed retrieving tracked YouTube livestreams")
+		return
+	}
+
+	for len(videoIDs) > 0 {
+		chunk := videoIDs
+		if len(chunk) > maxVideosPerList {
+			chunk = chunk[:maxVideosPerList]
+		}
+		videoIDs = videoIDs[len(chunk):]
+
+		resp, err := p.YTService.Videos.List([]string{"snippet", "contentDetails", "liveStreamingDetails"}).Id(chunk...).Do()
+		if err != nil {
+			logger.WithError(err).Error("Failed retrieving YouTube livestreams")
+			return
+		}
+
+		found := make(map[string]bool)
+		for _, video := range resp.Items {
+			found[video.Id] = true
+			if err := p.updateLivestream(video); err != nil {
+				logger.WithError(err).WithField("video", video.Id).Error("Failed updating YouTube livestream")
+			}
+		}
+
+		// deleted or made private
+		for _, videoID := range chunk {
+			if !found[videoID] {
+				untrackLivestream(videoID)
+			}
+		}
+	}
+}
+
+// checkLivestream handles WebSub notifications of tracked livestreams
+func (p *Plugin) checkLivestream(videoID string) error {
+	resp, err := p.YTService.Videos.List([]string{"snippet", "contentDetails", "liveStreamingDetails"}).Id(videoID).Do()
+	if err != nil {
+		return err
+	}
+
+	if len(resp.Items) < 1 {
+		return untrackLivestream(videoID)
+	}
+
+	return p.updateLivestream(resp.Items[0])
+}
+
+// updateLivestream edits the announcements of the livestream if it started or ended since they were posted
+func (p *Plugin) updateLivestream(video *youtube.Video) error {
+	lockKey := "youtube_livestream_lock:" + video.Id
+	locked, err := common.TryLockRedisKey(lockKey, 60)
+	if err != nil || !locked {
+		// someone else is on it already
+		return err
+	}
+	defer common.UnlockRedisKey(lockKey)
+
+	var state map[string]string
+	err = common.RedisPool.Do(radix.Cmd(&state, "HGETALL", KeyLivestream(video.Id)))
+	if err != nil {
+		return err
+	}
+
+	if len(state) == 0 {
+		// expired
+		return untrackLivestream(video.Id)
+	}
+
+	status := LivestreamStatus(video)
+	if status == "" {
+		// no longer a livestream, nothing to follow anymore
+		status = LivestreamEnded
+	}
+
+	if status == state["status"] {
+		return nil
+	}
+
+	var subs []*ChannelSubscription
+	err = common.GORM.Where("youtube_channel_id = ?", state["channel"]).Find(&subs).Error
+	if err != nil {
+		return err
+	}
+
+	subsByID := make(map[string]*ChannelSubscription)
+	for _, sub := range subs {
+		subsByID[strconv.FormatUint(uint64(sub.ID), 10)] = sub
+	}
+
+	for field, value := range state {
+		subID := strings.TrimPrefix(field, "msg:")
+		if subID == field {
+			continue
+		}
+
+		sub := subsByID[subID]
+		if sub == nil || !sub.Enabled.Bool {
+			continue
+		}
+
+		channelIDStr, messageIDStr, _ := strings.Cut(value, ":")
+		channelID, _ := strconv.ParseInt(channelIDStr, 10, 64)
+		messageID, _ := strconv.ParseInt(messageIDStr, 10, 64)
+		if channelID == 0 || messageID == 0 {
+			continue
+		}
+
+		p.editLivestreamMessage(sub, channelID, messageID, video)
+	}
+
+	if status == LivestreamEnded {
+		return untrackLivestream(video.Id)
+	}
+
+	return common.RedisPool.Do(radix.Cmd(nil, "HSET", KeyLivestream(video.Id), "status", status))
+}
+
+func (p *Plugin) editLivestreamMessage(sub *ChannelSubscription, channelID, messageID int64, video *youtube.Video) {
+	guildID, _ := strconv.ParseInt(sub.GuildID, 10, 64)
+
+	guildState, err := discorddata.GetFullGuild(guildID)
+	if err != nil || guildState == nil {
+		return
+	}
+
+	content, _, err := videoMessageContent(sub, guildState, guildState.GetChannel(channelID), video, false)
+	if err != nil {
+		logger.WithError(err).WithField("guild", guildID).Warn("Failed executing template on YouTube livestream update")
+		return
+	}
+	if content == "" {
+		return
+	}
+
+	edit := discordgo.NewMessageEdit(channelID, messageID).SetContent(content)
+	edit.AllowedMentions = videoAllowedMentions(sub)
+
+	_, err = common.BotSession.ChannelMessageEditComplex(edit)
+	if code, _ := common.DiscordError(err); code == discordgo.ErrCodeUnknownMessage || code == discordgo.ErrCodeUnknownChannel {
+		// the announcement was deleted
+		return
+	}
+
+	if err != nil {
+		logger.WithError(err).WithField("guild", guildID).Warn("Failed editing YouTube livestream announcement")
+	}
+}
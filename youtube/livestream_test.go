@@ -0,0 +1,54 @@
+package youtube
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/api/youtube/v3"
+)
+
+func TestLivestreamStatus(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Broadcast string
+		Details   *youtube.VideoLiveStreamingDetails
+		Expected  string
+	}{
+		{"upload", "none", nil, ""},
+		{"upcoming", "upcoming", &youtube.VideoLiveStreamingDetails{ScheduledStartTime: "2022-01-01T20:00:00Z"}, LivestreamUpcoming},
+		{"live", "live", &youtube.VideoLiveStreamingDetails{ActualStartTime: "2022-01-01T20:01:00Z"}, LivestreamLive},
+		{"started", "upcoming", &youtube.VideoLiveStreamingDetails{ActualStartTime: "2022-01-01T20:01:00Z"}, LivestreamLive},
+		{"ended", "none", &youtube.VideoLiveStreamingDetails{ActualStartTime: "2022-01-01T20:01:00Z", ActualEndTime: "2022-01-01T21:31:00Z"}, LivestreamEnded},
+	}
+
+	for _, c := range cases {
+		video := &youtube.Video{
+			Snippet:              &youtube.VideoSnippet{LiveBroadcastContent: c.Broadcast},
+			LiveStreamingDetails: c.Details,
+		}
+
+		if got := LivestreamStatus(video); got != c.Expected {
+			t.Errorf("%s: got %q, expected %q", c.Name, got, c.Expected)
+		}
+	}
+}
+
+func TestLivestreamDuration(t *testing.T) {
+	video := &youtube.Video{
+		Snippet: &youtube.VideoSnippet{LiveBroadcastContent: "none"},
+		LiveStreamingDetails: &youtube.VideoLiveStreamingDetails{
+			ActualStartTime: "2022-01-01T20:01:10Z",
+			ActualEndTime:   "2022-01-01T21:31:00Z",
+		},
+		ContentDetails: &youtube.VideoContentDetails{Duration: "PT1H2M"},
+	}
+
+	if got := livestreamDuration(video); got != time.Minute*90 {
+		t.Errorf("got %s, expected 1h30m", got)
+	}
+
+	video.LiveStreamingDetails.ActualEndTime = ""
+	if got := livestreamDuration(video); got != time.Minute*62 {
+		t.Errorf("got %s, expected the length of the recording", got)
+	}
+}
@@ -13,16 +13,19 @@ import (
 	"net/url"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jinzhu/gorm"
 	"github.com/mediocregopher/radix/v3"
 	"github.com/mrbentarikau/pagst/common"
 	"github.com/mrbentarikau/pagst/common/cplogs"
+	"github.com/mrbentarikau/pagst/feeds"
 	"github.com/mrbentarikau/pagst/lib/discordgo"
 	"github.com/mrbentarikau/pagst/web"
 	"goji.io"
 	"goji.io/pat"
+	"google.golang.org/api/youtube/v3"
 )
 
 //go:embed assets/youtube.html
@@ -43,7 +46,10 @@ type Form struct {
 	YoutubeURL         string
 	YoutubeAnnounceMsg string `json:"yt_announce_msg" valid:"template,2000"`
 	AnnounceEnabled    bool
-	DiscordChannel     int64 `valid:"channel,true"`
+	AnnounceMsg        string `valid:"template,2000"`
+	ForumTags          string `valid:",2000"`
+	DiscordChannel     int64  `valid:"channel,true"`
+	ThreadID           int64
 	ID                 uint
 	MentionEveryone    bool
 	MentionRole        int64 `valid:"role,true"`
@@ -52,6 +58,15 @@ type Form struct {
 	Enabled            bool
 }
 
+func (f *Form) Validate(tmpl web.TemplateData, guildID int64) (ok bool) {
+	if _, err := feeds.ParseForumTagMapping(f.ForumTags); err != nil {
+		tmpl.AddAlerts(web.ErrorAlert("Forum tags: ", err))
+		return false
+	}
+
+	return true
+}
+
 var (
 	ytChannelIDRegex  = regexp.MustCompile(`\AUC[\w\-]{21}[AQgw]\z`)
 	ytHandleRegex     = regexp.MustCompile(`\A@[\w\-.]{3,30}\z`)
@@ -87,6 +102,7 @@ func (p *Plugin) InitWeb() {
 	ytMux.Handle(pat.Post("/handle_announce"), web.ControllerPostHandler(p.HandleAnnouncement, mainGetHandler, Form{}))
 	ytMux.Handle(pat.Post("/:item/update"), web.ControllerPostHandler(BaseEditHandler(p.HandleEdit), mainGetHandler, Form{}))
 	ytMux.Handle(pat.Post("/:item/delete"), web.ControllerPostHandler(BaseEditHandler(p.HandleRemove), mainGetHandler, nil))
+	ytMux.Handle(pat.Post("/:item/preview"), web.ControllerPostHandler(BaseEditHandler(p.HandlePreview), mainGetHandler, Form{}))
 	ytMux.Handle(pat.Get("/:item/delete"), web.ControllerPostHandler(BaseEditHandler(p.HandleRemove), mainGetHandler, nil))
 
 	// The handler from pubsubhub
@@ -245,16 +261,33 @@ func (p *Plugin) HandleAnnouncement(w http.ResponseWriter, r *http.Request) (tem
 
 func (p *Plugin) HandleEdit(w http.ResponseWriter, r *http.Request) (templateData web.TemplateData, err error) {
 	ctx := r.Context()
-	_, templateData = web.GetBaseCPContextData(ctx)
+	activeGuild, templateData := web.GetBaseCPContextData(ctx)
 
 	sub := ctx.Value(ContextKeySub).(*ChannelSubscription)
 	data := ctx.Value(common.ContextKeyParsedForm).(*Form)
 
+	// an archived thread isn't in the state, keep it if nothing changed
+	threadID := ""
+	if data.ThreadID != 0 {
+		threadID = discordgo.StrID(data.ThreadID)
+	}
+	if threadID != sub.ThreadID || discordgo.StrID(data.DiscordChannel) != sub.ChannelID {
+		if err := feeds.ValidateThread(activeGuild, data.DiscordChannel, data.ThreadID); err != nil {
+			return templateData.AddAlerts(web.ErrorAlert("Thread: ", err)), nil
+		}
+	}
+
+	sub.ThreadID = threadID
 	sub.MentionEveryone = data.MentionEveryone
 	sub.PublishLivestream = data.PublishLivestream
 	sub.PublishShorts = sql.NullBool{Valid: true, Bool: data.PublishShorts}
 	sub.ChannelID = discordgo.StrID(data.DiscordChannel)
 	sub.MentionRole = discordgo.StrID(data.MentionRole)
+	sub.AnnounceMsg = data.AnnounceMsg
+	if strings.TrimSpace(sub.AnnounceMsg) == "" {
+		sub.AnnounceMsg = ""
+	}
+	sub.ForumTags = strings.TrimSpace(data.ForumTags)
 	if data.DiscordChannel == 0 {
 		sub.Enabled = sql.NullBool{Bool: false, Valid: false}
 	} else {
@@ -281,6 +314,62 @@ func (p *Plugin) HandleEdit(w http.ResponseWriter, r *http.Request) (templateDat
 	return
 }
 
+// AnnouncePreview is the announcement template of a feed rendered with an example video
+type AnnouncePreview struct {
+	SubID       uint
+	AnnounceMsg string
+	Content     string
+	Error       string
+}
+
+// previewVideo is the example video announcement previews are rendered with
+func previewVideo(sub *ChannelSubscription) *youtube.Video {
+	return &youtube.Video{
+		Id: "dQw4w9WgXcQ",
+		Snippet: &youtube.VideoSnippet{
+			Title:                "Example video",
+			ChannelTitle:         sub.YoutubeChannelName,
+			ChannelId:            sub.YoutubeChannelID,
+			Description:          "The description of the video",
+			LiveBroadcastContent: "none",
+		},
+		ContentDetails: &youtube.VideoContentDetails{
+			Duration: "PT3M33S",
+		},
+	}
+}
+
+// HandlePreview renders the announcement template of the form with an example video, without saving it
+func (p *Plugin) HandlePreview(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ctx := r.Context()
+	activeGuild, templateData := web.GetBaseCPContextData(ctx)
+
+	sub := ctx.Value(ContextKeySub).(*ChannelSubscription)
+	data := ctx.Value(common.ContextKeyParsedForm).(*Form)
+
+	preview := &AnnouncePreview{
+		SubID:       sub.ID,
+		AnnounceMsg: data.AnnounceMsg,
+	}
+	templateData["AnnouncePreview"] = preview
+
+	if strings.TrimSpace(data.AnnounceMsg) == "" {
+		preview.Error = "The template is empty, the default format is used"
+		return templateData, nil
+	}
+
+	channelID, _ := strconv.ParseInt(sub.ChannelID, 10, 64)
+	content, err := feeds.PreviewTemplate(activeGuild, activeGuild.GetChannel(channelID), data.AnnounceMsg, VideoTemplateData(sub, previewVideo(sub), false))
+	if err != nil {
+		preview.Error = err.Error()
+	} else if content == "" {
+		preview.Error = "The template produced no message, nothing would be sent"
+	}
+	preview.Content = content
+
+	return templateData, nil
+}
+
 func (p *Plugin) HandleRemove(w http.ResponseWriter, r *http.Request) (templateData web.TemplateData, err error) {
 	ctx := r.Context()
 	_, templateData = web.GetBaseCPContextData(ctx)
@@ -24,6 +24,9 @@ const (
 
 	RedisKeyWebSubChannels = "youtube_registered_websub_channels"
 	GoogleWebsubHub        = "https://pubsubhubbub.appspot.com/subscribe"
+
+	// RedisKeyLivestreams is a sorted set of the livestreams that are not over yet, scored by when they were announced
+	RedisKeyLivestreams = "youtube_tracked_livestreams"
 )
 
 var (
@@ -35,6 +38,9 @@ var (
 func KeyLastVidTime(channel string) string { return "youtube_last_video_time:" + channel }
 func KeyLastVidID(channel string) string   { return "youtube_last_video_id:" + channel }
 
+// KeyLivestream is a hash of the state of a livestream and the announcements of it
+func KeyLivestream(videoID string) string { return "youtube_livestream:" + videoID }
+
 type Plugin struct {
 	YTService *youtube.Service
 	Stop      chan *sync.WaitGroup
@@ -79,6 +85,12 @@ type ChannelSubscription struct {
 	PublishShorts      sql.NullBool `gorm:"default:true"`
 	PublishLivestream  bool
 	Enabled            sql.NullBool `sql:"DEFAULT:true"`
+	// AnnounceMsg is the template of the announcement, the server wide one or the default format is used if empty
+	AnnounceMsg string
+	// ForumTags maps the labels of videos to the tags of their posts when the channel is a forum, see feeds.ParseForumTagMapping
+	ForumTags string
+	// ThreadID is the thread of the channel the announcements are posted in, the channel itself if empty
+	ThreadID string
 }
 
 func (c *ChannelSubscription) TableName() string {